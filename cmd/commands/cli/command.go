@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	stdlog "log"
 	"path/filepath"
 	"strings"
@@ -34,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	"github.com/mysteriumnetwork/node/services/relay"
 	shared "github.com/mysteriumnetwork/node/services/shared"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
@@ -52,7 +54,8 @@ const serviceHelp = `service <action> [args]
 	list
 	sessions
 
-	example: service start 0x7d5ee3557775aed0b85d691b036769c17349db23 openvpn --openvpn.port=1194 --openvpn.proto=UDP`
+	example: service start 0x7d5ee3557775aed0b85d691b036769c17349db23 openvpn --openvpn.port=1194 --openvpn.proto=UDP
+	example: service start 0x7d5ee3557775aed0b85d691b036769c17349db23 wireguard --rate-limit.session=10000 --rate-limit.service=50000`
 
 var log = logconfig.NewLogger()
 
@@ -307,7 +310,7 @@ func (c *cliApp) serviceGet(id string) {
 func (c *cliApp) connect(argsString string) {
 	args := strings.Fields(argsString)
 
-	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [disable-kill-switch] [enable-dns]"
+	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [disable-kill-switch] [enable-dns] [failover] [disable-relay] [include=<destination,...>] [exclude=<destination,...>]"
 	if len(args) < 3 {
 		info(helpMsg)
 		return
@@ -317,13 +320,24 @@ func (c *cliApp) connect(argsString string) {
 
 	var disableKillSwitch bool
 	var enableDNS bool
+	var failover bool
+	var disableRelay bool
+	var include, exclude []string
 	var err error
 	for _, arg := range args[3:] {
-		switch arg {
-		case "enable-dns":
+		switch {
+		case arg == "enable-dns":
 			enableDNS = true
-		case "disable-kill-switch":
+		case arg == "disable-kill-switch":
 			disableKillSwitch = true
+		case arg == "failover":
+			failover = true
+		case arg == "disable-relay":
+			disableRelay = true
+		case strings.HasPrefix(arg, "include="):
+			include = strings.Split(strings.TrimPrefix(arg, "include="), ",")
+		case strings.HasPrefix(arg, "exclude="):
+			exclude = strings.Split(strings.TrimPrefix(arg, "exclude="), ",")
 		default:
 			warn("Unexpected arg:", arg)
 			info(helpMsg)
@@ -334,6 +348,10 @@ func (c *cliApp) connect(argsString string) {
 	connectOptions := tequilapi_client.ConnectOptions{
 		EnableDNS:         enableDNS,
 		DisableKillSwitch: disableKillSwitch,
+		Failover:          failover,
+		Include:           include,
+		Exclude:           exclude,
+		DisableRelay:      disableRelay,
 	}
 
 	if consumerID == "new" {
@@ -499,9 +517,20 @@ func (c *cliApp) natStatus() {
 	} else {
 		infof("NAT traversal status: %q (error: %q)\n", status.Status, status.Error)
 	}
+	if status.Stage != "" {
+		infof("NAT traversal stage: %q\n", status.Stage)
+	}
+	if status.Type != "" {
+		infof("NAT type: %q\n", status.Type)
+	}
 }
 
 func (c *cliApp) proposals(filter string) {
+	if strings.HasPrefix(filter, "where ") {
+		c.proposalsWhere(strings.TrimPrefix(filter, "where "))
+		return
+	}
+
 	proposals := c.fetchProposals()
 	c.fetchedProposals = proposals
 
@@ -528,6 +557,24 @@ func (c *cliApp) proposals(filter string) {
 	}
 }
 
+// proposalsWhere lists proposals matching the query expression in the order it defines
+func (c *cliApp) proposalsWhere(where string) {
+	proposals, err := c.tequilapi.ProposalsWhere(where)
+	if err != nil {
+		warn(err)
+		return
+	}
+
+	info(fmt.Sprintf("Found %v proposals (where: '%s')", len(proposals), where))
+	for _, proposal := range proposals {
+		country := proposal.ServiceDefinition.LocationOriginate.Country
+		if country == "" {
+			country = "Unknown"
+		}
+		info(fmt.Sprintf("- provider id: %v, proposal id: %v, country: %v", proposal.ProviderID, proposal.ID, country))
+	}
+}
+
 func (c *cliApp) fetchProposals() []tequilapi_client.ProposalDTO {
 	proposals, err := c.tequilapi.Proposals()
 	if err != nil {
@@ -559,20 +606,18 @@ func (c *cliApp) quit() {
 }
 
 func (c *cliApp) identities(argsString string) {
-	const usage = "identities command:\n    list\n    new [passphrase]"
+	const usage = "identities command:\n    list\n    new [passphrase]\n" +
+		"    export <identity> <file> [passphrase] [new passphrase]\n" +
+		"    import <file> [passphrase] [new passphrase]\n" +
+		"    import mnemonic <passphrase> <word>...\n" +
+		"    lock <identity>\n" +
+		"    passphrase <identity> <passphrase> <new passphrase>\n" +
+		"    earnings <identity> [day|week|month] [csv file]"
 	if len(argsString) == 0 {
 		info(usage)
 		return
 	}
 
-	switch argsString {
-	case "new", "list": // Known sub-commands.
-	default:
-		warnf("Unknown sub-command '%s'\n", argsString)
-		fmt.Println(usage)
-		return
-	}
-
 	args := strings.Fields(argsString)
 	if len(args) < 1 {
 		info(usage)
@@ -580,6 +625,14 @@ func (c *cliApp) identities(argsString string) {
 	}
 
 	action := args[0]
+	switch action {
+	case "new", "list", "export", "import", "lock", "passphrase", "earnings": // Known sub-commands.
+	default:
+		warnf("Unknown sub-command '%s'\n", argsString)
+		fmt.Println(usage)
+		return
+	}
+
 	if action == "list" {
 		if len(args) > 1 {
 			info(usage)
@@ -615,6 +668,147 @@ func (c *cliApp) identities(argsString string) {
 		}
 		success("New identity created:", id.Address)
 	}
+
+	if action == "export" {
+		c.exportIdentity(args[1:], usage)
+	}
+
+	if action == "import" {
+		c.importIdentity(args[1:], usage)
+	}
+
+	if action == "lock" {
+		if len(args) != 2 {
+			info(usage)
+			return
+		}
+		if err := c.tequilapi.Lock(args[1]); err != nil {
+			warn(err)
+			return
+		}
+		success("Identity locked:", args[1])
+	}
+
+	if action == "passphrase" {
+		if len(args) != 4 {
+			info(usage)
+			return
+		}
+		if err := c.tequilapi.ChangePassphrase(args[1], args[2], args[3]); err != nil {
+			warn(err)
+			return
+		}
+		success("Identity passphrase changed:", args[1])
+	}
+
+	if action == "earnings" {
+		c.identityEarnings(args[1:], usage)
+	}
+}
+
+func (c *cliApp) identityEarnings(args []string, usage string) {
+	if len(args) < 1 || len(args) > 3 {
+		info(usage)
+		return
+	}
+
+	period := "day"
+	if len(args) > 1 {
+		period = args[1]
+	}
+
+	if len(args) == 3 {
+		report, err := c.tequilapi.EarningsCSV(args[0], period)
+		if err != nil {
+			warn(err)
+			return
+		}
+		if err := ioutil.WriteFile(args[2], report, 0600); err != nil {
+			warn("Failed to write earnings file:", err)
+			return
+		}
+		success("Earnings exported to:", args[2])
+		return
+	}
+
+	report, err := c.tequilapi.Earnings(args[0], period)
+	if err != nil {
+		warn(err)
+		return
+	}
+	for _, summary := range report.Earnings {
+		status(
+			summary.DateStart,
+			fmt.Sprintf("%d (promises: %d, sessions: %d, consumers: %d)", summary.Amount, summary.Promises, summary.Sessions, summary.Consumers),
+		)
+	}
+	info("Total earned:", report.Total)
+}
+
+func (c *cliApp) exportIdentity(args []string, usage string) {
+	if len(args) < 2 || len(args) > 4 {
+		info(usage)
+		return
+	}
+
+	passphrase, newPassphrase := passphraseArgs(args[2:])
+	keyJSON, err := c.tequilapi.ExportIdentity(args[0], passphrase, newPassphrase)
+	if err != nil {
+		warn(err)
+		return
+	}
+	if err := ioutil.WriteFile(args[1], keyJSON, 0600); err != nil {
+		warn("Failed to write keystore file:", err)
+		return
+	}
+	success("Identity exported to:", args[1])
+}
+
+func (c *cliApp) importIdentity(args []string, usage string) {
+	if len(args) > 0 && args[0] == "mnemonic" {
+		if len(args) < 3 {
+			info(usage)
+			return
+		}
+		id, err := c.tequilapi.ImportIdentityFromMnemonic(strings.Join(args[2:], " "), "", args[1])
+		if err != nil {
+			warn(err)
+			return
+		}
+		success("Identity imported:", id.Address)
+		return
+	}
+
+	if len(args) < 1 || len(args) > 3 {
+		info(usage)
+		return
+	}
+
+	keyJSON, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		warn("Failed to read keystore file:", err)
+		return
+	}
+	passphrase, newPassphrase := passphraseArgs(args[1:])
+	id, err := c.tequilapi.ImportIdentity(keyJSON, passphrase, newPassphrase)
+	if err != nil {
+		warn(err)
+		return
+	}
+	success("Identity imported:", id.Address)
+}
+
+// passphraseArgs returns the passphrase and the new one, the new passphrase defaults to the old one
+func passphraseArgs(args []string) (string, string) {
+	passphrase := identityDefaultPassphrase
+	if len(args) > 0 {
+		passphrase = args[0]
+	}
+	newPassphrase := passphrase
+	if len(args) > 1 {
+		newPassphrase = args[1]
+	}
+	return passphrase, newPassphrase
 }
 
 func (c *cliApp) registration(argsString string) {
@@ -712,6 +906,7 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []tequilapi_
 				readline.PcItem("noop"),
 				readline.PcItem("openvpn"),
 				readline.PcItem("wireguard"),
+				readline.PcItem("relay"),
 			)),
 			readline.PcItem("stop"),
 			readline.PcItem("list"),
@@ -722,11 +917,27 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []tequilapi_
 			"identities",
 			readline.PcItem("new"),
 			readline.PcItem("list"),
+			readline.PcItem("export", readline.PcItemDynamic(
+				getIdentityOptionList(tequilapi),
+			)),
+			readline.PcItem("import", readline.PcItem("mnemonic")),
+			readline.PcItem("lock", readline.PcItemDynamic(
+				getIdentityOptionList(tequilapi),
+			)),
+			readline.PcItem("passphrase", readline.PcItemDynamic(
+				getIdentityOptionList(tequilapi),
+			)),
+			readline.PcItem("earnings", readline.PcItemDynamic(
+				getIdentityOptionList(tequilapi),
+				readline.PcItem("day"),
+				readline.PcItem("week"),
+				readline.PcItem("month"),
+			)),
 		),
 		readline.PcItem("status"),
 		readline.PcItem("healthcheck"),
 		readline.PcItem("nat"),
-		readline.PcItem("proposals"),
+		readline.PcItem("proposals", readline.PcItem("where")),
 		readline.PcItem("location"),
 		readline.PcItem("disconnect"),
 		readline.PcItem("help"),
@@ -781,6 +992,8 @@ func parseStartFlags(serviceType string, args ...string) (service.Options, share
 	switch serviceType {
 	case noop.ServiceType:
 		return noop.ParseFlags(ctx), shared.ConfiguredOptions(), nil
+	case relay.ServiceType:
+		return relay.ParseFlags(ctx), shared.ConfiguredOptions(), nil
 	case wireguard.ServiceType:
 		wireguard_service.Configure(ctx)
 		return wireguard_service.ConfiguredOptions(), shared.ConfiguredOptions(), nil
@@ -22,16 +22,18 @@ import (
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	"github.com/mysteriumnetwork/node/services/relay"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
 	"gopkg.in/urfave/cli.v1"
 )
 
 var (
-	serviceTypes = []string{"openvpn", "wireguard", "noop"}
+	serviceTypes = []string{"openvpn", "wireguard", "noop", "relay"}
 
 	serviceTypesFlagsParser = map[string]func(ctx *cli.Context) service.Options{
-		noop.ServiceType: noop.ParseFlags,
+		noop.ServiceType:  noop.ParseFlags,
+		relay.ServiceType: relay.ParseFlags,
 		openvpn.ServiceType: func(ctx *cli.Context) service.Options {
 			openvpn_service.Configure(ctx)
 			return openvpn_service.ConfiguredOptions()
@@ -23,11 +23,9 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/mysteriumnetwork/node/communication"
-	"github.com/mysteriumnetwork/node/communication/nats"
 	nats_dialog "github.com/mysteriumnetwork/node/communication/nats/dialog"
 	nats_discovery "github.com/mysteriumnetwork/node/communication/nats/discovery"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
@@ -38,8 +36,12 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery"
 	discovery_api "github.com/mysteriumnetwork/node/core/discovery/api"
 	discovery_broker "github.com/mysteriumnetwork/node/core/discovery/broker"
+	discovery_gossip "github.com/mysteriumnetwork/node/core/discovery/gossip"
+	"github.com/mysteriumnetwork/node/core/earnings"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/metrics"
+	"github.com/mysteriumnetwork/node/core/network"
 	"github.com/mysteriumnetwork/node/core/node"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/quality"
@@ -49,6 +51,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
 	"github.com/mysteriumnetwork/node/core/transactor"
+	"github.com/mysteriumnetwork/node/core/webhook"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/firewall/vnd"
@@ -59,7 +62,6 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/mysterium"
 	"github.com/mysteriumnetwork/node/metadata"
-	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/nat/mapping"
@@ -69,7 +71,7 @@ import (
 	"github.com/mysteriumnetwork/node/services"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
-	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
+	service_relay "github.com/mysteriumnetwork/node/services/relay"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/balance"
 	sessionevent "github.com/mysteriumnetwork/node/session/event"
@@ -122,6 +124,7 @@ type Authenticator interface {
 // NatPinger is responsible for pinging nat holes
 type NatPinger interface {
 	PingProvider(ip string, port int, consumerPort int, stop <-chan struct{}) error
+	RelayProvider(relay traversal.RelayParams, consumerPort int, stop <-chan struct{}) error
 	PingTarget(*traversal.Params)
 	BindServicePort(serviceType services.ServiceType, port int)
 	Start()
@@ -135,6 +138,7 @@ type NatPinger interface {
 type NatEventTracker interface {
 	ConsumeNATEvent(event event.Event)
 	LastEvent() *event.Event
+	LastStageEvent(stage string) *event.Event
 	WaitForEvent() event.Event
 }
 
@@ -149,6 +153,7 @@ type CacheResolver interface {
 	location.OriginResolver
 	HandleNodeEvent(se nodevent.Payload)
 	HandleConnectionEvent(connection.StateEvent)
+	HandleNetworkChangedEvent(network.ChangedEvent)
 }
 
 // UIServer represents our web server
@@ -173,39 +178,53 @@ type Dependencies struct {
 
 	NATService       nat.NATService
 	Storage          Storage
-	Keystore         *keystore.KeyStore
+	Keystore         identity.Keystore
 	PromiseStorage   *promise.Storage
 	IdentityManager  identity.Manager
+	IdentityBackup   identity.Backup
+	IdentityLocker   identity.Locker
+	IdentityCache    identity.IdentityCacheInterface
 	SignerFactory    identity.SignerFactory
 	IdentityRegistry identity_registry.IdentityRegistry
 	IdentitySelector identity_selector.Handler
 
 	DiscoveryFactory    service.DiscoveryFactory
 	DiscoveryFinder     *discovery.Finder
+	DiscoveryRepository *discovery.Repository
 	DiscoveryFetcherAPI *discovery_api.Fetcher
+	DiscoveryBroker     *nats_discovery.AddressNATS
+	DiscoveryListener   *discovery_broker.Listener
+	DiscoveryGossip     *discovery_gossip.Node
 
 	QualityMetricsSender *quality.Sender
 	QualityClient        *quality.MysteriumMORQA
 
 	IPResolver       ip.Resolver
 	LocationResolver CacheResolver
+	NetworkMonitor   *network.Monitor
 
-	StatisticsTracker  *statistics.SessionStatisticsTracker
-	StatisticsReporter *statistics.SessionStatisticsReporter
-	SessionStorage     *consumer_session.Storage
+	StatisticsTracker           *statistics.SessionStatisticsTracker
+	HopsTracker                 *statistics.HopStatisticsTracker
+	ConnectionStatisticsTracker *statistics.ConnectionStatisticsTracker
+	StatisticsReporter          *statistics.SessionStatisticsReporter
+	SessionStorage              *consumer_session.Storage
 
 	EventBus eventbus.EventBus
 
 	ConnectionManager  connection.Manager
+	ConnectionPool     connection.Pool
 	ConnectionRegistry *connection.Registry
 
 	ServicesManager       *service.Manager
 	ServiceRegistry       *service.Registry
 	ServiceSessionStorage serviceSessionStorage
+	ServiceSessionHistory *boltdb.SessionStorage
+	EarningsLedger        *earnings.Ledger
 
 	NATPinger      NatPinger
 	NATTracker     NatEventTracker
 	NATEventSender NatEventSender
+	NATClassifier  *traversal.Classifier
 
 	BandwidthTracker *bandwidth.Tracker
 
@@ -213,9 +232,20 @@ type Dependencies struct {
 
 	Authenticator    Authenticator
 	JWTAuthenticator JWTAuthenticator
+	TokenManager     *auth.TokenManager
+	Authorizer       *auth.Authorizer
 	UIServer         UIServer
 	SSEHandler       *sse.Handler
 	Transactor       Transactor
+
+	MetricsRegistry  *metrics.Registry
+	MetricsCollector *metrics.Collector
+
+	WebhookStorage         *boltdb.WebhookStorage
+	WebhookNotifier        *webhook.Notifier
+	IdentityBalanceTracker *identity.BalanceTracker
+
+	TokenStorage *boltdb.TokenStorage
 }
 
 // Bootstrap initiates all container dependencies
@@ -247,9 +277,12 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
 		return err
 	}
+	di.WebhookNotifier = webhook.NewNotifier(di.WebhookStorage, nodeOptions.Webhooks.LowBalance)
 
 	di.bootstrapEventBus()
-	di.bootstrapIdentityComponents(nodeOptions)
+	if err := di.bootstrapIdentityComponents(nodeOptions); err != nil {
+		return err
+	}
 
 	if err := di.bootstrapDiscoveryComponents(nodeOptions.Discovery); err != nil {
 		return err
@@ -267,11 +300,9 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
-	if err := di.bootstrapSSEHandler(); err != nil {
+	if err := di.bootstrapNATComponents(nodeOptions); err != nil {
 		return err
 	}
-
-	di.bootstrapNATComponents(nodeOptions)
 	if err := di.bootstrapServices(nodeOptions); err != nil {
 		return err
 	}
@@ -280,23 +311,72 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
+	if err := di.bootstrapSSEHandler(); err != nil {
+		return err
+	}
+
 	if err := di.bootstrapQualityComponents(nodeOptions.BindAddress, nodeOptions.Quality); err != nil {
 		return err
 	}
+	di.bootstrapMetrics()
 
 	di.bootstrapNodeComponents(nodeOptions, tequilaListener)
 
 	di.registerConnections(nodeOptions)
+	// relays are only looked up in the discovery, there are no connections to them
+	service_relay.Bootstrap()
 
 	if err = di.subscribeEventConsumers(); err != nil {
 		return err
 	}
-	if err = di.DiscoveryFetcherAPI.Start(); err != nil {
+	if err := di.Node.Start(); err != nil {
 		return err
 	}
-	if err := di.Node.Start(); err != nil {
+	// NAT type is detected in the background, it is published to the pinger and the state keeper once known
+	go di.NATClassifier.Detect()
+	// network changes are watched only once everything reacting to them is running
+	di.NetworkMonitor = network.NewMonitor(di.EventBus, di.IPResolver, network.DefaultSettleDuration)
+	// public IP is the one of the tunnel while connected, it is compared only when disconnected
+	err = di.EventBus.SubscribeAsync(connection.StateEventTopic, func(e connection.StateEvent) {
+		if e.ConnectionID == connection.DefaultConnectionID {
+			di.NetworkMonitor.TrackPublicIP(e.State == connection.NotConnected)
+		}
+	})
+	if err != nil {
 		return err
 	}
+	if err := di.NetworkMonitor.Start(); err != nil {
+		log.Warn("network changes will not be detected: ", err)
+	}
+	// balances of identities are checked only when low balance webhooks are enabled
+	if nodeOptions.Webhooks.LowBalance > 0 {
+		di.IdentityBalanceTracker = identity.NewBalanceTracker(
+			di.IdentityManager,
+			identity.NewBalance(di.EtherClient),
+			di.EventBus,
+			identity.DefaultBalanceCheckInterval,
+		)
+		di.IdentityBalanceTracker.Start()
+	}
+	// repository publishes proposal changes to SSE handler, which serves them only after node is started
+	if err = di.DiscoveryRepository.Start(); err != nil {
+		return err
+	}
+	if di.DiscoveryListener != nil {
+		if err = di.DiscoveryListener.Start(); err != nil {
+			return err
+		}
+	}
+	if di.DiscoveryGossip != nil {
+		if err = di.DiscoveryGossip.Start(); err != nil {
+			return err
+		}
+	}
+	if di.DiscoveryFetcherAPI != nil {
+		if err = di.DiscoveryFetcherAPI.Start(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -321,6 +401,10 @@ func (di *Dependencies) bootstrapStateKeeper(options node.Options) error {
 	if err != nil {
 		return err
 	}
+	err = di.EventBus.SubscribeAsync(traversal.NATTypeTopic, di.StateKeeper.ConsumeNATTypeEvent)
+	if err != nil {
+		return err
+	}
 	return di.EventBus.SubscribeAsync(event.Topic, di.StateKeeper.ConsumeNATEvent)
 }
 
@@ -345,13 +429,32 @@ func (di *Dependencies) registerNoopConnection() {
 
 // bootstrapSSEHandler bootstraps the SSEHandler and all of its dependencies
 func (di *Dependencies) bootstrapSSEHandler() error {
-	di.SSEHandler = sse.NewHandler(di.StateKeeper)
+	di.SSEHandler = sse.NewHandler(di.StateKeeper, di.BandwidthTracker, sse.DefaultStatisticsInterval)
 	err := di.EventBus.Subscribe(nodevent.Topic, di.SSEHandler.ConsumeNodeEvent)
 	if err != nil {
 		return err
 	}
 	err = di.EventBus.Subscribe(statevent.Topic, di.SSEHandler.ConsumeStateEvent)
-	return err
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(discovery.ProposalAddedTopic, di.SSEHandler.ConsumeProposalAdded)
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(connection.StateEventTopic, di.SSEHandler.ConsumeConnectionStateEvent)
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(connection.SessionEventTopic, di.SSEHandler.ConsumeSessionEvent)
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(connection.StatisticsEventTopic, di.SSEHandler.ConsumeStatisticsEvent)
+	if err != nil {
+		return err
+	}
+	return di.EventBus.Subscribe(discovery.ProposalRemovedTopic, di.SSEHandler.ConsumeProposalRemoved)
 }
 
 // Shutdown stops container
@@ -366,6 +469,9 @@ func (di *Dependencies) Shutdown() (err error) {
 		}
 	}()
 
+	if di.NetworkMonitor != nil {
+		di.NetworkMonitor.Stop()
+	}
 	if di.ServicesManager != nil {
 		if err := di.ServicesManager.Kill(); err != nil {
 			errs = append(errs, err)
@@ -376,14 +482,35 @@ func (di *Dependencies) Shutdown() (err error) {
 			errs = append(errs, err)
 		}
 	}
+	if di.DiscoveryListener != nil {
+		di.DiscoveryListener.Stop()
+	}
+	if di.DiscoveryBroker != nil {
+		di.DiscoveryBroker.Disconnect()
+	}
+	if di.DiscoveryGossip != nil {
+		di.DiscoveryGossip.Stop()
+	}
 	if di.DiscoveryFetcherAPI != nil {
 		di.DiscoveryFetcherAPI.Stop()
 	}
+	if di.DiscoveryRepository != nil {
+		di.DiscoveryRepository.Stop()
+	}
 	if di.Node != nil {
 		if err := di.Node.Kill(); err != nil {
 			errs = append(errs, err)
 		}
 	}
+	if di.IdentityBalanceTracker != nil {
+		di.IdentityBalanceTracker.Stop()
+	}
+	if di.WebhookNotifier != nil {
+		di.WebhookNotifier.Stop()
+	}
+	if externalKeystore, ok := di.Keystore.(*identity.KeystoreExternal); ok {
+		externalKeystore.Close()
+	}
 	if di.Storage != nil {
 		if err := di.Storage.Close(); err != nil {
 			errs = append(errs, err)
@@ -407,7 +534,14 @@ func (di *Dependencies) bootstrapStorage(path string) error {
 	}
 
 	di.Storage = localStorage
-	return nil
+
+	di.WebhookStorage = boltdb.NewWebhookStorage(localStorage)
+	di.TokenStorage = boltdb.NewTokenStorage(localStorage)
+
+	di.EarningsLedger = earnings.NewLedger(boltdb.NewEarningsStorage(localStorage))
+
+	di.ServiceSessionHistory, err = boltdb.NewSessionStorage(localStorage)
+	return err
 }
 
 func (di *Dependencies) subscribeEventConsumers() error {
@@ -424,12 +558,34 @@ func (di *Dependencies) subscribeEventConsumers() error {
 	if err != nil {
 		return err
 	}
+	err = di.EventBus.Subscribe(connection.SessionEventTopic, di.HopsTracker.ConsumeSessionEvent)
+	if err != nil {
+		return err
+	}
 
 	// statistics events
 	err = di.EventBus.Subscribe(connection.StatisticsEventTopic, di.StatisticsTracker.ConsumeStatisticsEvent)
 	if err != nil {
 		return err
 	}
+	err = di.EventBus.Subscribe(connection.HopStatisticsEventTopic, di.HopsTracker.ConsumeHopStatisticsEvent)
+	if err != nil {
+		return err
+	}
+
+	// events of every connection
+	err = di.EventBus.Subscribe(connection.StateEventTopic, di.ConnectionStatisticsTracker.ConsumeStateEvent)
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(connection.SessionEventTopic, di.ConnectionStatisticsTracker.ConsumeSessionEvent)
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(connection.StatisticsEventTopic, di.ConnectionStatisticsTracker.ConsumeStatisticsEvent)
+	if err != nil {
+		return err
+	}
 
 	// NAT events
 	err = di.EventBus.Subscribe(event.Topic, di.NATEventSender.ConsumeNATEvent)
@@ -458,7 +614,19 @@ func (di *Dependencies) subscribeEventConsumers() error {
 	if err != nil {
 		return err
 	}
-	return di.EventBus.SubscribeAsync(nodevent.Topic, di.QualityMetricsSender.SendStartupEvent)
+	err = di.EventBus.SubscribeAsync(nodevent.Topic, di.QualityMetricsSender.SendStartupEvent)
+	if err != nil {
+		return err
+	}
+
+	// Prometheus metrics
+	err = di.MetricsCollector.Subscribe(di.EventBus)
+	if err != nil {
+		return err
+	}
+
+	// Webhooks
+	return di.WebhookNotifier.Subscribe(di.EventBus)
 }
 
 func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, listener net.Listener) {
@@ -468,6 +636,8 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, listen
 	}
 
 	di.StatisticsTracker = statistics.NewSessionStatisticsTracker(time.Now)
+	di.HopsTracker = statistics.NewHopStatisticsTracker()
+	di.ConnectionStatisticsTracker = statistics.NewConnectionStatisticsTracker(time.Now)
 	di.StatisticsReporter = statistics.NewSessionStatisticsReporter(
 		di.StatisticsTracker,
 		di.MysteriumAPI,
@@ -479,12 +649,17 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, listen
 	di.PromiseStorage = promise.NewStorage(di.Storage)
 
 	di.ConnectionRegistry = connection.NewRegistry()
-	di.ConnectionManager = connection.NewManager(
+	connectionPool := connection.NewPool(connection.NewManager(
 		dialogFactory,
 		payment_factory.PaymentIssuerFactoryFunc(nodeOptions, di.SignerFactory),
 		di.ConnectionRegistry.CreateConnection,
 		di.EventBus,
-	)
+	))
+	di.ConnectionPool = connectionPool
+	di.ConnectionManager = connectionPool.Default()
+	if err := di.EventBus.SubscribeAsync(network.Topic, connectionPool.HandleNetworkChangedEvent); err != nil {
+		log.Error("failed to subscribe connections to network changes: ", err)
+	}
 
 	di.Transactor = transactor.NewTransactor(
 		nodeOptions.BindAddress,
@@ -497,26 +672,38 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, listen
 	router := tequilapi.NewAPIRouter()
 	tequilapi_endpoints.AddRouteForStop(router, utils.SoftKiller(di.Shutdown))
 	tequilapi_endpoints.AddRoutesForAuthentication(router, di.Authenticator, di.JWTAuthenticator)
+	tequilapi_endpoints.AddRoutesForAuthTokens(router, di.TokenManager)
 	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.IdentitySelector, di.IdentityRegistry)
-	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StatisticsTracker, di.DiscoveryFinder)
+	tequilapi_endpoints.AddRoutesForIdentitiesBackup(router, di.IdentityBackup)
+	if di.ServicesManager != nil {
+		tequilapi_endpoints.AddRoutesForIdentitiesLock(router, di.IdentityLocker, di.ConnectionPool, di.ServicesManager)
+	} else {
+		tequilapi_endpoints.AddRoutesForIdentitiesLock(router, di.IdentityLocker, di.ConnectionPool)
+	}
+	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StatisticsTracker, di.HopsTracker, di.DiscoveryFinder, di.QualityClient)
+	tequilapi_endpoints.AddRoutesForConnections(router, di.ConnectionPool, di.ConnectionStatisticsTracker, di.DiscoveryFinder, di.QualityClient)
 	tequilapi_endpoints.AddRoutesForConnectionSessions(router, di.SessionStorage)
 	tequilapi_endpoints.AddRoutesForConnectionLocation(router, di.ConnectionManager, di.IPResolver, di.LocationResolver, di.LocationResolver)
 	tequilapi_endpoints.AddRoutesForProposals(router, di.DiscoveryFinder, di.QualityClient)
 	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, serviceTypesRequestParser, nodeOptions.AccessPolicyEndpointAddress)
 	tequilapi_endpoints.AddRoutesForServiceSessions(router, di.StateKeeper)
+	tequilapi_endpoints.AddRoutesForServiceSessionHistory(router, di.ServiceSessionHistory)
+	tequilapi_endpoints.AddRoutesForEarnings(router, di.EarningsLedger)
+	tequilapi_endpoints.AddRoutesForWebhooks(router, di.WebhookStorage)
 	tequilapi_endpoints.AddRoutesForPayout(router, di.IdentityManager, di.SignerFactory, di.MysteriumAPI)
 	tequilapi_endpoints.AddRoutesForAccessPolicies(nodeOptions.BindAddress, router, nodeOptions.AccessPolicyEndpointAddress)
 	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper.GetState)
 	tequilapi_endpoints.AddRoutesForSSE(router, di.SSEHandler)
 	tequilapi_endpoints.AddRoutesForTransactor(router, di.Transactor)
 	tequilapi_endpoints.AddRoutesForConfig(router)
+	tequilapi_endpoints.AddRoutesForMetrics(router, di.MetricsRegistry)
 
 	identity_registry.AddIdentityRegistrationEndpoint(router, di.IdentityRegistry)
 
 	corsPolicy := tequilapi.NewMysteriumCorsPolicy()
-	httpAPIServer := tequilapi.NewServer(listener, router, corsPolicy)
+	httpAPIServer := tequilapi.NewServer(listener, tequilapi.RequireScopes(router, di.Authorizer, nodeOptions.TequilapiAuth), corsPolicy)
 
-	di.Node = node.NewNode(di.ConnectionManager, httpAPIServer, di.EventBus, di.NATPinger, di.UIServer)
+	di.Node = node.NewNode(di.ConnectionPool, httpAPIServer, di.EventBus, di.NATPinger, di.UIServer)
 }
 
 func newSessionManagerFactory(
@@ -529,17 +716,7 @@ func newSessionManagerFactory(
 	eventbus eventbus.EventBus,
 ) session.ManagerFactory {
 	return func(dialog communication.Dialog) *session.Manager {
-		providerBalanceTrackerFactory := func(consumerID, receiverID, issuerID identity.Identity) (session.BalanceTracker, error) {
-			timeTracker := session.NewTracker(time.Now)
-			// TODO: set the time and proper payment info
-			payment := dto.PaymentPerTime{
-				Price: money.Money{
-					Currency: money.CurrencyMyst,
-					Amount:   uint64(0),
-				},
-				Duration: time.Minute,
-			}
-			amountCalc := session.AmountCalc{PaymentDef: payment}
+		providerBalanceTrackerFactory := func(sessionID session.ID, consumerID, receiverID, issuerID identity.Identity) (session.BalanceTracker, error) {
 			sender := balance.NewBalanceSender(dialog)
 			promiseChan := make(chan promise.Message, 1)
 			listener := promise.NewListener(promiseChan)
@@ -549,9 +726,12 @@ func newSessionManagerFactory(
 			}
 
 			// TODO: the ints and times here need to be passed in as well, or defined as constants
-			tracker := balance.NewBalanceTracker(&timeTracker, amountCalc, 0)
+			tracker := payment_factory.NewBalanceTracker(proposal.PaymentMethod, func() session.DataTransfered {
+				sessionInstance, _ := sessionStorage.Find(sessionID)
+				return sessionInstance.DataTransfered
+			}, 0)
 			validator := validators.NewIssuedPromiseValidator(consumerID, receiverID, issuerID)
-			return session_payment.NewSessionBalance(sender, tracker, promiseChan, payment_factory.BalanceSendPeriod, payment_factory.PromiseWaitTimeout, validator, promiseStorage, consumerID, receiverID, issuerID), nil
+			return session_payment.NewSessionBalance(sender, tracker, promiseChan, payment_factory.BalanceSendPeriod, payment_factory.PromiseWaitTimeout, validator, promiseStorage, eventbus, string(sessionID), proposal.ServiceType, consumerID, receiverID, issuerID), nil
 		}
 		return session.NewManager(
 			proposal,
@@ -635,29 +815,85 @@ func (di *Dependencies) bootstrapEventBus() {
 	di.EventBus = eventbus.New()
 }
 
-func (di *Dependencies) bootstrapIdentityComponents(options node.Options) {
-	di.Keystore = identity.NewKeystoreFilesystem(options.Directories.Keystore, options.Keystore.UseLightweight)
-	di.IdentityManager = identity.NewIdentityManager(di.Keystore)
+func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error {
+	if options.Keystore.ExternalSigner != "" {
+		externalKeystore, err := identity.NewKeystoreExternal(options.Keystore.ExternalSigner)
+		if err != nil {
+			return err
+		}
+		di.Keystore = externalKeystore
+	} else {
+		di.Keystore = identity.NewKeystoreFilesystem(options.Directories.Keystore, options.Keystore.UseLightweight)
+	}
+	identityManager := identity.NewIdentityManager(di.Keystore)
+	di.IdentityManager = identityManager
+	di.IdentityBackup = identityManager
+	di.IdentityLocker = identityManager
+	di.IdentityCache = identity.NewIdentityCache(options.Directories.Keystore, "remember.json")
 	di.SignerFactory = func(id identity.Identity) identity.Signer {
 		return identity.NewSigner(di.Keystore, id)
 	}
 	di.IdentitySelector = identity_selector.NewHandler(
 		di.IdentityManager,
 		di.MysteriumAPI,
-		identity.NewIdentityCache(options.Directories.Keystore, "remember.json"),
+		di.IdentityCache,
 		di.SignerFactory,
 	)
-
+	return nil
 }
 
+// proposalMaxAge is the time after which the proposals which weren't fetched, pinged or gossiped are expired
+const proposalMaxAge = 3 * time.Minute
+
 func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscovery) error {
+	storage := discovery.NewStorage()
+	di.DiscoveryRepository = discovery.NewRepository(
+		storage,
+		boltdb.NewProposalSnapshotStorage(di.Storage),
+		di.EventBus,
+		proposalMaxAge,
+	)
+
 	var registry discovery.ProposalRegistry
+	fetchInterval := 30 * time.Second
 	switch options.Type {
 	case node.DiscoveryTypeAPI:
 		registry = discovery_api.NewRegistry(di.MysteriumAPI)
 	case node.DiscoveryTypeBroker:
-		sender := discovery_broker.NewSender(nats.NewConnectionMock())
-		registry = discovery_broker.NewRegistry(sender)
+		brokerAddress, err := nats_discovery.NewAddressFromHost(di.NetworkDefinition.BrokerAddress, "")
+		if err != nil {
+			return err
+		}
+		if err = brokerAddress.Connect(); err != nil {
+			return errors.Wrap(err, "failed to connect to broker")
+		}
+		di.DiscoveryBroker = brokerAddress
+
+		connection := brokerAddress.GetConnection()
+		registry = discovery_broker.NewRegistry(discovery_broker.NewSender(connection))
+		di.DiscoveryListener = discovery_broker.NewListener(discovery_broker.NewReceiver(connection), di.DiscoveryRepository)
+		// broker keeps proposals current, API is only needed to resync the ones missed,
+		// yet often enough for the fetched proposals not to expire in between
+		fetchInterval = proposalMaxAge - time.Minute
+	case node.DiscoveryTypeGossip:
+		di.DiscoveryGossip = discovery_gossip.NewNode(
+			discovery_gossip.Options{
+				Address:       options.GossipAddress,
+				PublicAddress: options.GossipPublicAddress,
+				Peers:         options.GossipPeers,
+				MaxPeers:      100,
+				Fanout:        3,
+				Interval:      5 * time.Second,
+				MaxAge:        proposalMaxAge,
+			},
+			di.DiscoveryRepository,
+			func(id identity.Identity) identity.Verifier {
+				return identity.NewVerifierIdentity(id)
+			},
+		)
+		registry = discovery_gossip.NewRegistry(di.DiscoveryGossip)
+		// peers keep proposals current, Mysterium API is not used at all
+		fetchInterval = 0
 	default:
 		return errors.Errorf("unknown discovery provider: %s", options.Type)
 	}
@@ -666,9 +902,10 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 		return discovery.NewService(di.IdentityRegistry, registry, di.SignerFactory, di.EventBus)
 	}
 
-	storage := discovery.NewStorage()
 	di.DiscoveryFinder = discovery.NewFinder(storage)
-	di.DiscoveryFetcherAPI = discovery_api.NewFetcher(storage, di.MysteriumAPI.Proposals, 30*time.Second)
+	if fetchInterval > 0 {
+		di.DiscoveryFetcherAPI = discovery_api.NewFetcher(di.DiscoveryRepository, di.MysteriumAPI.Proposals, fetchInterval)
+	}
 
 	return nil
 }
@@ -704,6 +941,11 @@ func (di *Dependencies) bootstrapQualityComponents(bindAddress string, options n
 	return nil
 }
 
+func (di *Dependencies) bootstrapMetrics() {
+	di.MetricsRegistry = metrics.NewRegistry()
+	di.MetricsCollector = metrics.NewCollector(di.MetricsRegistry)
+}
+
 func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err error) {
 	if _, err = firewall.AllowURLAccess(options.Location.IPDetectorURL); err != nil {
 		return errors.Wrap(err, "failed to add firewall exception")
@@ -742,6 +984,12 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 		return err
 	}
 
+	// location is refreshed before the other network change handlers are run
+	err = di.EventBus.Subscribe(network.Topic, di.LocationResolver.HandleNetworkChangedEvent)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -750,8 +998,11 @@ func (di *Dependencies) bootstrapAuthenticator() error {
 	if err != nil {
 		return err
 	}
+	jwtAuthenticator := auth.NewJWTAuthenticator(key)
 	di.Authenticator = auth.NewAuthenticator(di.Storage)
-	di.JWTAuthenticator = auth.NewJWTAuthenticator(key)
+	di.JWTAuthenticator = jwtAuthenticator
+	di.TokenManager = auth.NewTokenManager(di.TokenStorage)
+	di.Authorizer = auth.NewAuthorizer(jwtAuthenticator, di.TokenManager)
 
 	return nil
 }
@@ -766,20 +1017,32 @@ func (di *Dependencies) bootstrapBandwidthTracker() error {
 	return di.EventBus.SubscribeAsync(connection.StatisticsEventTopic, di.BandwidthTracker.ConsumeStatisticsEvent)
 }
 
-func (di *Dependencies) bootstrapNATComponents(options node.Options) {
+func (di *Dependencies) bootstrapNATComponents(options node.Options) error {
 	di.NATTracker = event.NewTracker()
+	di.NATClassifier = traversal.NewClassifier(options.STUNServers, di.EventBus)
+	if err := di.EventBus.SubscribeAsync(network.Topic, di.NATClassifier.HandleNetworkChangedEvent); err != nil {
+		return err
+	}
+	if err := di.EventBus.SubscribeAsync(network.Topic, mapping.HandleNetworkChangedEvent); err != nil {
+		return err
+	}
 	if options.ExperimentNATPunching {
 		log.Trace("experimental NAT punching enabled, creating a pinger")
-		di.NATPinger = traversal.NewPinger(
+		pinger := traversal.NewPinger(
 			di.NATTracker,
 			config.NewConfigParser(),
 			traversal.NewNATProxy(),
 			mapping.StageName,
 			di.EventBus,
 		)
+		if err := di.EventBus.Subscribe(traversal.NATTypeTopic, pinger.ConsumeNATTypeEvent); err != nil {
+			return err
+		}
+		di.NATPinger = pinger
 	} else {
 		di.NATPinger = &traversal.NoopPinger{}
 	}
+	return nil
 }
 
 func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
@@ -20,13 +20,18 @@
 package cmd
 
 import (
+	"net"
+	"strconv"
+
 	"github.com/mysteriumnetwork/node/communication"
 	nats_dialog "github.com/mysteriumnetwork/node/communication/nats/dialog"
 	nats_discovery "github.com/mysteriumnetwork/node/communication/nats/discovery"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/network"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat"
@@ -35,10 +40,13 @@ import (
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_discovery "github.com/mysteriumnetwork/node/services/openvpn/discovery"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn/service"
+	service_relay "github.com/mysteriumnetwork/node/services/relay"
+	"github.com/mysteriumnetwork/node/services/shared"
 	"github.com/mysteriumnetwork/node/services/wireguard"
 	wireguard_connection "github.com/mysteriumnetwork/node/services/wireguard/connection"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
 	"github.com/mysteriumnetwork/node/session"
+	sessionevent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/ui"
 	uinoop "github.com/mysteriumnetwork/node/ui/noop"
 	"github.com/pkg/errors"
@@ -54,6 +62,7 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 	di.bootstrapServiceOpenvpn(nodeOptions)
 	di.bootstrapServiceNoop(nodeOptions)
 	di.bootstrapServiceWireguard(nodeOptions)
+	di.bootstrapServiceRelay(nodeOptions)
 
 	return nil
 }
@@ -91,8 +100,13 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 				portPool = port.NewPool()
 			}
 
-			return wireguard_service.NewManager(di.IPResolver, di.NATService, mapPort, wgOptions, portPool),
-				wireguard_service.GetProposal(location), nil
+			dnsResolver, err := dns.NewResolver(shared.ConfiguredDNS())
+			if err != nil {
+				return nil, market.ServiceProposal{}, err
+			}
+
+			return wireguard_service.NewManager(di.IPResolver, di.NATService, mapPort, wgOptions, portPool, dnsResolver),
+				shared.WithConfiguredPayment(wireguard_service.GetProposal(location, wgOptions.RateLimit)), nil
 		},
 	)
 }
@@ -140,7 +154,9 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			Country: loc.Country,
 		}
 
-		proposal := openvpn_discovery.NewServiceProposalWithLocation(currentLocation, transportOptions.Protocol)
+		proposal := shared.WithConfiguredPayment(
+			openvpn_discovery.NewServiceProposalWithLocation(currentLocation, transportOptions.Protocol, transportOptions.OptionsRateLimit),
+		)
 
 		var portPool port.ServicePortSupplier
 		if transportOptions.Port != 0 {
@@ -149,6 +165,11 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			portPool = port.NewPool()
 		}
 
+		dnsResolver, err := dns.NewResolver(shared.ConfiguredDNS())
+		if err != nil {
+			return nil, market.ServiceProposal{}, err
+		}
+
 		manager := openvpn_service.NewManager(
 			nodeOptions,
 			transportOptions,
@@ -160,6 +181,9 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			di.NATTracker,
 			portPool,
 			di.EventBus,
+			service_relay.NewFinder(di.DiscoveryFinder),
+			di.SignerFactory,
+			dnsResolver,
 		)
 		return manager, proposal, nil
 	}
@@ -180,6 +204,40 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 	)
 }
 
+func (di *Dependencies) bootstrapServiceRelay(nodeOptions node.Options) {
+	di.ServiceRegistry.Register(
+		service_relay.ServiceType,
+		func(serviceOptions service.Options) (service.Service, market.ServiceProposal, error) {
+			location, err := di.LocationResolver.DetectLocation()
+			if err != nil {
+				return nil, market.ServiceProposal{}, err
+			}
+			outIP, err := di.IPResolver.GetOutboundIPAsString()
+			if err != nil {
+				return nil, market.ServiceProposal{}, err
+			}
+
+			relayPort, err := port.NewPool().Acquire()
+			if err != nil {
+				return nil, market.ServiceProposal{}, errors.Wrap(err, "failed to acquire relay port")
+			}
+
+			mapPort := func(port int) func() {
+				return mapping.GetPortMappingFunc(
+					location.IP,
+					outIP,
+					"UDP",
+					port,
+					"Myst node relay port mapping",
+					di.EventBus)
+			}
+
+			address := net.JoinHostPort(location.IP, strconv.Itoa(relayPort.Num()))
+			return service_relay.NewManager(relayPort.Num(), mapPort, di.IdentityRegistry), service_relay.GetProposal(location, address), nil
+		},
+	)
+}
+
 // bootstrapServiceComponents initiates ServicesManager dependency
 func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) error {
 	di.NATService = nat.NewService()
@@ -187,13 +245,21 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		log.Warn("Failed to enable NAT forwarding: ", err)
 	}
 	di.ServiceRegistry = service.NewRegistry()
-	storage := session.NewEventBasedStorage(di.EventBus, session.NewStorageMemory())
+	storage := session.NewEventBasedStorage(di.EventBus, di.ServiceSessionHistory)
 	di.ServiceSessionStorage = storage
 
 	err := storage.Subscribe()
 	if err != nil {
 		return errors.Wrap(err, "could not bootstrap service components")
 	}
+	err = di.EventBus.SubscribeAsync(sessionevent.Earnings, di.ServiceSessionHistory.ConsumeEarningsEvent)
+	if err != nil {
+		return errors.Wrap(err, "could not bootstrap service components")
+	}
+	err = di.EventBus.SubscribeAsync(sessionevent.PromiseReceived, di.EarningsLedger.ConsumePromiseEvent)
+	if err != nil {
+		return errors.Wrap(err, "could not bootstrap service components")
+	}
 
 	registeredIdentityValidator := func(peerID identity.Identity) error {
 		registered, err := di.IdentityRegistry.IsRegistered(peerID)
@@ -240,7 +306,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			di.NATTracker,
 			serviceID,
 			di.EventBus)
-		return session.NewDialogHandler(sessionManagerFactory, configProvider.ProvideConfig, di.PromiseStorage, identity.FromAddress(proposal.ProviderID))
+		return session.NewDialogHandler(sessionManagerFactory, configProvider.ProvideConfig, di.PromiseStorage, identity.FromAddress(proposal.ProviderID), di.EventBus)
 	}
 	di.ServicesManager = service.NewManager(
 		di.ServiceRegistry,
@@ -251,6 +317,10 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		nodeOptions.BindAddress,
 	)
 
+	if err := di.EventBus.SubscribeAsync(network.Topic, di.ServicesManager.HandleNetworkChangedEvent); err != nil {
+		return err
+	}
+
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessionStorage}
 	if err := di.EventBus.Subscribe(service.StatusTopic, serviceCleaner.HandleServiceStatus); err != nil {
 		log.Error("failed to subscribe service cleaner")
@@ -267,12 +337,12 @@ func (di *Dependencies) registerConnections(nodeOptions node.Options) {
 
 func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
 	wireguard.Bootstrap()
-	di.ConnectionRegistry.Register(wireguard.ServiceType, wireguard_connection.NewConnectionCreator(nodeOptions.Directories.Config))
+	di.ConnectionRegistry.Register(wireguard.ServiceType, wireguard_connection.NewConnectionCreator(nodeOptions.Directories.Config, nodeOptions.Wireguard.ReconnectTimeout))
 }
 
 func (di *Dependencies) bootstrapUIServer(options node.Options) {
 	if options.UI.UIEnabled {
-		di.UIServer = ui.NewServer(options.BindAddress, options.UI.UIPort, options.TequilapiPort, di.JWTAuthenticator)
+		di.UIServer = ui.NewServer(options.BindAddress, options.UI.UIPort, options.TequilapiPort, di.Authorizer)
 		return
 	}
 
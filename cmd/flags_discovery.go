@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/mysteriumnetwork/node/core/node"
 	"gopkg.in/urfave/cli.v1"
@@ -28,7 +29,7 @@ import (
 var (
 	discoveryTypeFlag = altsrc.NewStringFlag(cli.StringFlag{
 		Name:  "discovery.type",
-		Usage: fmt.Sprintf("Proposal discovery adapter. Options: { %s, %s }", node.DiscoveryTypeAPI, node.DiscoveryTypeBroker),
+		Usage: fmt.Sprintf("Proposal discovery adapter. Options: { %s, %s, %s }", node.DiscoveryTypeAPI, node.DiscoveryTypeBroker, node.DiscoveryTypeGossip),
 		Value: string(node.DiscoveryTypeAPI),
 	})
 	discoveryAddressFlag = altsrc.NewStringFlag(cli.StringFlag{
@@ -39,11 +40,26 @@ var (
 		),
 		Value: apiAddressFlag.Value,
 	})
+	discoveryGossipAddressFlag = altsrc.NewStringFlag(cli.StringFlag{
+		Name:  "discovery.gossip.address",
+		Usage: fmt.Sprintf("UDP address to exchange proposals with peers on, when '--%s=%s'", discoveryTypeFlag.Name, node.DiscoveryTypeGossip),
+		Value: ":4451",
+	})
+	discoveryGossipPublicAddressFlag = altsrc.NewStringFlag(cli.StringFlag{
+		Name:  "discovery.gossip.public-address",
+		Usage: "UDP address peers reach the node at, announced along with the proposals of the node, e.g. 1.2.3.4:4451",
+		Value: "",
+	})
+	discoveryGossipPeersFlag = altsrc.NewStringFlag(cli.StringFlag{
+		Name:  "discovery.gossip.peers",
+		Usage: "Comma separated addresses of peers to start exchanging proposals with, e.g. 1.2.3.4:4451,5.6.7.8:4451",
+		Value: "",
+	})
 )
 
 // RegisterFlagsDiscovery function register discovery flags to flag list
 func RegisterFlagsDiscovery(flags *[]cli.Flag) {
-	*flags = append(*flags, discoveryTypeFlag, discoveryAddressFlag)
+	*flags = append(*flags, discoveryTypeFlag, discoveryAddressFlag, discoveryGossipAddressFlag, discoveryGossipPublicAddressFlag, discoveryGossipPeersFlag)
 }
 
 // ParseFlagsDiscovery function fills in discovery options from CLI context
@@ -51,5 +67,19 @@ func ParseFlagsDiscovery(ctx *cli.Context) node.OptionsDiscovery {
 	return node.OptionsDiscovery{
 		Type:    node.DiscoveryType(ctx.GlobalString(discoveryTypeFlag.Name)),
 		Address: ctx.GlobalString(discoveryAddressFlag.Name),
+
+		GossipAddress:       ctx.GlobalString(discoveryGossipAddressFlag.Name),
+		GossipPublicAddress: ctx.GlobalString(discoveryGossipPublicAddressFlag.Name),
+		GossipPeers:         parseAddressList(ctx.GlobalString(discoveryGossipPeersFlag.Name)),
+	}
+}
+
+func parseAddressList(list string) []string {
+	addresses := make([]string, 0)
+	for _, address := range strings.Split(list, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
 	}
+	return addresses
 }
@@ -19,9 +19,11 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 	"gopkg.in/urfave/cli.v1"
 	"gopkg.in/urfave/cli.v1/altsrc"
 )
@@ -85,6 +87,11 @@ var (
 		Name:  "experiment-natpunching",
 		Usage: "Enables experimental NAT hole punching",
 	})
+	stunServersFlag = altsrc.NewStringFlag(cli.StringFlag{
+		Name:  "stun-servers",
+		Usage: "Comma separated list of two STUN servers used for NAT type detection, the first one has to support CHANGE-REQUEST",
+		Value: strings.Join(traversal.DefaultSTUNServers, ","),
+	})
 )
 
 // RegisterFlagsNetwork function register network flags to flag list
@@ -93,7 +100,7 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 		*flags,
 		testFlag, localnetFlag,
 		identityCheckFlag,
-		natPunchingFlag,
+		natPunchingFlag, stunServersFlag,
 		apiAddressFlag, apiAddressFlagDeprecated,
 		brokerAddressFlag,
 		etherRPCFlag, etherContractPaymentsFlag,
@@ -109,6 +116,7 @@ func ParseFlagsNetwork(ctx *cli.Context) node.OptionsNetwork {
 
 		ExperimentIdentityCheck: ctx.GlobalBool(identityCheckFlag.Name),
 		ExperimentNATPunching:   ctx.GlobalBool(natPunchingFlag.Name),
+		STUNServers:             parseAddressList(ctx.GlobalString(stunServersFlag.Name)),
 
 		MysteriumAPIAddress:         ctx.GlobalString(apiAddressFlag.Name),
 		AccessPolicyEndpointAddress: ctx.GlobalString(accessPolicyAddressFlag.Name),
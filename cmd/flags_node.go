@@ -36,10 +36,18 @@ var (
 		Usage: "Port for listening incoming api requests",
 		Value: 4050,
 	})
+	tequilapiAuthFlag = altsrc.NewBoolFlag(cli.BoolFlag{
+		Name:  "tequilapi.auth",
+		Usage: "Require JWT or API token credentials for all api requests, otherwise they are required only once any API token is issued",
+	})
 	keystoreLightweightFlag = altsrc.NewBoolFlag(cli.BoolFlag{
 		Name:  "keystore.lightweight",
 		Usage: "Determines the scrypt memory complexity. If set to true, will use 4MB blocks instead of the standard 256MB ones",
 	})
+	keystoreSignerFlag = altsrc.NewStringFlag(cli.StringFlag{
+		Name:  "keystore.signer",
+		Usage: "Local socket of the external signer holding the identity keys. If set, keys are not kept in the keystore directory",
+	})
 	bindAddressFlag = altsrc.NewStringFlag(cli.StringFlag{
 		Name:  "bind.address",
 		Usage: "IP address to bind to",
@@ -51,6 +59,7 @@ var (
 func ParseKeystoreFlags(ctx *cli.Context) node.OptionsKeystore {
 	return node.OptionsKeystore{
 		UseLightweight: ctx.GlobalBool(keystoreLightweightFlag.Name),
+		ExternalSigner: ctx.GlobalString(keystoreSignerFlag.Name),
 	}
 }
 
@@ -60,7 +69,7 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		return err
 	}
 
-	*flags = append(*flags, tequilapiAddressFlag, tequilapiPortFlag, keystoreLightweightFlag, bindAddressFlag)
+	*flags = append(*flags, tequilapiAddressFlag, tequilapiPortFlag, tequilapiAuthFlag, keystoreLightweightFlag, keystoreSignerFlag, bindAddressFlag)
 
 	RegisterFlagsNetwork(flags)
 	RegisterFlagsDiscovery(flags)
@@ -70,6 +79,8 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 	RegisterFlagsLocation(flags)
 	RegisterFlagsUI(flags)
 	RegisterFirewallFlags(flags)
+	RegisterFlagsWireguard(flags)
+	RegisterFlagsWebhooks(flags)
 
 	return nil
 }
@@ -82,6 +93,7 @@ func ParseFlagsNode(ctx *cli.Context) node.Options {
 
 		TequilapiAddress: ctx.GlobalString(tequilapiAddressFlag.Name),
 		TequilapiPort:    ctx.GlobalInt(tequilapiPortFlag.Name),
+		TequilapiAuth:    ctx.GlobalBool(tequilapiAuthFlag.Name),
 		UI:               ParseFlagsUI(ctx),
 		BindAddress:      ctx.GlobalString(bindAddressFlag.Name),
 
@@ -92,8 +104,10 @@ func ParseFlagsNode(ctx *cli.Context) node.Options {
 		Quality:        ParseFlagsQuality(ctx),
 		Location:       ParseFlagsLocation(ctx),
 		Transactor:     ParseFlagsTransactor(ctx),
+		Webhooks:       ParseFlagsWebhooks(ctx),
 
-		Openvpn: wrapper{nodeOptions: openvpn_core.ParseFlags(ctx)},
+		Openvpn:   wrapper{nodeOptions: openvpn_core.ParseFlags(ctx)},
+		Wireguard: ParseFlagsWireguard(ctx),
 
 		Firewall: ParseFirewallFlags(ctx),
 	}
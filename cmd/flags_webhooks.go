@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/mysteriumnetwork/node/core/node"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/urfave/cli.v1/altsrc"
+)
+
+var (
+	webhooksLowBalanceFlag = altsrc.NewUint64Flag(cli.Uint64Flag{
+		Name:  "webhooks.low-balance",
+		Usage: "Balance of node identity below which the 'low-balance' webhooks are notified, 0 disables the notifications",
+		Value: 0,
+	})
+)
+
+// RegisterFlagsWebhooks function register webhook flags to flag list
+func RegisterFlagsWebhooks(flags *[]cli.Flag) {
+	*flags = append(*flags, webhooksLowBalanceFlag)
+}
+
+// ParseFlagsWebhooks function fills in webhook options from CLI context
+func ParseFlagsWebhooks(ctx *cli.Context) node.OptionsWebhooks {
+	return node.OptionsWebhooks{
+		LowBalance: ctx.GlobalUint64(webhooksLowBalanceFlag.Name),
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	wireguardReconnectTimeout = cli.DurationFlag{
+		Name:  "wireguard.reconnect.timeout",
+		Usage: "Time the wireguard connection without the provider handshake keeps reconnecting before giving up",
+		Value: time.Minute,
+	}
+)
+
+// RegisterFlagsWireguard registers flags to control wireguard consumer connections
+func RegisterFlagsWireguard(flags *[]cli.Flag) {
+	*flags = append(*flags, wireguardReconnectTimeout)
+}
+
+// ParseFlagsWireguard parses registered flags and puts them into options structure
+func ParseFlagsWireguard(ctx *cli.Context) node.OptionsWireguard {
+	return node.OptionsWireguard{
+		ReconnectTimeout: ctx.GlobalDuration(wireguardReconnectTimeout.Name),
+	}
+}
@@ -18,6 +18,7 @@
 package dialog
 
 import (
+	"crypto/cipher"
 	"encoding/json"
 
 	"github.com/mysteriumnetwork/node/communication"
@@ -41,10 +42,24 @@ func NewCodecSecured(
 	}
 }
 
+// newCodecSealed returns secured codec which also seals payloads with the given AEAD before signing them,
+// so the broker relaying the messages is not able to read them
+func newCodecSealed(
+	codecPacker communication.Codec,
+	signer identity.Signer,
+	verifier identity.Verifier,
+	sealing cipher.AEAD,
+) *codecSecured {
+	codec := NewCodecSecured(codecPacker, signer, verifier)
+	codec.sealing = sealing
+	return codec
+}
+
 type codecSecured struct {
 	codecPacker communication.Codec
 	signer      identity.Signer
 	verifier    identity.Verifier
+	sealing     cipher.AEAD
 }
 
 func (codec *codecSecured) Pack(payloadPtr interface{}) ([]byte, error) {
@@ -53,6 +68,17 @@ func (codec *codecSecured) Pack(payloadPtr interface{}) ([]byte, error) {
 		return []byte{}, err
 	}
 
+	if codec.sealing != nil {
+		sealed, err := seal(codec.sealing, payloadData)
+		if err != nil {
+			return []byte{}, err
+		}
+		payloadData, err = codec.codecPacker.Pack(&sealedPayload{Sealed: sealed})
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+
 	signature, err := codec.signer.Sign(payloadData)
 	if err != nil {
 		return []byte{}, err
@@ -75,10 +101,31 @@ func (codec *codecSecured) Unpack(data []byte, payloadPtr interface{}) error {
 		return errors.Errorf("invalid message signature '%s'", envelope.Signature)
 	}
 
-	return codec.codecPacker.Unpack(envelope.Payload, payloadPtr)
+	payloadData := []byte(envelope.Payload)
+	if codec.sealing != nil {
+		sealed := &sealedPayload{}
+		if err := codec.codecPacker.Unpack(payloadData, sealed); err != nil {
+			return err
+		}
+		if len(sealed.Sealed) == 0 {
+			return errors.New("message payload is not sealed")
+		}
+
+		payloadData, err = open(codec.sealing, sealed.Sealed)
+		if err != nil {
+			return err
+		}
+	}
+
+	return codec.codecPacker.Unpack(payloadData, payloadPtr)
 }
 
 type messageEnvelope struct {
 	Payload   json.RawMessage `json:"payload"`
 	Signature string          `json:"signature"`
 }
+
+// sealedPayload is the payload sealed with the key agreed on dialog creation, JSON encodes it as base64
+type sealedPayload struct {
+	Sealed []byte `json:"sealed"`
+}
@@ -226,3 +226,39 @@ func TestCodecSigner_UnpackError(t *testing.T) {
 		assert.EqualError(t, err, tt.expectedError)
 	}
 }
+
+func TestCodecSealed_PackUnpack(t *testing.T) {
+	consumerKey, _ := generateEphemeralKey()
+	providerKey, _ := generateEphemeralKey()
+	consumerSealing, _ := consumerKey.sealingCipher(providerKey.publicBase64(), true)
+	providerSealing, _ := providerKey.sealingCipher(consumerKey.publicBase64(), false)
+
+	packer := newCodecSealed(communication.NewCodecJSON(), &identity.SignerFake{}, &identity.VerifierFake{}, consumerSealing)
+	unpacker := newCodecSealed(communication.NewCodecJSON(), &identity.SignerFake{}, &identity.VerifierFake{}, providerSealing)
+
+	data, err := packer.Pack(&customPayload{Field: 123456})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"sealed"`)
+	assert.NotContains(t, string(data), "123456")
+
+	var payload customPayload
+	err = unpacker.Unpack(data, &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, customPayload{Field: 123456}, payload)
+}
+
+func TestCodecSealed_UnpackRejectsPlainPayload(t *testing.T) {
+	consumerKey, _ := generateEphemeralKey()
+	providerKey, _ := generateEphemeralKey()
+	sealing, _ := providerKey.sealingCipher(consumerKey.publicBase64(), false)
+
+	plainCodec := NewCodecSecured(communication.NewCodecJSON(), &identity.SignerFake{}, &identity.VerifierFake{})
+	sealedCodec := newCodecSealed(communication.NewCodecJSON(), &identity.SignerFake{}, &identity.VerifierFake{}, sealing)
+
+	data, err := plainCodec.Pack(&customPayload{Field: 1})
+	assert.NoError(t, err)
+
+	var payload customPayload
+	err = sealedCodec.Unpack(data, &payload)
+	assert.EqualError(t, err, "message payload is not sealed")
+}
@@ -18,6 +18,7 @@
 package dialog
 
 import (
+	"crypto/cipher"
 	"fmt"
 
 	log "github.com/cihub/seelog"
@@ -67,33 +68,54 @@ func (establisher *dialogEstablisher) EstablishDialog(
 	peerCodec := establisher.newCodecForPeer(peerID)
 
 	peerSender := establisher.newSenderToPeer(peerAddress, peerCodec)
-	topic, err := establisher.negotiateTopic(peerSender)
+	topic, sealing, err := establisher.negotiateDialog(peerSender)
 	if err != nil {
 		peerAddress.Disconnect()
 		return nil, err
 	}
 
+	if sealing != nil {
+		peerCodec = newCodecSealed(communication.NewCodecJSON(), establisher.Signer, identity.NewVerifierIdentity(peerID), sealing)
+	}
 	dialog := establisher.newDialogToPeer(peerID, peerAddress, peerCodec, topic)
 	log.Info(establisherLogPrefix, fmt.Sprintf("Dialog established with: %#v", peerContact))
 
 	return dialog, nil
 }
 
-func (establisher *dialogEstablisher) negotiateTopic(sender communication.Sender) (string, error) {
+// negotiateDialog requests the dialog topic and agrees on the key sealing dialog payloads,
+// no sealing cipher is returned if the peer supports signed payloads only
+func (establisher *dialogEstablisher) negotiateDialog(sender communication.Sender) (string, cipher.AEAD, error) {
+	ephemeral, err := generateEphemeralKey()
+	if err != nil {
+		return "", nil, err
+	}
+
 	response, err := sender.Request(&dialogCreateProducer{
 		&dialogCreateRequest{
-			PeerID:  establisher.ID.Address,
-			Version: "v1",
+			PeerID:    establisher.ID.Address,
+			Version:   dialogVersionSealed,
+			PublicKey: ephemeral.publicBase64(),
 		},
 	})
 	if err != nil {
-		return "", errors.Wrapf(err, "dialog creation error")
+		return "", nil, errors.Wrapf(err, "dialog creation error")
 	}
-	if response.(*dialogCreateResponse).Reason != 200 {
-		return "", errors.Errorf("dialog creation rejected. %#v", response)
+	createResponse := response.(*dialogCreateResponse)
+	if createResponse.Reason != 200 {
+		return "", nil, errors.Errorf("dialog creation rejected. %#v", response)
 	}
 
-	return response.(*dialogCreateResponse).Topic, nil
+	if createResponse.Version != dialogVersionSealed {
+		log.Warn(establisherLogPrefix, "Peer does not support sealed dialogs, payloads will be signed only")
+		return createResponse.Topic, nil, nil
+	}
+
+	sealing, err := ephemeral.sealingCipher(createResponse.PublicKey, true)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to agree on dialog sealing key")
+	}
+	return createResponse.Topic, sealing, nil
 }
 
 func (establisher *dialogEstablisher) newCodecForPeer(peerID identity.Identity) *codecSecured {
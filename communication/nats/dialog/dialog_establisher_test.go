@@ -18,8 +18,11 @@
 package dialog
 
 import (
+	"crypto/ecdsa"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/communication/nats/discovery"
@@ -100,6 +103,47 @@ func TestDialogEstablisher_CreateDialogWhenResponseHijacked(t *testing.T) {
 	assert.Nil(t, dialogInstance)
 }
 
+func TestDialogEstablisher_EstablishSealedDialog(t *testing.T) {
+	consumerKey, _ := crypto.GenerateKey()
+	consumerID := identity.FromAddress(crypto.PubkeyToAddress(consumerKey.PublicKey).Hex())
+	providerKey, _ := crypto.GenerateKey()
+	providerID := identity.FromAddress(crypto.PubkeyToAddress(providerKey.PublicKey).Hex())
+
+	connection := nats.StartConnectionMock()
+	defer connection.Close()
+
+	waiter := &dialogWaiter{
+		address: discovery.NewAddressWithConnection(connection, "peer-topic"),
+		signer:  &privateKeySigner{providerKey},
+	}
+	handler := &dialogHandler{
+		dialogReceived: make(chan communication.Dialog, 1),
+	}
+	assert.NoError(t, waiter.ServeDialogs(handler))
+
+	establisher := mockEstablisher(consumerID, connection, &privateKeySigner{consumerKey})
+	consumerDialog, err := establisher.EstablishDialog(providerID, market.Contact{})
+	assert.NoError(t, err)
+
+	providerDialog, err := dialogWait(handler)
+	assert.NoError(t, err)
+
+	messages := make(chan string, 1)
+	err = providerDialog.Receive(&testMessageConsumer{messages})
+	assert.NoError(t, err)
+
+	err = consumerDialog.Send(&testMessageProducer{"secret message"})
+	assert.NoError(t, err)
+
+	select {
+	case message := <-messages:
+		assert.Equal(t, "secret message", message)
+	case <-time.After(100 * time.Millisecond):
+		assert.Fail(t, "message not received")
+	}
+	assert.NotContains(t, string(connection.GetLastMessage()), "secret message")
+}
+
 func mockEstablisher(ID identity.Identity, connection nats.Connection, signer identity.Signer) *dialogEstablisher {
 	peerTopic := "peer-topic"
 
@@ -111,3 +155,45 @@ func mockEstablisher(ID identity.Identity, connection nats.Connection, signer id
 		},
 	}
 }
+
+type privateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+}
+
+func (signer *privateKeySigner) Sign(message []byte) (identity.Signature, error) {
+	signature, err := crypto.Sign(crypto.Keccak256(message), signer.privateKey)
+	return identity.SignatureBytes(signature), err
+}
+
+type testMessage struct {
+	Text string `json:"text"`
+}
+
+type testMessageProducer struct {
+	text string
+}
+
+func (producer *testMessageProducer) GetMessageEndpoint() communication.MessageEndpoint {
+	return "test-message"
+}
+
+func (producer *testMessageProducer) Produce() interface{} {
+	return &testMessage{producer.text}
+}
+
+type testMessageConsumer struct {
+	received chan string
+}
+
+func (consumer *testMessageConsumer) GetMessageEndpoint() communication.MessageEndpoint {
+	return "test-message"
+}
+
+func (consumer *testMessageConsumer) NewMessage() interface{} {
+	return &testMessage{}
+}
+
+func (consumer *testMessageConsumer) Consume(messagePtr interface{}) error {
+	consumer.received <- messagePtr.(*testMessage).Text
+	return nil
+}
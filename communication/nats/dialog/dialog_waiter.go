@@ -99,7 +99,32 @@ func (waiter *dialogWaiter) ServeDialogs(dialogHandler communication.DialogHandl
 			// TODO this is a compatibility check. It should be removed once all consumers will migrate to the newer version.
 			topic = waiter.address.GetTopic() + "." + peerID.Address
 		}
-		dialog := waiter.newDialogToPeer(peerID, waiter.newCodecForPeer(peerID), topic)
+
+		response := &dialogCreateResponse{
+			Reason:        responseOK.Reason,
+			ReasonMessage: responseOK.ReasonMessage,
+			Topic:         topic,
+			Version:       dialogVersionPlain,
+		}
+		peerCodec := waiter.newCodecForPeer(peerID)
+		if request.Version == dialogVersionSealed {
+			ephemeral, err := generateEphemeralKey()
+			if err != nil {
+				log.Error(waiterLogPrefix, "Failed to generate ephemeral key: ", err)
+				return &responseInternalError, err
+			}
+			sealing, err := ephemeral.sealingCipher(request.PublicKey, false)
+			if err != nil {
+				log.Error(waiterLogPrefix, fmt.Sprintf("Invalid public key from: '%s'. %s", request.PeerID, err))
+				return &responseInvalidPublicKey, nil
+			}
+
+			peerCodec = newCodecSealed(communication.NewCodecJSON(), waiter.signer, identity.NewVerifierIdentity(peerID), sealing)
+			response.Version = dialogVersionSealed
+			response.PublicKey = ephemeral.publicBase64()
+		}
+
+		dialog := waiter.newDialogToPeer(peerID, peerCodec, topic)
 		err = dialogHandler.Handle(dialog)
 		if err != nil {
 			log.Error(waiterLogPrefix, fmt.Sprintf("Failed dialog from: '%s'. %s", request.PeerID, err))
@@ -110,12 +135,8 @@ func (waiter *dialogWaiter) ServeDialogs(dialogHandler communication.DialogHandl
 		waiter.dialogs = append(waiter.dialogs, dialog)
 		waiter.Unlock()
 
-		log.Info(waiterLogPrefix, fmt.Sprintf("Accepted dialog from: '%s'", request.PeerID))
-		return &dialogCreateResponse{
-			Reason:        responseOK.Reason,
-			ReasonMessage: responseOK.ReasonMessage,
-			Topic:         topic,
-		}, nil
+		log.Info(waiterLogPrefix, fmt.Sprintf("Accepted %s dialog from: '%s'", response.Version, request.PeerID))
+		return response, nil
 	}
 	codec := NewCodecSecured(communication.NewCodecJSON(), waiter.signer, identity.NewVerifierSigned())
 	receiver := nats.NewReceiver(waiter.address.GetConnection(), codec, waiter.address.GetTopic())
@@ -25,18 +25,25 @@ import (
 const endpointDialogCreate = communication.RequestEndpoint("dialog-create")
 
 var (
-	responseOK              = dialogCreateResponse{200, "OK", ""}
-	responseInvalidIdentity = dialogCreateResponse{400, "Invalid Identity", ""}
-	responseInternalError   = dialogCreateResponse{500, "Internal Error", ""}
+	responseOK               = dialogCreateResponse{Reason: 200, ReasonMessage: "OK"}
+	responseInvalidIdentity  = dialogCreateResponse{Reason: 400, ReasonMessage: "Invalid Identity"}
+	responseInvalidPublicKey = dialogCreateResponse{Reason: 400, ReasonMessage: "Invalid Public Key"}
+	responseInternalError    = dialogCreateResponse{Reason: 500, ReasonMessage: "Internal Error"}
 )
 
 type dialogCreateRequest struct {
 	PeerID  string `json:"peer_id"`
 	Version string `json:"version,omitempty"`
+	// PublicKey is the ephemeral key of the consumer, sent with the sealed dialog version only
+	PublicKey string `json:"public_key,omitempty"`
 }
 
 type dialogCreateResponse struct {
 	Reason        uint   `json:"reason"`
 	ReasonMessage string `json:"reasonMessage"`
 	Topic         string `json:"topic,omitempty"`
+	// Version is the dialog version chosen by the provider, older providers do not send it and only sign payloads
+	Version string `json:"version,omitempty"`
+	// PublicKey is the ephemeral key of the provider, sent with the sealed dialog version only
+	PublicKey string `json:"public_key,omitempty"`
 }
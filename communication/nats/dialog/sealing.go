@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dialog
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+
+const (
+	// dialogVersionPlain marks the dialogs which payloads are only signed
+	dialogVersionPlain = "v1"
+	// dialogVersionSealed marks the dialogs which payloads are also sealed with the key agreed on dialog creation
+	dialogVersionSealed = "v2"
+
+	ephemeralKeyLength = 32
+	sealingKeyLabel    = "mysterium dialog v2"
+)
+
+// ephemeralKey is the X25519 key pair generated for a single dialog, its public part is sent in the
+// dialog creation request or response, which are signed by the identities of both peers
+type ephemeralKey struct {
+	private [ephemeralKeyLength]byte
+	public  [ephemeralKeyLength]byte
+}
+
+func generateEphemeralKey() (*ephemeralKey, error) {
+	key := &ephemeralKey{}
+	if _, err := rand.Read(key.private[:]); err != nil {
+		return nil, errors.Wrap(err, "failed to generate ephemeral key")
+	}
+	curve25519.ScalarBaseMult(&key.public, &key.private)
+	return key, nil
+}
+
+func (key *ephemeralKey) publicBase64() string {
+	return base64.StdEncoding.EncodeToString(key.public[:])
+}
+
+// sealingCipher agrees on the key with the peer and returns AEAD sealing dialog payloads with it.
+// The key is bound to the public keys of both sides, the consumer one is always the first.
+func (key *ephemeralKey) sealingCipher(peerPublicBase64 string, consumerSide bool) (cipher.AEAD, error) {
+	peerPublicBytes, err := base64.StdEncoding.DecodeString(peerPublicBase64)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed peer public key")
+	}
+	if len(peerPublicBytes) != ephemeralKeyLength {
+		return nil, errors.Errorf("invalid peer public key length %d", len(peerPublicBytes))
+	}
+
+	var peerPublic, shared [ephemeralKeyLength]byte
+	copy(peerPublic[:], peerPublicBytes)
+	curve25519.ScalarMult(&shared, &key.private, &peerPublic)
+	if subtle.ConstantTimeCompare(shared[:], make([]byte, ephemeralKeyLength)) == 1 {
+		return nil, errors.New("peer public key is of low order")
+	}
+
+	consumerPublic, providerPublic := key.public[:], peerPublic[:]
+	if !consumerSide {
+		consumerPublic, providerPublic = providerPublic, consumerPublic
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(sealingKeyLabel))
+	hash.Write(shared[:])
+	hash.Write(consumerPublic)
+	hash.Write(providerPublic)
+	return chacha20poly1305.New(hash.Sum(nil))
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed payload is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	return plaintext, errors.Wrap(err, "failed to open sealed payload")
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dialog
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealingCipher_BothSidesAgreeOnKey(t *testing.T) {
+	consumerKey, err := generateEphemeralKey()
+	assert.NoError(t, err)
+	providerKey, err := generateEphemeralKey()
+	assert.NoError(t, err)
+
+	consumerSealing, err := consumerKey.sealingCipher(providerKey.publicBase64(), true)
+	assert.NoError(t, err)
+	providerSealing, err := providerKey.sealingCipher(consumerKey.publicBase64(), false)
+	assert.NoError(t, err)
+
+	sealed, err := seal(consumerSealing, []byte("hello"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(sealed), "hello")
+
+	opened, err := open(providerSealing, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), opened)
+}
+
+func TestSealingCipher_KeyIsBoundToSides(t *testing.T) {
+	consumerKey, _ := generateEphemeralKey()
+	providerKey, _ := generateEphemeralKey()
+
+	consumerSealing, err := consumerKey.sealingCipher(providerKey.publicBase64(), true)
+	assert.NoError(t, err)
+	mirroredSealing, err := providerKey.sealingCipher(consumerKey.publicBase64(), true)
+	assert.NoError(t, err)
+
+	sealed, err := seal(consumerSealing, []byte("hello"))
+	assert.NoError(t, err)
+
+	_, err = open(mirroredSealing, sealed)
+	assert.Error(t, err)
+}
+
+func TestSealingCipher_RejectsInvalidPublicKey(t *testing.T) {
+	key, _ := generateEphemeralKey()
+
+	_, err := key.sealingCipher("not base64!", true)
+	assert.Error(t, err)
+
+	_, err = key.sealingCipher(base64.StdEncoding.EncodeToString([]byte("short")), true)
+	assert.EqualError(t, err, "invalid peer public key length 5")
+
+	_, err = key.sealingCipher(base64.StdEncoding.EncodeToString(make([]byte, ephemeralKeyLength)), true)
+	assert.EqualError(t, err, "peer public key is of low order")
+}
+
+func TestOpen_RejectsTamperedPayload(t *testing.T) {
+	consumerKey, _ := generateEphemeralKey()
+	providerKey, _ := generateEphemeralKey()
+	sealing, _ := consumerKey.sealingCipher(providerKey.publicBase64(), true)
+
+	sealed, err := seal(sealing, []byte("hello"))
+	assert.NoError(t, err)
+	sealed[len(sealed)-1] ^= 0xff
+
+	_, err = open(sealing, sealed)
+	assert.Error(t, err)
+
+	_, err = open(sealing, []byte("short"))
+	assert.EqualError(t, err, "sealed payload is too short")
+}
@@ -45,6 +45,12 @@ func NewAddress(topic string, addresses ...string) *AddressNATS {
 
 // NewAddressFromHostAndID generates NATS address for current node
 func NewAddressFromHostAndID(uri string, myID identity.Identity, serviceType string) (*AddressNATS, error) {
+	topic := fmt.Sprintf("%v.%v", myID.Address, serviceType)
+	return NewAddressFromHost(uri, topic)
+}
+
+// NewAddressFromHost generates NATS address to the given broker host, default broker port is used if none given
+func NewAddressFromHost(uri string, topic string) (*AddressNATS, error) {
 	// Add scheme first otherwise url.Parse() fails.
 	var rawurl string
 	if strings.HasPrefix(uri, "nats:") {
@@ -62,7 +68,6 @@ func NewAddressFromHostAndID(uri string, myID identity.Identity, serviceType str
 		url.Host = fmt.Sprintf("%s:%d", url.Host, BrokerPort)
 	}
 
-	topic := fmt.Sprintf("%v.%v", myID.Address, serviceType)
 	return NewAddress(topic, url.String()), nil
 }
 
@@ -184,3 +184,8 @@ func (cfg *Config) GetString(key string) string {
 func (cfg *Config) GetBool(key string) bool {
 	return cast.ToBool(cfg.Get(key))
 }
+
+// GetFloat64 gets config value as float64
+func (cfg *Config) GetFloat64(key string) float64 {
+	return cast.ToFloat64(cfg.Get(key))
+}
@@ -53,3 +53,13 @@ func SetBool(cfg *config.Config, name string, ctx *cli.Context) {
 		cfg.RemoveCLI(name)
 	}
 }
+
+// SetFloat64 helper to register float64 CLI flag value from urfave/cli.Context.
+// Removes configured value if CLI flag value is not present.
+func SetFloat64(cfg *config.Config, name string, ctx *cli.Context) {
+	if ctx.IsSet(name) {
+		cfg.SetCLI(name, ctx.Float64(name))
+	} else {
+		cfg.RemoveCLI(name)
+	}
+}
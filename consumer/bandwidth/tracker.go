@@ -60,8 +60,12 @@ func (t *Tracker) Get() CurrentSpeed {
 	return t.currentSpeed
 }
 
-// ConsumeStatisticsEvent handles the connection statistics changes
-func (t *Tracker) ConsumeStatisticsEvent(stats consumer.SessionStatistics) {
+// ConsumeStatisticsEvent handles the statistics changes of the default connection
+func (t *Tracker) ConsumeStatisticsEvent(statisticsEvent connection.StatisticsEvent) {
+	if statisticsEvent.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	stats := statisticsEvent.Stats
 	t.lock.Lock()
 	defer func() {
 		t.previous = stats
@@ -89,8 +93,11 @@ func (t *Tracker) ConsumeStatisticsEvent(stats consumer.SessionStatistics) {
 	log.Tracef("%sUpload speed: %s", trackerLogPrefix, t.currentSpeed.Up)
 }
 
-// ConsumeSessionEvent handles the session state changes
+// ConsumeSessionEvent handles the session state changes of the default connection
 func (t *Tracker) ConsumeSessionEvent(sessionEvent connection.SessionEvent) {
+	if sessionEvent.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
 	t.lock.Lock()
 	defer t.lock.Unlock()
 	switch sessionEvent.Status {
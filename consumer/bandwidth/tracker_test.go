@@ -82,7 +82,8 @@ func Test_ConsumeSessionEvent_ResetsOnConnect(t *testing.T) {
 		},
 	}
 	tracker.ConsumeSessionEvent(connection.SessionEvent{
-		Status: connection.SessionCreatedStatus,
+		ConnectionID: connection.DefaultConnectionID,
+		Status:       connection.SessionCreatedStatus,
 	})
 
 	assert.True(t, tracker.previousTime.IsZero())
@@ -99,7 +100,8 @@ func Test_ConsumeSessionEvent_ResetsOnDisconnect(t *testing.T) {
 		},
 	}
 	tracker.ConsumeSessionEvent(connection.SessionEvent{
-		Status: connection.SessionEndedStatus,
+		ConnectionID: connection.DefaultConnectionID,
+		Status:       connection.SessionEndedStatus,
 	})
 
 	assert.True(t, tracker.previousTime.IsZero())
@@ -114,9 +116,12 @@ func Test_ConsumeStatisticsEvent_CalculatesCorrectly(t *testing.T) {
 		previousTime: startTime,
 	}
 
-	tracker.ConsumeStatisticsEvent(consumer.SessionStatistics{
-		BytesReceived: bytesTransfered,
-		BytesSent:     bytesTransfered,
+	tracker.ConsumeStatisticsEvent(connection.StatisticsEvent{
+		ConnectionID: connection.DefaultConnectionID,
+		Stats: consumer.SessionStatistics{
+			BytesReceived: bytesTransfered,
+			BytesSent:     bytesTransfered,
+		},
 	})
 
 	assert.NotEqual(t, tracker.previousTime, startTime)
@@ -132,9 +137,19 @@ func Test_ConsumeStatisticsEvent_SkipsOnZero(t *testing.T) {
 		BytesReceived: 1,
 		BytesSent:     2,
 	}
-	tracker.ConsumeStatisticsEvent(input)
+	tracker.ConsumeStatisticsEvent(connection.StatisticsEvent{ConnectionID: connection.DefaultConnectionID, Stats: input})
 	assert.False(t, tracker.previousTime.IsZero())
 	assert.Equal(t, input.BytesReceived, tracker.previous.BytesReceived)
 	assert.Equal(t, input.BytesSent, tracker.previous.BytesSent)
 	assert.Zero(t, tracker.Get().Down.BitsPerSecond)
 }
+
+func Test_ConsumeStatisticsEvent_SkipsOtherConnections(t *testing.T) {
+	tracker := Tracker{}
+	tracker.ConsumeStatisticsEvent(connection.StatisticsEvent{
+		ConnectionID: "other",
+		Stats:        consumer.SessionStatistics{BytesReceived: 1, BytesSent: 2},
+	})
+	assert.True(t, tracker.previousTime.IsZero())
+	assert.Zero(t, tracker.previous.BytesReceived)
+}
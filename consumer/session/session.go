@@ -55,6 +55,7 @@ type History struct {
 	Status          string
 	Updated         time.Time
 	DataStats       consumer.SessionStatistics // is updated on disconnect event
+	Relayed         bool                       // is updated when the session falls back to the relay
 }
 
 // GetDuration returns delta in seconds (TimeUpdated - TimeStarted)
@@ -66,13 +66,18 @@ func (repo *Storage) GetAll() ([]History, error) {
 	return sessions, nil
 }
 
-// ConsumeSessionEvent consumes the session state change events
+// ConsumeSessionEvent consumes the session state change events of the default connection
 func (repo *Storage) ConsumeSessionEvent(sessionEvent connection.SessionEvent) {
+	if sessionEvent.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
 	switch sessionEvent.Status {
 	case connection.SessionEndedStatus:
 		repo.handleEndedEvent(sessionEvent.SessionInfo.SessionID)
 	case connection.SessionCreatedStatus:
 		repo.handleCreatedEvent(sessionEvent.SessionInfo)
+	case connection.SessionRelayedStatus:
+		repo.handleRelayedEvent(sessionEvent.SessionInfo.SessionID)
 	}
 }
 
@@ -91,6 +96,20 @@ func (repo *Storage) handleEndedEvent(sessionID session.ID) {
 	}
 }
 
+func (repo *Storage) handleRelayedEvent(sessionID session.ID) {
+	updatedSession := &History{
+		SessionID: sessionID,
+		Updated:   time.Now().UTC(),
+		Relayed:   true,
+	}
+	err := repo.storage.Update(sessionStorageBucketName, updatedSession)
+	if err != nil {
+		log.Error(sessionStorageLogPrefix, err)
+	} else {
+		log.Trace(sessionStorageLogPrefix, fmt.Sprintf("Session %v marked relayed", sessionID))
+	}
+}
+
 func (repo *Storage) handleCreatedEvent(sessionInfo connection.SessionInfo) {
 	se := NewHistory(
 		sessionInfo.SessionID,
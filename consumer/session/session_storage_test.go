@@ -40,7 +40,8 @@ var (
 	serviceType = "serviceType"
 
 	mockPayload = connection.SessionEvent{
-		Status: connection.SessionCreatedStatus,
+		ConnectionID: connection.DefaultConnectionID,
+		Status:       connection.SessionCreatedStatus,
 		SessionInfo: connection.SessionInfo{
 			SessionID:  sessionID,
 			ConsumerID: consumerID,
@@ -78,7 +79,8 @@ func TestSessionStorageConsumeEventEndedOK(t *testing.T) {
 
 	storage := NewSessionStorage(storer, stubRetriever)
 	storage.ConsumeSessionEvent(connection.SessionEvent{
-		Status: connection.SessionEndedStatus,
+		ConnectionID: connection.DefaultConnectionID,
+		Status:       connection.SessionEndedStatus,
 	})
 	assert.True(t, storer.UpdateCalled)
 }
@@ -90,12 +92,26 @@ func TestSessionStorageConsumeEventEndedErrors(t *testing.T) {
 
 	storage := NewSessionStorage(storer, stubRetriever)
 	assert.NotPanics(t, func() {
-		storage.ConsumeSessionEvent(connection.SessionEvent{Status: connection.SessionEndedStatus})
+		storage.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionEndedStatus})
 	})
 
 	assert.True(t, storer.UpdateCalled)
 }
 
+func TestSessionStorageConsumeEventRelayedOK(t *testing.T) {
+	storer := &StubSessionStorer{}
+
+	storage := NewSessionStorage(storer, stubRetriever)
+	storage.ConsumeSessionEvent(connection.SessionEvent{
+		ConnectionID: connection.DefaultConnectionID,
+		Status:       connection.SessionRelayedStatus,
+		SessionInfo:  connection.SessionInfo{SessionID: sessionID, Relayed: true},
+	})
+	assert.True(t, storer.UpdateCalled)
+	assert.True(t, storer.Updated.(*History).Relayed)
+	assert.Equal(t, sessionID, storer.Updated.(*History).SessionID)
+}
+
 func TestSessionStorageConsumeEventConnectedOK(t *testing.T) {
 	storer := &StubSessionStorer{}
 
@@ -121,6 +137,7 @@ type StubSessionStorer struct {
 	SaveCalled   bool
 	UpdateError  error
 	UpdateCalled bool
+	Updated      interface{}
 	GetAllCalled bool
 	GetAllError  error
 }
@@ -132,6 +149,7 @@ func (sss *StubSessionStorer) Store(from string, object interface{}) error {
 
 func (sss *StubSessionStorer) Update(from string, object interface{}) error {
 	sss.UpdateCalled = true
+	sss.Updated = object
 	return sss.UpdateError
 }
 
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statistics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+)
+
+// ConnectionStatisticsTracker keeps the session stats of every connection, keyed by the connection ID
+type ConnectionStatisticsTracker struct {
+	timeGetter TimeGetter
+	trackers   map[string]*SessionStatisticsTracker
+	lock       sync.Mutex
+}
+
+// NewConnectionStatisticsTracker returns new stats tracker of multiple connections with given timeGetter function
+func NewConnectionStatisticsTracker(timeGetter TimeGetter) *ConnectionStatisticsTracker {
+	return &ConnectionStatisticsTracker{
+		timeGetter: timeGetter,
+		trackers:   make(map[string]*SessionStatisticsTracker),
+	}
+}
+
+// Retrieve retrieves session stats and duration of the connection, reports false if connection has no stats
+func (cst *ConnectionStatisticsTracker) Retrieve(connectionID string) (consumer.SessionStatistics, time.Duration, bool) {
+	cst.lock.Lock()
+	defer cst.lock.Unlock()
+
+	tracker, ok := cst.trackers[connectionID]
+	if !ok {
+		return consumer.SessionStatistics{}, 0, false
+	}
+	return tracker.Retrieve(), tracker.GetSessionDuration(), true
+}
+
+// ConsumeStateEvent forgets the stats of the connection once it is closed
+func (cst *ConnectionStatisticsTracker) ConsumeStateEvent(stateEvent connection.StateEvent) {
+	if stateEvent.State != connection.NotConnected {
+		return
+	}
+
+	cst.lock.Lock()
+	defer cst.lock.Unlock()
+	delete(cst.trackers, stateEvent.ConnectionID)
+}
+
+// ConsumeSessionEvent handles the session state changes of the connection
+func (cst *ConnectionStatisticsTracker) ConsumeSessionEvent(sessionEvent connection.SessionEvent) {
+	cst.lock.Lock()
+	defer cst.lock.Unlock()
+
+	cst.tracker(sessionEvent.ConnectionID).changeSessionStatus(sessionEvent.Status)
+}
+
+// ConsumeStatisticsEvent handles the statistics changes of the connection
+func (cst *ConnectionStatisticsTracker) ConsumeStatisticsEvent(statisticsEvent connection.StatisticsEvent) {
+	cst.lock.Lock()
+	defer cst.lock.Unlock()
+
+	cst.tracker(statisticsEvent.ConnectionID).addStatistics(statisticsEvent.Stats)
+}
+
+func (cst *ConnectionStatisticsTracker) tracker(connectionID string) *SessionStatisticsTracker {
+	tracker, ok := cst.trackers[connectionID]
+	if !ok {
+		tracker = NewSessionStatisticsTracker(cst.timeGetter)
+		cst.trackers[connectionID] = tracker
+	}
+	return tracker
+}
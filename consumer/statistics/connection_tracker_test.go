@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statistics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionStatisticsTrackerKeepsStatsOfEveryConnection(t *testing.T) {
+	settableClock := utils.SettableClock{}
+	settableClock.SetTime(time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC))
+	tracker := NewConnectionStatisticsTracker(settableClock.GetTime)
+
+	tracker.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: "first", Status: connection.SessionCreatedStatus})
+	tracker.ConsumeStatisticsEvent(connection.StatisticsEvent{
+		ConnectionID: "first",
+		Stats:        consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2},
+	})
+	tracker.ConsumeStatisticsEvent(connection.StatisticsEvent{
+		ConnectionID: "second",
+		Stats:        consumer.SessionStatistics{BytesSent: 3, BytesReceived: 4},
+	})
+	settableClock.SetTime(time.Date(2000, time.January, 0, 10, 12, 5, 0, time.UTC))
+
+	stats, duration, ok := tracker.Retrieve("first")
+	assert.True(t, ok)
+	assert.Equal(t, consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2}, stats)
+	assert.Equal(t, 2*time.Second, duration)
+
+	stats, duration, ok = tracker.Retrieve("second")
+	assert.True(t, ok)
+	assert.Equal(t, consumer.SessionStatistics{BytesSent: 3, BytesReceived: 4}, stats)
+	assert.Equal(t, time.Duration(0), duration)
+}
+
+func TestConnectionStatisticsTrackerForgetsClosedConnection(t *testing.T) {
+	tracker := NewConnectionStatisticsTracker(time.Now)
+	tracker.ConsumeStatisticsEvent(connection.StatisticsEvent{
+		ConnectionID: "first",
+		Stats:        consumer.SessionStatistics{BytesSent: 1},
+	})
+
+	tracker.ConsumeStateEvent(connection.StateEvent{ConnectionID: "first", State: connection.Connected})
+	_, _, ok := tracker.Retrieve("first")
+	assert.True(t, ok)
+
+	tracker.ConsumeStateEvent(connection.StateEvent{ConnectionID: "first", State: connection.NotConnected})
+	_, _, ok = tracker.Retrieve("first")
+	assert.False(t, ok)
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statistics
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+// HopStatistics represents statistics of a single hop in chained connection
+type HopStatistics struct {
+	SessionID   session.ID
+	ProviderID  string
+	ServiceType string
+	Stats       consumer.SessionStatistics
+}
+
+// HopStatisticsTracker keeps the stats of every hop of current connection, ordered from the entry towards the exit
+type HopStatisticsTracker struct {
+	hops []HopStatistics
+	lock sync.RWMutex
+}
+
+// NewHopStatisticsTracker returns new hop stats tracker
+func NewHopStatisticsTracker() *HopStatisticsTracker {
+	return &HopStatisticsTracker{}
+}
+
+// Retrieve retrieves stats of every hop
+func (hst *HopStatisticsTracker) Retrieve() []HopStatistics {
+	hst.lock.RLock()
+	defer hst.lock.RUnlock()
+
+	hops := make([]HopStatistics, len(hst.hops))
+	copy(hops, hst.hops)
+	return hops
+}
+
+// ConsumeSessionEvent adds and removes hops of the default connection as their sessions are created and ended
+func (hst *HopStatisticsTracker) ConsumeSessionEvent(sessionEvent connection.SessionEvent) {
+	if sessionEvent.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	hst.lock.Lock()
+	defer hst.lock.Unlock()
+
+	switch sessionEvent.Status {
+	case connection.SessionCreatedStatus:
+		hst.hops = append(hst.hops, HopStatistics{
+			SessionID:   sessionEvent.SessionInfo.SessionID,
+			ProviderID:  sessionEvent.SessionInfo.Proposal.ProviderID,
+			ServiceType: sessionEvent.SessionInfo.Proposal.ServiceType,
+		})
+	case connection.SessionEndedStatus:
+		for i := range hst.hops {
+			if hst.hops[i].SessionID == sessionEvent.SessionInfo.SessionID {
+				hst.hops = append(hst.hops[:i], hst.hops[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// ConsumeHopStatisticsEvent handles the statistics changes of a single hop of the default connection
+func (hst *HopStatisticsTracker) ConsumeHopStatisticsEvent(event connection.HopStatisticsEvent) {
+	if event.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	hst.lock.Lock()
+	defer hst.lock.Unlock()
+
+	for i := range hst.hops {
+		if hst.hops[i].SessionID == event.SessionInfo.SessionID {
+			hst.hops[i].Stats = event.Stats
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statistics
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHopStatisticsTrackerKeepsStatsOfEveryHop(t *testing.T) {
+	tracker := NewHopStatisticsTracker()
+	entry := connection.SessionInfo{SessionID: "session-1", Proposal: market.ServiceProposal{ProviderID: "provider-1", ServiceType: "wireguard"}}
+	exit := connection.SessionInfo{SessionID: "session-2", Proposal: market.ServiceProposal{ProviderID: "provider-2", ServiceType: "wireguard"}}
+
+	tracker.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionCreatedStatus, SessionInfo: entry})
+	tracker.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionCreatedStatus, SessionInfo: exit})
+	tracker.ConsumeHopStatisticsEvent(connection.HopStatisticsEvent{ConnectionID: connection.DefaultConnectionID, SessionInfo: entry, Stats: consumer.SessionStatistics{BytesSent: 20, BytesReceived: 40}})
+	tracker.ConsumeHopStatisticsEvent(connection.HopStatisticsEvent{ConnectionID: connection.DefaultConnectionID, SessionInfo: exit, Stats: consumer.SessionStatistics{BytesSent: 10, BytesReceived: 30}})
+
+	assert.Equal(
+		t,
+		[]HopStatistics{
+			{SessionID: "session-1", ProviderID: "provider-1", ServiceType: "wireguard", Stats: consumer.SessionStatistics{BytesSent: 20, BytesReceived: 40}},
+			{SessionID: "session-2", ProviderID: "provider-2", ServiceType: "wireguard", Stats: consumer.SessionStatistics{BytesSent: 10, BytesReceived: 30}},
+		},
+		tracker.Retrieve(),
+	)
+
+	tracker.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionEndedStatus, SessionInfo: exit})
+	tracker.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionEndedStatus, SessionInfo: entry})
+	assert.Empty(t, tracker.Retrieve())
+}
@@ -149,8 +149,11 @@ func (sr *SessionStatisticsReporter) send(serviceType, providerID, country strin
 	)
 }
 
-// ConsumeSessionEvent handles the session state changes
+// ConsumeSessionEvent handles the session state changes of the default connection
 func (sr *SessionStatisticsReporter) ConsumeSessionEvent(sessionEvent connection.SessionEvent) {
+	if sessionEvent.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
 	switch sessionEvent.Status {
 	case connection.SessionEndedStatus:
 		sr.stop()
@@ -70,15 +70,29 @@ func (sst *SessionStatisticsTracker) markSessionEnd() {
 	sst.sessionStart = nil
 }
 
-// ConsumeStatisticsEvent handles the connection statistics changes
-func (sst *SessionStatisticsTracker) ConsumeStatisticsEvent(stats consumer.SessionStatistics) {
+// ConsumeStatisticsEvent handles the statistics changes of the default connection
+func (sst *SessionStatisticsTracker) ConsumeStatisticsEvent(statisticsEvent connection.StatisticsEvent) {
+	if statisticsEvent.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	sst.addStatistics(statisticsEvent.Stats)
+}
+
+// ConsumeSessionEvent handles the session state changes of the default connection
+func (sst *SessionStatisticsTracker) ConsumeSessionEvent(sessionEvent connection.SessionEvent) {
+	if sessionEvent.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	sst.changeSessionStatus(sessionEvent.Status)
+}
+
+func (sst *SessionStatisticsTracker) addStatistics(stats consumer.SessionStatistics) {
 	sst.sessionStats = consumer.AddUpStatistics(sst.sessionStats, sst.lastStats.DiffWithNew(stats))
 	sst.lastStats = stats
 }
 
-// ConsumeSessionEvent handles the session state changes
-func (sst *SessionStatisticsTracker) ConsumeSessionEvent(sessionEvent connection.SessionEvent) {
-	switch sessionEvent.Status {
+func (sst *SessionStatisticsTracker) changeSessionStatus(status string) {
+	switch status {
 	case connection.SessionEndedStatus:
 		sst.markSessionEnd()
 	case connection.SessionCreatedStatus:
@@ -31,7 +31,7 @@ func TestStatsSavingWorks(t *testing.T) {
 	statisticsTracker := NewSessionStatisticsTracker(time.Now)
 	stats := consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2}
 
-	statisticsTracker.ConsumeStatisticsEvent(stats)
+	statisticsTracker.ConsumeStatisticsEvent(defaultStatistics(stats))
 	assert.Equal(t, stats, statisticsTracker.Retrieve())
 }
 
@@ -70,7 +70,8 @@ func TestStopSessionResetsSessionDuration(t *testing.T) {
 func TestStatisticsTrackerConsumeSessionEventCreated(t *testing.T) {
 	statisticsTracker := NewSessionStatisticsTracker(time.Now)
 	statisticsTracker.ConsumeSessionEvent(connection.SessionEvent{
-		Status: connection.SessionCreatedStatus,
+		ConnectionID: connection.DefaultConnectionID,
+		Status:       connection.SessionCreatedStatus,
 	})
 	assert.NotNil(t, statisticsTracker.sessionStart)
 }
@@ -80,11 +81,27 @@ func TestStatisticsTrackerConsumeSessionEventEnded(t *testing.T) {
 	statisticsTracker := NewSessionStatisticsTracker(time.Now)
 	statisticsTracker.sessionStart = &now
 	statisticsTracker.ConsumeSessionEvent(connection.SessionEvent{
-		Status: connection.SessionEndedStatus,
+		ConnectionID: connection.DefaultConnectionID,
+		Status:       connection.SessionEndedStatus,
 	})
 	assert.Nil(t, statisticsTracker.sessionStart)
 }
 
+func TestStatisticsTrackerSkipsEventsOfOtherConnections(t *testing.T) {
+	statisticsTracker := NewSessionStatisticsTracker(time.Now)
+	statisticsTracker.ConsumeSessionEvent(connection.SessionEvent{
+		ConnectionID: "other",
+		Status:       connection.SessionCreatedStatus,
+	})
+	statisticsTracker.ConsumeStatisticsEvent(connection.StatisticsEvent{
+		ConnectionID: "other",
+		Stats:        consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2},
+	})
+
+	assert.Nil(t, statisticsTracker.sessionStart)
+	assert.Equal(t, consumer.SessionStatistics{}, statisticsTracker.Retrieve())
+}
+
 func TestConsumeStatisticsEventChain(t *testing.T) {
 	sst := &SessionStatisticsTracker{
 		timeGetter: time.Now,
@@ -93,12 +110,12 @@ func TestConsumeStatisticsEventChain(t *testing.T) {
 		BytesReceived: 1,
 		BytesSent:     1,
 	}
-	sst.ConsumeStatisticsEvent(stats)
+	sst.ConsumeStatisticsEvent(defaultStatistics(stats))
 
 	assert.EqualValues(t, stats, sst.lastStats)
 	assert.EqualValues(t, stats, sst.sessionStats)
 
-	sst.ConsumeStatisticsEvent(stats)
+	sst.ConsumeStatisticsEvent(defaultStatistics(stats))
 	assert.EqualValues(t, stats, sst.lastStats)
 	assert.EqualValues(t, stats, sst.sessionStats)
 
@@ -107,7 +124,7 @@ func TestConsumeStatisticsEventChain(t *testing.T) {
 		BytesSent:     2,
 	}
 
-	sst.ConsumeStatisticsEvent(updatedStats)
+	sst.ConsumeStatisticsEvent(defaultStatistics(updatedStats))
 	assert.EqualValues(t, updatedStats, sst.lastStats)
 	assert.EqualValues(t, updatedStats, sst.sessionStats)
 
@@ -117,12 +134,16 @@ func TestConsumeStatisticsEventChain(t *testing.T) {
 	}
 
 	// Simulate a reconnect now stats wise
-	sst.ConsumeStatisticsEvent(stats)
+	sst.ConsumeStatisticsEvent(defaultStatistics(stats))
 	assert.EqualValues(t, stats, sst.lastStats)
 	assert.EqualValues(t, statsAfterChain, sst.sessionStats)
 
 	// Simulate no change in stats
-	sst.ConsumeStatisticsEvent(stats)
+	sst.ConsumeStatisticsEvent(defaultStatistics(stats))
 	assert.EqualValues(t, stats, sst.lastStats)
 	assert.EqualValues(t, statsAfterChain, sst.sessionStats)
 }
+
+func defaultStatistics(stats consumer.SessionStatistics) connection.StatisticsEvent {
+	return connection.StatisticsEvent{ConnectionID: connection.DefaultConnectionID, Stats: stats}
+}
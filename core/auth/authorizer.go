@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"net/http"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authorizer resolves the scopes granted to the request either by the JWT session cookie or by the bearer token,
+// the bearer token can be the API token or the JWT one
+type Authorizer struct {
+	jwtAuth *JWTAuthenticator
+	tokens  *TokenManager
+}
+
+// NewAuthorizer creates an authorizer checking both JWT and API tokens
+func NewAuthorizer(jwtAuth *JWTAuthenticator, tokens *TokenManager) *Authorizer {
+	return &Authorizer{
+		jwtAuth: jwtAuth,
+		tokens:  tokens,
+	}
+}
+
+// RequestScopes returns the scopes granted to the request, ErrNoCredentials is returned if the request has no credentials
+func (a *Authorizer) RequestScopes(req *http.Request) (Scopes, error) {
+	if header := req.Header.Get("Authorization"); header != "" {
+		if !strings.HasPrefix(header, bearerPrefix) {
+			return nil, ErrUnauthorized
+		}
+		return a.tokenScopes(strings.TrimPrefix(header, bearerPrefix))
+	}
+
+	cookie, err := req.Cookie(JWTCookieName)
+	if err != nil {
+		return nil, ErrNoCredentials
+	}
+	return a.tokenScopes(cookie.Value)
+}
+
+// Authorize checks if the request is granted the required scope
+func (a *Authorizer) Authorize(req *http.Request, required Scope) error {
+	scopes, err := a.RequestScopes(req)
+	if err != nil {
+		return err
+	}
+	if !scopes.Allows(required) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CredentialsRequired reports if every request has to present credentials. This is the case once any API token is issued,
+// otherwise the scopes of the token could be bypassed just by omitting it.
+func (a *Authorizer) CredentialsRequired() (bool, error) {
+	count, err := a.tokens.Count()
+	if err != nil {
+		return true, err
+	}
+	return count > 0, nil
+}
+
+func (a *Authorizer) tokenScopes(token string) (Scopes, error) {
+	if IsAPIToken(token) {
+		return a.tokens.Scopes(token)
+	}
+
+	scopes, err := a.jwtAuth.Scopes(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return scopes, nil
+}
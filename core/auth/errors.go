@@ -22,4 +22,8 @@ import "github.com/pkg/errors"
 var (
 	// ErrUnauthorized unauthorized
 	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNoCredentials no credentials given
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrForbidden credentials do not grant the required scope
+	ErrForbidden = errors.New("forbidden")
 )
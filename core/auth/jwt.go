@@ -43,6 +43,7 @@ const JWTCookieName string = "token"
 
 type jwtClaims struct {
 	Username string `json:"username"`
+	Scopes   Scopes `json:"scopes,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -57,11 +58,12 @@ func NewJWTAuthenticator(encryptionKey JWTEncryptionKey) *JWTAuthenticator {
 	return auth
 }
 
-// CreateToken creates a new JWT token
+// CreateToken creates a new JWT token, the token is issued for the password user so it grants all the scopes
 func (jwtAuth *JWTAuthenticator) CreateToken(username string) (JWT, error) {
 	expirationTime := jwtAuth.getExpirationTime()
 	claims := &jwtClaims{
 		Username: username,
+		Scopes:   AllScopes(),
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 		},
@@ -78,20 +80,42 @@ func (jwtAuth *JWTAuthenticator) CreateToken(username string) (JWT, error) {
 
 // ValidateToken validates a JWT token
 func (jwtAuth *JWTAuthenticator) ValidateToken(token string) (bool, error) {
+	if _, err := jwtAuth.parseToken(token); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Scopes validates a JWT token and returns the scopes granted by it
+func (jwtAuth *JWTAuthenticator) Scopes(token string) (Scopes, error) {
+	claims, err := jwtAuth.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens issued before scopes were introduced belong to the password user
+	if len(claims.Scopes) == 0 {
+		return AllScopes(), nil
+	}
+	return claims.Scopes, nil
+}
+
+func (jwtAuth *JWTAuthenticator) parseToken(token string) (*jwtClaims, error) {
 	claims := &jwtClaims{}
 
 	tkn, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
 		return jwtAuth.encryptionKey, nil
 	})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	if tkn == nil || !tkn.Valid {
-		return false, errors.New("invalid JWT token")
+		return nil, errors.New("invalid JWT token")
 	}
 
-	return true, nil
+	return claims, nil
 }
 
 func (jwtAuth *JWTAuthenticator) getExpirationTime() time.Time {
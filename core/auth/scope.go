@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Scope defines the part of Tequilapi which the token grants access to
+type Scope string
+
+const (
+	// ScopeStatus grants read-only access to the node state, it is implied by any other scope
+	ScopeStatus = Scope("status")
+	// ScopeConnection grants access to connecting and disconnecting the consumer
+	ScopeConnection = Scope("connection")
+	// ScopeService grants access to starting and stopping provider services
+	ScopeService = Scope("service")
+	// ScopeAdmin grants access to identity, payout and token administration, it implies all the other scopes
+	ScopeAdmin = Scope("admin")
+)
+
+// AllScopes returns the list of all known scopes
+func AllScopes() Scopes {
+	return Scopes{ScopeStatus, ScopeConnection, ScopeService, ScopeAdmin}
+}
+
+// ParseScopes validates the given scope names
+func ParseScopes(names []string) (Scopes, error) {
+	scopes := make(Scopes, 0, len(names))
+	for _, name := range names {
+		scope := Scope(name)
+		if !AllScopes().Contains(scope) {
+			return nil, errors.Errorf("unknown scope %q", name)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// Scopes is the list of scopes granted to the token
+type Scopes []Scope
+
+// Contains checks if the scope is in the list
+func (scopes Scopes) Contains(scope Scope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows checks if the required scope is granted by the list
+func (scopes Scopes) Allows(required Scope) bool {
+	if required == ScopeStatus && len(scopes) > 0 {
+		return true
+	}
+	return scopes.Contains(required) || scopes.Contains(ScopeAdmin)
+}
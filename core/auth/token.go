@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrTokenNotFound is returned when the API token does not exist
+var ErrTokenNotFound = errors.New("API token not found")
+
+// apiTokenPrefix makes API tokens distinguishable from the JWT ones
+const apiTokenPrefix = "myst_"
+
+// APIToken is the named token granting the given scopes, only the hash of its secret is kept
+type APIToken struct {
+	ID        string `storm:"id"`
+	Name      string
+	Scopes    Scopes
+	Hash      string `storm:"unique"`
+	CreatedAt time.Time
+}
+
+// TokenStorage keeps the API tokens
+type TokenStorage interface {
+	List() ([]APIToken, error)
+	GetByHash(hash string) (APIToken, error)
+	Save(token APIToken) error
+	Delete(id string) error
+}
+
+// TokenManager issues, checks and revokes API tokens
+type TokenManager struct {
+	storage TokenStorage
+
+	// count of the issued tokens is checked on every request, it is cached until a token is created or revoked
+	countLock sync.Mutex
+	count     int
+	counted   bool
+}
+
+// NewTokenManager creates a token manager on top of the given storage
+func NewTokenManager(storage TokenStorage) *TokenManager {
+	return &TokenManager{storage: storage}
+}
+
+// Create issues a new API token, the returned secret is not stored and can't be retrieved later
+func (manager *TokenManager) Create(name string, scopes Scopes) (APIToken, string, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return APIToken{}, "", errors.Wrap(err, "failed to generate token id")
+	}
+	secretBytes, err := generateRandomBytes(32)
+	if err != nil {
+		return APIToken{}, "", errors.Wrap(err, "failed to generate token secret")
+	}
+
+	secret := apiTokenPrefix + hex.EncodeToString(secretBytes)
+	token := APIToken{
+		ID:        id.String(),
+		Name:      name,
+		Scopes:    scopes,
+		Hash:      hashToken(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	err = manager.storage.Save(token)
+	manager.invalidateCount()
+	if err != nil {
+		return APIToken{}, "", errors.Wrap(err, "failed to store token")
+	}
+
+	log.Infof("API token %q created with scopes %v", name, scopes)
+	return token, secret, nil
+}
+
+// List returns all the issued API tokens
+func (manager *TokenManager) List() ([]APIToken, error) {
+	return manager.storage.List()
+}
+
+// Count returns the number of the issued API tokens
+func (manager *TokenManager) Count() (int, error) {
+	manager.countLock.Lock()
+	defer manager.countLock.Unlock()
+
+	if !manager.counted {
+		tokens, err := manager.storage.List()
+		if err != nil {
+			return 0, err
+		}
+		manager.count = len(tokens)
+		manager.counted = true
+	}
+	return manager.count, nil
+}
+
+// Revoke removes the API token with the given id
+func (manager *TokenManager) Revoke(id string) error {
+	defer manager.invalidateCount()
+	return manager.storage.Delete(id)
+}
+
+// Scopes returns the scopes granted by the given API token secret
+func (manager *TokenManager) Scopes(secret string) (Scopes, error) {
+	if !IsAPIToken(secret) {
+		return nil, ErrUnauthorized
+	}
+
+	hash := hashToken(secret)
+	token, err := manager.storage.GetByHash(hash)
+	if err == ErrTokenNotFound {
+		return nil, ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token.Scopes, nil
+}
+
+func (manager *TokenManager) invalidateCount() {
+	manager.countLock.Lock()
+	defer manager.countLock.Unlock()
+	manager.counted = false
+}
+
+// IsAPIToken checks if the given secret looks like an API token
+func IsAPIToken(secret string) bool {
+	return strings.HasPrefix(secret, apiTokenPrefix)
+}
+
+func hashToken(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenStorageMock struct {
+	tokens map[string]APIToken
+	listed int
+}
+
+func (mock *tokenStorageMock) List() ([]APIToken, error) {
+	mock.listed++
+	tokens := []APIToken{}
+	for _, token := range mock.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (mock *tokenStorageMock) GetByHash(hash string) (APIToken, error) {
+	for _, token := range mock.tokens {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return APIToken{}, ErrTokenNotFound
+}
+
+func (mock *tokenStorageMock) Save(token APIToken) error {
+	mock.tokens[token.ID] = token
+	return nil
+}
+
+func (mock *tokenStorageMock) Delete(id string) error {
+	if _, ok := mock.tokens[id]; !ok {
+		return ErrTokenNotFound
+	}
+	delete(mock.tokens, id)
+	return nil
+}
+
+func TestTokenManager_CreateAndRevoke(t *testing.T) {
+	storage := &tokenStorageMock{tokens: map[string]APIToken{}}
+	manager := NewTokenManager(storage)
+
+	token, secret, err := manager.Create("dashboard", Scopes{ScopeStatus})
+	assert.NoError(t, err)
+	assert.True(t, IsAPIToken(secret))
+	assert.NotContains(t, token.Hash, secret)
+	assert.Equal(t, token, storage.tokens[token.ID])
+
+	scopes, err := manager.Scopes(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, Scopes{ScopeStatus}, scopes)
+
+	_, err = manager.Scopes(secret + "0")
+	assert.Equal(t, ErrUnauthorized, err)
+
+	assert.NoError(t, manager.Revoke(token.ID))
+	_, err = manager.Scopes(secret)
+	assert.Equal(t, ErrUnauthorized, err)
+}
+
+func TestScopes_Allows(t *testing.T) {
+	assert.True(t, Scopes{ScopeStatus}.Allows(ScopeStatus))
+	assert.False(t, Scopes{ScopeStatus}.Allows(ScopeConnection))
+	assert.True(t, Scopes{ScopeAdmin}.Allows(ScopeService))
+	assert.True(t, Scopes{ScopeConnection}.Allows(ScopeStatus))
+	assert.False(t, Scopes{}.Allows(ScopeStatus))
+
+	_, err := ParseScopes([]string{"status", "root"})
+	assert.EqualError(t, err, `unknown scope "root"`)
+}
+
+func TestAuthorizer_CredentialsRequired(t *testing.T) {
+	storage := &tokenStorageMock{tokens: map[string]APIToken{}}
+	tokens := NewTokenManager(storage)
+	authorizer := NewAuthorizer(NewJWTAuthenticator(JWTEncryptionKey("key")), tokens)
+
+	required, err := authorizer.CredentialsRequired()
+	assert.NoError(t, err)
+	assert.False(t, required)
+
+	token, _, err := tokens.Create("dashboard", Scopes{ScopeStatus})
+	assert.NoError(t, err)
+	required, err = authorizer.CredentialsRequired()
+	assert.NoError(t, err)
+	assert.True(t, required)
+	required, err = authorizer.CredentialsRequired()
+	assert.NoError(t, err)
+	assert.True(t, required)
+	assert.Equal(t, 2, storage.listed)
+
+	assert.NoError(t, tokens.Revoke(token.ID))
+	required, err = authorizer.CredentialsRequired()
+	assert.NoError(t, err)
+	assert.False(t, required)
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	jwtAuth := NewJWTAuthenticator(JWTEncryptionKey("key"))
+	tokens := NewTokenManager(&tokenStorageMock{tokens: map[string]APIToken{}})
+	authorizer := NewAuthorizer(jwtAuth, tokens)
+
+	_, secret, err := tokens.Create("dashboard", Scopes{ScopeStatus})
+	assert.NoError(t, err)
+	jwtToken, err := jwtAuth.CreateToken("myst")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, ErrNoCredentials, authorizer.Authorize(req, ScopeStatus))
+
+	req.Header.Set("Authorization", "Bearer "+secret)
+	assert.NoError(t, authorizer.Authorize(req, ScopeStatus))
+	assert.Equal(t, ErrForbidden, authorizer.Authorize(req, ScopeConnection))
+
+	req.Header.Set("Authorization", "Bearer myst_invalid")
+	assert.Equal(t, ErrUnauthorized, authorizer.Authorize(req, ScopeStatus))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: JWTCookieName, Value: jwtToken.Token})
+	assert.NoError(t, authorizer.Authorize(req, ScopeAdmin))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: JWTCookieName, Value: "invalid"})
+	assert.Equal(t, ErrUnauthorized, authorizer.Authorize(req, ScopeStatus))
+}
@@ -23,11 +23,27 @@ import (
 	"github.com/mysteriumnetwork/node/session"
 )
 
+// ProposalLookup returns proposals which the connection can fail over to
+type ProposalLookup func() ([]market.ServiceProposal, error)
+
 // ConnectParams holds plugin specific params
 type ConnectParams struct {
 	// kill switch option restricting communication only through VPN
 	DisableKillSwitch bool
 	EnableDNS         bool
+
+	// Failover enables switching to the next proposal returned by ProposalLookup once the connection is lost
+	Failover       bool
+	ProposalLookup ProposalLookup
+
+	// Hops are the proposals chained behind the connected proposal, ordered from the entry towards the exit
+	Hops []market.ServiceProposal
+
+	// SplitTunnel lets the given destinations bypass the tunnel or limits the tunnel to them
+	SplitTunnel SplitTunnel
+
+	// DisableRelay stops the connection from falling back to the relay offered by provider when NAT hole punching fails
+	DisableRelay bool
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
@@ -38,4 +54,10 @@ type ConnectOptions struct {
 	SessionID     session.ID
 	EnableDNS     bool
 	SessionConfig []byte
+	// ChainedThrough is the tunnel interface of the previous hop, empty for the entry hop
+	ChainedThrough string
+	// Routes are the split tunneling networks, all traffic is tunneled when empty
+	Routes TunnelRoutes
+	// DisableRelay stops falling back to the relay when NAT hole punching fails
+	DisableRelay bool
 }
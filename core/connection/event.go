@@ -17,7 +17,10 @@
 
 package connection
 
-// Topic represents the different topics a consumer can subscribe to
+import "github.com/mysteriumnetwork/node/consumer"
+
+// Topic represents the different topics a consumer can subscribe to.
+// Events of every connection are published, the connection is identified by the ConnectionID of the event.
 const (
 	// StateEventTopic represents the connection state change topic
 	StateEventTopic = "State"
@@ -25,12 +28,15 @@ const (
 	StatisticsEventTopic = "Statistics"
 	// SessionEventTopic represents the session event
 	SessionEventTopic = "Session"
+	// HopStatisticsEventTopic represents the stats topic of every hop in chained connection
+	HopStatisticsEventTopic = "HopStatistics"
 )
 
 // StateEvent is the struct we'll emit on a StateEvent topic event
 type StateEvent struct {
-	State       State
-	SessionInfo SessionInfo
+	ConnectionID string
+	State        State
+	SessionInfo  SessionInfo
 }
 
 const (
@@ -38,10 +44,31 @@ const (
 	SessionCreatedStatus = "Created"
 	// SessionEndedStatus represents a session end
 	SessionEndedStatus = "Ended"
+	// SessionFailoverStatus represents a session which replaced the lost one during failover
+	SessionFailoverStatus = "Failover"
+	// SessionRelayedStatus represents a session connected through the relay since NAT hole punching failed
+	SessionRelayedStatus = "Relayed"
+	// SessionReconnectedStatus represents a session which replaced the one to the same provider after the network change
+	SessionReconnectedStatus = "Reconnected"
 )
 
 // SessionEvent represents a session related event
 type SessionEvent struct {
-	Status      string
-	SessionInfo SessionInfo
+	ConnectionID string
+	Status       string
+	SessionInfo  SessionInfo
+}
+
+// HopStatisticsEvent represents statistics of a single hop in chained connection
+type HopStatisticsEvent struct {
+	ConnectionID string
+	SessionInfo  SessionInfo
+	Stats        consumer.SessionStatistics
+}
+
+// StatisticsEvent represents statistics of the connection with the given ID
+type StatisticsEvent struct {
+	ConnectionID string
+	SessionInfo  SessionInfo
+	Stats        consumer.SessionStatistics
 }
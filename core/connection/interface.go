@@ -18,6 +18,8 @@
 package connection
 
 import (
+	"context"
+
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/consumer"
 	"github.com/mysteriumnetwork/node/identity"
@@ -38,6 +40,23 @@ type Connection interface {
 	GetConfig() (ConsumerConfig, error)
 }
 
+// Tunnel is implemented by connections which can be chained with other connections,
+// traffic of the next hop is routed through the tunnel interface of the previous one
+type Tunnel interface {
+	InterfaceName() string
+}
+
+// Relayable is implemented by connections which fall back to the relay when NAT hole punching fails
+type Relayable interface {
+	Relayed() bool
+}
+
+// Roamer is implemented by connections which survive the change of the network by updating their endpoint,
+// they are not reconnected once the network changes. Roaming gives up once the given context is canceled.
+type Roamer interface {
+	Roam(ctx context.Context) error
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan State
 
@@ -20,15 +20,12 @@ package connection
 import (
 	"context"
 	"sync"
-	"time"
 
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/consumer"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
-	"github.com/mysteriumnetwork/node/money"
-	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/balance"
 	"github.com/mysteriumnetwork/node/session/promise"
@@ -46,8 +43,18 @@ var (
 	ErrConnectionFailed = errors.New("connection has failed")
 	// ErrUnsupportedServiceType indicates that target proposal contains unsupported service type
 	ErrUnsupportedServiceType = errors.New("unsupported service type in proposal")
+	// ErrNoFailoverProposal indicates that there are no more proposals left to fail over to
+	ErrNoFailoverProposal = errors.New("no proposal to fail over to")
+	// ErrChainingUnsupported indicates that connection of some hop can not be chained with other connections
+	ErrChainingUnsupported = errors.New("connection chaining is not supported by the service type")
+	// ErrIncludeWithKillSwitch indicates that the tunnel is limited to included destinations while the kill switch
+	// would block all the other traffic
+	ErrIncludeWithKillSwitch = errors.New("kill switch must be disabled to limit the tunnel to included destinations")
 )
 
+// reconnectAttempts is the number of times the connection to the same provider is retried on reconnect
+const reconnectAttempts = 3
+
 // Creator creates new connection by given options and uses state channel to report state changes
 type Creator func(serviceType string, stateChannel StateChannel, statisticsChannel StatisticsChannel) (Connection, error)
 
@@ -56,6 +63,7 @@ type SessionInfo struct {
 	SessionID   session.ID
 	ConsumerID  identity.Identity
 	Proposal    market.ServiceProposal
+	Relayed     bool
 	acknowledge func()
 }
 
@@ -73,26 +81,37 @@ type PaymentIssuer interface {
 // PaymentIssuerFactory creates a new payment issuer from the given params
 type PaymentIssuerFactory func(
 	initialState promise.PaymentInfo,
-	paymentDefinition dto.PaymentPerTime,
+	paymentMethod market.PaymentMethod,
+	getTransferred func() session.DataTransfered,
 	messageChan chan balance.Message,
 	dialog communication.Dialog,
 	consumer, provider identity.Identity) (PaymentIssuer, error)
 
 type connectionManager struct {
 	//these are passed on creation
+	id                   string
 	newDialog            DialogCreator
 	paymentIssuerFactory PaymentIssuerFactory
 	newConnection        Creator
 	eventPublisher       Publisher
 
 	//these are populated by Connect at runtime
-	ctx         context.Context
-	status      Status
-	statusLock  sync.RWMutex
-	sessionInfo SessionInfo
-	cleanup     []func() error
-	cancel      func()
-
+	ctx                context.Context
+	cancel             func()
+	interrupted        bool
+	ctxLock            sync.Mutex
+	status             Status
+	statusLock         sync.RWMutex
+	sessionInfo        SessionInfo
+	proposal           market.ServiceProposal
+	consumerID         identity.Identity
+	params             ConnectParams
+	routes             TunnelRoutes
+	connections        []Connection
+	cleanup            []func() error
+	removeTrafficBlock firewall.RemoveRule
+
+	// discoLock is held while the connection is established, replaced or torn down
 	discoLock sync.Mutex
 }
 
@@ -102,8 +121,19 @@ func NewManager(
 	paymentIssuerFactory PaymentIssuerFactory,
 	connectionCreator Creator,
 	eventPublisher Publisher,
+) *connectionManager {
+	return newConnectionManager(DefaultConnectionID, dialogCreator, paymentIssuerFactory, connectionCreator, eventPublisher)
+}
+
+func newConnectionManager(
+	id string,
+	dialogCreator DialogCreator,
+	paymentIssuerFactory PaymentIssuerFactory,
+	connectionCreator Creator,
+	eventPublisher Publisher,
 ) *connectionManager {
 	return &connectionManager{
+		id:                   id,
 		newDialog:            dialogCreator,
 		paymentIssuerFactory: paymentIssuerFactory,
 		newConnection:        connectionCreator,
@@ -118,20 +148,65 @@ func (manager *connectionManager) Connect(consumerID identity.Identity, proposal
 		return ErrAlreadyExists
 	}
 
-	manager.ctx, manager.cancel = context.WithCancel(context.Background())
+	routes, err := params.SplitTunnel.Resolve()
+	if err != nil {
+		return err
+	}
+	if len(routes.Include) > 0 && !params.DisableKillSwitch {
+		return ErrIncludeWithKillSwitch
+	}
+
+	manager.resetInterrupt()
+	manager.statusLock.Lock()
+	manager.consumerID = consumerID
+	manager.params = params
+	manager.routes = routes
+	manager.status = statusConnecting()
+	manager.statusLock.Unlock()
+	manager.discoLock.Lock()
+	err = manager.connect(proposal)
+	manager.discoLock.Unlock()
+	if err != nil && manager.isInterrupted() {
+		// connection is being disconnected, it is cleaned up there
+		log.Info("connection initiation interrupted: ", err)
+		manager.onStateChanged(Canceled)
+	} else if err != nil {
+		log.Info("cancelling connection initiation: ", err)
+		manager.Cancel()
+		manager.setStatus(statusNotConnected())
+	}
+	if err == context.Canceled {
+		return ErrConnectionCancelled
+	}
+	return err
+}
 
-	manager.setStatus(statusConnecting())
-	defer func() {
+// connect establishes connection to the given proposal and chains all the hops from params behind it.
+// Every hop is started only when the previous one is connected, so its dialog, NAT pinging
+// and payments go through the tunnel of the previous hop. It has to be called holding the discoLock.
+func (manager *connectionManager) connect(proposal market.ServiceProposal) error {
+	manager.newContext()
+	manager.proposal = proposal
+
+	proposals := append([]market.ServiceProposal{proposal}, manager.params.Hops...)
+	var parent Connection
+	for i, hopProposal := range proposals {
+		connection, err := manager.connectHop(hopProposal, parent, i == len(proposals)-1)
 		if err != nil {
-			manager.setStatus(statusNotConnected())
+			return err
 		}
-	}()
+		parent = connection
+	}
+	return nil
+}
 
+func (manager *connectionManager) connectHop(proposal market.ServiceProposal, parent Connection, exit bool) (Connection, error) {
+	consumerID := manager.consumerID
 	providerID := identity.FromAddress(proposal.ProviderID)
 
 	dialog, err := manager.createDialog(consumerID, providerID, proposal.ProviderContacts[0])
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	stateChannel := make(chan State, 10)
@@ -139,27 +214,79 @@ func (manager *connectionManager) Connect(consumerID identity.Identity, proposal
 
 	connection, err := manager.newConnection(proposal.ServiceType, stateChannel, statisticsChannel)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if _, ok := connection.(Tunnel); manager.isChained() && !ok {
+		return nil, ErrChainingUnsupported
 	}
 
-	sessionDTO, paymentInfo, err := manager.createSession(connection, dialog, consumerID, proposal)
+	sessionDTO, paymentInfo, sessionInfo, err := manager.createSession(connection, dialog, consumerID, proposal)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = manager.launchPayments(paymentInfo, dialog, consumerID, providerID)
+	transferred := &dataTransferCounter{}
+	err = manager.launchPayments(paymentInfo, proposal.PaymentMethod, transferred.get, dialog, consumerID, providerID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = manager.startConnection(connection, consumerID, proposal, params, sessionDTO, stateChannel, statisticsChannel)
-	if err == context.Canceled {
-		return ErrConnectionCancelled
+	var chainedThrough string
+	if parent != nil {
+		chainedThrough = parent.(Tunnel).InterfaceName()
 	}
-	return err
+
+	hop := hopInfo{
+		sessionInfo:    sessionInfo,
+		sessionConfig:  sessionDTO.Config,
+		chainedThrough: chainedThrough,
+		exit:           exit,
+		transferred:    transferred,
+	}
+	return connection, manager.startConnection(connection, hop, stateChannel, statisticsChannel)
+}
+
+// hopInfo describes a single connection of the (possibly chained) connection
+type hopInfo struct {
+	sessionInfo    SessionInfo
+	sessionConfig  []byte
+	chainedThrough string
+	exit           bool
+	transferred    *dataTransferCounter
+}
+
+// dataTransferCounter keeps the data transferred through the connection, payments per bytes are charged by it
+type dataTransferCounter struct {
+	lock        sync.RWMutex
+	transferred session.DataTransfered
+}
+
+func (counter *dataTransferCounter) set(stats consumer.SessionStatistics) {
+	counter.lock.Lock()
+	defer counter.lock.Unlock()
+	counter.transferred = session.DataTransfered{
+		Up:   int64(stats.BytesSent),
+		Down: int64(stats.BytesReceived),
+	}
+}
+
+func (counter *dataTransferCounter) get() session.DataTransfered {
+	counter.lock.RLock()
+	defer counter.lock.RUnlock()
+	return counter.transferred
 }
 
-func (manager *connectionManager) launchPayments(paymentInfo *promise.PaymentInfo, dialog communication.Dialog, consumerID, providerID identity.Identity) error {
+func (manager *connectionManager) isChained() bool {
+	return len(manager.connectParams().Hops) > 0
+}
+
+func (manager *connectionManager) launchPayments(
+	paymentInfo *promise.PaymentInfo,
+	paymentMethod market.PaymentMethod,
+	getTransferred func() session.DataTransfered,
+	dialog communication.Dialog,
+	consumerID, providerID identity.Identity,
+) error {
 	var promiseState promise.PaymentInfo
 	if paymentInfo != nil {
 		promiseState.FreeCredit = paymentInfo.FreeCredit
@@ -168,16 +295,7 @@ func (manager *connectionManager) launchPayments(paymentInfo *promise.PaymentInf
 
 	messageChan := make(chan balance.Message, 1)
 
-	// TODO: set the time and proper payment info
-	payment := dto.PaymentPerTime{
-		Price: money.Money{
-			Currency: money.CurrencyMyst,
-			Amount:   uint64(0),
-		},
-		Duration: time.Minute,
-	}
-
-	payments, err := manager.paymentIssuerFactory(promiseState, payment, messageChan, dialog, consumerID, providerID)
+	payments, err := manager.paymentIssuerFactory(promiseState, paymentMethod, getTransferred, messageChan, dialog, consumerID, providerID)
 	if err != nil {
 		return err
 	}
@@ -191,8 +309,49 @@ func (manager *connectionManager) launchPayments(paymentInfo *promise.PaymentInf
 	return nil
 }
 
+// newContext replaces the context of the connection, it is canceled right away if the manager is being disconnected
+func (manager *connectionManager) newContext() {
+	manager.ctxLock.Lock()
+	defer manager.ctxLock.Unlock()
+
+	manager.ctx, manager.cancel = context.WithCancel(context.Background())
+	if manager.interrupted {
+		manager.cancel()
+	}
+}
+
+// interrupt cancels the connection being established, so the discoLock held by it is released as soon as possible.
+// Connections established after this are canceled as well until the interrupt is reset.
+func (manager *connectionManager) interrupt() {
+	manager.ctxLock.Lock()
+	defer manager.ctxLock.Unlock()
+
+	manager.interrupted = true
+	if manager.cancel != nil {
+		manager.cancel()
+	}
+}
+
+func (manager *connectionManager) isInterrupted() bool {
+	manager.ctxLock.Lock()
+	defer manager.ctxLock.Unlock()
+
+	return manager.interrupted
+}
+
+func (manager *connectionManager) resetInterrupt() {
+	manager.ctxLock.Lock()
+	defer manager.ctxLock.Unlock()
+
+	manager.interrupted = false
+}
+
 func (manager *connectionManager) cleanConnection() {
-	manager.cancel()
+	manager.ctxLock.Lock()
+	if manager.cancel != nil {
+		manager.cancel()
+	}
+	manager.ctxLock.Unlock()
 	for i := len(manager.cleanup) - 1; i >= 0; i-- {
 		err := manager.cleanup[i]()
 		if err != nil {
@@ -200,6 +359,7 @@ func (manager *connectionManager) cleanConnection() {
 		}
 	}
 	manager.cleanup = make([]func() error, 0)
+	manager.connections = nil
 }
 
 func (manager *connectionManager) createDialog(consumerID, providerID identity.Identity, contact market.Contact) (communication.Dialog, error) {
@@ -212,10 +372,10 @@ func (manager *connectionManager) createDialog(consumerID, providerID identity.I
 	return dialog, err
 }
 
-func (manager *connectionManager) createSession(c Connection, dialog communication.Dialog, consumerID identity.Identity, proposal market.ServiceProposal) (session.SessionDto, *promise.PaymentInfo, error) {
+func (manager *connectionManager) createSession(c Connection, dialog communication.Dialog, consumerID identity.Identity, proposal market.ServiceProposal) (session.SessionDto, *promise.PaymentInfo, SessionInfo, error) {
 	sessionCreateConfig, err := c.GetConfig()
 	if err != nil {
-		return session.SessionDto{}, nil, err
+		return session.SessionDto{}, nil, SessionInfo{}, err
 	}
 
 	consumerInfo := session.ConsumerInfo{
@@ -225,13 +385,12 @@ func (manager *connectionManager) createSession(c Connection, dialog communicati
 
 	s, paymentInfo, err := session.RequestSessionCreate(dialog, proposal.ID, sessionCreateConfig, consumerInfo)
 	if err != nil {
-		return session.SessionDto{}, nil, err
+		return session.SessionDto{}, nil, SessionInfo{}, err
 	}
 
 	manager.cleanup = append(manager.cleanup, func() error { return session.RequestSessionDestroy(dialog, s.ID) })
 
-	// set the session info for future use
-	manager.sessionInfo = SessionInfo{
+	sessionInfo := SessionInfo{
 		SessionID:  s.ID,
 		ConsumerID: consumerID,
 		Proposal:   proposal,
@@ -243,72 +402,93 @@ func (manager *connectionManager) createSession(c Connection, dialog communicati
 		},
 	}
 
-	manager.eventPublisher.Publish(SessionEventTopic, SessionEvent{
-		Status:      SessionCreatedStatus,
-		SessionInfo: manager.sessionInfo,
-	})
+	// set the session info for future use, the last hop is the one representing the connection
+	manager.statusLock.Lock()
+	manager.sessionInfo = sessionInfo
+	manager.statusLock.Unlock()
+
+	manager.publishSession(SessionCreatedStatus, sessionInfo)
 
 	manager.cleanup = append(manager.cleanup, func() error {
-		manager.eventPublisher.Publish(SessionEventTopic, SessionEvent{
-			Status:      SessionEndedStatus,
-			SessionInfo: manager.sessionInfo,
-		})
+		manager.publishSession(SessionEndedStatus, sessionInfo)
 		return nil
 	})
 
-	return s, paymentInfo, nil
+	return s, paymentInfo, sessionInfo, nil
 }
 
 func (manager *connectionManager) startConnection(
 	connection Connection,
-	consumerID identity.Identity,
-	proposal market.ServiceProposal,
-	params ConnectParams,
-	sessionDTO session.SessionDto,
+	hop hopInfo,
 	stateChannel chan State,
 	statisticsChannel chan consumer.SessionStatistics) (err error) {
 
-	defer func() {
-		if err != nil {
-			log.Info("cancelling connection initiation: ", err)
-			manager.Cancel()
-		}
-	}()
-
 	connectOptions := ConnectOptions{
-		SessionID:     sessionDTO.ID,
-		SessionConfig: sessionDTO.Config,
-		EnableDNS:     params.EnableDNS,
-		ConsumerID:    consumerID,
-		ProviderID:    identity.FromAddress(proposal.ProviderID),
-		Proposal:      proposal,
+		SessionID:      hop.sessionInfo.SessionID,
+		SessionConfig:  hop.sessionConfig,
+		EnableDNS:      manager.params.EnableDNS && hop.exit,
+		ConsumerID:     hop.sessionInfo.ConsumerID,
+		ProviderID:     identity.FromAddress(hop.sessionInfo.Proposal.ProviderID),
+		Proposal:       hop.sessionInfo.Proposal,
+		ChainedThrough: hop.chainedThrough,
+		Routes:         manager.hopRoutes(hop),
+		DisableRelay:   manager.params.DisableRelay,
 	}
 
 	if err = connection.Start(connectOptions); err != nil {
 		return err
 	}
+	if relayable, ok := connection.(Relayable); ok && relayable.Relayed() {
+		manager.markRelayed(&hop)
+	}
+	manager.connections = append(manager.connections, connection)
 	manager.cleanup = append(manager.cleanup, func() error {
 		connection.Stop()
 		return nil
 	})
 
-	err = manager.setupTrafficBlock(params.DisableKillSwitch)
+	err = manager.setupTrafficBlock(manager.params.DisableKillSwitch)
 	if err != nil {
 		return err
 	}
 
 	//consume statistics right after start - openvpn3 will publish them even before connected state
-	go manager.consumeStats(statisticsChannel)
-	err = manager.waitForConnectedState(stateChannel, sessionDTO.ID)
+	go manager.consumeStats(hop, statisticsChannel)
+	err = manager.waitForConnectedState(stateChannel, hop)
 	if err != nil {
 		return err
 	}
 
-	go manager.consumeConnectionStates(stateChannel)
-	go manager.connectionWaiter(connection)
+	go manager.consumeConnectionStates(manager.ctx, stateChannel)
+	go manager.connectionWaiter(manager.ctx, connection)
 	return nil
 }
 
+// markRelayed marks the session of the hop relayed, so are the events published about it
+func (manager *connectionManager) markRelayed(hop *hopInfo) {
+	hop.sessionInfo.Relayed = true
+	manager.statusLock.Lock()
+	if manager.sessionInfo.SessionID == hop.sessionInfo.SessionID {
+		manager.sessionInfo.Relayed = true
+	}
+	manager.statusLock.Unlock()
+	manager.publishSession(SessionRelayedStatus, hop.sessionInfo)
+}
+
+// hopRoutes returns split tunneling networks of the hop, included networks are tunneled through
+// the exit hop only and excluded networks are routed around the tunnel by the entry hop only,
+// as the following hops are reached through it.
+func (manager *connectionManager) hopRoutes(hop hopInfo) TunnelRoutes {
+	var routes TunnelRoutes
+	if hop.exit {
+		routes.Include = manager.routes.Include
+	}
+	if hop.chainedThrough == "" {
+		routes.Exclude = manager.routes.Exclude
+	}
+	return routes
+}
+
 func (manager *connectionManager) Status() Status {
 	manager.statusLock.RLock()
 	defer manager.statusLock.RUnlock()
@@ -316,6 +496,31 @@ func (manager *connectionManager) Status() Status {
 	return manager.status
 }
 
+// consumer returns the identity the connection was started with, it is set together with the params
+func (manager *connectionManager) consumer() identity.Identity {
+	manager.statusLock.RLock()
+	defer manager.statusLock.RUnlock()
+
+	return manager.consumerID
+}
+
+// session returns the info of the session representing the connection, it is the session of the exit hop
+func (manager *connectionManager) session() SessionInfo {
+	manager.statusLock.RLock()
+	defer manager.statusLock.RUnlock()
+
+	return manager.sessionInfo
+}
+
+// connectParams returns the params the connection was started with,
+// they are set before the status leaves NotConnected and are not changed until the next Connect
+func (manager *connectionManager) connectParams() ConnectParams {
+	manager.statusLock.RLock()
+	defer manager.statusLock.RUnlock()
+
+	return manager.params
+}
+
 func (manager *connectionManager) setStatus(cs Status) {
 	manager.statusLock.Lock()
 	manager.status = cs
@@ -330,8 +535,15 @@ func (manager *connectionManager) Cancel() {
 }
 
 func (manager *connectionManager) Disconnect() error {
+	if manager.Status().State == NotConnected {
+		return ErrNoConnection
+	}
+	// connection being established or replaced holds the lock, it has to be interrupted first
+	manager.interrupt()
+
 	manager.discoLock.Lock()
 	defer manager.discoLock.Unlock()
+	defer manager.resetInterrupt()
 
 	if manager.Status().State == NotConnected {
 		return ErrNoConnection
@@ -339,12 +551,10 @@ func (manager *connectionManager) Disconnect() error {
 
 	manager.setStatus(statusDisconnecting())
 	manager.cleanConnection()
+	manager.releaseTrafficBlock()
 	manager.setStatus(statusNotConnected())
 
-	manager.eventPublisher.Publish(StateEventTopic, StateEvent{
-		State:       NotConnected,
-		SessionInfo: manager.sessionInfo,
-	})
+	manager.publishState(NotConnected)
 	return nil
 }
 
@@ -359,7 +569,7 @@ func (manager *connectionManager) payForService(payments PaymentIssuer) {
 	}
 }
 
-func (manager *connectionManager) connectionWaiter(connection Connection) {
+func (manager *connectionManager) connectionWaiter(ctx context.Context, connection Connection) {
 	err := connection.Wait()
 	if err != nil {
 		log.Warn("connection exited with error: ", err)
@@ -367,10 +577,172 @@ func (manager *connectionManager) connectionWaiter(connection Connection) {
 		log.Info("connection exited")
 	}
 
-	logDisconnectError(manager.Disconnect())
+	manager.onConnectionLost(ctx)
+}
+
+func (manager *connectionManager) onConnectionLost(ctx context.Context) {
+	if ctx.Err() != nil {
+		// connection was closed on purpose or is being reconnected
+		return
+	}
+	if !manager.connectParams().Failover {
+		logDisconnectError(manager.Disconnect())
+		return
+	}
+
+	manager.failover(ctx)
+}
+
+// failover replaces the lost connection with a connection to the next matching proposal.
+// Traffic block stays in place between the hops, so nothing leaks outside of the tunnel.
+func (manager *connectionManager) failover(lostCtx context.Context) {
+	manager.discoLock.Lock()
+	if lostCtx.Err() != nil {
+		// connection was closed on purpose or is already being replaced
+		manager.discoLock.Unlock()
+		return
+	}
+	lostSession := manager.session()
+	manager.onStateChanged(Reconnecting)
+	manager.cleanConnection()
+	ok := manager.failoverFrom(lostSession)
+	manager.discoLock.Unlock()
+
+	if !ok && !manager.isInterrupted() {
+		logDisconnectError(manager.Disconnect())
+	}
+}
+
+// failoverFrom connects to the proposals other than the one of the lost session, until one succeeds.
+// Connection is expected to be cleaned and in the Reconnecting state, discoLock has to be held.
+func (manager *connectionManager) failoverFrom(lostSession SessionInfo) bool {
+	tried := map[string]bool{lostSession.Proposal.ProviderID: true}
+	for _, hopProposal := range manager.params.Hops {
+		tried[hopProposal.ProviderID] = true
+	}
+	for !manager.isInterrupted() {
+		proposal, err := manager.nextProposal(tried)
+		if err != nil {
+			log.Error("failover failed: ", err)
+			return false
+		}
+		tried[proposal.ProviderID] = true
+
+		log.Info("failing over to provider: ", proposal.ProviderID)
+		err = manager.connect(proposal)
+		if err == nil {
+			manager.publishSession(SessionFailoverStatus, manager.session())
+			return true
+		}
+
+		log.Warn("failover to provider ", proposal.ProviderID, " failed: ", err)
+		manager.cleanConnection()
+	}
+	return false
+}
+
+// Reconnect re-establishes the connection to the same provider, i.e. once the network has changed
+// and the tunnel is bound to the addresses which are gone. Connections which are able to roam
+// just update their endpoint. If the provider is not reachable anymore,
+// the connection fails over to another one or disconnects as the lost one does.
+func (manager *connectionManager) Reconnect() {
+	manager.discoLock.Lock()
+	if manager.Status().State != Connected {
+		manager.discoLock.Unlock()
+		return
+	}
+	if manager.roam() || manager.isInterrupted() {
+		manager.discoLock.Unlock()
+		return
+	}
+	ok := manager.reconnect()
+	manager.discoLock.Unlock()
+
+	if !ok && !manager.isInterrupted() {
+		logDisconnectError(manager.Disconnect())
+	}
+}
+
+// reconnect replaces the connection with the new one to the same provider, failing over to another one if allowed.
+// It has to be called holding the discoLock.
+func (manager *connectionManager) reconnect() bool {
+	lostSession := manager.session()
+	proposal := manager.proposal
+	manager.onStateChanged(Reconnecting)
+	manager.cleanConnection()
+
+	for attempt := 0; attempt < reconnectAttempts && !manager.isInterrupted(); attempt++ {
+		log.Info("reconnecting to provider: ", proposal.ProviderID)
+		err := manager.connect(proposal)
+		if err == nil {
+			manager.publishSession(SessionReconnectedStatus, manager.session())
+			return true
+		}
+
+		log.Warn("reconnect to provider ", proposal.ProviderID, " failed: ", err)
+		manager.cleanConnection()
+	}
+
+	if manager.isInterrupted() {
+		// disconnected in the meantime
+		return false
+	}
+	if manager.params.Failover {
+		return manager.failoverFrom(lostSession)
+	}
+	return false
 }
 
-func (manager *connectionManager) waitForConnectedState(stateChannel <-chan State, sessionID session.ID) error {
+// roam lets every connection of the chain update its endpoint in the new network,
+// reports false if any of them is not able to, so the connection has to be reestablished.
+// Roaming is canceled along with the context of the connection once the manager is being disconnected.
+func (manager *connectionManager) roam() bool {
+	if len(manager.connections) == 0 {
+		return false
+	}
+	for _, connection := range manager.connections {
+		if _, ok := connection.(Roamer); !ok {
+			return false
+		}
+	}
+
+	manager.ctxLock.Lock()
+	ctx := manager.ctx
+	manager.ctxLock.Unlock()
+
+	for _, connection := range manager.connections {
+		if ctx.Err() != nil {
+			log.Info("roaming canceled: ", ctx.Err())
+			return false
+		}
+		if err := connection.(Roamer).Roam(ctx); err != nil {
+			log.Warn("connection failed to roam, reconnecting: ", err)
+			return false
+		}
+	}
+	log.Info("connection roamed to the new network")
+	return true
+}
+
+func (manager *connectionManager) nextProposal(tried map[string]bool) (market.ServiceProposal, error) {
+	if manager.params.ProposalLookup == nil {
+		return market.ServiceProposal{}, ErrNoFailoverProposal
+	}
+
+	proposals, err := manager.params.ProposalLookup()
+	if err != nil {
+		return market.ServiceProposal{}, errors.Wrap(err, "failed to lookup proposals")
+	}
+
+	for _, proposal := range proposals {
+		if !tried[proposal.ProviderID] {
+			return proposal, nil
+		}
+	}
+	return market.ServiceProposal{}, ErrNoFailoverProposal
+}
+
+func (manager *connectionManager) waitForConnectedState(stateChannel <-chan State, hop hopInfo) error {
 	log.Trace("waiting for connected state")
 	for {
 		select {
@@ -382,8 +754,10 @@ func (manager *connectionManager) waitForConnectedState(stateChannel <-chan Stat
 			switch state {
 			case Connected:
 				log.Trace("connected started event received")
-				go manager.sessionInfo.acknowledge()
-				manager.onStateChanged(state)
+				go hop.sessionInfo.acknowledge()
+				if hop.exit {
+					manager.onStateChanged(state)
+				}
 				return nil
 			default:
 				manager.onStateChanged(state)
@@ -394,53 +768,107 @@ func (manager *connectionManager) waitForConnectedState(stateChannel <-chan Stat
 	}
 }
 
-func (manager *connectionManager) consumeConnectionStates(stateChannel <-chan State) {
+func (manager *connectionManager) consumeConnectionStates(ctx context.Context, stateChannel <-chan State) {
 	for state := range stateChannel {
 		manager.onStateChanged(state)
 	}
 
 	log.Debug("state updater stopCalled")
-	logDisconnectError(manager.Disconnect())
+	manager.onConnectionLost(ctx)
 }
 
-func (manager *connectionManager) consumeStats(statisticsChannel <-chan consumer.SessionStatistics) {
+func (manager *connectionManager) consumeStats(hop hopInfo, statisticsChannel <-chan consumer.SessionStatistics) {
 	for stats := range statisticsChannel {
-		manager.eventPublisher.Publish(StatisticsEventTopic, stats)
+		hop.transferred.set(stats)
+		if manager.isChained() {
+			manager.eventPublisher.Publish(HopStatisticsEventTopic, HopStatisticsEvent{
+				ConnectionID: manager.id,
+				SessionInfo:  hop.sessionInfo,
+				Stats:        stats,
+			})
+		}
+		if hop.exit {
+			manager.publishStatistics(hop.sessionInfo, stats)
+		}
 	}
 }
 
 func (manager *connectionManager) onStateChanged(state State) {
 	log.Trace("onStateChanged called")
-	manager.eventPublisher.Publish(StateEventTopic, StateEvent{
-		State:       state,
-		SessionInfo: manager.sessionInfo,
-	})
+	manager.publishState(state)
 
 	switch state {
 	case Connected:
 		log.Trace("connected state issued")
-		manager.setStatus(statusConnected(manager.sessionInfo.SessionID, manager.sessionInfo.Proposal))
+		sessionInfo := manager.session()
+		manager.setStatus(statusConnected(sessionInfo.SessionID, sessionInfo.Proposal))
 	case Reconnecting:
 		manager.setStatus(statusReconnecting())
 	}
 }
 
+func (manager *connectionManager) publishState(state State) {
+	manager.eventPublisher.Publish(StateEventTopic, StateEvent{
+		ConnectionID: manager.id,
+		State:        state,
+		SessionInfo:  manager.session(),
+	})
+}
+
+func (manager *connectionManager) publishSession(status string, sessionInfo SessionInfo) {
+	manager.eventPublisher.Publish(SessionEventTopic, SessionEvent{
+		ConnectionID: manager.id,
+		Status:       status,
+		SessionInfo:  sessionInfo,
+	})
+}
+
+func (manager *connectionManager) publishStatistics(sessionInfo SessionInfo, stats consumer.SessionStatistics) {
+	manager.eventPublisher.Publish(StatisticsEventTopic, StatisticsEvent{
+		ConnectionID: manager.id,
+		SessionInfo:  sessionInfo,
+		Stats:        stats,
+	})
+}
+
 func (manager *connectionManager) setupTrafficBlock(disableKillSwitch bool) error {
-	if disableKillSwitch {
+	if disableKillSwitch || manager.removeTrafficBlock != nil {
 		return nil
 	}
 
+	removeRules := make([]firewall.RemoveRule, 0, len(manager.routes.Exclude)+1)
+	removeAll := func() {
+		for i := len(removeRules) - 1; i >= 0; i-- {
+			removeRules[i]()
+		}
+	}
+
 	removeRule, err := firewall.BlockNonTunnelTraffic(firewall.Session)
 	if err != nil {
 		return err
 	}
-	manager.cleanup = append(manager.cleanup, func() error {
-		removeRule()
-		return nil
-	})
+	removeRules = append(removeRules, removeRule)
+
+	for _, network := range manager.routes.Exclude {
+		removeRule, err := firewall.AllowIPAccess(network.String())
+		if err != nil {
+			removeAll()
+			return errors.Wrap(err, "failed to allow excluded network "+network.String())
+		}
+		removeRules = append(removeRules, removeRule)
+	}
+
+	manager.removeTrafficBlock = removeAll
 	return nil
 }
 
+func (manager *connectionManager) releaseTrafficBlock() {
+	if manager.removeTrafficBlock != nil {
+		manager.removeTrafficBlock()
+		manager.removeTrafficBlock = nil
+	}
+}
+
 func logDisconnectError(err error) {
 	if err != nil && err != ErrNoConnection {
 		log.Error("disconnect error", err)
@@ -28,7 +28,6 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
-	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/balance"
 	"github.com/mysteriumnetwork/node/session/promise"
@@ -79,14 +78,16 @@ func (tc *testContext) SetupTest() {
 	}
 
 	mockPaymentFactory := func(initialState promise.PaymentInfo,
-		paymentDefinition dto.PaymentPerTime,
+		paymentMethod market.PaymentMethod,
+		getTransferred func() session.DataTransfered,
 		messageChan chan balance.Message,
 		dialog communication.Dialog,
 		consumer, provider identity.Identity) (PaymentIssuer, error) {
 		tc.MockPaymentIssuer = &MockPaymentIssuer{
-			initialState:      initialState,
-			paymentDefinition: paymentDefinition,
-			stopChan:          make(chan struct{}),
+			initialState:   initialState,
+			paymentMethod:  paymentMethod,
+			getTransferred: getTransferred,
+			stopChan:       make(chan struct{}),
 		}
 		return tc.MockPaymentIssuer, nil
 	}
@@ -116,6 +117,7 @@ func (tc *testContext) SetupTest() {
 			tc.mockStatistics,
 			sync.WaitGroup{},
 			nil,
+			false,
 			sync.RWMutex{},
 		},
 	}
@@ -332,6 +334,27 @@ func (tc *testContext) Test_ManagerSetsPaymentInfo() {
 	assert.Exactly(tc.T(), *paymentInfo, tc.MockPaymentIssuer.initialState)
 }
 
+func (tc *testContext) Test_ManagerPaysByConnectionStatistics() {
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{
+		connectedState,
+	}
+
+	err := tc.connManager.Connect(consumerID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	waitABit()
+
+	assert.Equal(tc.T(), activeProposal.PaymentMethod, tc.MockPaymentIssuer.paymentMethod)
+	assert.Equal(
+		tc.T(),
+		session.DataTransfered{
+			Up:   int64(tc.mockStatistics.BytesSent),
+			Down: int64(tc.mockStatistics.BytesReceived),
+		},
+		tc.MockPaymentIssuer.getTransferred(),
+	)
+}
+
 func (tc *testContext) Test_ManagerPublishesEvents() {
 	tc.stubPublisher.Clear()
 
@@ -349,12 +372,14 @@ func (tc *testContext) Test_ManagerPublishesEvents() {
 
 	for _, v := range history {
 		if v.calledWithTopic == StatisticsEventTopic {
-			event := v.calledWithData.(consumer.SessionStatistics)
-			assert.True(tc.T(), event.BytesReceived == tc.mockStatistics.BytesReceived)
-			assert.True(tc.T(), event.BytesSent == tc.mockStatistics.BytesSent)
+			event := v.calledWithData.(StatisticsEvent)
+			assert.Equal(tc.T(), DefaultConnectionID, event.ConnectionID)
+			assert.True(tc.T(), event.Stats.BytesReceived == tc.mockStatistics.BytesReceived)
+			assert.True(tc.T(), event.Stats.BytesSent == tc.mockStatistics.BytesSent)
 		}
 		if v.calledWithTopic == StateEventTopic {
 			event := v.calledWithData.(StateEvent)
+			assert.Equal(tc.T(), DefaultConnectionID, event.ConnectionID)
 			assert.Equal(tc.T(), Connected, event.State)
 			assert.Equal(tc.T(), consumerID, event.SessionInfo.ConsumerID)
 			assert.Equal(tc.T(), establishedSessionID, event.SessionInfo.SessionID)
@@ -363,6 +388,7 @@ func (tc *testContext) Test_ManagerPublishesEvents() {
 		}
 		if v.calledWithTopic == SessionEventTopic {
 			event := v.calledWithData.(SessionEvent)
+			assert.Equal(tc.T(), DefaultConnectionID, event.ConnectionID)
 			assert.Equal(tc.T(), SessionCreatedStatus, event.Status)
 			assert.Equal(tc.T(), consumerID, event.SessionInfo.ConsumerID)
 			assert.Equal(tc.T(), establishedSessionID, event.SessionInfo.SessionID)
@@ -372,6 +398,347 @@ func (tc *testContext) Test_ManagerPublishesEvents() {
 	}
 }
 
+func (tc *testContext) Test_ManagerPublishesRelayedSession() {
+	tc.stubPublisher.Clear()
+	tc.fakeConnectionFactory.mockConnection.relayed = true
+
+	err := tc.connManager.Connect(consumerID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	waitABit()
+
+	relayedPublished := false
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == SessionEventTopic && v.calledWithData.(SessionEvent).Status == SessionRelayedStatus {
+			relayedPublished = true
+			assert.True(tc.T(), v.calledWithData.(SessionEvent).SessionInfo.Relayed)
+		}
+		if v.calledWithTopic == StateEventTopic {
+			assert.True(tc.T(), v.calledWithData.(StateEvent).SessionInfo.Relayed)
+		}
+	}
+	assert.True(tc.T(), relayedPublished)
+}
+
+func (tc *testContext) Test_ManagerFailsOverToNextProposal_WhenConnectionIsLost() {
+	tc.fakeConnectionFactory.mockConnection.onStopReportStates = []fakeState{}
+	failoverProposal := market.ServiceProposal{
+		ProviderID:        "fake-node-2",
+		ProviderContacts:  []market.Contact{activeProviderContact},
+		ServiceType:       activeServiceType,
+		ServiceDefinition: &fakeServiceDefinition{},
+	}
+	params := ConnectParams{
+		Failover: true,
+		ProposalLookup: func() ([]market.ServiceProposal, error) {
+			return []market.ServiceProposal{activeProposal, failoverProposal}, nil
+		},
+	}
+
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, params))
+	tc.stubPublisher.Clear()
+
+	tc.fakeConnectionFactory.mockConnection.reportState(processExited)
+	waitABit()
+
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, failoverProposal), tc.connManager.Status())
+
+	found := false
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == SessionEventTopic {
+			event := v.calledWithData.(SessionEvent)
+			if event.Status == SessionFailoverStatus {
+				found = true
+				assert.Equal(tc.T(), failoverProposal.ProviderID, event.SessionInfo.Proposal.ProviderID)
+			}
+		}
+	}
+	assert.True(tc.T(), found)
+}
+
+func (tc *testContext) Test_ManagerDisconnects_WhenNoProposalIsLeftForFailover() {
+	tc.fakeConnectionFactory.mockConnection.onStopReportStates = []fakeState{}
+	params := ConnectParams{
+		Failover: true,
+		ProposalLookup: func() ([]market.ServiceProposal, error) {
+			return []market.ServiceProposal{activeProposal}, nil
+		},
+	}
+
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, params))
+
+	tc.fakeConnectionFactory.mockConnection.reportState(processExited)
+	waitABit()
+
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+}
+
+func (tc *testContext) Test_ManagerDisconnects_WhenDisconnectedDuringFailover() {
+	tc.fakeConnectionFactory.mockConnection.onStopReportStates = []fakeState{}
+	failoverProposal := market.ServiceProposal{
+		ProviderID:        "fake-node-2",
+		ProviderContacts:  []market.Contact{activeProviderContact},
+		ServiceType:       activeServiceType,
+		ServiceDefinition: &fakeServiceDefinition{},
+	}
+	params := ConnectParams{
+		Failover: true,
+		ProposalLookup: func() ([]market.ServiceProposal, error) {
+			return []market.ServiceProposal{activeProposal, failoverProposal}, nil
+		},
+	}
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, params))
+
+	// failover connection never gets connected
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{}
+	tc.fakeConnectionFactory.mockConnection.reportState(processExited)
+	waitABit()
+	assert.Equal(tc.T(), statusReconnecting(), tc.connManager.Status())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	waitABit()
+
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+	assert.Equal(tc.T(), ErrNoConnection, tc.connManager.Disconnect())
+}
+
+func (tc *testContext) Test_ManagerDisconnects_WhenDisconnectedDuringReconnect() {
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
+
+	// new connection never gets connected
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{}
+	go tc.connManager.Reconnect()
+	waitABit()
+	assert.Equal(tc.T(), statusReconnecting(), tc.connManager.Status())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	waitABit()
+
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+}
+
+func (tc *testContext) Test_ManagerReconnectsToSameProvider() {
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
+	tc.stubPublisher.Clear()
+
+	tc.connManager.Reconnect()
+	waitABit()
+
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+
+	var sessionStatuses []string
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == SessionEventTopic {
+			event := v.calledWithData.(SessionEvent)
+			sessionStatuses = append(sessionStatuses, event.Status)
+			assert.Equal(tc.T(), activeProposal.ProviderID, event.SessionInfo.Proposal.ProviderID)
+		}
+	}
+	assert.Equal(tc.T(), []string{SessionEndedStatus, SessionCreatedStatus, SessionReconnectedStatus}, sessionStatuses)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) Test_ManagerReconnectIsSkipped_WhenNotConnected() {
+	tc.stubPublisher.Clear()
+
+	tc.connManager.Reconnect()
+
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+	assert.Empty(tc.T(), tc.stubPublisher.GetEventHistory())
+}
+
+func (tc *testContext) Test_ManagerRoamsConnection_InsteadOfReconnecting() {
+	tc.fakeConnectionFactory.roamable = true
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
+	tc.stubPublisher.Clear()
+
+	tc.connManager.Reconnect()
+
+	assert.Equal(tc.T(), 1, tc.fakeConnectionFactory.roamed)
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	assert.Empty(tc.T(), tc.stubPublisher.GetEventHistory())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) Test_ManagerReconnects_WhenRoamingFails() {
+	tc.fakeConnectionFactory.roamable = true
+	tc.fakeConnectionFactory.roamError = errors.New("endpoint update failed")
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
+	tc.stubPublisher.Clear()
+
+	tc.connManager.Reconnect()
+	waitABit()
+
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	reconnected := false
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == SessionEventTopic && v.calledWithData.(SessionEvent).Status == SessionReconnectedStatus {
+			reconnected = true
+		}
+	}
+	assert.True(tc.T(), reconnected)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) Test_ManagerDisconnect_CancelsRoaming() {
+	tc.fakeConnectionFactory.roamable = true
+	tc.fakeConnectionFactory.roamBlock = true
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
+
+	reconnected := make(chan struct{})
+	go func() {
+		tc.connManager.Reconnect()
+		close(reconnected)
+	}()
+	waitABit()
+
+	disconnected := make(chan error)
+	go func() { disconnected <- tc.connManager.Disconnect() }()
+	select {
+	case err := <-disconnected:
+		assert.NoError(tc.T(), err)
+	case <-time.After(time.Second):
+		tc.T().Fatal("disconnect waited for roaming")
+	}
+	<-reconnected
+
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+	assert.Equal(tc.T(), 0, tc.fakeConnectionFactory.roamed)
+}
+
+func (tc *testContext) Test_ManagerDisconnects_WhenReconnectFails() {
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
+
+	tc.fakeConnectionFactory.mockError = errors.New("network is unreachable")
+	tc.connManager.Reconnect()
+	waitABit()
+
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+}
+
+func (tc *testContext) Test_ManagerFailsOver_WhenReconnectFails() {
+	failoverProposal := market.ServiceProposal{
+		ProviderID:        "fake-node-2",
+		ProviderContacts:  []market.Contact{activeProviderContact},
+		ServiceType:       activeServiceType,
+		ServiceDefinition: &fakeServiceDefinition{},
+	}
+	params := ConnectParams{
+		Failover: true,
+		ProposalLookup: func() ([]market.ServiceProposal, error) {
+			return []market.ServiceProposal{activeProposal, failoverProposal}, nil
+		},
+	}
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, params))
+
+	attempts := 0
+	tc.connManager.newConnection = func(serviceType string, stateChannel StateChannel, statisticsChannel StatisticsChannel) (Connection, error) {
+		attempts++
+		if attempts <= reconnectAttempts {
+			return nil, errors.New("network is unreachable")
+		}
+		return tc.fakeConnectionFactory.CreateConnection(serviceType, stateChannel, statisticsChannel)
+	}
+	tc.connManager.Reconnect()
+	waitABit()
+
+	assert.Equal(tc.T(), reconnectAttempts+1, attempts)
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, failoverProposal), tc.connManager.Status())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) Test_ManagerPassesSplitTunnelRoutesToConnection() {
+	params := ConnectParams{
+		DisableKillSwitch: true,
+		SplitTunnel: SplitTunnel{
+			Include: []string{"10.0.0.0/8"},
+			Exclude: []string{"192.168.0.0/16"},
+		},
+	}
+	err := tc.connManager.Connect(consumerID, activeProposal, params)
+	assert.NoError(tc.T(), err)
+
+	singleRoutes := tc.connManager.hopRoutes(hopInfo{exit: true})
+	assert.Equal(tc.T(), []string{"10.0.0.0/8"}, networkStrings(singleRoutes.Include))
+	assert.Equal(tc.T(), []string{"192.168.0.0/16"}, networkStrings(singleRoutes.Exclude))
+
+	entryRoutes := tc.connManager.hopRoutes(hopInfo{})
+	assert.Empty(tc.T(), entryRoutes.Include)
+	assert.Equal(tc.T(), []string{"192.168.0.0/16"}, networkStrings(entryRoutes.Exclude))
+
+	exitRoutes := tc.connManager.hopRoutes(hopInfo{chainedThrough: "fake-tun", exit: true})
+	assert.Equal(tc.T(), []string{"10.0.0.0/8"}, networkStrings(exitRoutes.Include))
+	assert.Empty(tc.T(), exitRoutes.Exclude)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) Test_ManagerFailsToConnect_WhenSplitTunnelIsInvalid() {
+	params := ConnectParams{SplitTunnel: SplitTunnel{Exclude: []string{"fd00::/8"}}}
+
+	err := tc.connManager.Connect(consumerID, activeProposal, params)
+	assert.Error(tc.T(), err)
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+}
+
+func (tc *testContext) Test_ManagerFailsToConnect_WhenTunnelIsLimitedWithKillSwitch() {
+	params := ConnectParams{SplitTunnel: SplitTunnel{Include: []string{"10.0.0.0/8"}}}
+
+	err := tc.connManager.Connect(consumerID, activeProposal, params)
+	assert.Equal(tc.T(), ErrIncludeWithKillSwitch, err)
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+}
+
+func (tc *testContext) Test_ManagerChainsHops() {
+	exitProposal := market.ServiceProposal{
+		ProviderID:        "fake-node-2",
+		ProviderContacts:  []market.Contact{activeProviderContact},
+		ServiceType:       activeServiceType,
+		ServiceDefinition: &fakeServiceDefinition{},
+	}
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, activeProposal, ConnectParams{Hops: []market.ServiceProposal{exitProposal}})
+	assert.NoError(tc.T(), err)
+	waitABit()
+
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, exitProposal), tc.connManager.Status())
+
+	var createdProviders []string
+	hopStats := 0
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == SessionEventTopic {
+			event := v.calledWithData.(SessionEvent)
+			if event.Status == SessionCreatedStatus {
+				createdProviders = append(createdProviders, event.SessionInfo.Proposal.ProviderID)
+			}
+		}
+		if v.calledWithTopic == HopStatisticsEventTopic {
+			hopStats++
+		}
+	}
+	assert.Equal(tc.T(), []string{activeProposal.ProviderID, exitProposal.ProviderID}, createdProviders)
+	assert.Equal(tc.T(), 2, hopStats)
+
+	tc.stubPublisher.Clear()
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+
+	var endedProviders []string
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == SessionEventTopic {
+			event := v.calledWithData.(SessionEvent)
+			if event.Status == SessionEndedStatus {
+				endedProviders = append(endedProviders, event.SessionInfo.Proposal.ProviderID)
+			}
+		}
+	}
+	assert.Equal(tc.T(), []string{exitProposal.ProviderID, activeProposal.ProviderID}, endedProviders)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -387,12 +754,13 @@ type fakeServiceDefinition struct{}
 func (fs *fakeServiceDefinition) GetLocation() market.Location { return market.Location{} }
 
 type MockPaymentIssuer struct {
-	initialState      promise.PaymentInfo
-	paymentDefinition dto.PaymentPerTime
-	startCalled       bool
-	stopCalled        bool
-	MockError         error
-	stopChan          chan struct{}
+	initialState   promise.PaymentInfo
+	paymentMethod  market.PaymentMethod
+	getTransferred func() session.DataTransfered
+	startCalled    bool
+	stopCalled     bool
+	MockError      error
+	stopChan       chan struct{}
 	sync.Mutex
 }
 
@@ -0,0 +1,234 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/network"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+)
+
+// DefaultConnectionID identifies the connection which is managed through the single connection Manager
+const DefaultConnectionID = "default"
+
+// ErrFullTunnelInUse indicates that another connection already tunnels all traffic,
+// so the new one has to be limited to some destinations with split tunneling
+var ErrFullTunnelInUse = errors.New("another connection already tunnels all traffic")
+
+// Pool manages multiple simultaneous connections, every connection is keyed by its ID
+type Pool interface {
+	// Connect creates new connection with the given ID, reports error if connection with such ID already exists
+	Connect(id string, consumerID identity.Identity, proposal market.ServiceProposal, params ConnectParams) error
+	// Status queries status of the connection with the given ID, reports error if no such connection
+	Status(id string) (Status, error)
+	// Disconnect closes connection with the given ID, reports error if no such connection
+	Disconnect(id string) error
+	// List returns statuses of all the connections keyed by their IDs
+	List() map[string]Status
+	// InUse tells if any connection is established or being established with the given consumer identity
+	InUse(consumerID identity.Identity) bool
+}
+
+type connectionPool struct {
+	defaultConnection *connectionManager
+	connections       map[string]*connectionManager
+	connecting        map[string]ConnectParams
+	lock              sync.Mutex
+}
+
+// NewPool creates the pool of connections, the given manager serves the connection with DefaultConnectionID
+// and every other connection is created with the same dependencies.
+// Every connection gets its own tunnel interface and routes, but only one of them may tunnel all traffic.
+func NewPool(defaultConnection *connectionManager) *connectionPool {
+	return &connectionPool{
+		defaultConnection: defaultConnection,
+		connections: map[string]*connectionManager{
+			DefaultConnectionID: defaultConnection,
+		},
+		connecting: make(map[string]ConnectParams),
+	}
+}
+
+// Connect creates new connection with the given ID, the connection failed to connect is forgotten unless it is the default one
+func (pool *connectionPool) Connect(id string, consumerID identity.Identity, proposal market.ServiceProposal, params ConnectParams) (err error) {
+	manager, err := pool.reserve(id, params)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		pool.lock.Lock()
+		defer pool.lock.Unlock()
+
+		delete(pool.connecting, id)
+		if err != nil && id != DefaultConnectionID && manager.Status().State == NotConnected {
+			delete(pool.connections, id)
+		}
+	}()
+	return manager.Connect(consumerID, proposal, params)
+}
+
+// Status queries status of the connection with the given ID
+func (pool *connectionPool) Status(id string) (Status, error) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	manager, ok := pool.connections[id]
+	if !ok {
+		return Status{}, ErrNoConnection
+	}
+	return manager.Status(), nil
+}
+
+// Disconnect closes connection with the given ID and forgets it, the default connection is always kept
+func (pool *connectionPool) Disconnect(id string) error {
+	pool.lock.Lock()
+	manager, ok := pool.connections[id]
+	pool.lock.Unlock()
+	if !ok {
+		return ErrNoConnection
+	}
+
+	err := manager.Disconnect()
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if id != DefaultConnectionID && manager.Status().State == NotConnected {
+		delete(pool.connections, id)
+	}
+	return err
+}
+
+// List returns statuses of all the connections
+func (pool *connectionPool) List() map[string]Status {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	statuses := make(map[string]Status, len(pool.connections))
+	for id, manager := range pool.connections {
+		statuses[id] = manager.Status()
+	}
+	return statuses
+}
+
+// InUse tells if any connection is established or being established with the given consumer identity
+func (pool *connectionPool) InUse(consumerID identity.Identity) bool {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	for id, manager := range pool.connections {
+		if pool.isActive(id, manager) && manager.consumer() == consumerID {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleNetworkChangedEvent reconnects all the established connections, their tunnels are bound to the previous network
+func (pool *connectionPool) HandleNetworkChangedEvent(_ network.ChangedEvent) {
+	pool.lock.Lock()
+	managers := make([]*connectionManager, 0, len(pool.connections))
+	for _, manager := range pool.connections {
+		managers = append(managers, manager)
+	}
+	pool.lock.Unlock()
+
+	var wg sync.WaitGroup
+	for _, manager := range managers {
+		wg.Add(1)
+		go func(manager *connectionManager) {
+			defer wg.Done()
+			manager.Reconnect()
+		}(manager)
+	}
+	wg.Wait()
+}
+
+// Default returns the Manager of the connection with DefaultConnectionID,
+// which is checked against the other connections of the pool the same way as they are
+func (pool *connectionPool) Default() Manager {
+	return &defaultConnection{pool: pool}
+}
+
+// reserve returns the connection manager for the given ID and marks it as connecting,
+// so the connections started at the same time could be checked for competing for the default route
+func (pool *connectionPool) reserve(id string, params ConnectParams) (*connectionManager, error) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	manager, ok := pool.connections[id]
+	if ok && pool.isActive(id, manager) {
+		return nil, ErrAlreadyExists
+	}
+
+	if len(params.SplitTunnel.Include) == 0 {
+		for otherID, other := range pool.connections {
+			if otherID != id && pool.isActive(otherID, other) && pool.tunnelsAllTraffic(otherID, other) {
+				return nil, ErrFullTunnelInUse
+			}
+		}
+	}
+
+	if !ok {
+		manager = pool.newConnection(id)
+		pool.connections[id] = manager
+	}
+	pool.connecting[id] = params
+	return manager, nil
+}
+
+func (pool *connectionPool) isActive(id string, manager *connectionManager) bool {
+	_, connecting := pool.connecting[id]
+	return connecting || manager.Status().State != NotConnected
+}
+
+func (pool *connectionPool) tunnelsAllTraffic(id string, manager *connectionManager) bool {
+	params, connecting := pool.connecting[id]
+	if !connecting {
+		params = manager.connectParams()
+	}
+	return len(params.SplitTunnel.Include) == 0
+}
+
+func (pool *connectionPool) newConnection(id string) *connectionManager {
+	return newConnectionManager(
+		id,
+		pool.defaultConnection.newDialog,
+		pool.defaultConnection.paymentIssuerFactory,
+		pool.defaultConnection.newConnection,
+		pool.defaultConnection.eventPublisher,
+	)
+}
+
+type defaultConnection struct {
+	pool *connectionPool
+}
+
+func (dc *defaultConnection) Connect(consumerID identity.Identity, proposal market.ServiceProposal, params ConnectParams) error {
+	return dc.pool.Connect(DefaultConnectionID, consumerID, proposal, params)
+}
+
+func (dc *defaultConnection) Status() Status {
+	return dc.pool.defaultConnection.Status()
+}
+
+func (dc *defaultConnection) Disconnect() error {
+	return dc.pool.Disconnect(DefaultConnectionID)
+}
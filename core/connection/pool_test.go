@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+
+	"github.com/mysteriumnetwork/node/core/network"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+var splitTunnelParams = ConnectParams{DisableKillSwitch: true, SplitTunnel: SplitTunnel{Include: []string{"10.0.0.0/8"}}}
+
+func (tc *testContext) Test_PoolKeepsConnectionsByID() {
+	pool := NewPool(tc.connManager)
+
+	assert.NoError(tc.T(), pool.Connect(DefaultConnectionID, consumerID, activeProposal, ConnectParams{}))
+	assert.NoError(tc.T(), pool.Connect("second", consumerID, activeProposal, splitTunnelParams))
+
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	status, err := pool.Status("second")
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), status)
+	assert.Len(tc.T(), pool.List(), 2)
+
+	assert.NoError(tc.T(), pool.Disconnect("second"))
+	_, err = pool.Status("second")
+	assert.Equal(tc.T(), ErrNoConnection, err)
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+
+	assert.NoError(tc.T(), pool.Disconnect(DefaultConnectionID))
+	assert.Equal(tc.T(), map[string]Status{DefaultConnectionID: statusNotConnected()}, pool.List())
+}
+
+func (tc *testContext) Test_PoolRejectsDuplicateConnectionID() {
+	pool := NewPool(tc.connManager)
+
+	assert.NoError(tc.T(), pool.Connect("first", consumerID, activeProposal, splitTunnelParams))
+	assert.Equal(tc.T(), ErrAlreadyExists, pool.Connect("first", consumerID, activeProposal, splitTunnelParams))
+	assert.NoError(tc.T(), pool.Disconnect("first"))
+}
+
+func (tc *testContext) Test_PoolForgetsConnectionsFailedToConnect() {
+	pool := NewPool(tc.connManager)
+	tc.fakeConnectionFactory.mockError = errors.New("network is unreachable")
+
+	assert.Error(tc.T(), pool.Connect("second", consumerID, activeProposal, splitTunnelParams))
+	assert.Error(tc.T(), pool.Connect(DefaultConnectionID, consumerID, activeProposal, ConnectParams{}))
+
+	_, err := pool.Status("second")
+	assert.Equal(tc.T(), ErrNoConnection, err)
+	assert.Equal(tc.T(), map[string]Status{DefaultConnectionID: statusNotConnected()}, pool.List())
+}
+
+func (tc *testContext) Test_PoolAllowsSingleFullTunnel() {
+	pool := NewPool(tc.connManager)
+
+	assert.NoError(tc.T(), pool.Default().Connect(consumerID, activeProposal, ConnectParams{}))
+	assert.Equal(tc.T(), ErrFullTunnelInUse, pool.Connect("second", consumerID, activeProposal, ConnectParams{}))
+	assert.NoError(tc.T(), pool.Connect("second", consumerID, activeProposal, splitTunnelParams))
+
+	assert.NoError(tc.T(), pool.Default().Disconnect())
+	assert.NoError(tc.T(), pool.Connect("third", consumerID, activeProposal, ConnectParams{}))
+
+	assert.Equal(tc.T(), ErrFullTunnelInUse, pool.Default().Connect(consumerID, activeProposal, ConnectParams{}))
+
+	assert.NoError(tc.T(), pool.Disconnect("second"))
+	assert.NoError(tc.T(), pool.Disconnect("third"))
+}
+
+func (tc *testContext) Test_PoolReconnectsConnectionsOnNetworkChange() {
+	pool := NewPool(tc.connManager)
+	assert.NoError(tc.T(), pool.Connect("second", consumerID, activeProposal, splitTunnelParams))
+	tc.stubPublisher.Clear()
+
+	pool.HandleNetworkChangedEvent(network.ChangedEvent{})
+	waitABit()
+
+	reconnected := 0
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == SessionEventTopic {
+			event := v.calledWithData.(SessionEvent)
+			if event.Status == SessionReconnectedStatus {
+				reconnected++
+				assert.Equal(tc.T(), "second", event.ConnectionID)
+			}
+		}
+	}
+	assert.Equal(tc.T(), 1, reconnected)
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+	status, err := pool.Status("second")
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), status)
+
+	assert.NoError(tc.T(), pool.Disconnect("second"))
+}
+
+func (tc *testContext) Test_PoolTellsIfConsumerIdentityIsInUse() {
+	pool := NewPool(tc.connManager)
+	assert.False(tc.T(), pool.InUse(consumerID))
+
+	assert.NoError(tc.T(), pool.Connect("second", consumerID, activeProposal, splitTunnelParams))
+	assert.True(tc.T(), pool.InUse(consumerID))
+	assert.False(tc.T(), pool.InUse(identity.FromAddress("other")))
+
+	assert.NoError(tc.T(), pool.Disconnect("second"))
+	assert.False(tc.T(), pool.InUse(consumerID))
+}
+
+func (tc *testContext) Test_PoolChecksConnectionStartedBypassingIt() {
+	pool := NewPool(tc.connManager)
+
+	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
+	assert.Equal(tc.T(), ErrFullTunnelInUse, pool.Connect("second", consumerID, activeProposal, ConnectParams{}))
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+}
+
+func (tc *testContext) Test_PoolPublishesEventsOfEveryConnection() {
+	pool := NewPool(tc.connManager)
+	tc.stubPublisher.Clear()
+
+	assert.NoError(tc.T(), pool.Connect("second", consumerID, activeProposal, ConnectParams{}))
+	waitABit()
+
+	var connectionEvents int
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		switch v.calledWithTopic {
+		case StateEventTopic:
+			connectionEvents++
+			assert.Equal(tc.T(), "second", v.calledWithData.(StateEvent).ConnectionID)
+		case SessionEventTopic:
+			connectionEvents++
+			assert.Equal(tc.T(), "second", v.calledWithData.(SessionEvent).ConnectionID)
+		case StatisticsEventTopic:
+			connectionEvents++
+			assert.Equal(tc.T(), "second", v.calledWithData.(StatisticsEvent).ConnectionID)
+		}
+	}
+	assert.Equal(tc.T(), 3, connectionEvents)
+	assert.NoError(tc.T(), pool.Disconnect("second"))
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var lookupIP = net.LookupIP
+
+// SplitTunnel lists destinations which are routed into or around the tunnel.
+// Every entry is either a CIDR, a single IP address or a DNS name.
+type SplitTunnel struct {
+	// Include limits the tunnel to the given destinations, all traffic is tunneled when empty.
+	// Kill switch has to be disabled, it would block the traffic outside of the tunnel.
+	Include []string
+	// Exclude destinations bypass the tunnel and are allowed through the kill switch
+	Exclude []string
+}
+
+// TunnelRoutes holds resolved split tunneling networks passed to the connection
+type TunnelRoutes struct {
+	Include []net.IPNet
+	Exclude []net.IPNet
+}
+
+// Resolve turns split tunneling destinations into IPv4 networks, resolving DNS names at the time of call
+func (st SplitTunnel) Resolve() (TunnelRoutes, error) {
+	include, err := resolveNetworks(st.Include)
+	if err != nil {
+		return TunnelRoutes{}, errors.Wrap(err, "failed to resolve included destinations")
+	}
+	exclude, err := resolveNetworks(st.Exclude)
+	if err != nil {
+		return TunnelRoutes{}, errors.Wrap(err, "failed to resolve excluded destinations")
+	}
+	return TunnelRoutes{Include: include, Exclude: exclude}, nil
+}
+
+func resolveNetworks(destinations []string) ([]net.IPNet, error) {
+	var networks []net.IPNet
+	for _, destination := range destinations {
+		destination = strings.TrimSpace(destination)
+		if destination == "" {
+			continue
+		}
+
+		if _, network, err := net.ParseCIDR(destination); err == nil {
+			if network.IP.To4() == nil {
+				return nil, errors.Errorf("only IPv4 networks are supported: %s", destination)
+			}
+			networks = append(networks, *network)
+			continue
+		}
+
+		if ip := net.ParseIP(destination); ip != nil {
+			if ip.To4() == nil {
+				return nil, errors.Errorf("only IPv4 addresses are supported: %s", destination)
+			}
+			networks = append(networks, hostNetwork(ip))
+			continue
+		}
+
+		ips, err := lookupIP(destination)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to lookup "+destination)
+		}
+		resolved := false
+		for _, ip := range ips {
+			if ip.To4() != nil {
+				networks = append(networks, hostNetwork(ip))
+				resolved = true
+			}
+		}
+		if !resolved {
+			return nil, errors.Errorf("no IPv4 addresses found for %s", destination)
+		}
+	}
+	return networks, nil
+}
+
+func hostNetwork(ip net.IP) net.IPNet {
+	return net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}
+}
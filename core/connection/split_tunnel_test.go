@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTunnelResolvesNetworksAddressesAndNames(t *testing.T) {
+	defer func(original func(string) ([]net.IP, error)) { lookupIP = original }(lookupIP)
+	lookupIP = func(host string) ([]net.IP, error) {
+		assert.Equal(t, "intranet.example.com", host)
+		return []net.IP{net.ParseIP("::1"), net.ParseIP("172.16.0.10")}, nil
+	}
+
+	routes, err := SplitTunnel{
+		Include: []string{"10.0.0.0/8", " "},
+		Exclude: []string{"192.168.1.1", "intranet.example.com"},
+	}.Resolve()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, networkStrings(routes.Include))
+	assert.Equal(t, []string{"192.168.1.1/32", "172.16.0.10/32"}, networkStrings(routes.Exclude))
+}
+
+func TestSplitTunnelResolveFails(t *testing.T) {
+	defer func(original func(string) ([]net.IP, error)) { lookupIP = original }(lookupIP)
+	lookupIP = func(host string) ([]net.IP, error) {
+		return nil, errors.New("no such host")
+	}
+
+	_, err := SplitTunnel{Exclude: []string{"fd00::/8"}}.Resolve()
+	assert.EqualError(t, err, "failed to resolve excluded destinations: only IPv4 networks are supported: fd00::/8")
+
+	_, err = SplitTunnel{Include: []string{"unknown.example.com"}}.Resolve()
+	assert.EqualError(t, err, "failed to resolve included destinations: failed to lookup unknown.example.com: no such host")
+}
+
+func networkStrings(networks []net.IPNet) []string {
+	var result []string
+	for _, network := range networks {
+		result = append(result, network.String())
+	}
+	return result
+}
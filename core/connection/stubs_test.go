@@ -18,6 +18,7 @@
 package connection
 
 import (
+	"context"
 	"errors"
 	"sync"
 
@@ -115,6 +116,10 @@ const (
 type connectionFactoryFake struct {
 	mockError      error
 	mockConnection *connectionMock
+	roamable       bool
+	roamError      error
+	roamBlock      bool
+	roamed         int
 }
 
 func (cff *connectionFactoryFake) CreateConnection(serviceType string, stateChannel StateChannel, statisticsChannel StatisticsChannel) (Connection, error) {
@@ -150,11 +155,34 @@ func (cff *connectionFactoryFake) CreateConnection(serviceType string, stateChan
 		onStartReportStats:  cff.mockConnection.onStartReportStats,
 		fakeProcess:         sync.WaitGroup{},
 		stopBlock:           cff.mockConnection.stopBlock,
+		relayed:             cff.mockConnection.relayed,
 	}
 
+	if cff.roamable {
+		return &roamingConnectionMock{connectionMock: &copy, roamError: cff.roamError, roamBlock: cff.roamBlock, roamed: &cff.roamed}, nil
+	}
 	return &copy, nil
 }
 
+type roamingConnectionMock struct {
+	*connectionMock
+	roamError error
+	roamBlock bool
+	roamed    *int
+}
+
+func (rcm *roamingConnectionMock) Roam(ctx context.Context) error {
+	if rcm.roamBlock {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if rcm.roamError != nil {
+		return rcm.roamError
+	}
+	*rcm.roamed++
+	return nil
+}
+
 type connectionMock struct {
 	onStartReturnError  error
 	onStartReportStates []fakeState
@@ -163,6 +191,7 @@ type connectionMock struct {
 	onStartReportStats  consumer.SessionStatistics
 	fakeProcess         sync.WaitGroup
 	stopBlock           chan struct{}
+	relayed             bool
 	sync.RWMutex
 }
 
@@ -200,6 +229,14 @@ func (foc *connectionMock) Stop() {
 	foc.fakeProcess.Done()
 }
 
+func (foc *connectionMock) InterfaceName() string {
+	return "fake-tun"
+}
+
+func (foc *connectionMock) Relayed() bool {
+	return foc.relayed
+}
+
 func (foc *connectionMock) reportState(state fakeState) {
 	foc.RLock()
 	defer foc.RUnlock()
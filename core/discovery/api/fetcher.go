@@ -20,7 +20,6 @@ package api
 import (
 	"time"
 
-	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/pkg/errors"
 )
@@ -28,18 +27,23 @@ import (
 // FetchCallback does real fetch of proposals through Mysterium API
 type FetchCallback func() ([]market.ServiceProposal, error)
 
+// ProposalStorage keeps fetched proposals
+type ProposalStorage interface {
+	Set(proposals ...market.ServiceProposal)
+}
+
 // Fetcher represents async proposal fetcher from Mysterium API
 type Fetcher struct {
 	fetch         FetchCallback
 	fetchInterval time.Duration
 	fetchShutdown chan bool
 
-	proposalStorage       *discovery.ProposalStorage
+	proposalStorage       ProposalStorage
 	proposalSubscriptions []chan market.ServiceProposal
 }
 
 // NewFetcher create instance of Fetcher
-func NewFetcher(proposalsStorage *discovery.ProposalStorage, callback FetchCallback, interval time.Duration) *Fetcher {
+func NewFetcher(proposalsStorage ProposalStorage, callback FetchCallback, interval time.Duration) *Fetcher {
 	return &Fetcher{
 		fetch:         callback,
 		fetchInterval: interval,
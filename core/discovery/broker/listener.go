@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package broker
+
+import (
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// ProposalRepository keeps proposals announced thru Broker
+type ProposalRepository interface {
+	Register(proposal market.ServiceProposal)
+	Unregister(proposal market.ServiceProposal)
+}
+
+// NewReceiver creates communication receiver thru NATS
+func NewReceiver(connection nats.Connection) communication.Receiver {
+	return nats.NewReceiver(
+		connection,
+		communication.NewCodecJSON(),
+		"*",
+	)
+}
+
+// Listener applies proposal announcements from Broker to repository
+type Listener struct {
+	receiver   communication.Receiver
+	repository ProposalRepository
+}
+
+// NewListener create an instance of Broker listener
+func NewListener(receiver communication.Receiver, repository ProposalRepository) *Listener {
+	return &Listener{
+		receiver:   receiver,
+		repository: repository,
+	}
+}
+
+// Start begins listening for proposal register, ping and unregister messages
+func (listener *Listener) Start() error {
+	if err := listener.receiver.Receive(&registerConsumer{callback: listener.repository.Register}); err != nil {
+		return err
+	}
+	return listener.receiver.Receive(&unregisterConsumer{callback: listener.repository.Unregister})
+}
+
+// Stop ends listening for proposal messages
+func (listener *Listener) Stop() {
+	listener.receiver.Unsubscribe()
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package broker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type repositoryMock struct {
+	mu           sync.Mutex
+	registered   []market.ServiceProposal
+	unregistered []market.ServiceProposal
+}
+
+func (repository *repositoryMock) Register(proposal market.ServiceProposal) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.registered = append(repository.registered, proposal)
+}
+
+func (repository *repositoryMock) Unregister(proposal market.ServiceProposal) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.unregistered = append(repository.unregistered, proposal)
+}
+
+func (repository *repositoryMock) counts() (int, int) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	return len(repository.registered), len(repository.unregistered)
+}
+
+func Test_Listener_AppliesRegistryMessages(t *testing.T) {
+	connection := nats.StartConnectionMock()
+	defer connection.Close()
+
+	repository := &repositoryMock{}
+	listener := NewListener(NewReceiver(connection), repository)
+	assert.NoError(t, listener.Start())
+	defer listener.Stop()
+
+	registry := NewRegistry(NewSender(connection))
+	assert.NoError(t, registry.RegisterProposal(newProposal, &identity.SignerFake{}))
+	assert.NoError(t, registry.PingProposal(newProposal, &identity.SignerFake{}))
+	assert.NoError(t, registry.UnregisterProposal(newProposal, &identity.SignerFake{}))
+
+	waitForCounts(repository, 2, 1)
+	registered, unregistered := repository.counts()
+	assert.Equal(t, 2, registered)
+	assert.Equal(t, 1, unregistered)
+
+	assert.Equal(t, newProposal.ProviderID, repository.registered[0].ProviderID)
+	assert.Equal(t, newProposal.ProviderID, repository.unregistered[0].ProviderID)
+}
+
+func waitForCounts(repository *repositoryMock, registered, unregistered int) {
+	for i := 0; i < 100; i++ {
+		r, u := repository.counts()
+		if r == registered && u == unregistered {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
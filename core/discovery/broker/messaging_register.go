@@ -36,7 +36,7 @@ const registerEndpoint = communication.MessageEndpoint("proposal-register")
 
 // registerConsumer
 type registerConsumer struct {
-	queue chan registerMessage
+	callback func(proposal market.ServiceProposal)
 }
 
 // GetMessageEndpoint returns endpoint where to receive messages
@@ -51,12 +51,12 @@ func (pmc *registerConsumer) NewMessage() (messagePtr interface{}) {
 
 // Consume handles messages from endpoint
 func (pmc *registerConsumer) Consume(messagePtr interface{}) error {
-	msg, ok := messagePtr.(registerMessage)
+	msg, ok := messagePtr.(*registerMessage)
 	if !ok {
-		return errors.Errorf("consume received message of type %q, expected registerMessage instead", reflect.TypeOf(messagePtr))
+		return errors.Errorf("consume received message of type %q, expected *registerMessage instead", reflect.TypeOf(messagePtr))
 	}
 
-	pmc.queue <- msg
+	pmc.callback(msg.Proposal)
 	return nil
 }
 
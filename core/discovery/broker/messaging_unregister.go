@@ -36,7 +36,7 @@ const unregisterEndpoint = communication.MessageEndpoint("proposal-unregister")
 
 // unregisterConsumer
 type unregisterConsumer struct {
-	queue chan unregisterMessage
+	callback func(proposal market.ServiceProposal)
 }
 
 // GetMessageEndpoint returns endpoint where to receive messages
@@ -51,12 +51,12 @@ func (pmc *unregisterConsumer) NewMessage() (messagePtr interface{}) {
 
 // Consume handles messages from endpoint
 func (pmc *unregisterConsumer) Consume(messagePtr interface{}) error {
-	msg, ok := messagePtr.(unregisterMessage)
+	msg, ok := messagePtr.(*unregisterMessage)
 	if !ok {
-		return errors.Errorf("consume received message of type %q, expected unregisterMessage instead", reflect.TypeOf(messagePtr))
+		return errors.Errorf("consume received message of type %q, expected *unregisterMessage instead", reflect.TypeOf(messagePtr))
 	}
 
-	pmc.queue <- msg
+	pmc.callback(msg.Proposal)
 	return nil
 }
 
@@ -45,8 +45,23 @@ const (
 const (
 	// ProposalEventTopic represent proposal events topic.
 	ProposalEventTopic = "proposalEvent"
+	// RegistryFailureEventTopic represent topic of failed proposal registry calls.
+	RegistryFailureEventTopic = "proposalRegistryFailureEvent"
 )
 
+// Proposal registry actions which might fail
+const (
+	RegistryActionRegister   = "register"
+	RegistryActionPing       = "ping"
+	RegistryActionUnregister = "unregister"
+)
+
+// RegistryFailureEvent represents a failed call to the proposal registry
+type RegistryFailureEvent struct {
+	Action string
+	Error  error
+}
+
 // Publisher is responsible for publishing given events.
 type Publisher interface {
 	Publish(topic string, data interface{})
@@ -192,6 +207,7 @@ func (d *Discovery) registerProposal() {
 	err := d.proposalRegistry.RegisterProposal(d.proposal, d.signer)
 	if err != nil {
 		log.Errorf("failed to register proposal, retrying after 1 min. %s", err.Error())
+		d.publishRegistryFailure(RegistryActionRegister, err)
 		time.Sleep(1 * time.Minute)
 		d.changeStatus(RegisterProposal)
 		return
@@ -205,6 +221,7 @@ func (d *Discovery) pingProposal() {
 	err := d.proposalRegistry.PingProposal(d.proposal, d.signer)
 	if err != nil {
 		log.Error("failed to ping proposal: ", err)
+		d.publishRegistryFailure(RegistryActionPing, err)
 	}
 	d.eventPublisher.Publish(ProposalEventTopic, d.proposal)
 	d.changeStatus(PingProposal)
@@ -214,12 +231,17 @@ func (d *Discovery) unregisterProposal() {
 	err := d.proposalRegistry.UnregisterProposal(d.proposal, d.signer)
 	if err != nil {
 		log.Error("failed to unregister proposal: ", err)
+		d.publishRegistryFailure(RegistryActionUnregister, err)
 		d.changeStatus(UnregisterProposalFailed)
 	}
 	log.Info("proposal unregistered")
 	d.changeStatus(ProposalUnregistered)
 }
 
+func (d *Discovery) publishRegistryFailure(action string, err error) {
+	d.eventPublisher.Publish(RegistryFailureEventTopic, RegistryFailureEvent{Action: action, Error: err})
+}
+
 func (d *Discovery) checkRegistration() {
 	// check if node's identity is registered
 	registered, err := d.identityRegistry.IsRegistered(d.ownIdentity)
@@ -75,6 +75,12 @@ finder.MatchProposals(reducer.Or(
   )
 ))
 ```
+- By query expression, which is parsed to the reducer tree together with the sort order and limit:
+```
+q, err := query.Parse(`country in (DE, NL) and not isp = "Foo" and price < 0.1 order by price limit 10`)
+proposals, err := finder.MatchProposals(q.Match)
+proposals = q.SortAndLimit(proposals, nil)
+```
 */
 
 package discovery
@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// finderGossip implements ProposalFinder, which finds proposals known to the gossip node
+type finderGossip struct {
+	node *Node
+}
+
+// NewFinder creates new instance of finderGossip
+func NewFinder(node *Node) *finderGossip {
+	return &finderGossip{
+		node: node,
+	}
+}
+
+// GetProposal fetches service proposal from discovery by exact ID
+func (finder *finderGossip) GetProposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	for _, proposal := range finder.node.Proposals() {
+		if proposal.UniqueID() == id {
+			return &proposal, nil
+		}
+	}
+	return nil, nil
+}
+
+// FindProposals fetches currently active service proposals from discovery by given filter
+func (finder *finderGossip) FindProposals(filter discovery.ProposalFilter) ([]market.ServiceProposal, error) {
+	proposals := make([]market.ServiceProposal, 0)
+	for _, proposal := range finder.node.Proposals() {
+		if filter.Matches(proposal) {
+			proposals = append(proposals, proposal)
+		}
+	}
+	return proposals, nil
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxMessageSize is the largest datagram the node sends or reads, larger ones are dropped
+	maxMessageSize = 8 * 1024
+	// defaultMaxPeers bounds the peer table when the options leave it unset
+	defaultMaxPeers = 100
+)
+
+// ProposalRepository keeps proposals received thru gossip
+type ProposalRepository interface {
+	Register(proposal market.ServiceProposal)
+	Unregister(proposal market.ServiceProposal)
+}
+
+// Options describes the gossip node configuration
+type Options struct {
+	// Address is the UDP address to listen on, e.g. ":4451"
+	Address string
+	// Peers are the addresses the node starts gossiping with
+	Peers []string
+	// PublicAddress is the UDP address peers reach the node at, it is announced in the records of the local providers
+	PublicAddress string
+	// MaxPeers bounds the peer table
+	MaxPeers int
+	// Fanout is the count of peers gossiped to every round
+	Fanout int
+	// Interval is the time between the gossip rounds
+	Interval time.Duration
+	// MaxAge is the time proposal record is kept without being refreshed by its provider
+	MaxAge time.Duration
+}
+
+// message is a single gossip datagram, peers are learned only from the records it carries
+type message struct {
+	Records []signedRecord `json:"records"`
+}
+
+type entry struct {
+	signed signedRecord
+	record record
+}
+
+// Node exchanges signed proposal records with its peers over UDP
+type Node struct {
+	options         Options
+	repository      ProposalRepository
+	verifierFactory VerifierFactory
+	peers           *peerTable
+	now             func() time.Time
+
+	conn *net.UDPConn
+
+	mu      sync.Mutex
+	records map[market.ProposalID]entry
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewNode creates the gossip node applying the verified proposals to the given repository
+func NewNode(options Options, repository ProposalRepository, verifierFactory VerifierFactory) *Node {
+	if options.MaxPeers <= 0 {
+		options.MaxPeers = defaultMaxPeers
+	}
+	return &Node{
+		options:         options,
+		repository:      repository,
+		verifierFactory: verifierFactory,
+		peers:           newPeerTable(options.MaxPeers),
+		now:             time.Now,
+		records:         make(map[market.ProposalID]entry),
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start begins listening for and sending the gossip
+func (node *Node) Start() error {
+	address, err := net.ResolveUDPAddr("udp", node.options.Address)
+	if err != nil {
+		return errors.Wrap(err, "invalid gossip address")
+	}
+	node.conn, err = net.ListenUDP("udp", address)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for gossip")
+	}
+	log.Info("gossiping proposals on ", node.conn.LocalAddr())
+
+	for _, peer := range node.options.Peers {
+		node.addPeer(peer, node.now())
+	}
+
+	go node.receiveLoop()
+	go node.gossipLoop()
+	return nil
+}
+
+// Stop ends the gossip
+func (node *Node) Stop() {
+	node.stopOnce.Do(func() {
+		close(node.stop)
+		if node.conn != nil {
+			node.conn.Close()
+		}
+	})
+}
+
+// Addr returns the address the node listens on
+func (node *Node) Addr() string {
+	return node.conn.LocalAddr().String()
+}
+
+// Proposals returns the currently announced proposals
+func (node *Node) Proposals() []market.ServiceProposal {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	proposals := make([]market.ServiceProposal, 0, len(node.records))
+	for _, e := range node.records {
+		if !e.record.Removed {
+			proposals = append(proposals, e.record.Proposal)
+		}
+	}
+	return proposals
+}
+
+// announce accepts the record signed by the local provider, it is spread in the following rounds
+func (node *Node) announce(signed signedRecord) error {
+	_, err := node.accept(signed)
+	return err
+}
+
+// accept stores the record if it is valid and newer than the known one, reports whether the record was taken
+func (node *Node) accept(signed signedRecord) (bool, error) {
+	r, err := signed.open(node.verifierFactory)
+	if err != nil {
+		return false, err
+	}
+	return node.store(signed, r), nil
+}
+
+// store keeps the verified record if it is fresh and newer than the known one, reports whether the record was taken
+func (node *Node) store(signed signedRecord, r record) bool {
+	now := node.now()
+	if r.time().Before(now.Add(-node.options.MaxAge)) || r.time().After(now.Add(node.options.MaxAge)) {
+		return false
+	}
+
+	id := r.Proposal.UniqueID()
+	node.mu.Lock()
+	known, ok := node.records[id]
+	if ok && known.record.Timestamp >= r.Timestamp {
+		node.mu.Unlock()
+		return false
+	}
+	node.records[id] = entry{signed: signed, record: r}
+	node.mu.Unlock()
+
+	if r.Removed {
+		node.repository.Unregister(r.Proposal)
+	} else {
+		node.repository.Register(r.Proposal)
+	}
+	return true
+}
+
+// addPeer puts the peer to the table unless it is the node itself
+func (node *Node) addPeer(address string, seen time.Time) {
+	if address == "" || address == node.options.PublicAddress || (node.conn != nil && address == node.Addr()) {
+		return
+	}
+	node.peers.add(address, seen)
+}
+
+// expire drops the records which weren't refreshed by their providers for longer than max age
+func (node *Node) expire() {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+
+	deadline := node.now().Add(-node.options.MaxAge)
+	for id, e := range node.records {
+		if e.record.time().Before(deadline) {
+			delete(node.records, id)
+		}
+	}
+}
+
+func (node *Node) receiveLoop() {
+	// one byte more than allowed tells the oversized datagrams apart
+	buffer := make([]byte, maxMessageSize+1)
+	for {
+		n, from, err := node.conn.ReadFromUDP(buffer)
+		if err != nil {
+			select {
+			case <-node.stop:
+				return
+			default:
+			}
+			log.Warn("failed to read gossip: ", err)
+			continue
+		}
+		if n > maxMessageSize {
+			log.Warn("dropped oversized gossip from ", from)
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal(buffer[:n], &msg); err != nil {
+			log.Warn("malformed gossip from ", from, ": ", err)
+			continue
+		}
+		node.receive(from.String(), msg)
+	}
+}
+
+// receive accepts the records of the message, the sender and the announced peers are trusted
+// only when they come with a record signed by its provider
+func (node *Node) receive(from string, msg message) {
+	verified := false
+	for _, signed := range msg.Records {
+		r, err := signed.open(node.verifierFactory)
+		if err != nil {
+			log.Warn("rejected gossip from ", from, ": ", err)
+			continue
+		}
+		verified = true
+		node.addPeer(r.Peer, time.Time{})
+		node.store(signed, r)
+	}
+	if verified {
+		node.addPeer(from, node.now())
+	}
+}
+
+func (node *Node) gossipLoop() {
+	for {
+		select {
+		case <-node.stop:
+			return
+		case <-time.After(node.options.Interval):
+			node.expire()
+			node.gossip()
+		}
+	}
+}
+
+// gossip sends all the known records to a few random peers
+func (node *Node) gossip() {
+	node.mu.Lock()
+	records := make([]signedRecord, 0, len(node.records))
+	for _, e := range node.records {
+		records = append(records, e.signed)
+	}
+	node.mu.Unlock()
+
+	messages := pack(records)
+	if len(messages) == 0 {
+		return
+	}
+
+	for _, peer := range node.peers.sample(node.options.Fanout) {
+		address, err := net.ResolveUDPAddr("udp", peer)
+		if err != nil {
+			log.Warn("invalid gossip peer ", peer, ": ", err)
+			continue
+		}
+		for _, msg := range messages {
+			if err := node.send(address, msg); err != nil {
+				log.Warn("failed to gossip to ", peer, ": ", err)
+				break
+			}
+		}
+	}
+}
+
+// pack splits the records to the messages which fit to a single datagram
+func pack(records []signedRecord) []message {
+	// `{"records":[` and `]}` surround the comma separated records
+	const envelopeSize = 14
+
+	var messages []message
+	var current []signedRecord
+	size := envelopeSize
+	for _, signed := range records {
+		data, err := json.Marshal(signed)
+		if err != nil {
+			log.Warn("failed to pack gossip record: ", err)
+			continue
+		}
+		if envelopeSize+len(data) > maxMessageSize {
+			log.Warn("skipped gossip record of ", len(data), " bytes, it does not fit to a datagram")
+			continue
+		}
+		if len(current) > 0 && size+1+len(data) > maxMessageSize {
+			messages = append(messages, message{Records: current})
+			current, size = nil, envelopeSize
+		}
+		if len(current) > 0 {
+			size++
+		}
+		current = append(current, signed)
+		size += len(data)
+	}
+	if len(current) > 0 {
+		messages = append(messages, message{Records: current})
+	}
+	return messages
+}
+
+func (node *Node) send(address *net.UDPAddr, msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxMessageSize {
+		return errors.Errorf("gossip message of %d bytes is too large", len(data))
+	}
+	_, err = node.conn.WriteToUDP(data, address)
+	return err
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	proposalProvider1 = market.ServiceProposal{ProviderID: "0x1", ServiceType: "openvpn"}
+	proposalProvider2 = market.ServiceProposal{ProviderID: "0x2", ServiceType: "wireguard"}
+)
+
+type repositoryMock struct {
+	mu        sync.Mutex
+	proposals map[market.ProposalID]market.ServiceProposal
+}
+
+func newRepositoryMock() *repositoryMock {
+	return &repositoryMock{proposals: make(map[market.ProposalID]market.ServiceProposal)}
+}
+
+func (repository *repositoryMock) Register(proposal market.ServiceProposal) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.proposals[proposal.UniqueID()] = proposal
+}
+
+func (repository *repositoryMock) Unregister(proposal market.ServiceProposal) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	delete(repository.proposals, proposal.UniqueID())
+}
+
+func (repository *repositoryMock) ids() []market.ProposalID {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	ids := make([]market.ProposalID, 0, len(repository.proposals))
+	for id := range repository.proposals {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func verifierFake(identity.Identity) identity.Verifier {
+	return &identity.VerifierFake{}
+}
+
+func startTestNode(t *testing.T, peers ...string) (*Node, *repositoryMock) {
+	repository := newRepositoryMock()
+	node := NewNode(
+		Options{
+			Address:  "127.0.0.1:0",
+			Peers:    peers,
+			MaxPeers: 10,
+			Fanout:   2,
+			Interval: 10 * time.Millisecond,
+			MaxAge:   time.Minute,
+		},
+		repository,
+		verifierFake,
+	)
+	assert.NoError(t, node.Start())
+	return node, repository
+}
+
+func waitForCount(repository *repositoryMock, count int) []market.ProposalID {
+	for i := 0; i < 200; i++ {
+		if len(repository.ids()) == count {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return repository.ids()
+}
+
+func TestNode_ProposalsConverge(t *testing.T) {
+	nodeB, repositoryB := startTestNode(t)
+	defer nodeB.Stop()
+	nodeA, repositoryA := startTestNode(t, nodeB.Addr())
+	defer nodeA.Stop()
+	nodeC, repositoryC := startTestNode(t, nodeB.Addr())
+	defer nodeC.Stop()
+
+	assert.NoError(t, NewRegistry(nodeA).RegisterProposal(proposalProvider1, &identity.SignerFake{}))
+	assert.NoError(t, NewRegistry(nodeC).RegisterProposal(proposalProvider2, &identity.SignerFake{}))
+
+	both := []market.ProposalID{proposalProvider1.UniqueID(), proposalProvider2.UniqueID()}
+	for _, repository := range []*repositoryMock{repositoryA, repositoryB, repositoryC} {
+		assert.ElementsMatch(t, both, waitForCount(repository, 2))
+	}
+	proposal, err := NewFinder(nodeB).GetProposal(proposalProvider2.UniqueID())
+	assert.NoError(t, err)
+	assert.NotNil(t, proposal)
+	assert.Equal(t, proposalProvider2.UniqueID(), proposal.UniqueID())
+
+	assert.NoError(t, NewRegistry(nodeA).UnregisterProposal(proposalProvider1, &identity.SignerFake{}))
+
+	remaining := []market.ProposalID{proposalProvider2.UniqueID()}
+	for _, repository := range []*repositoryMock{repositoryA, repositoryB, repositoryC} {
+		assert.Equal(t, remaining, waitForCount(repository, 1))
+	}
+}
+
+func TestNode_RejectsInvalidRecords(t *testing.T) {
+	repository := newRepositoryMock()
+	node := NewNode(Options{MaxPeers: 10, MaxAge: time.Minute}, repository, verifierFake)
+	now := time.Unix(1000, 0)
+	node.now = func() time.Time { return now }
+
+	signed, err := signRecord(record{Proposal: proposalProvider1, Timestamp: now.UnixNano()}, &identity.SignerFake{})
+	assert.NoError(t, err)
+	tampered := signed
+	tampered.Record = []byte(`{"proposal":{"provider_id":"0x1","service_type":"openvpn"},"removed":true,"timestamp":1000000000000}`)
+	_, err = node.accept(tampered)
+	assert.Error(t, err)
+
+	stale, err := signRecord(record{Proposal: proposalProvider1, Timestamp: now.Add(-2 * time.Minute).UnixNano()}, &identity.SignerFake{})
+	assert.NoError(t, err)
+	accepted, err := node.accept(stale)
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+	assert.Empty(t, repository.ids())
+
+	accepted, err = node.accept(signed)
+	assert.NoError(t, err)
+	assert.True(t, accepted)
+
+	older, err := signRecord(record{Proposal: proposalProvider1, Removed: true, Timestamp: now.Add(-time.Second).UnixNano()}, &identity.SignerFake{})
+	assert.NoError(t, err)
+	accepted, err = node.accept(older)
+	assert.NoError(t, err)
+	assert.False(t, accepted)
+	assert.Equal(t, []market.ProposalID{proposalProvider1.UniqueID()}, repository.ids())
+}
+
+type verifierRejecting struct{}
+
+func (verifierRejecting) Verify(message []byte, signature identity.Signature) bool {
+	return false
+}
+
+func TestNode_TrustsOnlyPeersOfSignedRecords(t *testing.T) {
+	node := NewNode(Options{PublicAddress: "10.0.0.1:4451", MaxAge: time.Minute}, newRepositoryMock(), verifierFake)
+	signed, err := signRecord(record{Proposal: proposalProvider1, Timestamp: node.now().UnixNano(), Peer: "10.0.0.3:4451"}, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	node.receive("10.0.0.2:4451", message{})
+	assert.Equal(t, 0, node.peers.size())
+
+	node.verifierFactory = func(identity.Identity) identity.Verifier { return verifierRejecting{} }
+	node.receive("10.0.0.2:4451", message{Records: []signedRecord{signed}})
+	assert.Equal(t, 0, node.peers.size())
+
+	node.verifierFactory = verifierFake
+	node.receive("10.0.0.2:4451", message{Records: []signedRecord{signed}})
+	assert.ElementsMatch(t, []string{"10.0.0.2:4451", "10.0.0.3:4451"}, node.peers.sample(10))
+
+	own, err := signRecord(record{Proposal: proposalProvider2, Timestamp: node.now().UnixNano(), Peer: "10.0.0.1:4451"}, &identity.SignerFake{})
+	assert.NoError(t, err)
+	node.receive("10.0.0.2:4451", message{Records: []signedRecord{own}})
+	assert.Equal(t, 2, node.peers.size())
+}
+
+func TestNode_PeerTableIsBoundedByDefault(t *testing.T) {
+	node := NewNode(Options{}, newRepositoryMock(), verifierFake)
+	for i := 0; i < 2*defaultMaxPeers; i++ {
+		node.addPeer(fmt.Sprintf("10.0.%d.%d:4451", i/256, i%256), time.Unix(int64(i), 0))
+	}
+	assert.Equal(t, defaultMaxPeers, node.peers.size())
+}
+
+func TestPack_FitsMessagesToDatagrams(t *testing.T) {
+	records := make([]signedRecord, 0, 100)
+	for i := 0; i < 100; i++ {
+		proposal := market.ServiceProposal{ProviderID: fmt.Sprintf("0x%d", i), ServiceType: "openvpn"}
+		signed, err := signRecord(record{Proposal: proposal, Timestamp: int64(i)}, &identity.SignerFake{})
+		assert.NoError(t, err)
+		records = append(records, signed)
+	}
+	oversized := signedRecord{Record: make([]byte, maxMessageSize)}
+
+	messages := pack(append(records, oversized))
+	assert.True(t, len(messages) > 1)
+
+	packed := 0
+	for _, msg := range messages {
+		data, err := json.Marshal(msg)
+		assert.NoError(t, err)
+		assert.True(t, len(data) <= maxMessageSize)
+		packed += len(msg.Records)
+	}
+	assert.Equal(t, len(records), packed)
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// peerTable keeps a bounded set of peer addresses, evicting the longest unseen peer when full
+type peerTable struct {
+	max int
+
+	mu    sync.Mutex
+	peers map[string]time.Time
+}
+
+func newPeerTable(max int) *peerTable {
+	return &peerTable{
+		max:   max,
+		peers: make(map[string]time.Time),
+	}
+}
+
+// add puts the peer to the table or refreshes its last seen time
+func (table *peerTable) add(address string, seen time.Time) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	if last, ok := table.peers[address]; ok {
+		if seen.After(last) {
+			table.peers[address] = seen
+		}
+		return
+	}
+
+	if len(table.peers) >= table.max {
+		var oldest string
+		for peer, last := range table.peers {
+			if oldest == "" || last.Before(table.peers[oldest]) {
+				oldest = peer
+			}
+		}
+		if !table.peers[oldest].Before(seen) {
+			return
+		}
+		delete(table.peers, oldest)
+	}
+	table.peers[address] = seen
+}
+
+// sample returns up to n randomly chosen peers
+func (table *peerTable) sample(n int) []string {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	peers := make([]string, 0, len(table.peers))
+	for peer := range table.peers {
+		peers = append(peers, peer)
+	}
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+
+	if len(peers) > n {
+		peers = peers[:n]
+	}
+	return peers
+}
+
+func (table *peerTable) size() int {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+
+	return len(table.peers)
+}
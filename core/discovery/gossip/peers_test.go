@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerTable_EvictsLongestUnseenPeer(t *testing.T) {
+	table := newPeerTable(2)
+	table.add("10.0.0.1:4451", time.Unix(10, 0))
+	table.add("10.0.0.2:4451", time.Unix(20, 0))
+	table.add("10.0.0.1:4451", time.Unix(30, 0))
+
+	table.add("10.0.0.3:4451", time.Unix(40, 0))
+	assert.Equal(t, 2, table.size())
+	assert.ElementsMatch(t, []string{"10.0.0.1:4451", "10.0.0.3:4451"}, table.sample(10))
+
+	table.add("10.0.0.4:4451", time.Unix(5, 0))
+	assert.ElementsMatch(t, []string{"10.0.0.1:4451", "10.0.0.3:4451"}, table.sample(10))
+}
+
+func TestPeerTable_SampleIsBounded(t *testing.T) {
+	table := newPeerTable(10)
+	table.add("10.0.0.1:4451", time.Unix(1, 0))
+	table.add("10.0.0.2:4451", time.Unix(1, 0))
+	table.add("10.0.0.3:4451", time.Unix(1, 0))
+
+	assert.Len(t, table.sample(2), 2)
+	assert.Len(t, table.sample(5), 3)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+)
+
+// VerifierFactory creates the verifier of messages signed by the given identity
+type VerifierFactory func(id identity.Identity) identity.Verifier
+
+// record is the proposal state announced by its provider
+type record struct {
+	Proposal  market.ServiceProposal `json:"proposal"`
+	Removed   bool                   `json:"removed,omitempty"`
+	Timestamp int64                  `json:"timestamp"`
+	// Peer is the gossip address of the node announcing the record, peers learn each other from it
+	Peer string `json:"peer,omitempty"`
+}
+
+// signedRecord carries the record exactly as it was signed, so every node could verify it
+type signedRecord struct {
+	Record    []byte `json:"record"`
+	Signature string `json:"signature"`
+}
+
+func (r record) time() time.Time {
+	return time.Unix(0, r.Timestamp)
+}
+
+func signRecord(r record, signer identity.Signer) (signedRecord, error) {
+	recordBytes, err := json.Marshal(r)
+	if err != nil {
+		return signedRecord{}, err
+	}
+
+	signature, err := signer.Sign(recordBytes)
+	if err != nil {
+		return signedRecord{}, errors.Wrap(err, "failed to sign proposal record")
+	}
+
+	return signedRecord{Record: recordBytes, Signature: signature.Base64()}, nil
+}
+
+// open unpacks the record and checks that it was signed by the provider of the proposal
+func (sr signedRecord) open(verifierFactory VerifierFactory) (record, error) {
+	var r record
+	if err := json.Unmarshal(sr.Record, &r); err != nil {
+		return r, errors.Wrap(err, "malformed proposal record")
+	}
+
+	verifier := verifierFactory(identity.FromAddress(r.Proposal.ProviderID))
+	if !verifier.Verify(sr.Record, identity.SignatureBase64(sr.Signature)) {
+		return r, errors.Errorf("proposal record of %q has invalid signature", r.Proposal.ProviderID)
+	}
+	return r, nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type registry struct {
+	node *Node
+}
+
+// NewRegistry create an instance of gossip registry, proposals are spread by the given node
+func NewRegistry(node *Node) *registry {
+	return &registry{
+		node: node,
+	}
+}
+
+// RegisterProposal registers service proposal to discovery service
+func (registry *registry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return registry.announce(proposal, false, signer)
+}
+
+// UnregisterProposal unregisters a service proposal when client disconnects
+func (registry *registry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return registry.announce(proposal, true, signer)
+}
+
+// PingProposal pings service proposal as being alive
+func (registry *registry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return registry.announce(proposal, false, signer)
+}
+
+func (registry *registry) announce(proposal market.ServiceProposal, removed bool, signer identity.Signer) error {
+	signed, err := signRecord(record{
+		Proposal:  proposal,
+		Removed:   removed,
+		Timestamp: registry.node.now().UnixNano(),
+		Peer:      registry.node.options.PublicAddress,
+	}, signer)
+	if err != nil {
+		return err
+	}
+	return registry.node.announce(signed)
+}
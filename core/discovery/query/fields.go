@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
+)
+
+type fieldKind int
+
+const (
+	fieldString fieldKind = iota
+	fieldNumber
+)
+
+type field struct {
+	selector reducer.FieldSelector
+	kind     fieldKind
+}
+
+// fields lists the proposal fields the query filters and sorts by, keyed by lower cased names
+var fields = map[string]field{
+	"providerid":  {reducer.ProviderID, fieldString},
+	"servicetype": {reducer.ServiceType, fieldString},
+	"country":     {reducer.LocationCountry, fieldString},
+	"city":        {reducer.LocationCity, fieldString},
+	"continent":   {reducer.LocationContinent, fieldString},
+	"isp":         {reducer.LocationISP, fieldString},
+	"asn":         {reducer.LocationASN, fieldNumber},
+	"nodetype":    {reducer.LocationType, fieldString},
+	"price":       {reducer.Price, fieldNumber},
+}
+
+const (
+	// MetricQuality is the share of successful connections to the provider
+	MetricQuality = "quality"
+	// MetricConnects is the count of successful connections to the provider
+	MetricConnects = "connects"
+)
+
+// metrics lists the quality metrics the query sorts by
+var metrics = map[string]func(ConnectCount) (float64, bool){
+	MetricQuality: func(count ConnectCount) (float64, bool) {
+		total := count.Success + count.Fail + count.Timeout
+		if total == 0 {
+			return 0, false
+		}
+		return float64(count.Success) / float64(total), true
+	},
+	MetricConnects: func(count ConnectCount) (float64, bool) {
+		return float64(count.Success), true
+	},
+}
+
+func lookupField(name string) (field, bool) {
+	f, ok := fields[strings.ToLower(name)]
+	return f, ok
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+)
+
+// parser builds the query from tokens with recursive descent:
+//   query      = [or] ["order" "by" key {"," key}] ["limit" number]
+//   or         = and {"or" and}
+//   and        = not {"and" not}
+//   not        = "not" not | "(" or ")" | comparison
+//   comparison = field operator value | field "in" "(" value {"," value} ")"
+//   key        = (field | metric) ["asc" | "desc"]
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.position]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.position]
+	if t.kind != tokenEnd {
+		p.position++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, expected string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, unexpected(t, expected)
+	}
+	return t, nil
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if t := p.next(); !t.is(keyword) {
+		return unexpected(t, strconv.Quote(keyword))
+	}
+	return nil
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	query := &Query{Match: reducer.All()}
+
+	if t := p.peek(); t.kind != tokenEnd && !t.is("order") && !t.is("limit") {
+		match, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		query.Match = match
+	}
+
+	if p.peek().is("order") {
+		p.next()
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		for {
+			order, err := p.parseOrder()
+			if err != nil {
+				return nil, err
+			}
+			query.Order = append(query.Order, order)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.peek().is("limit") {
+		p.next()
+		t, err := p.expect(tokenNumber, "limit number")
+		if err != nil {
+			return nil, err
+		}
+		limit, err := strconv.Atoi(t.text)
+		if err != nil || limit < 1 {
+			return nil, errors.Errorf("invalid limit %s at %d, expected positive integer", t, t.position)
+		}
+		query.Limit = limit
+	}
+
+	if t := p.next(); t.kind != tokenEnd {
+		return nil, unexpected(t, "\"and\", \"or\", \"order by\", \"limit\" or end of query")
+	}
+	return query, nil
+}
+
+func (p *parser) parseOr() (func(market.ServiceProposal) bool, error) {
+	var conditions []reducer.OrCondition
+	for {
+		condition, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		if !p.peek().is("or") {
+			break
+		}
+		p.next()
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return reducer.Or(conditions...), nil
+}
+
+func (p *parser) parseAnd() (func(market.ServiceProposal) bool, error) {
+	var conditions []reducer.AndCondition
+	for {
+		condition, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		if !p.peek().is("and") {
+			break
+		}
+		p.next()
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return reducer.And(conditions...), nil
+}
+
+func (p *parser) parseNot() (func(market.ServiceProposal) bool, error) {
+	t := p.peek()
+	if t.is("not") {
+		p.next()
+		condition, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return reducer.Not(condition), nil
+	}
+	if t.kind == tokenOpen {
+		p.next()
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenClose, "\")\""); err != nil {
+			return nil, err
+		}
+		return condition, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (func(market.ServiceProposal) bool, error) {
+	name, err := p.expect(tokenWord, "field")
+	if err != nil {
+		return nil, err
+	}
+	f, ok := lookupField(name.text)
+	if !ok {
+		return nil, errors.Errorf("unknown field %s at %d", name, name.position)
+	}
+
+	if p.peek().is("in") {
+		p.next()
+		if _, err := p.expect(tokenOpen, "\"(\""); err != nil {
+			return nil, err
+		}
+		var values []interface{}
+		for {
+			value, err := p.parseValue(f)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokenClose, "\",\" or \")\""); err != nil {
+			return nil, err
+		}
+		if f.kind == fieldNumber {
+			return reducer.Number(f.selector, func(number float64) bool {
+				for _, value := range values {
+					if number == value {
+						return true
+					}
+				}
+				return false
+			}), nil
+		}
+		return reducer.In(f.selector, values...), nil
+	}
+
+	operator, err := p.expect(tokenOperator, "operator or \"in\"")
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.kind == fieldString {
+		switch operator.text {
+		case "=":
+			return reducer.Equal(f.selector, value), nil
+		case "!=":
+			return reducer.Not(reducer.Equal(f.selector, value)), nil
+		}
+		return nil, errors.Errorf("operator %s at %d can't compare text field %s", operator, operator.position, name)
+	}
+
+	number := value.(float64)
+	switch operator.text {
+	case "=":
+		return reducer.Number(f.selector, func(value float64) bool { return value == number }), nil
+	case "!=":
+		return reducer.Number(f.selector, func(value float64) bool { return value != number }), nil
+	case "<":
+		return reducer.LessThan(f.selector, number), nil
+	case "<=":
+		return reducer.Number(f.selector, func(value float64) bool { return value <= number }), nil
+	case ">":
+		return reducer.GreaterThan(f.selector, number), nil
+	default:
+		return reducer.Number(f.selector, func(value float64) bool { return value >= number }), nil
+	}
+}
+
+// parseValue returns the value typed as the field is, text fields take numbers and words as they are written
+func (p *parser) parseValue(f field) (interface{}, error) {
+	t := p.next()
+	switch {
+	case f.kind == fieldNumber && t.kind == tokenNumber:
+		return t.number, nil
+	case f.kind == fieldNumber:
+		return nil, unexpected(t, "number")
+	case t.kind == tokenString || t.kind == tokenWord || t.kind == tokenNumber:
+		return t.text, nil
+	}
+	return nil, unexpected(t, "value")
+}
+
+func (p *parser) parseOrder() (Order, error) {
+	t, err := p.expect(tokenWord, "field or metric")
+	if err != nil {
+		return Order{}, err
+	}
+	key := strings.ToLower(t.text)
+	if _, ok := lookupField(key); !ok {
+		if _, ok := metrics[key]; !ok {
+			return Order{}, errors.Errorf("unknown field or metric %s at %d", t, t.position)
+		}
+	}
+
+	order := Order{Key: key}
+	if next := p.peek(); next.is("asc") || next.is("desc") {
+		p.next()
+		order.Descending = next.is("desc")
+	}
+	return order, nil
+}
+
+func unexpected(t token, expected string) error {
+	return errors.Errorf("unexpected %s at %d, expected %s", t, t.position, expected)
+}
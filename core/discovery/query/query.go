@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"sort"
+
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// ConnectCount holds the connection metrics of the proposal reported by the quality oracle
+type ConnectCount struct {
+	Success int `json:"success"`
+	Fail    int `json:"fail"`
+	Timeout int `json:"timeout"`
+}
+
+// QualityLookup returns the connection metrics of the proposal, flag is false if the proposal has none
+type QualityLookup func(proposal market.ServiceProposal) (ConnectCount, bool)
+
+// Order defines the field or quality metric proposals are sorted by
+type Order struct {
+	Key        string
+	Descending bool
+}
+
+// Query holds the proposal filter, sort order and limit parsed from the query expression
+type Query struct {
+	Match func(market.ServiceProposal) bool
+	Order []Order
+	Limit int
+}
+
+// Parse parses the query expression, for example:
+//   country in (DE, NL) and not isp = "Foo" and price < 0.1 order by quality desc, price limit 10
+func Parse(expression string) (*Query, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.parseQuery()
+}
+
+// NeedsQuality tells if the proposals are sorted by quality metrics
+func (q *Query) NeedsQuality() bool {
+	for _, order := range q.Order {
+		if _, ok := metrics[order.Key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SortAndLimit sorts the proposals and cuts them to the limit,
+// proposals having no value to sort by are put to the end whatever the direction is
+func (q *Query) SortAndLimit(proposals []market.ServiceProposal, quality QualityLookup) []market.ServiceProposal {
+	if len(q.Order) > 0 {
+		sort.SliceStable(proposals, func(i, j int) bool {
+			for _, order := range q.Order {
+				if c := compare(q.value(order.Key, proposals[i], quality), q.value(order.Key, proposals[j], quality)); c != 0 {
+					if order.Descending && !isMissing(c) {
+						return c > 0
+					}
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+
+	if q.Limit > 0 && len(proposals) > q.Limit {
+		proposals = proposals[:q.Limit]
+	}
+	return proposals
+}
+
+func (q *Query) value(key string, proposal market.ServiceProposal, quality QualityLookup) interface{} {
+	if f, ok := lookupField(key); ok {
+		value := f.selector(proposal)
+		if text, ok := value.(string); ok && text == "" {
+			return nil
+		}
+		return value
+	}
+	if quality == nil {
+		return nil
+	}
+	count, ok := quality(proposal)
+	if !ok {
+		return nil
+	}
+	if value, ok := metrics[key](count); ok {
+		return value
+	}
+	return nil
+}
+
+// comparison results of the values when one of them is missing, those keep missing values last in both directions
+const (
+	firstMissing  = 2
+	secondMissing = -2
+)
+
+func isMissing(c int) bool {
+	return c == firstMissing || c == secondMissing
+}
+
+// compare returns -1, 0 or 1 comparing the values, or firstMissing and secondMissing if one of them is nil
+func compare(first, second interface{}) int {
+	switch {
+	case first == nil && second == nil:
+		return 0
+	case first == nil:
+		return firstMissing
+	case second == nil:
+		return secondMissing
+	}
+
+	if firstNumber, ok := reducer.ToNumber(first); ok {
+		secondNumber, _ := reducer.ToNumber(second)
+		switch {
+		case firstNumber < secondNumber:
+			return -1
+		case firstNumber > secondNumber:
+			return 1
+		}
+		return 0
+	}
+
+	firstText, _ := first.(string)
+	secondText, _ := second.(string)
+	switch {
+	case firstText < secondText:
+		return -1
+	case firstText > secondText:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockService struct {
+	Location market.Location
+}
+
+func (service mockService) GetLocation() market.Location {
+	return service.Location
+}
+
+type mockPaymentMethod struct {
+	Price money.Money
+}
+
+func (method mockPaymentMethod) GetPrice() money.Money {
+	return method.Price
+}
+
+func newProposal(providerID, country, isp string, price float64) market.ServiceProposal {
+	return market.ServiceProposal{
+		ProviderID:        providerID,
+		ServiceType:       "openvpn",
+		ServiceDefinition: mockService{Location: market.Location{Country: country, ISP: isp, ASN: 100}},
+		PaymentMethod:     mockPaymentMethod{Price: money.NewMoney(price, money.CurrencyMyst)},
+	}
+}
+
+var (
+	proposalDE    = newProposal("0x1", "DE", "Foo", 0.05)
+	proposalNL    = newProposal("0x2", "NL", "Bar", 0.08)
+	proposalNLFoo = newProposal("0x3", "NL", "Foo", 0.01)
+	proposalLT    = newProposal("0x4", "LT", "Bar", 0.2)
+	proposals     = []market.ServiceProposal{proposalDE, proposalNL, proposalNLFoo, proposalLT}
+)
+
+func match(t *testing.T, expression string) []market.ServiceProposal {
+	q, err := Parse(expression)
+	assert.NoError(t, err)
+	if err != nil {
+		return nil
+	}
+
+	var matched []market.ServiceProposal
+	for _, proposal := range proposals {
+		if q.Match(proposal) {
+			matched = append(matched, proposal)
+		}
+	}
+	return matched
+}
+
+func Test_Parse_Filters(t *testing.T) {
+	assert.Equal(t, proposals, match(t, ""))
+	assert.Equal(t, []market.ServiceProposal{proposalNL}, match(t, `country in (DE,NL) and not isp = "Foo" and price < 0.1`))
+	assert.Equal(t, []market.ServiceProposal{proposalDE, proposalLT}, match(t, `country = DE OR price >= 0.2`))
+	assert.Equal(t, []market.ServiceProposal{proposalNLFoo}, match(t, `country = 'NL' and (isp = Foo or price > 1)`))
+	assert.Equal(t, []market.ServiceProposal{proposalDE, proposalNL, proposalNLFoo}, match(t, `country != LT and asn in (100, 200)`))
+	assert.Equal(t, []market.ServiceProposal{proposalNL, proposalLT}, match(t, `not not providerId in ("0x2", "0x4")`))
+	assert.Equal(t, []market.ServiceProposal{proposalDE, proposalNLFoo}, match(t, `price <= 0.05 and serviceType = openvpn`))
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	for expression, message := range map[string]string{
+		`country = `:                    `unexpected end of query at 10, expected value`,
+		`country ~ DE`:                  `unexpected '~' at 8`,
+		`colour = red`:                  `unknown field "colour" at 0`,
+		`country < DE`:                  `operator "<" at 8 can't compare text field "country"`,
+		`price < cheap`:                 `unexpected "cheap" at 8, expected number`,
+		`country in (DE, NL`:            `unexpected end of query at 18, expected "," or ")"`,
+		`(country = DE`:                 `unexpected end of query at 13, expected ")"`,
+		`country = "DE`:                 `unterminated string at 10`,
+		`country = DE NL`:               `unexpected "NL" at 13, expected "and", "or", "order by", "limit" or end of query`,
+		`order price`:                   `unexpected "price" at 6, expected "by"`,
+		`order by colour`:               `unknown field or metric "colour" at 9`,
+		`limit 0`:                       `invalid limit "0" at 6, expected positive integer`,
+		`country = DE limit 1.5`:        `invalid limit "1.5" at 19, expected positive integer`,
+		`country = DE order by price 1`: `unexpected "1" at 28, expected "and", "or", "order by", "limit" or end of query`,
+	} {
+		_, err := Parse(expression)
+		assert.EqualError(t, err, message, expression)
+	}
+}
+
+func Test_Query_SortAndLimit(t *testing.T) {
+	q, err := Parse("order by price")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalNLFoo, proposalDE, proposalNL, proposalLT}, q.SortAndLimit(append([]market.ServiceProposal{}, proposals...), nil))
+	assert.False(t, q.NeedsQuality())
+
+	q, err = Parse("order by country desc, price asc limit 3")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalNLFoo, proposalNL, proposalLT}, q.SortAndLimit(append([]market.ServiceProposal{}, proposals...), nil))
+}
+
+func Test_Query_SortsByQualityKeepingMissingLast(t *testing.T) {
+	counts := map[string]ConnectCount{
+		proposalDE.ProviderID: {Success: 5, Fail: 5},
+		proposalNL.ProviderID: {Success: 9, Timeout: 1},
+		proposalLT.ProviderID: {},
+	}
+	quality := func(proposal market.ServiceProposal) (ConnectCount, bool) {
+		count, ok := counts[proposal.ProviderID]
+		return count, ok
+	}
+
+	q, err := Parse("country != XX order by quality desc")
+	assert.NoError(t, err)
+	assert.True(t, q.NeedsQuality())
+	assert.Equal(t, []market.ServiceProposal{proposalNL, proposalDE, proposalNLFoo, proposalLT}, q.SortAndLimit(append([]market.ServiceProposal{}, proposals...), quality))
+
+	q, err = Parse("order by quality")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalDE, proposalNL, proposalNLFoo, proposalLT}, q.SortAndLimit(append([]market.ServiceProposal{}, proposals...), quality))
+
+	q, err = Parse("order by connects desc limit 1")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalNL}, q.SortAndLimit(append([]market.ServiceProposal{}, proposals...), quality))
+}
@@ -19,6 +19,7 @@ package reducer
 
 import (
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
 )
 
 var (
@@ -42,6 +43,7 @@ var (
 		ProviderID:        provider1,
 		ServiceType:       serviceTypeStreaming,
 		ServiceDefinition: mockService{Location: locationDatacenter},
+		PaymentMethod:     mockPaymentMethod{Price: money.NewMoney(0.05, money.CurrencyMyst)},
 		AccessPolicies:    &[]market.AccessPolicy{accessRuleWhitelist},
 	}
 	proposalProvider1Noop = market.ServiceProposal{
@@ -53,6 +55,7 @@ var (
 		ProviderID:        provider2,
 		ServiceType:       serviceTypeStreaming,
 		ServiceDefinition: mockService{Location: locationResidential},
+		PaymentMethod:     mockPaymentMethod{Price: money.NewMoney(0.2, money.CurrencyMyst)},
 		AccessPolicies:    &[]market.AccessPolicy{accessRuleWhitelist, accessRuleBlacklist},
 	}
 )
@@ -65,6 +68,14 @@ func (service mockService) GetLocation() market.Location {
 	return service.Location
 }
 
+type mockPaymentMethod struct {
+	Price money.Money
+}
+
+func (method mockPaymentMethod) GetPrice() money.Money {
+	return method.Price
+}
+
 func conditionAlwaysMatch(_ market.ServiceProposal) bool {
 	return true
 }
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reducer
+
+import (
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// NumberCondition returns flag if numeric field value matches against it's rules
+type NumberCondition func(value float64) bool
+
+// Number returns a matcher for checking proposal's numeric field value with custom callback,
+// proposals having no numeric value of the field never match
+func Number(field FieldSelector, condition NumberCondition) func(market.ServiceProposal) bool {
+	return Field(field, func(value interface{}) bool {
+		number, ok := ToNumber(value)
+		return ok && condition(number)
+	})
+}
+
+// LessThan returns a matcher for checking if proposal's numeric field value is less than given value
+func LessThan(field FieldSelector, valueExpected float64) func(market.ServiceProposal) bool {
+	return Number(field, func(value float64) bool {
+		return value < valueExpected
+	})
+}
+
+// GreaterThan returns a matcher for checking if proposal's numeric field value is greater than given value
+func GreaterThan(field FieldSelector, valueExpected float64) func(market.ServiceProposal) bool {
+	return Number(field, func(value float64) bool {
+		return value > valueExpected
+	})
+}
+
+// ToNumber converts numeric field value to float64, flag is false for values of other types
+func ToNumber(value interface{}) (float64, bool) {
+	switch valueTyped := value.(type) {
+	case int:
+		return float64(valueTyped), true
+	case int64:
+		return float64(valueTyped), true
+	case uint64:
+		return float64(valueTyped), true
+	case float64:
+		return valueTyped, true
+	}
+	return 0, false
+}
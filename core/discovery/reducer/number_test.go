@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reducer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Number(t *testing.T) {
+	match := Number(LocationASN, func(value float64) bool {
+		return value == 124
+	})
+
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.False(t, match(proposalProvider1Noop))
+	assert.True(t, match(proposalProvider2Streaming))
+}
+
+func Test_LessThan(t *testing.T) {
+	match := LessThan(Price, 0.1)
+
+	assert.False(t, match(proposalEmpty))
+	assert.True(t, match(proposalProvider1Streaming))
+	assert.False(t, match(proposalProvider1Noop))
+	assert.False(t, match(proposalProvider2Streaming))
+}
+
+func Test_GreaterThan(t *testing.T) {
+	match := GreaterThan(Price, 0.1)
+
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.False(t, match(proposalProvider1Noop))
+	assert.True(t, match(proposalProvider2Streaming))
+}
+
+func Test_ToNumber(t *testing.T) {
+	number, ok := ToNumber(5)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, number)
+
+	number, ok = ToNumber(uint64(7))
+	assert.True(t, ok)
+	assert.Equal(t, 7.0, number)
+
+	_, ok = ToNumber("5")
+	assert.False(t, ok)
+	_, ok = ToNumber(nil)
+	assert.False(t, ok)
+}
@@ -54,6 +54,52 @@ func LocationCountry(proposal market.ServiceProposal) interface{} {
 	return service.GetLocation().Country
 }
 
+// LocationCity selects location city from proposal
+func LocationCity(proposal market.ServiceProposal) interface{} {
+	service := proposal.ServiceDefinition
+	if service == nil {
+		return nil
+	}
+	return service.GetLocation().City
+}
+
+// LocationContinent selects location continent from proposal
+func LocationContinent(proposal market.ServiceProposal) interface{} {
+	service := proposal.ServiceDefinition
+	if service == nil {
+		return nil
+	}
+	return service.GetLocation().Continent
+}
+
+// LocationISP selects location ISP from proposal
+func LocationISP(proposal market.ServiceProposal) interface{} {
+	service := proposal.ServiceDefinition
+	if service == nil {
+		return nil
+	}
+	return service.GetLocation().ISP
+}
+
+// LocationASN selects location autonomous system number from proposal
+func LocationASN(proposal market.ServiceProposal) interface{} {
+	service := proposal.ServiceDefinition
+	if service == nil {
+		return nil
+	}
+	return service.GetLocation().ASN
+}
+
+// Price selects price amount in whole currency units from proposal
+func Price(proposal market.ServiceProposal) interface{} {
+	switch proposal.PaymentMethod.(type) {
+	case nil, market.UnsupportedPaymentMethod:
+		return nil
+	}
+	// money amounts are kept in the smallest units, 10^8 of them make a whole one
+	return float64(proposal.PaymentMethod.GetPrice().Amount) / 100000000
+}
+
 // LocationType selects location type from proposal
 func LocationType(proposal market.ServiceProposal) interface{} {
 	service := proposal.ServiceDefinition
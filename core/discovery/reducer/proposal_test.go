@@ -20,6 +20,7 @@ package reducer
 import (
 	"testing"
 
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,6 +60,21 @@ func Test_Location_FiltersByNodeType(t *testing.T) {
 	assert.True(t, match(proposalProvider2Streaming))
 }
 
+func Test_Location_FiltersByCityAndASN(t *testing.T) {
+	match := And(EqualString(LocationCity, "Vilnius"), EqualInt(LocationASN, 124))
+
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.False(t, match(proposalProvider1Noop))
+	assert.True(t, match(proposalProvider2Streaming))
+}
+
+func Test_Price(t *testing.T) {
+	assert.Nil(t, Price(proposalEmpty))
+	assert.Equal(t, 0.05, Price(proposalProvider1Streaming))
+	assert.Nil(t, Price(market.ServiceProposal{PaymentMethod: market.UnsupportedPaymentMethod{}}))
+}
+
 func Test_AccessPolicy_FiltersByID(t *testing.T) {
 	match := AccessPolicy(accessRuleWhitelist.ID, "")
 
@@ -0,0 +1,231 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	// ProposalAddedTopic represents the topic the proposals appearing in the repository are published to
+	ProposalAddedTopic = "proposalAdded"
+	// ProposalRemovedTopic represents the topic the proposals disappearing from the repository are published to
+	ProposalRemovedTopic = "proposalRemoved"
+)
+
+// SnapshotStorage keeps the proposals between node restarts, so the node could start without reaching Mysterium API
+type SnapshotStorage interface {
+	Load() ([]market.ServiceProposal, error)
+	Save(proposals []market.ServiceProposal) error
+}
+
+// Repository keeps the proposals of the storage current. It takes the full proposal lists fetched from Mysterium API
+// and the proposals registered, pinged and unregistered by the providers through the broker.
+// Proposals which weren't pinged for longer than the max age are expired.
+// Every appearing and disappearing proposal is published, the proposals are persisted to the snapshot storage.
+type Repository struct {
+	storage   *ProposalStorage
+	snapshots SnapshotStorage
+	publisher Publisher
+	maxAge    time.Duration
+	now       func() time.Time
+
+	lastSeen map[market.ProposalID]time.Time
+	changed  bool
+	mu       sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRepository creates the repository of the proposals kept in the given storage
+func NewRepository(storage *ProposalStorage, snapshots SnapshotStorage, publisher Publisher, maxAge time.Duration) *Repository {
+	return &Repository{
+		storage:   storage,
+		snapshots: snapshots,
+		publisher: publisher,
+		maxAge:    maxAge,
+		now:       time.Now,
+		lastSeen:  make(map[market.ProposalID]time.Time),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start loads the proposals of the last snapshot and begins expiring the stale ones
+func (repo *Repository) Start() error {
+	proposals, err := repo.snapshots.Load()
+	if err != nil {
+		return err
+	}
+	if len(proposals) > 0 {
+		log.Infof("proposals loaded from snapshot: %d", len(proposals))
+		repo.mu.Lock()
+		repo.markSeen(proposals...)
+		repo.update(proposals)
+		repo.mu.Unlock()
+	}
+
+	go repo.expireLoop()
+	return nil
+}
+
+// Stop ends expiring the proposals and saves the snapshot of them
+func (repo *Repository) Stop() {
+	repo.stopOnce.Do(func() {
+		close(repo.stop)
+		repo.saveSnapshot()
+	})
+}
+
+// Set replaces the proposals with the full list of them, e.g. fetched from Mysterium API
+func (repo *Repository) Set(proposals ...market.ServiceProposal) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.markSeen(proposals...)
+	repo.update(proposals)
+	repo.changed = true
+}
+
+// Register adds the proposal or refreshes the one having the same ID, registering and pinging providers do this
+func (repo *Repository) Register(proposal market.ServiceProposal) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	proposals := make([]market.ServiceProposal, 0, len(repo.storage.Proposals())+1)
+	found := false
+	for _, current := range repo.storage.Proposals() {
+		if current.UniqueID() == proposal.UniqueID() {
+			current = proposal
+			found = true
+		}
+		proposals = append(proposals, current)
+	}
+	if !found {
+		proposals = append(proposals, proposal)
+	}
+
+	repo.markSeen(proposal)
+	repo.update(proposals)
+	repo.changed = true
+}
+
+// Unregister removes the proposal having the same ID
+func (repo *Repository) Unregister(proposal market.ServiceProposal) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.keep(func(current market.ServiceProposal) bool {
+		return current.UniqueID() != proposal.UniqueID()
+	})
+}
+
+// Expire removes the proposals which weren't seen for longer than the max age
+func (repo *Repository) Expire() {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	deadline := repo.now().Add(-repo.maxAge)
+	repo.keep(func(current market.ServiceProposal) bool {
+		return repo.lastSeen[current.UniqueID()].After(deadline)
+	})
+}
+
+func (repo *Repository) markSeen(proposals ...market.ServiceProposal) {
+	now := repo.now()
+	for _, proposal := range proposals {
+		repo.lastSeen[proposal.UniqueID()] = now
+	}
+}
+
+// keep removes the proposals not passing the given condition
+func (repo *Repository) keep(condition func(market.ServiceProposal) bool) {
+	proposals := make([]market.ServiceProposal, 0, len(repo.storage.Proposals()))
+	for _, current := range repo.storage.Proposals() {
+		if condition(current) {
+			proposals = append(proposals, current)
+		}
+	}
+	if len(proposals) < len(repo.storage.Proposals()) {
+		repo.update(proposals)
+		repo.changed = true
+	}
+}
+
+// update puts the proposals to the storage and publishes the appeared and disappeared ones
+func (repo *Repository) update(proposals []market.ServiceProposal) {
+	current := make(map[market.ProposalID]bool, len(repo.storage.Proposals()))
+	for _, proposal := range repo.storage.Proposals() {
+		current[proposal.UniqueID()] = true
+	}
+
+	var added []market.ServiceProposal
+	updated := make(map[market.ProposalID]bool, len(proposals))
+	for _, proposal := range proposals {
+		updated[proposal.UniqueID()] = true
+		if !current[proposal.UniqueID()] {
+			added = append(added, proposal)
+		}
+	}
+
+	var removed []market.ServiceProposal
+	for _, proposal := range repo.storage.Proposals() {
+		if !updated[proposal.UniqueID()] {
+			removed = append(removed, proposal)
+			delete(repo.lastSeen, proposal.UniqueID())
+		}
+	}
+
+	repo.storage.Set(proposals...)
+
+	for _, proposal := range removed {
+		repo.publisher.Publish(ProposalRemovedTopic, proposal)
+	}
+	for _, proposal := range added {
+		repo.publisher.Publish(ProposalAddedTopic, proposal)
+	}
+}
+
+func (repo *Repository) expireLoop() {
+	for {
+		select {
+		case <-repo.stop:
+			return
+		case <-time.After(repo.maxAge / 2):
+			repo.Expire()
+			repo.saveSnapshot()
+		}
+	}
+}
+
+func (repo *Repository) saveSnapshot() {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if !repo.changed {
+		return
+	}
+	if err := repo.snapshots.Save(repo.storage.Proposals()); err != nil {
+		log.Warn("failed to save proposals snapshot: ", err)
+		return
+	}
+	repo.changed = false
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type publishedEvent struct {
+	topic    string
+	proposal market.ServiceProposal
+}
+
+type recordingPublisher struct {
+	events []publishedEvent
+}
+
+func (publisher *recordingPublisher) Publish(topic string, data interface{}) {
+	publisher.events = append(publisher.events, publishedEvent{topic, data.(market.ServiceProposal)})
+}
+
+type snapshotStorageMock struct {
+	proposals []market.ServiceProposal
+	saves     int
+}
+
+func (storage *snapshotStorageMock) Load() ([]market.ServiceProposal, error) {
+	return storage.proposals, nil
+}
+
+func (storage *snapshotStorageMock) Save(proposals []market.ServiceProposal) error {
+	storage.proposals = proposals
+	storage.saves++
+	return nil
+}
+
+func newTestRepository(snapshots *snapshotStorageMock) (*Repository, *ProposalStorage, *recordingPublisher, *time.Time) {
+	storage := NewStorage()
+	publisher := &recordingPublisher{}
+	repo := NewRepository(storage, snapshots, publisher, time.Minute)
+	now := time.Unix(1000, 0)
+	repo.now = func() time.Time { return now }
+	return repo, storage, publisher, &now
+}
+
+func Test_Repository_SetPublishesChanges(t *testing.T) {
+	repo, storage, publisher, _ := newTestRepository(&snapshotStorageMock{})
+
+	repo.Set(proposalProvider1Streaming, proposalProvider1Noop)
+	repo.Set(proposalProvider1Noop, proposalProvider2Streaming)
+
+	assert.Equal(t, []market.ServiceProposal{proposalProvider1Noop, proposalProvider2Streaming}, storage.Proposals())
+	assert.Equal(t, []publishedEvent{
+		{ProposalAddedTopic, proposalProvider1Streaming},
+		{ProposalAddedTopic, proposalProvider1Noop},
+		{ProposalRemovedTopic, proposalProvider1Streaming},
+		{ProposalAddedTopic, proposalProvider2Streaming},
+	}, publisher.events)
+}
+
+func Test_Repository_RegisterAndUnregister(t *testing.T) {
+	repo, storage, publisher, _ := newTestRepository(&snapshotStorageMock{})
+	repo.Set(proposalProvider1Streaming)
+
+	updated := proposalProvider1Streaming
+	updated.ID = 2
+	repo.Register(proposalProvider2Streaming)
+	repo.Register(updated)
+	assert.Equal(t, []market.ServiceProposal{updated, proposalProvider2Streaming}, storage.Proposals())
+
+	repo.Unregister(proposalProvider1Streaming)
+	repo.Unregister(proposalProvider1Noop)
+	assert.Equal(t, []market.ServiceProposal{proposalProvider2Streaming}, storage.Proposals())
+	assert.Equal(t, []publishedEvent{
+		{ProposalAddedTopic, proposalProvider1Streaming},
+		{ProposalAddedTopic, proposalProvider2Streaming},
+		{ProposalRemovedTopic, updated},
+	}, publisher.events)
+}
+
+func Test_Repository_ExpiresProposalsNotPinged(t *testing.T) {
+	repo, storage, publisher, now := newTestRepository(&snapshotStorageMock{})
+	repo.Set(proposalProvider1Streaming, proposalProvider1Noop)
+
+	*now = now.Add(40 * time.Second)
+	repo.Register(proposalProvider1Noop)
+	repo.Expire()
+	assert.Len(t, storage.Proposals(), 2)
+
+	*now = now.Add(40 * time.Second)
+	repo.Expire()
+	assert.Equal(t, []market.ServiceProposal{proposalProvider1Noop}, storage.Proposals())
+	assert.Equal(t, publishedEvent{ProposalRemovedTopic, proposalProvider1Streaming}, publisher.events[len(publisher.events)-1])
+}
+
+func Test_Repository_StartsFromSnapshot(t *testing.T) {
+	snapshots := &snapshotStorageMock{proposals: []market.ServiceProposal{proposalProvider2Streaming}}
+	repo, storage, publisher, _ := newTestRepository(snapshots)
+
+	assert.NoError(t, repo.Start())
+	assert.Equal(t, []market.ServiceProposal{proposalProvider2Streaming}, storage.Proposals())
+	assert.Equal(t, []publishedEvent{{ProposalAddedTopic, proposalProvider2Streaming}}, publisher.events)
+
+	repo.Register(proposalProvider1Noop)
+	repo.Stop()
+	repo.Stop()
+	assert.Equal(t, 1, snapshots.saves)
+	assert.Equal(t, []market.ServiceProposal{proposalProvider2Streaming, proposalProvider1Noop}, snapshots.proposals)
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package earnings
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/pkg/errors"
+)
+
+// Entry records the amount added by a single validated promise of the consumer
+type Entry struct {
+	ID          int    `storm:"id,increment"`
+	ProviderID  string `storm:"index"`
+	ConsumerID  string
+	SessionID   string
+	ServiceType string
+	Amount      uint64
+	RecordedAt  time.Time `storm:"index"`
+}
+
+// Storage keeps the ledger entries
+type Storage interface {
+	Save(entry Entry) error
+	// List returns the entries of the provider recorded in [from, to), oldest first, zero times leave the range open
+	List(providerID string, from, to time.Time) ([]Entry, error)
+}
+
+// Period is the length of time the earnings are summed up by
+type Period string
+
+const (
+	// Day sums up the earnings by UTC days
+	Day = Period("day")
+	// Week sums up the earnings by weeks starting on UTC Monday
+	Week = Period("week")
+	// Month sums up the earnings by UTC calendar months
+	Month = Period("month")
+)
+
+// ErrUnknownPeriod indicates that the earnings can't be summed up by the given period
+var ErrUnknownPeriod = errors.New("unknown period, should be one of: day, week, month")
+
+// ParsePeriod converts the name to the period
+func ParsePeriod(name string) (Period, error) {
+	switch period := Period(name); period {
+	case Day, Week, Month:
+		return period, nil
+	}
+	return "", ErrUnknownPeriod
+}
+
+// start returns the beginning of the period the given time belongs to
+func (period Period) start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case Week:
+		// time.Weekday starts on Sunday
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// end returns the beginning of the following period
+func (period Period) end(start time.Time) time.Time {
+	switch period {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Summary is the sum of the earnings during a single period
+type Summary struct {
+	Start     time.Time
+	End       time.Time
+	Amount    uint64
+	Promises  int
+	Sessions  int
+	Consumers int
+}
+
+// Ledger records every validated promise of the consumers and sums up the earnings of the providers
+type Ledger struct {
+	storage Storage
+	now     func() time.Time
+}
+
+// NewLedger creates the ledger keeping the entries in the given storage
+func NewLedger(storage Storage) *Ledger {
+	return &Ledger{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// ConsumePromiseEvent records the amount added by the received promise
+func (ledger *Ledger) ConsumePromiseEvent(e event.PromiseEventPayload) {
+	err := ledger.storage.Save(Entry{
+		ProviderID:  e.ProviderID,
+		ConsumerID:  e.ConsumerID,
+		SessionID:   e.SessionID,
+		ServiceType: e.ServiceType,
+		Amount:      e.Amount,
+		RecordedAt:  ledger.now().UTC(),
+	})
+	if err != nil {
+		log.Error("failed to record promise of consumer ", e.ConsumerID, ": ", err)
+	}
+}
+
+// Report sums up the earnings of the provider recorded in [from, to) by the given period, oldest first.
+// Periods without earnings are left out.
+func (ledger *Ledger) Report(providerID string, period Period, from, to time.Time) ([]Summary, error) {
+	entries, err := ledger.storage.List(providerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type tally struct {
+		summary   Summary
+		sessions  map[string]bool
+		consumers map[string]bool
+	}
+	tallies := make(map[time.Time]*tally)
+	for _, entry := range entries {
+		start := period.start(entry.RecordedAt)
+		t, ok := tallies[start]
+		if !ok {
+			t = &tally{
+				summary:   Summary{Start: start, End: period.end(start)},
+				sessions:  make(map[string]bool),
+				consumers: make(map[string]bool),
+			}
+			tallies[start] = t
+		}
+		t.summary.Amount += entry.Amount
+		t.summary.Promises++
+		t.sessions[entry.SessionID] = true
+		t.consumers[entry.ConsumerID] = true
+	}
+
+	summaries := make([]Summary, 0, len(tallies))
+	for _, t := range tallies {
+		t.summary.Sessions = len(t.sessions)
+		t.summary.Consumers = len(t.consumers)
+		summaries = append(summaries, t.summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Start.Before(summaries[j].Start)
+	})
+	return summaries, nil
+}
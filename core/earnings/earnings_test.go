@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package earnings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type storageMock struct {
+	entries []Entry
+}
+
+func (storage *storageMock) Save(entry Entry) error {
+	storage.entries = append(storage.entries, entry)
+	return nil
+}
+
+func (storage *storageMock) List(providerID string, from, to time.Time) ([]Entry, error) {
+	var entries []Entry
+	for _, entry := range storage.entries {
+		if entry.ProviderID == providerID && !entry.RecordedAt.Before(from) && (to.IsZero() || entry.RecordedAt.Before(to)) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func recordPromise(ledger *Ledger, at time.Time, providerID, consumerID, sessionID string, amount uint64) {
+	ledger.now = func() time.Time { return at }
+	ledger.ConsumePromiseEvent(event.PromiseEventPayload{
+		ProviderID:  providerID,
+		ConsumerID:  consumerID,
+		SessionID:   sessionID,
+		ServiceType: "openvpn",
+		Amount:      amount,
+	})
+}
+
+func TestLedger_ReportSumsUpByPeriod(t *testing.T) {
+	storage := &storageMock{}
+	ledger := NewLedger(storage)
+	// Tuesday
+	recordPromise(ledger, time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC), "0x1", "0xa", "s1", 10)
+	recordPromise(ledger, time.Date(2019, 10, 1, 11, 0, 0, 0, time.UTC), "0x1", "0xa", "s1", 20)
+	recordPromise(ledger, time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC), "0x1", "0xb", "s2", 5)
+	// Sunday of the same week
+	recordPromise(ledger, time.Date(2019, 10, 6, 23, 0, 0, 0, time.UTC), "0x1", "0xa", "s3", 7)
+	// Monday of the following week
+	recordPromise(ledger, time.Date(2019, 10, 7, 1, 0, 0, 0, time.UTC), "0x1", "0xa", "s3", 3)
+	recordPromise(ledger, time.Date(2019, 10, 7, 1, 0, 0, 0, time.UTC), "0x2", "0xa", "s4", 100)
+
+	days, err := ledger.Report("0x1", Day, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Summary{
+		{
+			Start: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2019, 10, 2, 0, 0, 0, 0, time.UTC),
+			Amount: 35, Promises: 3, Sessions: 2, Consumers: 2,
+		},
+		{
+			Start: time.Date(2019, 10, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC),
+			Amount: 7, Promises: 1, Sessions: 1, Consumers: 1,
+		},
+		{
+			Start: time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC), End: time.Date(2019, 10, 8, 0, 0, 0, 0, time.UTC),
+			Amount: 3, Promises: 1, Sessions: 1, Consumers: 1,
+		},
+	}, days)
+
+	weeks, err := ledger.Report("0x1", Week, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Summary{
+		{
+			Start: time.Date(2019, 9, 30, 0, 0, 0, 0, time.UTC), End: time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC),
+			Amount: 42, Promises: 4, Sessions: 3, Consumers: 2,
+		},
+		{
+			Start: time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC), End: time.Date(2019, 10, 14, 0, 0, 0, 0, time.UTC),
+			Amount: 3, Promises: 1, Sessions: 1, Consumers: 1,
+		},
+	}, weeks)
+
+	months, err := ledger.Report("0x1", Month, time.Date(2019, 10, 6, 0, 0, 0, 0, time.UTC), time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []Summary{
+		{
+			Start: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC),
+			Amount: 10, Promises: 2, Sessions: 1, Consumers: 1,
+		},
+	}, months)
+}
+
+func TestParsePeriod(t *testing.T) {
+	period, err := ParsePeriod("week")
+	assert.NoError(t, err)
+	assert.Equal(t, Week, period)
+
+	_, err = ParsePeriod("year")
+	assert.Equal(t, ErrUnknownPeriod, err)
+}
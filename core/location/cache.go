@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/network"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
 )
 
@@ -32,6 +33,7 @@ type Cache struct {
 	location         Location
 	origin           Location
 	expiry           time.Duration
+	connected        bool
 	lock             sync.Mutex
 }
 
@@ -78,12 +80,17 @@ func (c *Cache) DetectLocation() (Location, error) {
 
 // HandleConnectionEvent handles connection state change and fetches the location info accordingly.
 // On the consumer side, we'll need to re-fetch the location once the user is connected or disconnected from a service.
+// Only the default connection is taken into account.
 func (c *Cache) HandleConnectionEvent(se connection.StateEvent) {
+	if se.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	if se.State != connection.Connected && se.State != connection.NotConnected {
 		return
 	}
+	c.connected = se.State == connection.Connected
 
 	loc, err := c.fetchAndSave()
 	if err != nil {
@@ -111,3 +118,33 @@ func (c *Cache) HandleNodeEvent(se nodevent.Payload) {
 		log.Tracef("original location detected: %s (%s)", c.origin.Country, c.origin.NodeType)
 	}
 }
+
+// HandleNetworkChangedEvent re-fetches the location, as the node may have moved to another network.
+// Origin is updated too, unless the location is the one of the connected service.
+// The location is fetched without holding the lock, so that the cache is not blocked meanwhile.
+func (c *Cache) HandleNetworkChangedEvent(_ network.ChangedEvent) {
+	c.lock.Lock()
+	connected := c.connected
+	c.lock.Unlock()
+
+	loc, err := c.locationDetector.DetectLocation()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if connected != c.connected {
+		// connection state changed during the fetch, location was fetched again on that
+		return
+	}
+	if err != nil {
+		log.Error("location update after network change failed: ", err)
+		c.lastFetched = time.Time{}
+		return
+	}
+	log.Trace("location updated after network change", loc)
+
+	c.location = loc
+	c.lastFetched = time.Now()
+	if !c.connected {
+		c.origin = loc
+	}
+}
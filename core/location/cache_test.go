@@ -21,9 +21,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/network"
 )
 
 func TestCache_needsRefresh(t *testing.T) {
@@ -72,13 +74,14 @@ func TestCache_needsRefresh(t *testing.T) {
 }
 
 type mockResolver struct {
-	called      bool
-	errToReturn error
+	called           bool
+	locationToReturn Location
+	errToReturn      error
 }
 
 func (mr *mockResolver) DetectLocation() (Location, error) {
 	mr.called = true
-	return Location{}, mr.errToReturn
+	return mr.locationToReturn, mr.errToReturn
 }
 
 func TestCacheHandlesConnection_Connected(t *testing.T) {
@@ -87,7 +90,7 @@ func TestCacheHandlesConnection_Connected(t *testing.T) {
 		expiry:           time.Second * 1,
 		locationDetector: r,
 	}
-	c.HandleConnectionEvent(connection.StateEvent{State: connection.Connected})
+	c.HandleConnectionEvent(connection.StateEvent{ConnectionID: connection.DefaultConnectionID, State: connection.Connected})
 	assert.True(t, r.called)
 }
 
@@ -97,7 +100,7 @@ func TestCacheHandlesConnection_NotConnected(t *testing.T) {
 		expiry:           time.Second * 1,
 		locationDetector: r,
 	}
-	c.HandleConnectionEvent(connection.StateEvent{State: connection.NotConnected})
+	c.HandleConnectionEvent(connection.StateEvent{ConnectionID: connection.DefaultConnectionID, State: connection.NotConnected})
 	assert.True(t, r.called)
 }
 
@@ -107,6 +110,91 @@ func TestCacheIgnoresOther(t *testing.T) {
 		expiry:           time.Second * 1,
 		locationDetector: r,
 	}
-	c.HandleConnectionEvent(connection.StateEvent{State: connection.Reconnecting})
+	c.HandleConnectionEvent(connection.StateEvent{ConnectionID: connection.DefaultConnectionID, State: connection.Reconnecting})
 	assert.False(t, r.called)
 }
+
+func TestCacheHandlesNetworkChanged_UpdatesOrigin(t *testing.T) {
+	r := &mockResolver{locationToReturn: Location{IP: "2.2.2.2"}}
+	c := &Cache{
+		expiry:           time.Second * 1,
+		locationDetector: r,
+		origin:           Location{IP: "1.1.1.1"},
+	}
+	c.HandleNetworkChangedEvent(network.ChangedEvent{})
+
+	loc, err := c.DetectLocation()
+	assert.NoError(t, err)
+	assert.Equal(t, "2.2.2.2", loc.IP)
+	origin, err := c.GetOrigin()
+	assert.NoError(t, err)
+	assert.Equal(t, "2.2.2.2", origin.IP)
+}
+
+func TestCacheHandlesNetworkChanged_KeepsOriginWhenConnected(t *testing.T) {
+	r := &mockResolver{locationToReturn: Location{IP: "3.3.3.3"}}
+	c := &Cache{
+		expiry:           time.Second * 1,
+		locationDetector: r,
+		origin:           Location{IP: "1.1.1.1"},
+	}
+	c.HandleConnectionEvent(connection.StateEvent{ConnectionID: connection.DefaultConnectionID, State: connection.Connected})
+	c.HandleNetworkChangedEvent(network.ChangedEvent{})
+
+	origin, err := c.GetOrigin()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", origin.IP)
+}
+
+func TestCacheHandlesNetworkChanged_ResetsOnFailure(t *testing.T) {
+	r := &mockResolver{errToReturn: errors.New("no route to host")}
+	c := &Cache{
+		expiry:           time.Second * 1,
+		locationDetector: r,
+		lastFetched:      time.Now(),
+		origin:           Location{IP: "1.1.1.1"},
+	}
+	c.HandleNetworkChangedEvent(network.ChangedEvent{})
+
+	assert.True(t, r.called)
+	assert.True(t, c.lastFetched.IsZero())
+	origin, err := c.GetOrigin()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", origin.IP)
+}
+
+type blockingResolver struct {
+	fetching chan struct{}
+	release  chan struct{}
+}
+
+func (br *blockingResolver) DetectLocation() (Location, error) {
+	close(br.fetching)
+	<-br.release
+	return Location{IP: "2.2.2.2"}, nil
+}
+
+func TestCacheHandlesNetworkChanged_DoesNotLockWhileFetching(t *testing.T) {
+	r := &blockingResolver{fetching: make(chan struct{}), release: make(chan struct{})}
+	c := &Cache{
+		expiry:           time.Second * 1,
+		locationDetector: r,
+		origin:           Location{IP: "1.1.1.1"},
+	}
+	done := make(chan struct{})
+	go func() {
+		c.HandleNetworkChangedEvent(network.ChangedEvent{})
+		close(done)
+	}()
+
+	<-r.fetching
+	origin, err := c.GetOrigin()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.1.1", origin.IP)
+
+	close(r.release)
+	<-done
+	origin, err = c.GetOrigin()
+	assert.NoError(t, err)
+	assert.Equal(t, "2.2.2.2", origin.IP)
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/service"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+)
+
+const (
+	sideConsumer = "consumer"
+	sideProvider = "provider"
+
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// Collector turns the events of the node into Prometheus metrics
+type Collector struct {
+	lock sync.Mutex
+
+	connections        map[session.ID]string
+	connectionStats    map[string]consumer.SessionStatistics
+	sessionsTransfered map[string]stateEvent.ServiceSession
+	balances           map[string]uint64
+
+	activeConnections *Gauge
+	activeServices    *Gauge
+	activeSessions    *Gauge
+	bytesSent         *Counter
+	bytesReceived     *Counter
+	natTraversals     *Counter
+	discoveryFailures *Counter
+	promises          *Counter
+	promisedAmount    *Counter
+	balance           *Gauge
+}
+
+// NewCollector registers the node metrics in the given registry and returns their collector
+func NewCollector(registry *Registry) *Collector {
+	return &Collector{
+		connections:        make(map[session.ID]string),
+		connectionStats:    make(map[string]consumer.SessionStatistics),
+		sessionsTransfered: make(map[string]stateEvent.ServiceSession),
+		balances:           make(map[string]uint64),
+
+		activeConnections: registry.NewGauge("myst_connections_active", "Active consumer connections per service type", "service_type"),
+		activeServices:    registry.NewGauge("myst_services_active", "Running provider services per service type", "service_type"),
+		activeSessions:    registry.NewGauge("myst_service_sessions_active", "Active provider sessions per service type", "service_type"),
+		bytesSent:         registry.NewCounter("myst_bytes_sent_total", "Bytes sent through the consumer connections and provider sessions", "side"),
+		bytesReceived:     registry.NewCounter("myst_bytes_received_total", "Bytes received through the consumer connections and provider sessions", "side"),
+		natTraversals:     registry.NewCounter("myst_nat_traversal_total", "NAT traversal outcomes per stage", "stage", "outcome"),
+		discoveryFailures: registry.NewCounter("myst_discovery_failures_total", "Failed proposal registry calls per action", "action"),
+		promises:          registry.NewCounter("myst_promises_received_total", "Promises received from consumers"),
+		promisedAmount:    registry.NewCounter("myst_promised_amount_total", "Amount promised by consumers"),
+		balance:           registry.NewGauge("myst_balance_unconsumed", "Sum of the last balances of the consumer sessions"),
+	}
+}
+
+// EventSubscriber allows subscribing to the event bus topics
+type EventSubscriber interface {
+	Subscribe(topic string, fn interface{}) error
+}
+
+// Subscribe subscribes the collector to all the topics it builds metrics from
+func (c *Collector) Subscribe(bus EventSubscriber) error {
+	subscriptions := []struct {
+		topic string
+		fn    interface{}
+	}{
+		{connection.StateEventTopic, c.ConsumeConnectionStateEvent},
+		{connection.SessionEventTopic, c.ConsumeConnectionSessionEvent},
+		{connection.StatisticsEventTopic, c.ConsumeConnectionStatisticsEvent},
+		{stateEvent.Topic, c.ConsumeStateEvent},
+		{natEvent.Topic, c.ConsumeNATEvent},
+		{discovery.RegistryFailureEventTopic, c.ConsumeRegistryFailureEvent},
+		{sessionEvent.PromiseReceived, c.ConsumePromiseEvent},
+		{sessionEvent.Topic, c.ConsumeSessionEvent},
+	}
+	for _, subscription := range subscriptions {
+		if err := bus.Subscribe(subscription.topic, subscription.fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConsumeConnectionStateEvent counts the established consumer connections
+func (c *Collector) ConsumeConnectionStateEvent(e connection.StateEvent) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch e.State {
+	case connection.Connected:
+		c.connections[e.SessionInfo.SessionID] = e.SessionInfo.Proposal.ServiceType
+	case connection.NotConnected, connection.Canceled:
+		delete(c.connections, e.SessionInfo.SessionID)
+	default:
+		return
+	}
+	c.updateConnections()
+}
+
+// ConsumeConnectionSessionEvent forgets the consumer connections once their sessions end
+func (c *Collector) ConsumeConnectionSessionEvent(e connection.SessionEvent) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch e.Status {
+	case connection.SessionCreatedStatus, connection.SessionFailoverStatus:
+		delete(c.connectionStats, e.ConnectionID)
+	case connection.SessionEndedStatus:
+		delete(c.connectionStats, e.ConnectionID)
+		delete(c.connections, e.SessionInfo.SessionID)
+		c.updateConnections()
+	}
+}
+
+// ConsumeConnectionStatisticsEvent counts the bytes transfered through the consumer connections
+func (c *Collector) ConsumeConnectionStatisticsEvent(e connection.StatisticsEvent) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	previous := c.connectionStats[e.ConnectionID]
+	c.bytesSent.Add(float64(delta(previous.BytesSent, e.Stats.BytesSent)), sideConsumer)
+	c.bytesReceived.Add(float64(delta(previous.BytesReceived, e.Stats.BytesReceived)), sideConsumer)
+	c.connectionStats[e.ConnectionID] = e.Stats
+}
+
+// ConsumeStateEvent counts the provider services, sessions and bytes transfered through them
+func (c *Collector) ConsumeStateEvent(state stateEvent.State) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	serviceTypes := make(map[string]string)
+	c.activeServices.Reset()
+	services := make(map[string]int)
+	for _, serviceInfo := range state.Services {
+		serviceTypes[serviceInfo.ID] = serviceInfo.Type
+		if serviceInfo.Status == string(service.Running) {
+			services[serviceInfo.Type]++
+		}
+	}
+	for serviceType, count := range services {
+		c.activeServices.Set(float64(count), serviceType)
+	}
+
+	c.activeSessions.Reset()
+	sessions := make(map[string]int)
+	transfered := make(map[string]stateEvent.ServiceSession)
+	for _, serviceSession := range state.Sessions {
+		sessions[serviceTypes[serviceSession.ServiceID]]++
+
+		previous := c.sessionsTransfered[serviceSession.ID]
+		c.bytesSent.Add(float64(delta(uint64(previous.BytesOut), uint64(serviceSession.BytesOut))), sideProvider)
+		c.bytesReceived.Add(float64(delta(uint64(previous.BytesIn), uint64(serviceSession.BytesIn))), sideProvider)
+		transfered[serviceSession.ID] = serviceSession
+	}
+	for serviceType, count := range sessions {
+		c.activeSessions.Set(float64(count), serviceType)
+	}
+	c.sessionsTransfered = transfered
+}
+
+// ConsumeNATEvent counts the NAT traversal outcomes
+func (c *Collector) ConsumeNATEvent(e natEvent.Event) {
+	outcome := outcomeSuccess
+	if !e.Successful {
+		outcome = outcomeFailure
+	}
+	c.natTraversals.Inc(e.Stage, outcome)
+}
+
+// ConsumeRegistryFailureEvent counts the failed proposal registry calls
+func (c *Collector) ConsumeRegistryFailureEvent(e discovery.RegistryFailureEvent) {
+	c.discoveryFailures.Inc(e.Action)
+}
+
+// ConsumePromiseEvent counts the received promises and keeps the balance of consumer sessions
+func (c *Collector) ConsumePromiseEvent(e sessionEvent.PromiseEventPayload) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.promises.Inc()
+	c.promisedAmount.Add(float64(e.Amount))
+
+	c.balances[e.SessionID] = e.Balance
+	c.updateBalance()
+}
+
+// ConsumeSessionEvent forgets the balance of the provider session once it is removed
+func (c *Collector) ConsumeSessionEvent(e sessionEvent.Payload) {
+	if e.Action != sessionEvent.Removed {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.balances[e.ID]; !ok {
+		return
+	}
+	delete(c.balances, e.ID)
+	c.updateBalance()
+}
+
+func (c *Collector) updateBalance() {
+	var total uint64
+	for _, balance := range c.balances {
+		total += balance
+	}
+	c.balance.Set(float64(total))
+}
+
+func (c *Collector) updateConnections() {
+	c.activeConnections.Reset()
+	connections := make(map[string]int)
+	for _, serviceType := range c.connections {
+		connections[serviceType]++
+	}
+	for serviceType, count := range connections {
+		c.activeConnections.Set(float64(count), serviceType)
+	}
+}
+
+// delta returns the growth of the cumulative value, the value is considered restarted if it has shrunk
+func delta(previous, current uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/market"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CollectorCountsConsumerConnections(t *testing.T) {
+	registry := NewRegistry()
+	collector := NewCollector(registry)
+	sessionInfo := connection.SessionInfo{
+		SessionID: "session-1",
+		Proposal:  market.ServiceProposal{ServiceType: "wireguard"},
+	}
+
+	collector.ConsumeConnectionStateEvent(connection.StateEvent{State: connection.Connected, SessionInfo: sessionInfo})
+	collector.ConsumeConnectionStatisticsEvent(connection.StatisticsEvent{ConnectionID: connection.DefaultConnectionID, Stats: consumer.SessionStatistics{BytesSent: 10, BytesReceived: 20}})
+	collector.ConsumeConnectionStatisticsEvent(connection.StatisticsEvent{ConnectionID: connection.DefaultConnectionID, Stats: consumer.SessionStatistics{BytesSent: 15, BytesReceived: 50}})
+	assert.Contains(t, writeMetrics(t, registry), `myst_connections_active{service_type="wireguard"} 1`)
+
+	collector.ConsumeConnectionSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionEndedStatus, SessionInfo: sessionInfo})
+	collector.ConsumeConnectionSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionCreatedStatus})
+	collector.ConsumeConnectionStatisticsEvent(connection.StatisticsEvent{ConnectionID: connection.DefaultConnectionID, Stats: consumer.SessionStatistics{BytesSent: 5, BytesReceived: 5}})
+
+	metrics := writeMetrics(t, registry)
+	assert.NotContains(t, metrics, `myst_connections_active{`)
+	assert.Contains(t, metrics, `myst_bytes_sent_total{side="consumer"} 20`)
+	assert.Contains(t, metrics, `myst_bytes_received_total{side="consumer"} 55`)
+}
+
+func Test_CollectorCountsBytesOfEveryConsumerConnection(t *testing.T) {
+	registry := NewRegistry()
+	collector := NewCollector(registry)
+
+	collector.ConsumeConnectionStatisticsEvent(connection.StatisticsEvent{ConnectionID: "first", Stats: consumer.SessionStatistics{BytesSent: 10, BytesReceived: 20}})
+	collector.ConsumeConnectionStatisticsEvent(connection.StatisticsEvent{ConnectionID: "second", Stats: consumer.SessionStatistics{BytesSent: 5, BytesReceived: 5}})
+	collector.ConsumeConnectionStatisticsEvent(connection.StatisticsEvent{ConnectionID: "first", Stats: consumer.SessionStatistics{BytesSent: 15, BytesReceived: 30}})
+
+	metrics := writeMetrics(t, registry)
+	assert.Contains(t, metrics, `myst_bytes_sent_total{side="consumer"} 20`)
+	assert.Contains(t, metrics, `myst_bytes_received_total{side="consumer"} 35`)
+}
+
+func Test_CollectorCountsProviderSessions(t *testing.T) {
+	registry := NewRegistry()
+	collector := NewCollector(registry)
+	services := []stateEvent.ServiceInfo{
+		{ID: "service-1", Type: "openvpn", Status: "Running"},
+		{ID: "service-2", Type: "wireguard", Status: "Starting"},
+	}
+
+	collector.ConsumeStateEvent(stateEvent.State{
+		Services: services,
+		Sessions: []stateEvent.ServiceSession{
+			{ID: "session-1", ServiceID: "service-1", BytesOut: 10, BytesIn: 5},
+			{ID: "session-2", ServiceID: "service-1", BytesOut: 1, BytesIn: 1},
+		},
+	})
+	collector.ConsumeStateEvent(stateEvent.State{
+		Services: services,
+		Sessions: []stateEvent.ServiceSession{
+			{ID: "session-1", ServiceID: "service-1", BytesOut: 30, BytesIn: 15},
+		},
+	})
+
+	metrics := writeMetrics(t, registry)
+	assert.Contains(t, metrics, `myst_services_active{service_type="openvpn"} 1`)
+	assert.NotContains(t, metrics, `myst_services_active{service_type="wireguard"}`)
+	assert.Contains(t, metrics, `myst_service_sessions_active{service_type="openvpn"} 1`)
+	assert.Contains(t, metrics, `myst_bytes_sent_total{side="provider"} 31`)
+	assert.Contains(t, metrics, `myst_bytes_received_total{side="provider"} 16`)
+}
+
+func Test_CollectorCountsNATDiscoveryAndPromises(t *testing.T) {
+	registry := NewRegistry()
+	collector := NewCollector(registry)
+
+	collector.ConsumeNATEvent(natEvent.BuildSuccessfulEvent("port_mapping"))
+	collector.ConsumeNATEvent(natEvent.BuildFailureEvent("hole_punching", errors.New("timeout")))
+	collector.ConsumeRegistryFailureEvent(discovery.RegistryFailureEvent{Action: discovery.RegistryActionPing})
+	collector.ConsumePromiseEvent(sessionEvent.PromiseEventPayload{ConsumerID: "0x1", SessionID: "session-1", Amount: 10, Balance: 10})
+	collector.ConsumePromiseEvent(sessionEvent.PromiseEventPayload{ConsumerID: "0x1", SessionID: "session-1", Amount: 5, Balance: 7})
+	collector.ConsumePromiseEvent(sessionEvent.PromiseEventPayload{ConsumerID: "0x2", SessionID: "session-2", Amount: 3, Balance: 3})
+
+	metrics := writeMetrics(t, registry)
+	assert.Contains(t, metrics, `myst_nat_traversal_total{stage="port_mapping",outcome="success"} 1`)
+	assert.Contains(t, metrics, `myst_nat_traversal_total{stage="hole_punching",outcome="failure"} 1`)
+	assert.Contains(t, metrics, `myst_discovery_failures_total{action="ping"} 1`)
+	assert.Contains(t, metrics, "myst_promises_received_total 3\n")
+	assert.Contains(t, metrics, "myst_promised_amount_total 18\n")
+	assert.Contains(t, metrics, "myst_balance_unconsumed 10\n")
+}
+
+func Test_CollectorForgetsBalancesOfRemovedSessions(t *testing.T) {
+	registry := NewRegistry()
+	collector := NewCollector(registry)
+
+	collector.ConsumePromiseEvent(sessionEvent.PromiseEventPayload{ConsumerID: "0x1", SessionID: "session-1", Amount: 10, Balance: 10})
+	collector.ConsumePromiseEvent(sessionEvent.PromiseEventPayload{ConsumerID: "0x2", SessionID: "session-2", Amount: 3, Balance: 3})
+	collector.ConsumeSessionEvent(sessionEvent.Payload{Action: sessionEvent.Updated, ID: "session-1"})
+	assert.Contains(t, writeMetrics(t, registry), "myst_balance_unconsumed 13\n")
+
+	collector.ConsumeSessionEvent(sessionEvent.Payload{Action: sessionEvent.Removed, ID: "session-1"})
+	assert.Contains(t, writeMetrics(t, registry), "myst_balance_unconsumed 3\n")
+	assert.Len(t, collector.balances, 1)
+}
+
+func writeMetrics(t *testing.T, registry *Registry) string {
+	out := &bytes.Buffer{}
+	assert.Nil(t, registry.Write(out))
+	return out.String()
+}
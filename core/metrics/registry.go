@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	counterType = "counter"
+	gaugeType   = "gauge"
+)
+
+const registryLogPrefix = "[metrics-registry] "
+
+// contentType is the content type of the Prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry keeps the metric families and exposes them in the Prometheus text format
+type Registry struct {
+	lock     sync.Mutex
+	families []*family
+}
+
+// NewRegistry returns an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+type family struct {
+	name       string
+	help       string
+	metricType string
+	labels     []string
+	samples    map[string]*sample
+}
+
+type sample struct {
+	labelValues []string
+	value       float64
+}
+
+// Counter is a metric with label dimensions, which value only goes up
+type Counter struct {
+	registry *Registry
+	family   *family
+}
+
+// Gauge is a metric with label dimensions, which value can arbitrarily go up and down
+type Gauge struct {
+	registry *Registry
+	family   *family
+}
+
+// NewCounter registers and returns a new counter
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{registry: r, family: r.register(name, help, counterType, labels)}
+}
+
+// NewGauge registers and returns a new gauge
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{registry: r, family: r.register(name, help, gaugeType, labels)}
+}
+
+func (r *Registry) register(name, help, metricType string, labels []string) *family {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	f := &family{
+		name:       name,
+		help:       help,
+		metricType: metricType,
+		labels:     labels,
+		samples:    make(map[string]*sample),
+	}
+	r.families = append(r.families, f)
+	return f
+}
+
+// Inc increments the counter of the given label values by 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter of the given label values by the given non negative value
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+	c.registry.lock.Lock()
+	defer c.registry.lock.Unlock()
+	c.family.sample(labelValues).value += value
+}
+
+// Set sets the gauge of the given label values to the given value
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.registry.lock.Lock()
+	defer g.registry.lock.Unlock()
+	g.family.sample(labelValues).value = value
+}
+
+// Reset removes all the label values of the gauge
+func (g *Gauge) Reset() {
+	g.registry.lock.Lock()
+	defer g.registry.lock.Unlock()
+	g.family.samples = make(map[string]*sample)
+}
+
+func (f *family) sample(labelValues []string) *sample {
+	values := make([]string, len(f.labels))
+	copy(values, labelValues)
+
+	key := strings.Join(values, "\xff")
+	s, ok := f.samples[key]
+	if !ok {
+		s = &sample{labelValues: values}
+		f.samples[key] = s
+	}
+	return s
+}
+
+// Write writes all the metrics in the Prometheus text exposition format
+func (r *Registry) Write(w io.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	buffer := bufio.NewWriter(w)
+	for _, f := range r.families {
+		f.write(buffer)
+	}
+	return buffer.Flush()
+}
+
+// ServeHTTP serves the metrics for Prometheus to scrape
+func (r *Registry) ServeHTTP(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", contentType)
+	if err := r.Write(resp); err != nil {
+		log.Error(registryLogPrefix, "failed to write metrics: ", err)
+	}
+}
+
+func (f *family) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escape(f.help, false))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.metricType)
+
+	keys := make([]string, 0, len(f.samples))
+	for key := range f.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if len(keys) == 0 && len(f.labels) == 0 {
+		fmt.Fprintf(w, "%s 0\n", f.name)
+		return
+	}
+	for _, key := range keys {
+		s := f.samples[key]
+		w.WriteString(f.name)
+		if len(f.labels) > 0 {
+			pairs := make([]string, len(f.labels))
+			for i, label := range f.labels {
+				pairs[i] = fmt.Sprintf(`%s="%s"`, label, escape(s.labelValues[i], true))
+			}
+			fmt.Fprintf(w, "{%s}", strings.Join(pairs, ","))
+		}
+		fmt.Fprintf(w, " %s\n", strconv.FormatFloat(s.value, 'g', -1, 64))
+	}
+}
+
+func escape(value string, quoted bool) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	if quoted {
+		value = strings.Replace(value, `"`, `\"`, -1)
+	}
+	return value
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RegistryWritesTextFormat(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("test_total", "Test counter", "stage", "outcome")
+	gauge := registry.NewGauge("test_active", "Test gauge")
+
+	counter.Inc("port \"mapping\"", "success")
+	counter.Add(2, "hole_punching", "failure")
+	counter.Add(-1, "hole_punching", "failure")
+
+	out := &bytes.Buffer{}
+	assert.Nil(t, registry.Write(out))
+	assert.Equal(
+		t,
+		"# HELP test_total Test counter\n"+
+			"# TYPE test_total counter\n"+
+			"test_total{stage=\"hole_punching\",outcome=\"failure\"} 2\n"+
+			"test_total{stage=\"port \\\"mapping\\\"\",outcome=\"success\"} 1\n"+
+			"# HELP test_active Test gauge\n"+
+			"# TYPE test_active gauge\n"+
+			"test_active 0\n",
+		out.String(),
+	)
+
+	gauge.Set(1.5)
+	out.Reset()
+	assert.Nil(t, registry.Write(out))
+	assert.Contains(t, out.String(), "test_active 1.5\n")
+}
+
+func Test_RegistryGaugeReset(t *testing.T) {
+	registry := NewRegistry()
+	gauge := registry.NewGauge("test_active", "Test gauge", "service_type")
+	gauge.Set(3, "openvpn")
+	gauge.Reset()
+	gauge.Set(1, "wireguard")
+
+	out := &bytes.Buffer{}
+	assert.Nil(t, registry.Write(out))
+	assert.NotContains(t, out.String(), "openvpn")
+	assert.Contains(t, out.String(), "test_active{service_type=\"wireguard\"} 1\n")
+}
+
+func Test_RegistryServesMetrics(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "Test counter").Inc()
+
+	resp := httptest.NewRecorder()
+	registry.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, contentType, resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), "test_total 1\n")
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package network
+
+// Topic represents the topic the network changes are published to
+const Topic = "NetworkChanged"
+
+// ChangedEvent represents the change of the local network or of the public IP of the node,
+// addresses are listed as "interface=IP" of the interfaces the node reaches the network through
+type ChangedEvent struct {
+	Previous []string
+	Current  []string
+
+	PreviousPublicIP string
+	CurrentPublicIP  string
+}
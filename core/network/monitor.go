@@ -0,0 +1,264 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package network
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultSettleDuration is the time the network has to stay unchanged before it is compared to the previous one,
+// changes come in bursts of link, address and route updates
+const DefaultSettleDuration = 3 * time.Second
+
+// DefaultPublicIPCheckInterval is how often the public IP is checked, it changes without any change
+// of the local addresses when the node is behind NAT
+const DefaultPublicIPCheckInterval = 5 * time.Minute
+
+// tunnelPrefixes are the interfaces of VPN tunnels, both ours and foreign ones,
+// they come and go with connections and do not change the network the node is in
+var tunnelPrefixes = []string{"tun", "tap", "utun", "wg", "myst"}
+
+// Publisher is responsible for publishing given events
+type Publisher interface {
+	Publish(topic string, data interface{})
+}
+
+// PublicIPResolver resolves the public IP of the node
+type PublicIPResolver interface {
+	GetPublicIP() (string, error)
+}
+
+// watchFunc notifies about possible network changes to the given channel until stopped
+type watchFunc func(changes chan<- struct{}, stop <-chan struct{}) error
+
+// Monitor watches the local network and publishes the event once the addresses of the node change,
+// i.e. on DHCP renew, Wi-Fi roaming or mobile handover, or once its public IP changes
+type Monitor struct {
+	publisher         Publisher
+	ipResolver        PublicIPResolver
+	addresses         func() ([]string, error)
+	watch             watchFunc
+	settleDuration    time.Duration
+	publicIPInterval  time.Duration
+	publicIPTracked   bool
+	publicIPTrackLock sync.Mutex
+
+	current  []string
+	publicIP string
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMonitor creates new network monitor
+func NewMonitor(publisher Publisher, ipResolver PublicIPResolver, settleDuration time.Duration) *Monitor {
+	return &Monitor{
+		publisher:        publisher,
+		ipResolver:       ipResolver,
+		addresses:        interfaceAddresses,
+		watch:            watchChanges,
+		settleDuration:   settleDuration,
+		publicIPInterval: DefaultPublicIPCheckInterval,
+		publicIPTracked:  true,
+		stop:             make(chan struct{}),
+	}
+}
+
+// Start starts watching the network in the background
+func (m *Monitor) Start() error {
+	current, err := m.addresses()
+	if err != nil {
+		return errors.Wrap(err, "failed to list network addresses")
+	}
+	m.current = current
+
+	changes := make(chan struct{}, 1)
+	if err := m.watch(changes, m.stop); err != nil {
+		return errors.Wrap(err, "failed to watch network changes")
+	}
+
+	go m.run(changes)
+	return nil
+}
+
+// TrackPublicIP enables or disables the public IP checks. They are disabled while the traffic
+// is tunneled, the public IP is the one of the tunnel then. Once enabled again the public IP
+// is compared to the one resolved before disabling.
+func (m *Monitor) TrackPublicIP(enabled bool) {
+	m.publicIPTrackLock.Lock()
+	defer m.publicIPTrackLock.Unlock()
+	m.publicIPTracked = enabled
+}
+
+func (m *Monitor) publicIPTrackingEnabled() bool {
+	m.publicIPTrackLock.Lock()
+	defer m.publicIPTrackLock.Unlock()
+	return m.publicIPTracked
+}
+
+// Stop stops watching the network
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+func (m *Monitor) run(changes <-chan struct{}) {
+	m.publicIP = m.resolvePublicIP()
+
+	ticker := time.NewTicker(m.publicIPInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-changes:
+			if !m.settle(changes) {
+				return
+			}
+		case <-ticker.C:
+		case <-m.stop:
+			return
+		}
+		m.check()
+	}
+}
+
+// settle waits until no changes are notified for the settle duration, returns false if monitor was stopped
+func (m *Monitor) settle(changes <-chan struct{}) bool {
+	timer := time.NewTimer(m.settleDuration)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-changes:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(m.settleDuration)
+		case <-timer.C:
+			return true
+		case <-m.stop:
+			return false
+		}
+	}
+}
+
+func (m *Monitor) check() {
+	current, err := m.addresses()
+	if err != nil {
+		log.Warn("failed to list network addresses: ", err)
+		return
+	}
+	if len(current) == 0 {
+		// network is down, it is compared to the last known one once it comes back
+		log.Info("network is unavailable")
+		return
+	}
+	publicIP := m.resolvePublicIP()
+	if m.publicIP == "" {
+		// public IP was not resolved before, there is nothing to compare it to
+		m.publicIP = publicIP
+	}
+	if equalAddresses(m.current, current) && publicIP == m.publicIP {
+		return
+	}
+
+	event := ChangedEvent{Previous: m.current, Current: current, PreviousPublicIP: m.publicIP, CurrentPublicIP: publicIP}
+	m.current = current
+	m.publicIP = publicIP
+	log.Infof("network changed from %v (%s) to %v (%s)", event.Previous, event.PreviousPublicIP, event.Current, event.CurrentPublicIP)
+	m.publisher.Publish(Topic, event)
+}
+
+// resolvePublicIP returns the last known public IP when it is not tracked or fails to resolve
+func (m *Monitor) resolvePublicIP() string {
+	if !m.publicIPTrackingEnabled() {
+		return m.publicIP
+	}
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		log.Warn("failed to resolve public IP: ", err)
+		return m.publicIP
+	}
+	return publicIP
+}
+
+func equalAddresses(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// interfaceAddresses lists IPv4 addresses of the interfaces which are up, sorted.
+// Loopback and tunnel interfaces are skipped, as well as IPv6 addresses - temporary ones are rotated
+// periodically without any change of the network.
+func interfaceAddresses() ([]string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&(net.FlagLoopback|net.FlagPointToPoint) != 0 || isTunnel(iface.Name) {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get addresses of "+iface.Name)
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			addresses = append(addresses, iface.Name+"="+ipNet.IP.String())
+		}
+	}
+
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+func isTunnel(name string) bool {
+	for _, prefix := range tunnelPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func notify(changes chan<- struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package network
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPublisher struct {
+	lock   sync.Mutex
+	events []ChangedEvent
+}
+
+func (mp *mockPublisher) Publish(topic string, data interface{}) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	if topic == Topic {
+		mp.events = append(mp.events, data.(ChangedEvent))
+	}
+}
+
+func (mp *mockPublisher) published() []ChangedEvent {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	return append([]ChangedEvent(nil), mp.events...)
+}
+
+type mockNetwork struct {
+	lock      sync.Mutex
+	addresses []string
+	err       error
+	changes   chan<- struct{}
+}
+
+func (mn *mockNetwork) list() ([]string, error) {
+	mn.lock.Lock()
+	defer mn.lock.Unlock()
+	return mn.addresses, mn.err
+}
+
+func (mn *mockNetwork) watch(changes chan<- struct{}, stop <-chan struct{}) error {
+	mn.changes = changes
+	return nil
+}
+
+func (mn *mockNetwork) change(addresses []string, err error) {
+	mn.lock.Lock()
+	mn.addresses = addresses
+	mn.err = err
+	mn.lock.Unlock()
+	notify(mn.changes)
+}
+
+type mockIPResolver struct {
+	lock     sync.Mutex
+	publicIP string
+	err      error
+}
+
+func (mr *mockIPResolver) GetPublicIP() (string, error) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	return mr.publicIP, mr.err
+}
+
+func (mr *mockIPResolver) change(publicIP string, err error) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	mr.publicIP = publicIP
+	mr.err = err
+}
+
+func newTestMonitor(network *mockNetwork, publisher *mockPublisher) *Monitor {
+	return newTestMonitorWithResolver(network, &mockIPResolver{publicIP: "1.2.3.4"}, publisher)
+}
+
+func newTestMonitorWithResolver(network *mockNetwork, resolver *mockIPResolver, publisher *mockPublisher) *Monitor {
+	monitor := NewMonitor(publisher, resolver, 10*time.Millisecond)
+	monitor.addresses = network.list
+	monitor.watch = network.watch
+	return monitor
+}
+
+func TestMonitor_PublishesChangedAddresses(t *testing.T) {
+	network := &mockNetwork{addresses: []string{"eth0=192.168.1.2"}}
+	publisher := &mockPublisher{}
+	monitor := newTestMonitor(network, publisher)
+	assert.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	network.change([]string{"wlan0=10.0.0.5"}, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(
+		t,
+		[]ChangedEvent{{
+			Previous:         []string{"eth0=192.168.1.2"},
+			Current:          []string{"wlan0=10.0.0.5"},
+			PreviousPublicIP: "1.2.3.4",
+			CurrentPublicIP:  "1.2.3.4",
+		}},
+		publisher.published(),
+	)
+}
+
+func TestMonitor_SkipsUnchangedAddresses(t *testing.T) {
+	network := &mockNetwork{addresses: []string{"eth0=192.168.1.2"}}
+	publisher := &mockPublisher{}
+	monitor := newTestMonitor(network, publisher)
+	assert.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	network.change([]string{"eth0=192.168.1.2"}, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Empty(t, publisher.published())
+}
+
+func TestMonitor_WaitsForNetworkToComeBack(t *testing.T) {
+	network := &mockNetwork{addresses: []string{"eth0=192.168.1.2"}}
+	publisher := &mockPublisher{}
+	monitor := newTestMonitor(network, publisher)
+	assert.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	network.change(nil, nil)
+	time.Sleep(50 * time.Millisecond)
+	network.change(nil, errors.New("interfaces unavailable"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, publisher.published())
+
+	network.change([]string{"eth0=192.168.1.2"}, nil)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, publisher.published())
+}
+
+func TestMonitor_DebouncesBurstOfChanges(t *testing.T) {
+	network := &mockNetwork{addresses: []string{"eth0=192.168.1.2"}}
+	publisher := &mockPublisher{}
+	monitor := newTestMonitor(network, publisher)
+	monitor.settleDuration = 30 * time.Millisecond
+	assert.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	network.change(nil, nil)
+	time.Sleep(10 * time.Millisecond)
+	network.change([]string{"eth0=192.168.1.3"}, nil)
+	time.Sleep(10 * time.Millisecond)
+	network.change([]string{"eth0=192.168.1.4"}, nil)
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(
+		t,
+		[]ChangedEvent{{
+			Previous:         []string{"eth0=192.168.1.2"},
+			Current:          []string{"eth0=192.168.1.4"},
+			PreviousPublicIP: "1.2.3.4",
+			CurrentPublicIP:  "1.2.3.4",
+		}},
+		publisher.published(),
+	)
+}
+
+func TestMonitor_PublishesChangedPublicIP(t *testing.T) {
+	network := &mockNetwork{addresses: []string{"eth0=192.168.1.2"}}
+	resolver := &mockIPResolver{publicIP: "1.2.3.4"}
+	publisher := &mockPublisher{}
+	monitor := newTestMonitorWithResolver(network, resolver, publisher)
+	monitor.publicIPInterval = 20 * time.Millisecond
+	assert.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	time.Sleep(10 * time.Millisecond)
+	resolver.change("5.6.7.8", nil)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(
+		t,
+		[]ChangedEvent{{
+			Previous:         []string{"eth0=192.168.1.2"},
+			Current:          []string{"eth0=192.168.1.2"},
+			PreviousPublicIP: "1.2.3.4",
+			CurrentPublicIP:  "5.6.7.8",
+		}},
+		publisher.published(),
+	)
+}
+
+func TestMonitor_SkipsPublicIPWhenResolvingFails(t *testing.T) {
+	network := &mockNetwork{addresses: []string{"eth0=192.168.1.2"}}
+	resolver := &mockIPResolver{publicIP: "1.2.3.4"}
+	publisher := &mockPublisher{}
+	monitor := newTestMonitorWithResolver(network, resolver, publisher)
+	monitor.publicIPInterval = 20 * time.Millisecond
+	assert.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	time.Sleep(10 * time.Millisecond)
+	resolver.change("", errors.New("ip detector unavailable"))
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Empty(t, publisher.published())
+}
+
+func TestMonitor_ComparesPublicIPOnceTrackedAgain(t *testing.T) {
+	network := &mockNetwork{addresses: []string{"eth0=192.168.1.2"}}
+	resolver := &mockIPResolver{publicIP: "1.2.3.4"}
+	publisher := &mockPublisher{}
+	monitor := newTestMonitorWithResolver(network, resolver, publisher)
+	monitor.publicIPInterval = 20 * time.Millisecond
+	assert.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	time.Sleep(10 * time.Millisecond)
+	monitor.TrackPublicIP(false)
+	resolver.change("9.9.9.9", nil)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, publisher.published())
+
+	resolver.change("1.2.3.4", nil)
+	monitor.TrackPublicIP(true)
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, publisher.published())
+}
+
+func TestMonitor_StartFailsWhenAddressesUnavailable(t *testing.T) {
+	network := &mockNetwork{err: errors.New("interfaces unavailable")}
+	monitor := newTestMonitor(network, &mockPublisher{})
+
+	assert.EqualError(t, monitor.Start(), "failed to list network addresses: interfaces unavailable")
+}
+
+func TestIsTunnel(t *testing.T) {
+	assert.True(t, isTunnel("tun0"))
+	assert.True(t, isTunnel("myst1"))
+	assert.True(t, isTunnel("utun2"))
+	assert.False(t, isTunnel("eth0"))
+	assert.False(t, isTunnel("wlan0"))
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package network
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// netlink multicast groups of route updates, see rtnetlink.h
+const (
+	rtmgrpLink       = 0x1
+	rtmgrpIPv4IfAddr = 0x10
+	rtmgrpIPv4Route  = 0x40
+)
+
+// receiveTimeout bounds the blocking receive from the netlink socket, so that the watcher notices being stopped
+const receiveTimeout = time.Second
+
+// watchChanges subscribes to link, address and route updates of the kernel through the netlink socket
+func watchChanges(changes chan<- struct{}, stop <-chan struct{}) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return errors.Wrap(err, "failed to open netlink socket")
+	}
+
+	addr := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: rtmgrpLink | rtmgrpIPv4IfAddr | rtmgrpIPv4Route,
+	}
+	if err := syscall.Bind(fd, addr); err != nil {
+		syscall.Close(fd)
+		return errors.Wrap(err, "failed to bind netlink socket")
+	}
+
+	timeout := syscall.NsecToTimeval(receiveTimeout.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout); err != nil {
+		syscall.Close(fd)
+		return errors.Wrap(err, "failed to set netlink receive timeout")
+	}
+
+	go receiveChanges(fd, changes, stop)
+	return nil
+}
+
+func receiveChanges(fd int, changes chan<- struct{}, stop <-chan struct{}) {
+	defer syscall.Close(fd)
+
+	buf := make([]byte, os.Getpagesize())
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		switch err {
+		case nil:
+		case syscall.EAGAIN, syscall.EINTR:
+			continue
+		case syscall.ENOBUFS:
+			// updates were dropped by the kernel, network has to be checked anyway
+			notify(changes)
+			continue
+		default:
+			log.Error("failed to receive from netlink socket: ", err)
+			return
+		}
+
+		messages, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			log.Warn("failed to parse netlink message: ", err)
+			continue
+		}
+		if len(messages) > 0 {
+			notify(changes)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// +build !linux
+
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package network
+
+import "time"
+
+// pollInterval is the interval the network is checked at, where no change notifications are available
+const pollInterval = 10 * time.Second
+
+// watchChanges checks the network periodically
+func watchChanges(changes chan<- struct{}, stop <-chan struct{}) error {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				notify(changes)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
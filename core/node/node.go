@@ -21,6 +21,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/tequilapi"
+	"github.com/pkg/errors"
 )
 
 // NatPinger allows to send nat pings as well as stop it
@@ -42,28 +43,28 @@ type UIServer interface {
 
 // NewNode function creates new Mysterium node by given options
 func NewNode(
-	connectionManager connection.Manager,
+	connectionPool connection.Pool,
 	tequilapiServer tequilapi.APIServer,
 	publisher Publisher,
 	natPinger NatPinger,
 	uiServer UIServer,
 ) *Node {
 	return &Node{
-		connectionManager: connectionManager,
-		httpAPIServer:     tequilapiServer,
-		publisher:         publisher,
-		natPinger:         natPinger,
-		uiServer:          uiServer,
+		connectionPool: connectionPool,
+		httpAPIServer:  tequilapiServer,
+		publisher:      publisher,
+		natPinger:      natPinger,
+		uiServer:       uiServer,
 	}
 }
 
 // Node represent entrypoint for Mysterium node with top level components
 type Node struct {
-	connectionManager connection.Manager
-	httpAPIServer     tequilapi.APIServer
-	publisher         Publisher
-	natPinger         NatPinger
-	uiServer          UIServer
+	connectionPool connection.Pool
+	httpAPIServer  tequilapi.APIServer
+	publisher      Publisher
+	natPinger      NatPinger
+	uiServer       UIServer
 }
 
 // Start starts Mysterium node (Tequilapi service, fetches location)
@@ -95,18 +96,30 @@ func (node *Node) Wait() error {
 	return node.httpAPIServer.Wait()
 }
 
-// Kill stops Mysterium node
-func (node *Node) Kill() error {
-	err := node.connectionManager.Disconnect()
-	if err != nil {
-		switch err {
-		case connection.ErrNoConnection:
-			log.Info("no active connection - proceeding")
-		default:
-			return err
+// Kill stops Mysterium node, every connection is closed even if some of them fail to close
+func (node *Node) Kill() (err error) {
+	var errs []error
+	defer func() {
+		for i := range errs {
+			log.Error("Node kill failed: ", errs[i])
+			if err == nil {
+				err = errs[i]
+			}
+		}
+	}()
+
+	for id := range node.connectionPool.List() {
+		err := node.connectionPool.Disconnect(id)
+		if err != nil {
+			switch err {
+			case connection.ErrNoConnection:
+				log.Infof("no active connection %s - proceeding", id)
+			default:
+				errs = append(errs, errors.Wrapf(err, "failed to close connection %s", id))
+			}
+		} else {
+			log.Infof("connection %s closed", id)
 		}
-	} else {
-		log.Info("connection closed")
 	}
 
 	node.httpAPIServer.Stop()
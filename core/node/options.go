@@ -32,6 +32,7 @@ type Options struct {
 
 	TequilapiAddress string
 	TequilapiPort    int
+	TequilapiAuth    bool
 	BindAddress      string
 	UI               OptionsUI
 
@@ -46,12 +47,16 @@ type Options struct {
 	Quality    OptionsQuality
 	Location   OptionsLocation
 	Transactor OptionsTransactor
+	Webhooks   OptionsWebhooks
 
-	Openvpn  Openvpn
-	Firewall OptionsFirewall
+	Openvpn   Openvpn
+	Wireguard OptionsWireguard
+	Firewall  OptionsFirewall
 }
 
 // OptionsKeystore stores the keystore configuration
 type OptionsKeystore struct {
 	UseLightweight bool
+	// ExternalSigner is the local socket of the signer keeping the keys, the keystore directory is used when empty
+	ExternalSigner string
 }
@@ -25,10 +25,16 @@ const (
 	DiscoveryTypeAPI = DiscoveryType("api")
 	// DiscoveryTypeBroker defines type which discovers proposals through Broker (Mysterium Communication)
 	DiscoveryTypeBroker = DiscoveryType("broker")
+	// DiscoveryTypeGossip defines type which discovers proposals by exchanging them with peer nodes directly
+	DiscoveryTypeGossip = DiscoveryType("gossip")
 )
 
 // OptionsDiscovery describes possible parameters of discovery configuration
 type OptionsDiscovery struct {
 	Type    DiscoveryType
 	Address string
+
+	GossipAddress       string
+	GossipPublicAddress string
+	GossipPeers         []string
 }
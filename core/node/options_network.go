@@ -24,6 +24,7 @@ type OptionsNetwork struct {
 
 	ExperimentIdentityCheck bool
 	ExperimentNATPunching   bool
+	STUNServers             []string
 
 	MysteriumAPIAddress         string
 	AccessPolicyEndpointAddress string
@@ -22,7 +22,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/mysteriumnetwork/node/consumer"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node/event"
@@ -102,10 +101,15 @@ type sessionContext struct {
 	ServiceType     string
 	ProviderCountry string
 	ConsumerCountry string
+	Relayed         bool
 }
 
-// SendSessionData sends transferred information about session.
-func (sender *Sender) SendSessionData(data consumer.SessionStatistics) {
+// SendSessionData sends transferred information about session of the default connection.
+func (sender *Sender) SendSessionData(e connection.StatisticsEvent) {
+	if e.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	data := e.Stats
 	currentSession := sender.session()
 	if len(currentSession.SessionID) == 0 {
 		return
@@ -121,23 +125,32 @@ func (sender *Sender) SendSessionData(data consumer.SessionStatistics) {
 			ServiceType:     currentSession.Proposal.ServiceType,
 			ProviderCountry: currentSession.Proposal.ServiceDefinition.GetLocation().Country,
 			ConsumerCountry: sender.originCountry(),
+			Relayed:         currentSession.Relayed,
 		},
 	})
 }
 
-// SendSessionEvent sends session update events.
+// SendSessionEvent sends session update events of the default connection.
 func (sender *Sender) SendSessionEvent(e interface{}) {
 	var id, eventName, provider, consumer, serviceType, providerCountry string
+	var relayed bool
 	switch state := e.(type) {
 	case connection.StateEvent:
+		if state.ConnectionID != connection.DefaultConnectionID {
+			return
+		}
 		id = string(state.SessionInfo.SessionID)
 		eventName = string(state.State)
 		consumer = state.SessionInfo.ConsumerID.Address
 		provider = state.SessionInfo.Proposal.ProviderID
 		serviceType = state.SessionInfo.Proposal.ServiceType
 		providerCountry = state.SessionInfo.Proposal.ServiceDefinition.GetLocation().Country
+		relayed = state.SessionInfo.Relayed
 	case connection.SessionEvent:
-		if state.Status == connection.SessionCreatedStatus {
+		if state.ConnectionID != connection.DefaultConnectionID {
+			return
+		}
+		if state.Status == connection.SessionCreatedStatus || state.Status == connection.SessionRelayedStatus {
 			sender.setCurrentSession(&state.SessionInfo)
 		} else if state.Status == connection.SessionEndedStatus {
 			sender.setCurrentSession(nil)
@@ -149,6 +162,7 @@ func (sender *Sender) SendSessionEvent(e interface{}) {
 		provider = state.SessionInfo.Proposal.ProviderID
 		serviceType = state.SessionInfo.Proposal.ServiceType
 		providerCountry = state.SessionInfo.Proposal.ServiceDefinition.GetLocation().Country
+		relayed = state.SessionInfo.Relayed
 	default:
 		log.Warn("unknown session event type", e)
 		return
@@ -163,6 +177,7 @@ func (sender *Sender) SendSessionEvent(e interface{}) {
 			ServiceType:     serviceType,
 			ProviderCountry: providerCountry,
 			ConsumerCountry: sender.originCountry(),
+			Relayed:         relayed,
 		},
 	})
 }
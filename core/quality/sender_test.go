@@ -22,7 +22,11 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -86,3 +90,34 @@ func TestSender_SendNATMappingFailEvent_SendsToTransport(t *testing.T) {
 	assert.Equal(t, "hole_punching", c.Stage)
 	assert.Equal(t, mockGateways, c.Gateways)
 }
+
+func TestSender_SendSessionData_MarksRelayedSession(t *testing.T) {
+	mockTransport := buildMockEventsTransport(nil)
+	sender := &Sender{Transport: mockTransport, AppVersion: "test version", location: &mockOriginResolver{}}
+
+	sessionInfo := connection.SessionInfo{SessionID: "session1", Proposal: market.ServiceProposal{ServiceDefinition: &mockServiceDefinition{}}}
+	sender.SendSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionCreatedStatus, SessionInfo: sessionInfo})
+	sessionInfo.Relayed = true
+	sender.SendSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionRelayedStatus, SessionInfo: sessionInfo})
+
+	c := mockTransport.sentEvent.Context.(sessionEventContext)
+	assert.Equal(t, "Relayed", c.Event)
+	assert.True(t, c.Relayed)
+
+	sender.SendSessionData(connection.StatisticsEvent{ConnectionID: connection.DefaultConnectionID, Stats: consumer.SessionStatistics{BytesReceived: 1, BytesSent: 2}})
+
+	assert.Equal(t, "session_data", mockTransport.sentEvent.EventName)
+	assert.True(t, mockTransport.sentEvent.Context.(sessionDataContext).Relayed)
+}
+
+type mockOriginResolver struct{}
+
+func (resolver *mockOriginResolver) GetOrigin() (location.Location, error) {
+	return location.Location{Country: "LT"}, nil
+}
+
+type mockServiceDefinition struct{}
+
+func (service *mockServiceDefinition) GetLocation() market.Location {
+	return market.Location{Country: "DE"}
+}
@@ -19,9 +19,11 @@ package service
 
 import (
 	"encoding/json"
+	"sync"
 
 	"github.com/gofrs/uuid"
 	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/core/network"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/traversal"
@@ -93,6 +95,8 @@ type Manager struct {
 	discoveryFactory DiscoveryFactory
 	eventPublisher   Publisher
 	bindAddress      string
+
+	announceLock sync.Mutex
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
@@ -160,7 +164,10 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 			log.Error("Service stop failed: ", stopErr)
 		}
 
-		discovery.Wait()
+		// discovery is replaced when the service is announced again after a network change
+		if discovery := manager.instanceDiscovery(&instance); discovery != nil {
+			discovery.Wait()
+		}
 	}()
 
 	return id, nil
@@ -179,6 +186,16 @@ func (manager *Manager) List() map[ID]*Instance {
 	return manager.servicePool.List()
 }
 
+// InUse tells if any running service is provided by the given identity.
+func (manager *Manager) InUse(providerID identity.Identity) bool {
+	for _, instance := range manager.servicePool.List() {
+		if instance.Proposal().ProviderID == providerID.Address {
+			return true
+		}
+	}
+	return false
+}
+
 // Kill stops all services.
 func (manager *Manager) Kill() error {
 	return manager.servicePool.StopAll()
@@ -194,6 +211,66 @@ func (manager *Manager) Stop(id ID) error {
 	return nil
 }
 
+// HandleNetworkChangedEvent registers proposals of the running services again, so that
+// they are announced through the new network. Services and their sessions are kept running.
+func (manager *Manager) HandleNetworkChangedEvent(_ network.ChangedEvent) {
+	manager.announceLock.Lock()
+	defer manager.announceLock.Unlock()
+
+	for id, instance := range manager.runningInstances() {
+		if manager.reannounce(id, instance) {
+			log.Info("service ", id, " announced again after network change")
+		}
+	}
+}
+
+func (manager *Manager) runningInstances() map[ID]*Instance {
+	manager.servicePool.Lock()
+	defer manager.servicePool.Unlock()
+
+	instances := make(map[ID]*Instance, len(manager.servicePool.instances))
+	for id, instance := range manager.servicePool.instances {
+		instances[id] = instance
+	}
+	return instances
+}
+
+// reannounce replaces the discovery of the instance, the new one is started only after the previous
+// one unregistered the proposal. Returns false if the instance was stopped meanwhile.
+func (manager *Manager) reannounce(id ID, instance *Instance) bool {
+	manager.servicePool.Lock()
+	if _, ok := manager.servicePool.instances[id]; !ok {
+		manager.servicePool.Unlock()
+		return false
+	}
+	previous := instance.discovery
+	instance.discovery = nil
+	if previous != nil {
+		previous.Stop()
+	}
+	manager.servicePool.Unlock()
+
+	if previous != nil {
+		previous.Wait()
+	}
+
+	manager.servicePool.Lock()
+	defer manager.servicePool.Unlock()
+	if _, ok := manager.servicePool.instances[id]; !ok {
+		return false
+	}
+	proposal := instance.Proposal()
+	instance.discovery = manager.discoveryFactory()
+	instance.discovery.Start(identity.FromAddress(proposal.ProviderID), proposal)
+	return true
+}
+
+func (manager *Manager) instanceDiscovery(instance *Instance) Discovery {
+	manager.servicePool.Lock()
+	defer manager.servicePool.Unlock()
+	return instance.discovery
+}
+
 // Service returns a service instance by requested id.
 func (manager *Manager) Service(id ID) *Instance {
 	return manager.servicePool.Instance(id)
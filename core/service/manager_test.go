@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/core/network"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
@@ -84,6 +85,38 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 	assert.Len(t, manager.servicePool.List(), 0)
 }
 
+func TestManager_InUse(t *testing.T) {
+	registry := NewRegistry()
+	mockCopy := *serviceMock
+	mockCopy.mockProcess = make(chan struct{})
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return &mockCopy, proposalMock, nil
+	})
+
+	discovery := mockDiscovery{}
+	discoveryFactory := MockDiscoveryFactoryFunc(&discovery)
+	manager := NewManager(
+		registry,
+		MockDialogWaiterFactory,
+		MockDialogHandlerFactory,
+		discoveryFactory,
+		&mockPublisher{},
+		bindAllAddress,
+	)
+	providerID := identity.FromAddress(proposalMock.ProviderID)
+	assert.False(t, manager.InUse(providerID))
+
+	id, err := manager.Start(providerID, serviceType, nil, struct{}{})
+	assert.Nil(t, err)
+	assert.True(t, manager.InUse(providerID))
+	assert.False(t, manager.InUse(identity.FromAddress("other")))
+
+	err = manager.Stop(id)
+	assert.Nil(t, err)
+	discovery.Wait()
+	assert.False(t, manager.InUse(providerID))
+}
+
 func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 	registry := NewRegistry()
 	mockCopy := *serviceMock
@@ -138,3 +171,41 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 	}
 	assert.True(t, matchFound)
 }
+
+func TestManager_HandleNetworkChangedEventAnnouncesRunningServicesAgain(t *testing.T) {
+	registry := NewRegistry()
+	var createdWith []Options
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		createdWith = append(createdWith, options)
+		return &serviceFake{mockProcess: make(chan struct{})}, market.ServiceProposal{ServiceType: serviceType}, nil
+	})
+
+	discovery := mockDiscovery{}
+	var discoveriesCreated int
+	discoveryFactory := func() Discovery {
+		discoveriesCreated++
+		return &discovery
+	}
+	manager := NewManager(
+		registry,
+		MockDialogWaiterFactory,
+		MockDialogHandlerFactory,
+		discoveryFactory,
+		&mockPublisher{},
+		bindAllAddress,
+	)
+	providerID := identity.FromAddress("0x1")
+	id, err := manager.Start(providerID, serviceType, nil, "service options")
+	assert.NoError(t, err)
+
+	manager.HandleNetworkChangedEvent(network.ChangedEvent{})
+
+	instances := manager.List()
+	assert.Len(t, instances, 1)
+	assert.NotNil(t, instances[id])
+	assert.Equal(t, []Options{"service options"}, createdWith)
+	assert.Equal(t, 2, discoveriesCreated)
+
+	assert.NoError(t, manager.Kill())
+	discovery.Wait()
+}
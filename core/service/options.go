@@ -25,3 +25,25 @@ type OptionsIdentity struct {
 
 // Options represents any type of options for pluggable service
 type Options interface{}
+
+// OptionsRateLimit describes the bandwidth limits of a service in bits per second, zero means unlimited
+type OptionsRateLimit struct {
+	// Session limits the bandwidth of every consumer session
+	Session uint64 `json:"sessionRateLimit,omitempty"`
+	// Service limits the bandwidth of all the sessions of the service together
+	Service uint64 `json:"serviceRateLimit,omitempty"`
+}
+
+// SessionRate returns the bandwidth a single session may use when the service is shared by the given count of sessions
+func (limit OptionsRateLimit) SessionRate(sessions int) uint64 {
+	rate := limit.Session
+	if limit.Service == 0 || sessions < 1 {
+		return rate
+	}
+
+	share := limit.Service / uint64(sessions)
+	if rate == 0 || share < rate {
+		return share
+	}
+	return rate
+}
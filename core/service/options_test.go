@@ -0,0 +1,33 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OptionsRateLimit_SessionRate(t *testing.T) {
+	assert.Equal(t, uint64(0), OptionsRateLimit{}.SessionRate(3))
+	assert.Equal(t, uint64(100), OptionsRateLimit{Session: 100}.SessionRate(3))
+	assert.Equal(t, uint64(200), OptionsRateLimit{Service: 600}.SessionRate(3))
+	assert.Equal(t, uint64(100), OptionsRateLimit{Session: 100, Service: 600}.SessionRate(3))
+	assert.Equal(t, uint64(150), OptionsRateLimit{Session: 300, Service: 600}.SessionRate(4))
+	assert.Equal(t, uint64(300), OptionsRateLimit{Session: 300, Service: 600}.SessionRate(0))
+}
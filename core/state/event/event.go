@@ -38,6 +38,10 @@ type State struct {
 type NATStatus struct {
 	Status string `json:"status"`
 	Error  string `json:"error"`
+	// stage which made NAT traversal successful, e.g. the port mapping method ("port_mapping_upnp"/"port_mapping_natpmp"/"port_mapping_pcp")
+	Stage string `json:"stage,omitempty"`
+	// NAT type detected using STUN ("unknown"/"none"/"full_cone"/"restricted"/"port_restricted"/"symmetric")
+	Type string `json:"type"`
 }
 
 // ConnectionStatistics shows the successful and attempted connection count
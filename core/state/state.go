@@ -26,6 +26,7 @@ import (
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/nat"
 	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/session"
 	sevent "github.com/mysteriumnetwork/node/session/event"
 )
@@ -73,6 +74,7 @@ func NewKeeper(natStatusProvider natStatusProvider, publisher publisher, service
 		state: &stateEvent.State{
 			NATStatus: stateEvent.NATStatus{
 				Status: "not_finished",
+				Type:   string(traversal.NATTypeUnknown),
 			},
 		},
 		natStatusProvider:     natStatusProvider,
@@ -182,7 +184,7 @@ func (k *Keeper) updateNatStatus(e interface{}) {
 
 	k.natStatusProvider.ConsumeNATEvent(event)
 	status := k.natStatusProvider.Status()
-	k.state.NATStatus = stateEvent.NATStatus{Status: status.Status}
+	k.state.NATStatus = stateEvent.NATStatus{Status: status.Status, Stage: status.Stage, Type: k.state.NATStatus.Type}
 	if status.Error != nil {
 		k.state.NATStatus.Error = status.Error.Error()
 	}
@@ -190,6 +192,16 @@ func (k *Keeper) updateNatStatus(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
+// ConsumeNATTypeEvent consumes the detected NAT type
+func (k *Keeper) ConsumeNATTypeEvent(e traversal.NATTypeEvent) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.state.NATStatus.Type = string(e.Type)
+
+	go k.announceStateChanges(nil)
+}
+
 func (k *Keeper) updateSessionState(e interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
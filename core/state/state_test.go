@@ -27,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/nat"
 	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/stretchr/testify/assert"
@@ -165,6 +166,44 @@ func Test_ConsumesNATEvents(t *testing.T) {
 	assert.Equal(t, natProvider.statusToReturn.Status, keeper.GetState().NATStatus.Status)
 }
 
+func Test_ConsumesNATTypeEvents(t *testing.T) {
+	natProvider := &natStatusProviderMock{
+		statusToReturn: mockNATStatus,
+	}
+	publisher := &mockPublisher{}
+	sl := &serviceListerMock{}
+	sessionStorage := &serviceSessionStorageMock{}
+
+	duration := time.Millisecond * 3
+	keeper := NewKeeper(natProvider, publisher, sl, sessionStorage, duration)
+	assert.Equal(t, "unknown", keeper.GetState().NATStatus.Type)
+
+	keeper.ConsumeNATTypeEvent(traversal.NATTypeEvent{Type: traversal.NATTypeSymmetric})
+	assert.Equal(t, "symmetric", keeper.GetState().NATStatus.Type)
+
+	keeper.ConsumeNATEvent(natEvent.Event{Stage: "booster separation", Successful: true})
+	time.Sleep(duration * 3)
+	assert.Equal(t, mockNATStatus.Status, keeper.GetState().NATStatus.Status)
+	assert.Equal(t, "symmetric", keeper.GetState().NATStatus.Type)
+}
+
+func Test_ConsumesNATEventsKeepingSuccessfulStage(t *testing.T) {
+	natProvider := &natStatusProviderMock{
+		statusToReturn: nat.Status{Status: "successful", Stage: "port_mapping_pcp"},
+	}
+	publisher := &mockPublisher{}
+	sl := &serviceListerMock{}
+	sessionStorage := &serviceSessionStorageMock{}
+
+	duration := time.Millisecond * 3
+	keeper := NewKeeper(natProvider, publisher, sl, sessionStorage, duration)
+
+	keeper.ConsumeNATEvent(natEvent.Event{Stage: "port_mapping_pcp", Successful: true})
+	time.Sleep(duration * 3)
+	assert.Equal(t, "successful", keeper.GetState().NATStatus.Status)
+	assert.Equal(t, "port_mapping_pcp", keeper.GetState().NATStatus.Stage)
+}
+
 func Test_ConsumesSessionEvents(t *testing.T) {
 	expected := session.Session{}
 
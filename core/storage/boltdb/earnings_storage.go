@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/mysteriumnetwork/node/core/earnings"
+)
+
+const earningsBucket = "earnings-ledger"
+
+// EarningsStorage keeps the earnings ledger entries in boltdb
+type EarningsStorage struct {
+	db *Bolt
+}
+
+// NewEarningsStorage creates a new earnings ledger storage on top of the given database
+func NewEarningsStorage(db *Bolt) *EarningsStorage {
+	return &EarningsStorage{db: db}
+}
+
+// Save appends the entry to the ledger
+func (storage *EarningsStorage) Save(entry earnings.Entry) error {
+	return storage.db.Store(earningsBucket, &entry)
+}
+
+// List returns the entries of the provider recorded in [from, to), oldest first, zero times leave the range open
+func (storage *EarningsStorage) List(providerID string, from, to time.Time) ([]earnings.Entry, error) {
+	matchers := []q.Matcher{q.Eq("ProviderID", providerID)}
+	if !from.IsZero() {
+		matchers = append(matchers, q.Gte("RecordedAt", from))
+	}
+	if !to.IsZero() {
+		matchers = append(matchers, q.Lt("RecordedAt", to))
+	}
+
+	entries := []earnings.Entry{}
+	err := storage.db.Select(earningsBucket, matchers...).OrderBy("RecordedAt").Find(&entries)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return entries, nil
+}
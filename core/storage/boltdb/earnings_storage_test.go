@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/earnings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EarningsStorageListsEntriesInRange(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	storage := NewEarningsStorage(db)
+	entries, err := storage.List("0x1", time.Time{}, time.Time{})
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+
+	first := earnings.Entry{ProviderID: "0x1", ConsumerID: "0xa", SessionID: "s1", Amount: 10, RecordedAt: time.Unix(10, 0).UTC()}
+	second := earnings.Entry{ProviderID: "0x1", ConsumerID: "0xa", SessionID: "s1", Amount: 20, RecordedAt: time.Unix(20, 0).UTC()}
+	other := earnings.Entry{ProviderID: "0x2", ConsumerID: "0xa", SessionID: "s2", Amount: 30, RecordedAt: time.Unix(15, 0).UTC()}
+	assert.Nil(t, storage.Save(second))
+	assert.Nil(t, storage.Save(other))
+	assert.Nil(t, storage.Save(first))
+
+	entries, err = storage.List("0x1", time.Time{}, time.Time{})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, uint64(10), entries[0].Amount)
+	assert.Equal(t, uint64(20), entries[1].Amount)
+
+	entries, err = storage.List("0x1", time.Unix(10, 0), time.Unix(20, 0))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, uint64(10), entries[0].Amount)
+}
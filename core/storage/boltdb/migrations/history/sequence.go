@@ -31,4 +31,10 @@ var Sequence = []migrations.Migration{
 			2018, 12, 04, 12, 00, 00, 0, time.UTC),
 		Migrate: migrations.MigrateSessionToHistory,
 	},
+	{
+		Name: "service-session-history",
+		Date: time.Date(
+			2019, 10, 01, 12, 00, 00, 0, time.UTC),
+		Migrate: migrations.CreateServiceSessionHistory,
+	},
 }
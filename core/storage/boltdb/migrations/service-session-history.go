@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+)
+
+const serviceSessionHistoryBucket = "service-session-history"
+
+// ServiceSession holds the initial structure of the provider session history
+type ServiceSession struct {
+	ID             string    `storm:"id"`
+	ConsumerID     string    `storm:"index"`
+	ServiceID      string    `storm:"index"`
+	Started        time.Time `storm:"index"`
+	Updated        time.Time
+	Ended          time.Time
+	DataTransfered struct {
+		Up, Down int64
+	}
+	Earned uint64
+}
+
+// CreateServiceSessionHistory creates the provider session history bucket together with its indexes
+func CreateServiceSessionHistory(db *storm.DB) error {
+	return db.From(serviceSessionHistoryBucket).Init(&ServiceSession{})
+}
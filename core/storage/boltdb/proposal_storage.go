@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"github.com/asdine/storm"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	proposalBucket      = "proposals"
+	proposalSnapshotKey = "snapshot"
+)
+
+// ProposalSnapshotStorage keeps the last known proposal list in boltdb, so discovery has something to show before going online
+type ProposalSnapshotStorage struct {
+	db *Bolt
+}
+
+// NewProposalSnapshotStorage creates a new proposal snapshot storage on top of the given database
+func NewProposalSnapshotStorage(db *Bolt) *ProposalSnapshotStorage {
+	return &ProposalSnapshotStorage{db: db}
+}
+
+// Load returns the stored proposals, empty list if nothing was stored yet
+func (storage *ProposalSnapshotStorage) Load() ([]market.ServiceProposal, error) {
+	proposals := []market.ServiceProposal{}
+	err := storage.db.GetValue(proposalBucket, proposalSnapshotKey, &proposals)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// Save replaces the stored proposals with the given ones
+func (storage *ProposalSnapshotStorage) Save(proposals []market.ServiceProposal) error {
+	return storage.db.SetValue(proposalBucket, proposalSnapshotKey, proposals)
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ProposalSnapshotStorageKeepsProposals(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	storage := NewProposalSnapshotStorage(db)
+	proposals, err := storage.Load()
+	assert.Nil(t, err)
+	assert.Empty(t, proposals)
+
+	first := market.ServiceProposal{ProviderID: "0x1", ServiceType: "openvpn"}
+	second := market.ServiceProposal{ProviderID: "0x2", ServiceType: "wireguard"}
+	assert.Nil(t, storage.Save([]market.ServiceProposal{first, second}))
+	assert.Nil(t, storage.Save([]market.ServiceProposal{second}))
+
+	proposals, err = storage.Load()
+	assert.Nil(t, err)
+	assert.Len(t, proposals, 1)
+	assert.Equal(t, second.UniqueID(), proposals[0].UniqueID())
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+const sessionStorageLogPrefix = "[service-session-storage] "
+const serviceSessionHistoryBucket = "service-session-history"
+
+// SessionStorage keeps the ongoing provider sessions in memory and records the history of every session into boltdb,
+// so it survives node restarts
+type SessionStorage struct {
+	*session.StorageMemory
+	db *Bolt
+}
+
+// NewSessionStorage creates a new session storage on top of the given database.
+// Sessions left ongoing by the previous run of the node are marked as ended at their last update.
+func NewSessionStorage(db *Bolt) (*SessionStorage, error) {
+	storage := &SessionStorage{
+		StorageMemory: session.NewStorageMemory(),
+		db:            db,
+	}
+	return storage, storage.endDanglingSessions()
+}
+
+// Add puts the given session to storage and records it into the history
+func (storage *SessionStorage) Add(sessionInstance session.Session) {
+	storage.StorageMemory.Add(sessionInstance)
+
+	history := session.NewHistory(sessionInstance)
+	if err := storage.db.Store(serviceSessionHistoryBucket, &history); err != nil {
+		log.Error(sessionStorageLogPrefix, "failed to save session history: ", err)
+	}
+}
+
+// UpdateDataTransfer updates the data transfer info of the session and its history
+func (storage *SessionStorage) UpdateDataTransfer(id session.ID, up, down int64) {
+	storage.StorageMemory.UpdateDataTransfer(id, up, down)
+
+	storage.update(&session.History{
+		ID:             id,
+		Updated:        time.Now().UTC(),
+		DataTransfered: session.DataTransfered{Up: up, Down: down},
+	})
+}
+
+// Remove removes the given session from the ongoing ones and marks its history as ended
+func (storage *SessionStorage) Remove(id session.ID) {
+	storage.StorageMemory.Remove(id)
+
+	now := time.Now().UTC()
+	storage.update(&session.History{
+		ID:      id,
+		Updated: now,
+		Ended:   now,
+	})
+}
+
+// RemoveForService removes all sessions which belong to given service
+func (storage *SessionStorage) RemoveForService(serviceID string) {
+	for _, sessionInstance := range storage.GetAll() {
+		if sessionInstance.ServiceID == serviceID {
+			storage.Remove(sessionInstance.ID)
+		}
+	}
+}
+
+// ConsumeEarningsEvent records the amount earned during the session
+func (storage *SessionStorage) ConsumeEarningsEvent(e event.EarningsEventPayload) {
+	storage.update(&session.History{
+		ID:     session.ID(e.ID),
+		Earned: e.Amount,
+	})
+}
+
+// Query returns the session history matching the given query, newest sessions first,
+// together with the total count of matching sessions
+func (storage *SessionStorage) Query(query session.HistoryQuery) ([]session.History, int, error) {
+	var matchers []q.Matcher
+	if query.ConsumerID != "" {
+		matchers = append(matchers, q.Eq("ConsumerID", query.ConsumerID))
+	}
+	if query.ServiceID != "" {
+		matchers = append(matchers, q.Eq("ServiceID", query.ServiceID))
+	}
+	if !query.StartedFrom.IsZero() {
+		matchers = append(matchers, q.Gte("Started", query.StartedFrom))
+	}
+	if !query.StartedTo.IsZero() {
+		matchers = append(matchers, q.Lt("Started", query.StartedTo))
+	}
+
+	total, err := storage.db.Select(serviceSessionHistoryBucket, matchers...).Count(&session.History{})
+	if err != nil && err != storm.ErrNotFound {
+		return nil, 0, err
+	}
+
+	selection := storage.db.Select(serviceSessionHistoryBucket, matchers...).OrderBy("Started").Reverse().Skip(query.Offset)
+	if query.Limit > 0 {
+		selection = selection.Limit(query.Limit)
+	}
+
+	sessions := []session.History{}
+	err = selection.Find(&sessions)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+func (storage *SessionStorage) update(history *session.History) {
+	if err := storage.db.Update(serviceSessionHistoryBucket, history); err != nil {
+		log.Error(sessionStorageLogPrefix, "failed to update history of session ", history.ID, ": ", err)
+	}
+}
+
+func (storage *SessionStorage) endDanglingSessions() error {
+	var dangling []session.History
+	err := storage.db.Select(serviceSessionHistoryBucket, q.Eq("Ended", time.Time{})).Find(&dangling)
+	if err == storm.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for i := range dangling {
+		err := storage.db.Update(serviceSessionHistoryBucket, &session.History{
+			ID:    dangling[i].ID,
+			Ended: dangling[i].Updated,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+var sessionStarted = time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestSession(id, consumer, service string, started time.Time) session.Session {
+	return session.Session{
+		ID:         session.ID(id),
+		ConsumerID: identity.FromAddress(consumer),
+		ServiceID:  service,
+		CreatedAt:  started,
+	}
+}
+
+func Test_SessionStorageRecordsHistory(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	storage, err := NewSessionStorage(db)
+	assert.Nil(t, err)
+
+	storage.Add(newTestSession("session-1", "consumer-1", "service-1", sessionStarted))
+	storage.UpdateDataTransfer("session-1", 10, 20)
+	storage.ConsumeEarningsEvent(event.EarningsEventPayload{ID: "session-1", Amount: 5})
+	storage.Remove("session-1")
+
+	_, found := storage.Find("session-1")
+	assert.False(t, found)
+
+	sessions, total, err := storage.Query(session.HistoryQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "consumer-1", sessions[0].ConsumerID)
+	assert.Equal(t, "service-1", sessions[0].ServiceID)
+	assert.True(t, sessionStarted.Equal(sessions[0].Started))
+	assert.False(t, sessions[0].Ended.IsZero())
+	assert.Equal(t, session.DataTransfered{Up: 10, Down: 20}, sessions[0].DataTransfered)
+	assert.Equal(t, uint64(5), sessions[0].Earned)
+}
+
+func Test_SessionStorageQueriesFilteredPages(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	storage, err := NewSessionStorage(db)
+	assert.Nil(t, err)
+
+	storage.Add(newTestSession("session-1", "consumer-1", "service-1", sessionStarted))
+	storage.Add(newTestSession("session-2", "consumer-2", "service-1", sessionStarted.Add(time.Hour)))
+	storage.Add(newTestSession("session-3", "consumer-1", "service-1", sessionStarted.Add(2*time.Hour)))
+	storage.Add(newTestSession("session-4", "consumer-1", "service-2", sessionStarted.Add(3*time.Hour)))
+
+	sessions, total, err := storage.Query(session.HistoryQuery{ConsumerID: "consumer-1", ServiceID: "service-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []session.ID{"session-3", "session-1"}, historyIDs(sessions))
+
+	sessions, total, err = storage.Query(session.HistoryQuery{Offset: 1, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []session.ID{"session-3", "session-2"}, historyIDs(sessions))
+
+	sessions, total, err = storage.Query(session.HistoryQuery{
+		StartedFrom: sessionStarted.Add(time.Hour),
+		StartedTo:   sessionStarted.Add(3 * time.Hour),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []session.ID{"session-3", "session-2"}, historyIDs(sessions))
+}
+
+func Test_SessionStorageEndsDanglingSessionsOnStart(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	storage, err := NewSessionStorage(db)
+	assert.Nil(t, err)
+	storage.Add(newTestSession("session-1", "consumer-1", "service-1", sessionStarted))
+
+	storage, err = NewSessionStorage(db)
+	assert.Nil(t, err)
+
+	assert.Empty(t, storage.GetAll())
+	sessions, _, err := storage.Query(session.HistoryQuery{})
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+	assert.True(t, sessionStarted.Equal(sessions[0].Ended))
+}
+
+func historyIDs(sessions []session.History) []session.ID {
+	ids := make([]session.ID, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+	}
+	return ids
+}
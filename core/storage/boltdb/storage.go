@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 	"github.com/pkg/errors"
 )
 
@@ -75,6 +76,11 @@ func (b *Bolt) GetOneByField(bucket string, fieldName string, key interface{}, t
 	return b.db.From(bucket).One(fieldName, key, to)
 }
 
+// Select returns the query of the structs in the given bucket matching all the given matchers
+func (b *Bolt) Select(bucket string, matchers ...q.Matcher) storm.Query {
+	return b.db.From(bucket).Select(matchers...)
+}
+
 // GetLast returns the last entry in the bucket
 func (b *Bolt) GetLast(bucket string, to interface{}) error {
 	return b.db.From(bucket).Select().Reverse().First(to)
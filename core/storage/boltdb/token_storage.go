@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"github.com/asdine/storm"
+	"github.com/mysteriumnetwork/node/core/auth"
+)
+
+const tokenBucket = "auth-tokens"
+
+// TokenStorage keeps the API tokens in boltdb
+type TokenStorage struct {
+	db *Bolt
+}
+
+// NewTokenStorage creates a new API token storage on top of the given database
+func NewTokenStorage(db *Bolt) *TokenStorage {
+	return &TokenStorage{db: db}
+}
+
+// List returns all the stored tokens, oldest first
+func (storage *TokenStorage) List() ([]auth.APIToken, error) {
+	tokens := []auth.APIToken{}
+	err := storage.db.Select(tokenBucket).OrderBy("CreatedAt").Find(&tokens)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetByHash returns the token having the given secret hash
+func (storage *TokenStorage) GetByHash(hash string) (auth.APIToken, error) {
+	var token auth.APIToken
+	err := storage.db.GetOneByField(tokenBucket, "Hash", hash, &token)
+	if err == storm.ErrNotFound {
+		return token, auth.ErrTokenNotFound
+	}
+	return token, err
+}
+
+// Save stores the given token, the token having the same id is replaced
+func (storage *TokenStorage) Save(token auth.APIToken) error {
+	return storage.db.Store(tokenBucket, &token)
+}
+
+// Delete removes the token with the given id
+func (storage *TokenStorage) Delete(id string) error {
+	var token auth.APIToken
+	err := storage.db.GetOneByField(tokenBucket, "ID", id, &token)
+	if err == storm.ErrNotFound {
+		return auth.ErrTokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return storage.db.Delete(tokenBucket, &token)
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TokenStorageKeepsTokens(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	storage := NewTokenStorage(db)
+	tokens, err := storage.List()
+	assert.Nil(t, err)
+	assert.Empty(t, tokens)
+
+	first := auth.APIToken{ID: "token-1", Name: "dashboard", Scopes: auth.Scopes{auth.ScopeStatus}, Hash: "hash-1", CreatedAt: time.Unix(1, 0).UTC()}
+	second := auth.APIToken{ID: "token-2", Name: "admin", Scopes: auth.AllScopes(), Hash: "hash-2", CreatedAt: time.Unix(2, 0).UTC()}
+	assert.Nil(t, storage.Save(second))
+	assert.Nil(t, storage.Save(first))
+
+	tokens, err = storage.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []auth.APIToken{first, second}, tokens)
+
+	token, err := storage.GetByHash("hash-2")
+	assert.Nil(t, err)
+	assert.Equal(t, second, token)
+
+	assert.Nil(t, storage.Delete("token-2"))
+	_, err = storage.GetByHash("hash-2")
+	assert.Equal(t, auth.ErrTokenNotFound, err)
+	assert.Equal(t, auth.ErrTokenNotFound, storage.Delete("token-2"))
+}
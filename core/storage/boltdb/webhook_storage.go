@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"github.com/asdine/storm"
+	"github.com/mysteriumnetwork/node/core/webhook"
+)
+
+const webhookBucket = "webhooks"
+
+// WebhookStorage keeps the webhooks in boltdb
+type WebhookStorage struct {
+	db *Bolt
+}
+
+// NewWebhookStorage creates a new webhook storage on top of the given database
+func NewWebhookStorage(db *Bolt) *WebhookStorage {
+	return &WebhookStorage{db: db}
+}
+
+// List returns all the stored webhooks, oldest first
+func (storage *WebhookStorage) List() ([]webhook.Webhook, error) {
+	hooks := []webhook.Webhook{}
+	err := storage.db.Select(webhookBucket).OrderBy("CreatedAt").Find(&hooks)
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// Get returns the webhook with the given id
+func (storage *WebhookStorage) Get(id string) (webhook.Webhook, error) {
+	var hook webhook.Webhook
+	err := storage.db.GetOneByField(webhookBucket, "ID", id, &hook)
+	if err == storm.ErrNotFound {
+		return hook, webhook.ErrNotFound
+	}
+	return hook, err
+}
+
+// Save stores the given webhook, the webhook having the same id is replaced
+func (storage *WebhookStorage) Save(hook webhook.Webhook) error {
+	return storage.db.Store(webhookBucket, &hook)
+}
+
+// Delete removes the webhook with the given id
+func (storage *WebhookStorage) Delete(id string) error {
+	hook, err := storage.Get(id)
+	if err != nil {
+		return err
+	}
+	return storage.db.Delete(webhookBucket, &hook)
+}
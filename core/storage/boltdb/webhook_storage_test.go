@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WebhookStorageKeepsWebhooks(t *testing.T) {
+	db, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	storage := NewWebhookStorage(db)
+	hooks, err := storage.List()
+	assert.Nil(t, err)
+	assert.Empty(t, hooks)
+
+	first := webhook.Webhook{ID: "hook-1", URL: "http://first", Secret: "s1", CreatedAt: time.Unix(1, 0).UTC()}
+	second := webhook.Webhook{ID: "hook-2", URL: "http://second", Topics: []string{"nat"}, CreatedAt: time.Unix(2, 0).UTC()}
+	assert.Nil(t, storage.Save(second))
+	assert.Nil(t, storage.Save(first))
+
+	hooks, err = storage.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []webhook.Webhook{first, second}, hooks)
+
+	first.URL = "http://updated"
+	assert.Nil(t, storage.Save(first))
+	hook, err := storage.Get("hook-1")
+	assert.Nil(t, err)
+	assert.Equal(t, first, hook)
+
+	assert.Nil(t, storage.Delete("hook-1"))
+	_, err = storage.Get("hook-1")
+	assert.Equal(t, webhook.ErrNotFound, err)
+	assert.Equal(t, webhook.ErrNotFound, storage.Delete("hook-1"))
+}
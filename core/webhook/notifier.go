@@ -0,0 +1,215 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 signature of the notification body made with webhook secret
+	SignatureHeader = "X-Mysterium-Signature"
+	// TopicHeader carries the topic name of the notification
+	TopicHeader = "X-Mysterium-Topic"
+
+	defaultMaxAttempts = 5
+	defaultRetryDelay  = time.Second
+	maxRetryDelay      = time.Minute
+	requestTimeout     = 10 * time.Second
+)
+
+// Notification is the JSON body posted to the webhook URL
+type Notification struct {
+	ID        string      `json:"id"`
+	Topic     string      `json:"topic"`
+	Timestamp time.Time   `json:"timestamp"`
+	Payload   interface{} `json:"payload"`
+}
+
+// EventSubscriber allows subscribing to the event bus topics
+type EventSubscriber interface {
+	SubscribeAsync(topic string, fn interface{}) error
+}
+
+// Notifier posts the events to the stored webhooks, failed deliveries are retried with exponential backoff
+type Notifier struct {
+	storage     Storage
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+	stop        chan struct{}
+
+	lowBalance   uint64
+	notifiedLock sync.Mutex
+	notified     map[string]bool
+}
+
+// NewNotifier creates the notifier of the webhooks kept in the given storage,
+// low balance is notified once the balance of node identity falls below the given threshold, zero disables it
+func NewNotifier(storage Storage, lowBalance uint64) *Notifier {
+	return &Notifier{
+		storage:     storage,
+		client:      &http.Client{Timeout: requestTimeout},
+		maxAttempts: defaultMaxAttempts,
+		retryDelay:  defaultRetryDelay,
+		stop:        make(chan struct{}),
+		lowBalance:  lowBalance,
+		notified:    make(map[string]bool),
+	}
+}
+
+// Subscribe subscribes the notifier to the event bus topics of every webhook topic
+func (n *Notifier) Subscribe(bus EventSubscriber) error {
+	for _, topic := range Topics() {
+		if topic == TopicLowBalance {
+			continue
+		}
+		topic := topic
+		err := bus.SubscribeAsync(eventTopics[topic], func(data interface{}) {
+			n.Notify(topic, data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return bus.SubscribeAsync(eventTopics[TopicLowBalance], n.ConsumeBalanceEvent)
+}
+
+// ConsumeBalanceEvent notifies the low balance once the balance of node identity falls below the threshold,
+// it is notified again only after the balance rises back to the threshold and falls once more
+func (n *Notifier) ConsumeBalanceEvent(e identity.BalanceEvent) {
+	if n.lowBalance == 0 {
+		return
+	}
+
+	low := e.Balance < n.lowBalance
+	n.notifiedLock.Lock()
+	notified := n.notified[e.Identity.Address]
+	n.notified[e.Identity.Address] = low
+	n.notifiedLock.Unlock()
+
+	if !low || notified {
+		return
+	}
+	n.Notify(TopicLowBalance, lowBalancePayload{
+		Identity:  e.Identity.Address,
+		Balance:   e.Balance,
+		Threshold: n.lowBalance,
+	})
+}
+
+// Notify posts the event to every webhook subscribed to the given topic
+func (n *Notifier) Notify(topic string, data interface{}) {
+	hooks, err := n.storage.List()
+	if err != nil {
+		log.Error("failed to list webhooks: ", err)
+		return
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		log.Error("failed to generate notification id: ", err)
+		return
+	}
+	body, err := json.Marshal(Notification{
+		ID:        id.String(),
+		Topic:     topic,
+		Timestamp: time.Now().UTC(),
+		Payload:   toPayload(data),
+	})
+	if err != nil {
+		log.Error("failed to serialize ", topic, " notification: ", err)
+		return
+	}
+
+	for _, hook := range hooks {
+		if hook.Subscribed(topic) {
+			go n.deliver(hook, topic, body)
+		}
+	}
+}
+
+// Stop cancels the retries of failed deliveries
+func (n *Notifier) Stop() {
+	close(n.stop)
+}
+
+func (n *Notifier) deliver(hook Webhook, topic string, body []byte) {
+	delay := n.retryDelay
+	for attempt := 1; ; attempt++ {
+		err := n.post(hook, topic, body)
+		if err == nil {
+			return
+		}
+		if attempt >= n.maxAttempts {
+			log.Errorf("giving up %s notification of webhook %s after %d attempts: %v", topic, hook.ID, attempt, err)
+			return
+		}
+		log.Warnf("%s notification of webhook %s failed, retrying in %s: %v", topic, hook.ID, delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-n.stop:
+			return
+		}
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+func (n *Notifier) post(hook Webhook, topic string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TopicHeader, topic)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the body, receivers verify notifications with it
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
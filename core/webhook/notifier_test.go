@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStorage struct {
+	hooks []Webhook
+}
+
+func (ms *mockStorage) List() ([]Webhook, error) {
+	return ms.hooks, nil
+}
+
+func (ms *mockStorage) Get(id string) (Webhook, error) {
+	return Webhook{}, ErrNotFound
+}
+
+func (ms *mockStorage) Save(hook Webhook) error {
+	return nil
+}
+
+func (ms *mockStorage) Delete(id string) error {
+	return nil
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(failures int) (*httptest.Server, chan receivedRequest) {
+	received := make(chan receivedRequest, 10)
+	var lock sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- receivedRequest{header: r.Header, body: body}
+
+		lock.Lock()
+		defer lock.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	return server, received
+}
+
+func receive(t *testing.T, received chan receivedRequest) receivedRequest {
+	select {
+	case req := <-received:
+		return req
+	case <-time.After(time.Second):
+		t.Fatal("notification was not received")
+		return receivedRequest{}
+	}
+}
+
+func TestNotifierPostsSignedNotification(t *testing.T) {
+	server, received := newReceiver(0)
+	defer server.Close()
+
+	notifier := NewNotifier(&mockStorage{hooks: []Webhook{
+		{ID: "hook-1", URL: server.URL, Secret: "secret", Topics: []string{TopicNAT}},
+		{ID: "hook-2", URL: server.URL, Secret: "secret", Topics: []string{TopicProposal}},
+	}}, 0)
+	notifier.Notify(TopicNAT, natEvent.BuildFailureEvent("hole_punching", errors.New("timeout")))
+
+	req := receive(t, received)
+	assert.Equal(t, TopicNAT, req.header.Get(TopicHeader))
+	assert.Equal(t, Sign("secret", req.body), req.header.Get(SignatureHeader))
+
+	var notification struct {
+		Topic   string          `json:"topic"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	assert.NoError(t, json.Unmarshal(req.body, &notification))
+	assert.Equal(t, TopicNAT, notification.Topic)
+	assert.JSONEq(t, `{"stage": "hole_punching", "successful": false, "error": "timeout"}`, string(notification.Payload))
+
+	select {
+	case <-received:
+		t.Fatal("webhook which is not subscribed to the topic was notified")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifierRetriesFailedDelivery(t *testing.T) {
+	server, received := newReceiver(2)
+	defer server.Close()
+
+	notifier := NewNotifier(&mockStorage{hooks: []Webhook{{ID: "hook-1", URL: server.URL}}}, 0)
+	notifier.retryDelay = time.Millisecond
+	notifier.Notify(TopicSession, "payload")
+
+	first := receive(t, received)
+	receive(t, received)
+	last := receive(t, received)
+	assert.Equal(t, first.body, last.body)
+}
+
+func TestNotifierGivesUpAfterMaxAttempts(t *testing.T) {
+	server, received := newReceiver(10)
+	defer server.Close()
+
+	notifier := NewNotifier(&mockStorage{hooks: []Webhook{{ID: "hook-1", URL: server.URL}}}, 0)
+	notifier.retryDelay = time.Millisecond
+	notifier.maxAttempts = 2
+	notifier.Notify(TopicSession, "payload")
+
+	receive(t, received)
+	receive(t, received)
+	select {
+	case <-received:
+		t.Fatal("notification was retried after max attempts")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifierNotifiesLowBalanceOnceItIsCrossed(t *testing.T) {
+	server, received := newReceiver(0)
+	defer server.Close()
+
+	notifier := NewNotifier(&mockStorage{hooks: []Webhook{{ID: "hook-1", URL: server.URL, Topics: []string{TopicLowBalance}}}}, 100)
+	balance := func(address string, balance uint64) {
+		notifier.ConsumeBalanceEvent(identity.BalanceEvent{Identity: identity.FromAddress(address), Balance: balance})
+	}
+	notReceived := func() {
+		select {
+		case <-received:
+			t.Fatal("low balance was notified without crossing the threshold")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	balance("0x1", 150)
+	notReceived()
+
+	balance("0x1", 90)
+	req := receive(t, received)
+	var notification struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	assert.NoError(t, json.Unmarshal(req.body, &notification))
+	assert.JSONEq(t, `{"identity": "0x1", "balance": 90, "threshold": 100}`, string(notification.Payload))
+
+	balance("0x1", 80)
+	notReceived()
+
+	balance("0x2", 50)
+	receive(t, received)
+	balance("0x1", 60)
+	notReceived()
+
+	balance("0x1", 120)
+	balance("0x1", 70)
+	receive(t, received)
+}
+
+func TestWebhookSubscribed(t *testing.T) {
+	assert.True(t, Webhook{}.Subscribed(TopicNAT))
+	assert.True(t, Webhook{Topics: []string{TopicNAT}}.Subscribed(TopicNAT))
+	assert.False(t, Webhook{Topics: []string{TopicProposal}}.Subscribed(TopicNAT))
+}
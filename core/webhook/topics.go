@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+)
+
+// Topic names webhooks can be subscribed to
+const (
+	// TopicServiceStatus is notified when the provider service is started or stopped
+	TopicServiceStatus = "service-status"
+	// TopicSession is notified when the session of provider service changes
+	TopicSession = "session"
+	// TopicLowBalance is notified once the balance of node identity falls below the configured threshold
+	TopicLowBalance = "low-balance"
+	// TopicNAT is notified about the NAT traversal successes and failures
+	TopicNAT = "nat"
+	// TopicProposal is notified when the service proposal is registered or unregistered
+	TopicProposal = "proposal"
+	// TopicConnectionSession is notified when the session of consumer connection changes
+	TopicConnectionSession = "connection-session"
+)
+
+// eventTopics maps the webhook topic names to the event bus topics,
+// sessions of every consumer connection are notified about, not only the ones of the default connection
+var eventTopics = map[string]string{
+	TopicServiceStatus:     service.StatusTopic,
+	TopicSession:           sessionEvent.Topic,
+	TopicLowBalance:        identity.BalanceEventTopic,
+	TopicNAT:               natEvent.Topic,
+	TopicProposal:          discovery.ProposalEventTopic,
+	TopicConnectionSession: connection.SessionEventTopic,
+}
+
+// Topics returns the names of all topics webhooks can be subscribed to
+func Topics() []string {
+	return []string{
+		TopicServiceStatus,
+		TopicSession,
+		TopicLowBalance,
+		TopicNAT,
+		TopicProposal,
+		TopicConnectionSession,
+	}
+}
+
+// IsTopic tells whether webhooks can be subscribed to the given topic
+func IsTopic(name string) bool {
+	_, ok := eventTopics[name]
+	return ok
+}
+
+type natPayload struct {
+	Stage      string `json:"stage"`
+	Successful bool   `json:"successful"`
+	Error      string `json:"error,omitempty"`
+}
+
+type lowBalancePayload struct {
+	Identity  string `json:"identity"`
+	Balance   uint64 `json:"balance"`
+	Threshold uint64 `json:"threshold"`
+}
+
+type connectionSessionPayload struct {
+	ConnectionID string `json:"connectionId"`
+	Status       string `json:"status"`
+	SessionID    string `json:"sessionId"`
+	ConsumerID   string `json:"consumerId"`
+	ProviderID   string `json:"providerId"`
+	ServiceType  string `json:"serviceType"`
+}
+
+// toPayload converts the event to the form which is serialized into notification,
+// events having errors or functions inside are not serializable as they are
+func toPayload(data interface{}) interface{} {
+	switch e := data.(type) {
+	case natEvent.Event:
+		payload := natPayload{Stage: e.Stage, Successful: e.Successful}
+		if e.Error != nil {
+			payload.Error = e.Error.Error()
+		}
+		return payload
+	case connection.SessionEvent:
+		return connectionSessionPayload{
+			ConnectionID: e.ConnectionID,
+			Status:       e.Status,
+			SessionID:    string(e.SessionInfo.SessionID),
+			ConsumerID:   e.SessionInfo.ConsumerID.Address,
+			ProviderID:   e.SessionInfo.Proposal.ProviderID,
+			ServiceType:  e.SessionInfo.Proposal.ServiceType,
+		}
+	default:
+		return data
+	}
+}
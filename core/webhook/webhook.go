@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound indicates that webhook with the given id does not exist
+var ErrNotFound = errors.New("webhook not found")
+
+// Webhook describes the URL which is notified about the node events
+type Webhook struct {
+	ID  string `storm:"id"`
+	URL string
+	// Secret is the key of HMAC-SHA256 signature sent with every notification
+	Secret string
+	// Topics are the names of event topics the webhook is notified about, all topics when empty
+	Topics    []string
+	CreatedAt time.Time
+}
+
+// New creates the webhook with generated id, the secret is generated when empty
+func New(url, secret string, topics []string) (Webhook, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return Webhook{}, errors.Wrap(err, "failed to generate webhook id")
+	}
+
+	if secret == "" {
+		secret, err = generateSecret()
+		if err != nil {
+			return Webhook{}, err
+		}
+	}
+
+	return Webhook{
+		ID:        id.String(),
+		URL:       url,
+		Secret:    secret,
+		Topics:    topics,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Subscribed tells whether the webhook is notified about the given topic
+func (hook Webhook) Subscribed(topic string) bool {
+	if len(hook.Topics) == 0 {
+		return true
+	}
+	for _, subscribed := range hook.Topics {
+		if subscribed == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// Storage keeps the webhooks
+type Storage interface {
+	List() ([]Webhook, error)
+	Get(id string) (Webhook, error)
+	Save(hook Webhook) error
+	Delete(id string) error
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "failed to generate webhook secret")
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// names listed by the default hosts files, which should never be blocked
+var hostsFileNames = map[string]struct{}{
+	"localhost":             {},
+	"localhost.localdomain": {},
+	"local":                 {},
+	"broadcasthost":         {},
+	"ip6-localhost":         {},
+	"ip6-loopback":          {},
+}
+
+// Blocklist keeps the domains, which are refused to be resolved together with all their subdomains
+type Blocklist struct {
+	domains map[string]struct{}
+}
+
+// NewBlocklist creates an empty blocklist
+func NewBlocklist() *Blocklist {
+	return &Blocklist{domains: make(map[string]struct{})}
+}
+
+// LoadBlocklist creates a blocklist of domains listed in the given files.
+// Files either list a domain per line or are in the hosts file format ("0.0.0.0 ads.example.com"), lines starting with # are ignored.
+func LoadBlocklist(paths ...string) (*Blocklist, error) {
+	blocklist := NewBlocklist()
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open DNS blocklist")
+		}
+		err = blocklist.Read(file)
+		file.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read DNS blocklist %s", path)
+		}
+	}
+	return blocklist, nil
+}
+
+// Read adds the domains listed by the given reader to the blocklist
+func (blocklist *Blocklist) Read(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+			fields = fields[1:]
+		}
+		for _, domain := range fields {
+			blocklist.Add(domain)
+		}
+	}
+	return scanner.Err()
+}
+
+// Add blocks the given domain together with its subdomains
+func (blocklist *Blocklist) Add(domain string) {
+	domain = normalizeDomain(domain)
+	if _, ok := hostsFileNames[domain]; ok || domain == "" {
+		return
+	}
+	blocklist.domains[domain] = struct{}{}
+}
+
+// Blocked checks if the given domain or any of its parent domains are blocked
+func (blocklist *Blocklist) Blocked(domain string) bool {
+	if blocklist == nil || len(blocklist.domains) == 0 {
+		return false
+	}
+
+	domain = normalizeDomain(domain)
+	for domain != "" {
+		if _, ok := blocklist.domains[domain]; ok {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+// Len returns the count of blocked domains
+func (blocklist *Blocklist) Len() int {
+	return len(blocklist.domains)
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
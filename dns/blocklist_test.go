@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BlocklistReadsHostsAndDomainLists(t *testing.T) {
+	blocklist := NewBlocklist()
+	err := blocklist.Read(strings.NewReader(`
+# ads
+127.0.0.1 localhost
+0.0.0.0 ads.example.com tracker.example.com # inline comment
+malware.org
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, blocklist.Len())
+	assert.True(t, blocklist.Blocked("ads.example.com."))
+	assert.True(t, blocklist.Blocked("cdn.ADS.example.com"))
+	assert.True(t, blocklist.Blocked("malware.org"))
+	assert.False(t, blocklist.Blocked("example.com"))
+	assert.False(t, blocklist.Blocked("localhost"))
+}
+
+func Test_NilBlocklistBlocksNothing(t *testing.T) {
+	var blocklist *Blocklist
+
+	assert.False(t, blocklist.Blocked("example.com"))
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// cache is a LRU cache of DNS responses, responses expire together with the TTL of their records
+type cache struct {
+	size    int
+	now     func() time.Time
+	entries map[string]*list.Element
+	order   *list.List
+	lock    sync.Mutex
+}
+
+type cacheEntry struct {
+	key     string
+	msg     *dns.Msg
+	stored  time.Time
+	expires time.Time
+}
+
+func newCache(size int, now func() time.Time) *cache {
+	return &cache{
+		size:    size,
+		now:     now,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached response to the given request with TTLs decreased by the time spent in cache
+func (c *cache) get(req *dns.Msg) *dns.Msg {
+	key, ok := cacheKey(req)
+	if !ok {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := element.Value.(*cacheEntry)
+	now := c.now()
+	if !now.Before(entry.expires) {
+		c.remove(element)
+		return nil
+	}
+	c.order.MoveToFront(element)
+
+	resp := entry.msg.Copy()
+	resp.Id = req.Id
+	elapsed := uint32(now.Sub(entry.stored) / time.Second)
+	for _, rr := range records(resp) {
+		rr.Header().Ttl -= elapsed
+	}
+	return resp
+}
+
+// put stores the response in cache, if it is cacheable
+func (c *cache) put(resp *dns.Msg) {
+	if c.size <= 0 || resp.Truncated {
+		return
+	}
+	if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+		return
+	}
+	key, ok := cacheKey(resp)
+	if !ok {
+		return
+	}
+	ttl, ok := responseTTL(resp)
+	if !ok || ttl == 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	now := c.now()
+	c.entries[key] = c.order.PushFront(&cacheEntry{
+		key:     key,
+		msg:     resp.Copy(),
+		stored:  now,
+		expires: now.Add(ttl),
+	})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
+
+func cacheKey(msg *dns.Msg) (string, bool) {
+	if len(msg.Question) != 1 {
+		return "", false
+	}
+	question := msg.Question[0]
+	return fmt.Sprintf("%s:%d:%d", strings.ToLower(question.Name), question.Qtype, question.Qclass), true
+}
+
+// responseTTL returns the lowest TTL of the response records,
+// negative responses are cached for the minimum TTL of their SOA record
+func responseTTL(msg *dns.Msg) (time.Duration, bool) {
+	var ttl uint32
+	found := false
+	for _, rr := range records(msg) {
+		rrTTL := rr.Header().Ttl
+		if soa, ok := rr.(*dns.SOA); ok && soa.Minttl < rrTTL {
+			rrTTL = soa.Minttl
+		}
+		if !found || rrTTL < ttl {
+			ttl = rrTTL
+			found = true
+		}
+	}
+	return time.Duration(ttl) * time.Second, found
+}
+
+// records returns all the records of the message except the OPT pseudo record, which has no TTL
+func records(msg *dns.Msg) []dns.RR {
+	var rrs []dns.RR
+	for _, section := range [][]dns.RR{msg.Answer, msg.Ns, msg.Extra} {
+		for _, rr := range section {
+			if rr.Header().Rrtype != dns.TypeOPT {
+				rrs = append(rrs, rr)
+			}
+		}
+	}
+	return rrs
+}
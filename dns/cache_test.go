@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func newQuery(name string) *dns.Msg {
+	req := &dns.Msg{}
+	req.SetQuestion(dns.Fqdn(name), dns.TypeA)
+	return req
+}
+
+func newAnswer(req *dns.Msg, ttl uint32) *dns.Msg {
+	resp := &dns.Msg{}
+	resp.SetReply(req)
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+		A:   net.ParseIP("1.2.3.4"),
+	})
+	return resp
+}
+
+type mockClock struct {
+	now time.Time
+}
+
+func (clock *mockClock) Now() time.Time {
+	return clock.now
+}
+
+func Test_CacheDecreasesTTL(t *testing.T) {
+	clock := &mockClock{now: time.Unix(100, 0)}
+	c := newCache(10, clock.Now)
+	c.put(newAnswer(newQuery("example.com"), 60))
+
+	clock.now = clock.now.Add(15 * time.Second)
+	req := newQuery("EXAMPLE.com")
+	resp := c.get(req)
+
+	assert.NotNil(t, resp)
+	assert.Equal(t, req.Id, resp.Id)
+	assert.Equal(t, uint32(45), resp.Answer[0].Header().Ttl)
+}
+
+func Test_CacheExpiresResponses(t *testing.T) {
+	clock := &mockClock{now: time.Unix(100, 0)}
+	c := newCache(10, clock.Now)
+	c.put(newAnswer(newQuery("example.com"), 60))
+
+	clock.now = clock.now.Add(time.Minute)
+
+	assert.Nil(t, c.get(newQuery("example.com")))
+	assert.Equal(t, 0, c.order.Len())
+}
+
+func Test_CacheEvictsLeastRecentlyUsed(t *testing.T) {
+	clock := &mockClock{now: time.Unix(100, 0)}
+	c := newCache(2, clock.Now)
+	c.put(newAnswer(newQuery("one.com"), 60))
+	c.put(newAnswer(newQuery("two.com"), 60))
+
+	assert.NotNil(t, c.get(newQuery("one.com")))
+	c.put(newAnswer(newQuery("three.com"), 60))
+
+	assert.NotNil(t, c.get(newQuery("one.com")))
+	assert.Nil(t, c.get(newQuery("two.com")))
+	assert.NotNil(t, c.get(newQuery("three.com")))
+}
+
+func Test_CacheSkipsUncacheableResponses(t *testing.T) {
+	c := newCache(10, time.Now)
+
+	failed := &dns.Msg{}
+	failed.SetRcode(newQuery("failed.com"), dns.RcodeServerFailure)
+	c.put(failed)
+	c.put(newAnswer(newQuery("zero.com"), 0))
+
+	assert.Nil(t, c.get(newQuery("failed.com")))
+	assert.Nil(t, c.get(newQuery("zero.com")))
+}
+
+func Test_CacheKeepsNegativeResponsesForSOAMinimum(t *testing.T) {
+	req := newQuery("missing.com")
+	resp := &dns.Msg{}
+	resp.SetRcode(req, dns.RcodeNameError)
+	resp.Ns = append(resp.Ns, &dns.SOA{
+		Hdr:    dns.RR_Header{Name: "com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 900},
+		Minttl: 30,
+	})
+
+	ttl, ok := responseTTL(resp)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, ttl)
+}
@@ -18,37 +18,22 @@
 package dns
 
 import (
-	"net"
-
 	"github.com/miekg/dns"
 	"github.com/mysteriumnetwork/node/logconfig"
 )
 
 var log = logconfig.NewLogger()
 
-// ResolveViaConfigured create new dns.Server handler which handles incoming DNS requests
+// ResolveViaConfigured create new dns.Server handler which resolves incoming DNS requests via the servers configured by the system
 func ResolveViaConfigured() dns.Handler {
-	client := &dns.Client{}
-
-	return dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
-		resp := &dns.Msg{}
-		resp.SetRcode(req, dns.RcodeServerFailure)
-
-		config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
-		if err != nil {
-			log.Error("Error loading DNS config: ", err)
+	resolver, err := NewResolver(Options{})
+	if err != nil {
+		log.Error("Error loading DNS config: ", err)
+		return dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+			resp := &dns.Msg{}
+			resp.SetRcode(req, dns.RcodeServerFailure)
 			writer.WriteMsg(resp)
-			return
-		}
-
-		for _, server := range config.Servers {
-			forwardAddress := net.JoinHostPort(server, config.Port)
-			resp, _, err = client.Exchange(req, forwardAddress)
-			if err != nil {
-				log.Errorf(`Error proxying DNS query to "%s". %s`, forwardAddress, err)
-				continue
-			}
-		}
-		writer.WriteMsg(resp)
-	})
+		})
+	}
+	return resolver
 }
@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultCacheSize = 4096
+	systemConfigPath = "/etc/resolv.conf"
+)
+
+// Options describes the configuration of the resolver
+type Options struct {
+	// Upstreams are the addresses of DNS servers as accepted by ParseUpstream, servers configured by the system are used if empty
+	Upstreams []string
+	// Blocklists are the files listing the domains to refuse resolving
+	Blocklists []string
+	// CacheSize is the count of responses kept in cache
+	CacheSize int
+}
+
+// Resolver is a caching DNS resolver, which races every query among all its upstreams
+type Resolver struct {
+	upstreams []Upstream
+	blocklist *Blocklist
+	cache     *cache
+}
+
+// NewResolver creates a new resolver with the given options
+func NewResolver(options Options) (*Resolver, error) {
+	upstreams, err := parseUpstreams(options.Upstreams)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocklist *Blocklist
+	if len(options.Blocklists) > 0 {
+		blocklist, err = LoadBlocklist(options.Blocklists...)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("loaded DNS blocklist of ", blocklist.Len(), " domains")
+	}
+
+	cacheSize := options.CacheSize
+	if cacheSize <= 0 {
+		cacheSize = defaultCacheSize
+	}
+	return newResolver(upstreams, blocklist, newCache(cacheSize, time.Now)), nil
+}
+
+func newResolver(upstreams []Upstream, blocklist *Blocklist, cache *cache) *Resolver {
+	return &Resolver{
+		upstreams: upstreams,
+		blocklist: blocklist,
+		cache:     cache,
+	}
+}
+
+func parseUpstreams(addresses []string) ([]Upstream, error) {
+	if len(addresses) == 0 {
+		return systemUpstreams()
+	}
+
+	upstreams := make([]Upstream, len(addresses))
+	for i, address := range addresses {
+		upstream, err := ParseUpstream(address)
+		if err != nil {
+			return nil, err
+		}
+		upstreams[i] = upstream
+	}
+	return upstreams, nil
+}
+
+func systemUpstreams() ([]Upstream, error) {
+	config, err := dns.ClientConfigFromFile(systemConfigPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load system DNS config")
+	}
+
+	upstreams := make([]Upstream, len(config.Servers))
+	for i, server := range config.Servers {
+		upstreams[i] = newPlainUpstream(withDefaultPort(server, config.Port))
+	}
+	return upstreams, nil
+}
+
+// ServeDNS handles incoming DNS requests
+func (resolver *Resolver) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	resp, err := resolver.Resolve(req)
+	if err != nil {
+		log.Error("failed to resolve DNS query: ", err)
+		resp = &dns.Msg{}
+		resp.SetRcode(req, dns.RcodeServerFailure)
+	}
+	if err := writer.WriteMsg(resp); err != nil {
+		log.Error("failed to write DNS response: ", err)
+	}
+}
+
+// Resolve answers the given DNS query, blocked domains are answered as non existing ones
+func (resolver *Resolver) Resolve(req *dns.Msg) (*dns.Msg, error) {
+	if len(req.Question) > 0 && resolver.blocklist.Blocked(req.Question[0].Name) {
+		resp := &dns.Msg{}
+		resp.SetRcode(req, dns.RcodeNameError)
+		return resp, nil
+	}
+
+	if resp := resolver.cache.get(req); resp != nil {
+		return resp, nil
+	}
+
+	resp, err := resolver.race(req)
+	if err != nil {
+		return nil, err
+	}
+	resolver.cache.put(resp)
+	return resp, nil
+}
+
+type exchangeResult struct {
+	upstream Upstream
+	resp     *dns.Msg
+	err      error
+}
+
+// race forwards the query to all the upstreams at once and returns the first successful response
+func (resolver *Resolver) race(req *dns.Msg) (*dns.Msg, error) {
+	if len(resolver.upstreams) == 0 {
+		return nil, errors.New("no DNS upstreams configured")
+	}
+
+	results := make(chan exchangeResult, len(resolver.upstreams))
+	for _, upstream := range resolver.upstreams {
+		go func(upstream Upstream) {
+			resp, err := upstream.Exchange(req.Copy())
+			results <- exchangeResult{upstream: upstream, resp: resp, err: err}
+		}(upstream)
+	}
+
+	var failedResp *dns.Msg
+	var lastErr error
+	for range resolver.upstreams {
+		result := <-results
+		switch {
+		case result.err != nil:
+			log.Debugf("DNS upstream %s failed: %v", result.upstream, result.err)
+			lastErr = result.err
+		case result.resp.Rcode == dns.RcodeServerFailure || result.resp.Rcode == dns.RcodeRefused:
+			failedResp = result.resp
+		default:
+			return result.resp, nil
+		}
+	}
+
+	if failedResp != nil {
+		return failedResp, nil
+	}
+	return nil, errors.Wrap(lastErr, "all DNS upstreams failed")
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUpstream struct {
+	name   string
+	delay  time.Duration
+	rcode  int
+	err    error
+	calls  int
+	lock   sync.Mutex
+	answer uint32
+}
+
+func (upstream *mockUpstream) Exchange(req *dns.Msg) (*dns.Msg, error) {
+	upstream.lock.Lock()
+	upstream.calls++
+	upstream.lock.Unlock()
+
+	time.Sleep(upstream.delay)
+	if upstream.err != nil {
+		return nil, upstream.err
+	}
+	if upstream.rcode != dns.RcodeSuccess {
+		resp := &dns.Msg{}
+		resp.SetRcode(req, upstream.rcode)
+		return resp, nil
+	}
+	return newAnswer(req, upstream.answer), nil
+}
+
+func (upstream *mockUpstream) String() string {
+	return upstream.name
+}
+
+func (upstream *mockUpstream) callCount() int {
+	upstream.lock.Lock()
+	defer upstream.lock.Unlock()
+	return upstream.calls
+}
+
+func Test_ResolverRacesUpstreams(t *testing.T) {
+	slow := &mockUpstream{name: "slow", delay: 100 * time.Millisecond, answer: 10}
+	fast := &mockUpstream{name: "fast", answer: 20}
+	resolver := newResolver([]Upstream{slow, fast}, nil, newCache(10, time.Now))
+
+	resp, err := resolver.Resolve(newQuery("example.com"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(20), resp.Answer[0].Header().Ttl)
+}
+
+func Test_ResolverPrefersSuccessfulResponses(t *testing.T) {
+	failing := &mockUpstream{name: "failing", rcode: dns.RcodeServerFailure}
+	broken := &mockUpstream{name: "broken", err: errors.New("timeout")}
+	working := &mockUpstream{name: "working", delay: 10 * time.Millisecond, answer: 30}
+	resolver := newResolver([]Upstream{failing, broken, working}, nil, newCache(10, time.Now))
+
+	resp, err := resolver.Resolve(newQuery("example.com"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+}
+
+func Test_ResolverFailsWhenAllUpstreamsFail(t *testing.T) {
+	broken := &mockUpstream{name: "broken", err: errors.New("timeout")}
+	resolver := newResolver([]Upstream{broken}, nil, newCache(10, time.Now))
+
+	_, err := resolver.Resolve(newQuery("example.com"))
+
+	assert.Error(t, err)
+}
+
+func Test_ResolverAnswersFromCache(t *testing.T) {
+	upstream := &mockUpstream{name: "upstream", answer: 60}
+	resolver := newResolver([]Upstream{upstream}, nil, newCache(10, time.Now))
+
+	_, err := resolver.Resolve(newQuery("example.com"))
+	assert.NoError(t, err)
+	resp, err := resolver.Resolve(newQuery("example.com"))
+	assert.NoError(t, err)
+
+	assert.Len(t, resp.Answer, 1)
+	assert.Equal(t, 1, upstream.callCount())
+}
+
+func Test_ResolverRefusesBlockedDomains(t *testing.T) {
+	upstream := &mockUpstream{name: "upstream", answer: 60}
+	blocklist := NewBlocklist()
+	blocklist.Add("ads.example.com")
+	resolver := newResolver([]Upstream{upstream}, blocklist, newCache(10, time.Now))
+
+	resp, err := resolver.Resolve(newQuery("x.ads.example.com"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, dns.RcodeNameError, resp.Rcode)
+	assert.Equal(t, 0, upstream.callCount())
+}
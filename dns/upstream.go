@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+const (
+	upstreamTimeout = 5 * time.Second
+
+	dnsMessageContentType = "application/dns-message"
+	maxDNSMessageSize     = 65535
+)
+
+// Upstream is a DNS server the queries are forwarded to
+type Upstream interface {
+	Exchange(req *dns.Msg) (*dns.Msg, error)
+	String() string
+}
+
+// ParseUpstream creates upstream from the given address, which is either a plain DNS server address ("1.1.1.1", "1.1.1.1:53"),
+// DNS-over-TLS server address ("tls://1.1.1.1", "tls://cloudflare-dns.com:853") or DNS-over-HTTPS URL ("https://cloudflare-dns.com/dns-query")
+func ParseUpstream(address string) (Upstream, error) {
+	switch {
+	case strings.HasPrefix(address, "https://"):
+		endpoint, err := url.Parse(address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid DNS-over-HTTPS upstream %q", address)
+		}
+		return newHTTPSUpstream(endpoint), nil
+	case strings.HasPrefix(address, "tls://"):
+		hostPort := withDefaultPort(strings.TrimPrefix(address, "tls://"), "853")
+		host, _, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid DNS-over-TLS upstream %q", address)
+		}
+		return newTLSUpstream(hostPort, host), nil
+	case strings.Contains(address, "://"):
+		return nil, errors.Errorf("unsupported DNS upstream %q", address)
+	default:
+		hostPort := withDefaultPort(address, "53")
+		if _, _, err := net.SplitHostPort(hostPort); err != nil {
+			return nil, errors.Wrapf(err, "invalid DNS upstream %q", address)
+		}
+		return newPlainUpstream(hostPort), nil
+	}
+}
+
+func withDefaultPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), port)
+}
+
+// plainUpstream forwards queries over UDP and retries the truncated ones over TCP
+type plainUpstream struct {
+	address   string
+	udpClient *dns.Client
+	tcpClient *dns.Client
+}
+
+func newPlainUpstream(address string) *plainUpstream {
+	return &plainUpstream{
+		address:   address,
+		udpClient: &dns.Client{Net: "udp", Timeout: upstreamTimeout},
+		tcpClient: &dns.Client{Net: "tcp", Timeout: upstreamTimeout},
+	}
+}
+
+func (upstream *plainUpstream) Exchange(req *dns.Msg) (*dns.Msg, error) {
+	resp, _, err := upstream.udpClient.Exchange(req, upstream.address)
+	if err == nil && resp.Truncated {
+		resp, _, err = upstream.tcpClient.Exchange(req, upstream.address)
+	}
+	return resp, err
+}
+
+func (upstream *plainUpstream) String() string {
+	return upstream.address
+}
+
+// tlsUpstream forwards queries to DNS-over-TLS server
+type tlsUpstream struct {
+	address string
+	client  *dns.Client
+}
+
+func newTLSUpstream(address, serverName string) *tlsUpstream {
+	return &tlsUpstream{
+		address: address,
+		client: &dns.Client{
+			Net:       "tcp-tls",
+			TLSConfig: &tls.Config{ServerName: serverName},
+			Timeout:   upstreamTimeout,
+		},
+	}
+}
+
+func (upstream *tlsUpstream) Exchange(req *dns.Msg) (*dns.Msg, error) {
+	resp, _, err := upstream.client.Exchange(req, upstream.address)
+	return resp, err
+}
+
+func (upstream *tlsUpstream) String() string {
+	return "tls://" + upstream.address
+}
+
+// httpsUpstream forwards queries to DNS-over-HTTPS server as described in RFC 8484
+type httpsUpstream struct {
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func newHTTPSUpstream(endpoint *url.URL) *httpsUpstream {
+	return &httpsUpstream{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: upstreamTimeout},
+	}
+}
+
+func (upstream *httpsUpstream) Exchange(req *dns.Msg) (*dns.Msg, error) {
+	// the ID is zeroed for responses to be cacheable by HTTP caches
+	query := req.Copy()
+	query.Id = 0
+	body, err := query.Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pack DNS query")
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, upstream.endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", dnsMessageContentType)
+	httpReq.Header.Set("Accept", dnsMessageContentType)
+
+	httpResp, err := upstream.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("DNS-over-HTTPS upstream %s responded with status %d", upstream, httpResp.StatusCode)
+	}
+
+	respBody, err := ioutil.ReadAll(&io.LimitedReader{R: httpResp.Body, N: maxDNSMessageSize})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dns.Msg{}
+	if err := resp.Unpack(respBody); err != nil {
+		return nil, errors.Wrap(err, "failed to unpack DNS response")
+	}
+	resp.Id = req.Id
+	return resp, nil
+}
+
+func (upstream *httpsUpstream) String() string {
+	return upstream.endpoint.String()
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseUpstream(t *testing.T) {
+	upstream, err := ParseUpstream("1.1.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1.1.1:53", upstream.String())
+
+	upstream, err = ParseUpstream("[2606:4700:4700::1111]:5353")
+	assert.NoError(t, err)
+	assert.Equal(t, "[2606:4700:4700::1111]:5353", upstream.String())
+
+	upstream, err = ParseUpstream("tls://cloudflare-dns.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "tls://cloudflare-dns.com:853", upstream.String())
+	assert.Equal(t, "cloudflare-dns.com", upstream.(*tlsUpstream).client.TLSConfig.ServerName)
+
+	upstream, err = ParseUpstream("https://cloudflare-dns.com/dns-query")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://cloudflare-dns.com/dns-query", upstream.String())
+
+	_, err = ParseUpstream("quic://dns.example.com")
+	assert.Error(t, err)
+}
+
+func Test_HTTPSUpstreamExchange(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, dnsMessageContentType, request.Header.Get("Content-Type"))
+
+		body, err := ioutil.ReadAll(request.Body)
+		assert.NoError(t, err)
+		req := &dns.Msg{}
+		assert.NoError(t, req.Unpack(body))
+		assert.Equal(t, uint16(0), req.Id)
+
+		resp, err := newAnswer(req, 60).Pack()
+		assert.NoError(t, err)
+		writer.Header().Set("Content-Type", dnsMessageContentType)
+		writer.Write(resp)
+	}))
+	defer server.Close()
+
+	upstream, err := ParseUpstream(server.URL + "/dns-query")
+	assert.NoError(t, err)
+	upstream.(*httpsUpstream).client = server.Client()
+
+	req := newQuery("example.com")
+	resp, err := upstream.Exchange(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, req.Id, resp.Id)
+	assert.Len(t, resp.Answer, 1)
+}
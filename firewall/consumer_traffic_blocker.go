@@ -34,7 +34,7 @@ func AllowURLAccess(urls ...string) (RemoveRule, error) {
 	return trackingBlocker.AllowURLAccess(urls...)
 }
 
-// AllowIPAccess adds IP based exception to underlying blocker implementation
+// AllowIPAccess adds IP based exception to underlying blocker implementation, ip can also be a network in CIDR notation
 func AllowIPAccess(ip string) (RemoveRule, error) {
 	return trackingBlocker.AllowIPAccess(ip)
 }
@@ -31,6 +31,9 @@ type Balance func(Identity) (uint64, error)
 func NewBalance(etherClient *ethclient.Client) Balance {
 	return func(identity Identity) (uint64, error) {
 		balance, err := etherClient.BalanceAt(context.Background(), common.HexToAddress(identity.Address), nil)
-		return balance.Uint64(), err
+		if err != nil {
+			return 0, err
+		}
+		return balance.Uint64(), nil
 	}
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"sync"
+	"time"
+)
+
+// BalanceEventTopic is the topic the balances of node identities are published to
+const BalanceEventTopic = "identity-balance"
+
+// DefaultBalanceCheckInterval is how often the balances of node identities are checked
+const DefaultBalanceCheckInterval = 5 * time.Minute
+
+// BalanceEvent carries the balance of the node identity
+type BalanceEvent struct {
+	Identity Identity
+	Balance  uint64
+}
+
+// Publisher is responsible for publishing given events
+type Publisher interface {
+	Publish(topic string, data interface{})
+}
+
+// BalanceTracker periodically checks the balances of node identities and publishes them
+type BalanceTracker struct {
+	manager   Manager
+	balance   Balance
+	publisher Publisher
+	interval  time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewBalanceTracker creates the tracker of the balances of identities kept by the given manager
+func NewBalanceTracker(manager Manager, balance Balance, publisher Publisher, interval time.Duration) *BalanceTracker {
+	return &BalanceTracker{
+		manager:   manager,
+		balance:   balance,
+		publisher: publisher,
+		interval:  interval,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start starts checking the balances in the background
+func (bt *BalanceTracker) Start() {
+	go func() {
+		for {
+			bt.check()
+
+			select {
+			case <-time.After(bt.interval):
+			case <-bt.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops checking the balances
+func (bt *BalanceTracker) Stop() {
+	bt.stopOnce.Do(func() {
+		close(bt.stop)
+	})
+}
+
+func (bt *BalanceTracker) check() {
+	for _, id := range bt.manager.GetIdentities() {
+		balance, err := bt.balance(id)
+		if err != nil {
+			log.Warn("failed to check balance of identity ", id.Address, ": ", err)
+			continue
+		}
+		bt.publisher.Publish(BalanceEventTopic, BalanceEvent{Identity: id, Balance: balance})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type balancePublisherFake struct {
+	lock   sync.Mutex
+	events []BalanceEvent
+}
+
+func (bpf *balancePublisherFake) Publish(topic string, data interface{}) {
+	bpf.lock.Lock()
+	defer bpf.lock.Unlock()
+	if topic == BalanceEventTopic {
+		bpf.events = append(bpf.events, data.(BalanceEvent))
+	}
+}
+
+func (bpf *balancePublisherFake) published() []BalanceEvent {
+	bpf.lock.Lock()
+	defer bpf.lock.Unlock()
+	return bpf.events
+}
+
+func TestBalanceTrackerPublishesBalancesOfIdentities(t *testing.T) {
+	funded, failing := FromAddress("0x1"), FromAddress("0x2")
+	manager := NewIdentityManagerFake([]Identity{funded, failing}, Identity{})
+	balance := func(id Identity) (uint64, error) {
+		if id == failing {
+			return 0, errors.New("rpc is unreachable")
+		}
+		return 100, nil
+	}
+	publisher := &balancePublisherFake{}
+
+	tracker := NewBalanceTracker(manager, balance, publisher, time.Hour)
+	tracker.Start()
+	defer tracker.Stop()
+
+	for i := 0; i < 100 && len(publisher.published()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []BalanceEvent{{Identity: funded, Balance: 100}}, publisher.published())
+}
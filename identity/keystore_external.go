@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// ErrBackupNotSupported is returned when the keys are kept by the external signer and can't be exported or imported
+var ErrBackupNotSupported = errors.New("identity backup is not supported by the external signer")
+
+// ErrPassphraseChangeNotSupported is returned when the passphrase is kept by the external signer and can't be changed through the node
+var ErrPassphraseChangeNotSupported = errors.New("passphrase change is not supported by the external signer")
+
+// externalSignerTimeout is long enough for the signer to ask its user for a confirmation
+const externalSignerTimeout = time.Minute
+
+// KeystoreExternal delegates keeping the keys and signing to the external signer listening on the local socket.
+// The signer speaks JSON-RPC in the style of Clef and exposes the following methods:
+// account_list, account_new(passphrase), account_unlock(address, passphrase), account_lock(address) and account_signHash(address, hash).
+type KeystoreExternal struct {
+	client *rpc.Client
+}
+
+// NewKeystoreExternal connects to the external signer listening on the given socket
+func NewKeystoreExternal(socketPath string) (*KeystoreExternal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), externalSignerTimeout)
+	defer cancel()
+
+	client, err := rpc.DialIPC(ctx, socketPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to external signer %s", socketPath)
+	}
+	return &KeystoreExternal{client: client}, nil
+}
+
+// Accounts returns the accounts held by the signer
+func (ks *KeystoreExternal) Accounts() []accounts.Account {
+	var addresses []common.Address
+	if err := ks.call(&addresses, "account_list"); err != nil {
+		log.Error("failed to list external signer accounts: ", err)
+		return []accounts.Account{}
+	}
+
+	list := make([]accounts.Account, len(addresses))
+	for i, address := range addresses {
+		list[i] = accounts.Account{Address: address}
+	}
+	return list
+}
+
+// NewAccount asks the signer to create a new account
+func (ks *KeystoreExternal) NewAccount(passphrase string) (accounts.Account, error) {
+	var address common.Address
+	if err := ks.call(&address, "account_new", passphrase); err != nil {
+		return accounts.Account{}, errors.Wrap(err, "external signer failed to create account")
+	}
+	return accounts.Account{Address: address}, nil
+}
+
+// Find returns the account if it is held by the signer
+func (ks *KeystoreExternal) Find(a accounts.Account) (accounts.Account, error) {
+	for _, account := range ks.Accounts() {
+		if account.Address == a.Address {
+			return account, nil
+		}
+	}
+	return a, errors.New("account not found")
+}
+
+// Unlock passes the passphrase to the signer, the signer decides if it needs one
+func (ks *KeystoreExternal) Unlock(a accounts.Account, passphrase string) error {
+	var unlocked bool
+	if err := ks.call(&unlocked, "account_unlock", a.Address, passphrase); err != nil {
+		return errors.Wrap(err, "external signer failed to unlock account")
+	}
+	if !unlocked {
+		return errors.New("external signer refused to unlock account")
+	}
+	return nil
+}
+
+// Lock asks the signer to forget the unlocked account, the signer asks for the passphrase again on the next unlock
+func (ks *KeystoreExternal) Lock(addr common.Address) error {
+	if err := ks.call(nil, "account_lock", addr); err != nil {
+		return errors.Wrap(err, "external signer failed to lock account")
+	}
+	return nil
+}
+
+// Update is not supported, the passphrase should be changed in the signer directly
+func (ks *KeystoreExternal) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	return ErrPassphraseChangeNotSupported
+}
+
+// SignHash asks the signer to sign the hash, the signature is checked to be made by the given account
+func (ks *KeystoreExternal) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	if err := ks.call(&signature, "account_signHash", a.Address, hexutil.Bytes(hash)); err != nil {
+		return nil, errors.Wrap(err, "external signer failed to sign")
+	}
+
+	publicKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "external signer returned malformed signature")
+	}
+	if crypto.PubkeyToAddress(*publicKey) != a.Address {
+		return nil, errors.New("external signer returned signature of another account")
+	}
+	return signature, nil
+}
+
+// Export is not supported, the keys never leave the signer
+func (ks *KeystoreExternal) Export(a accounts.Account, passphrase, newPassphrase string) ([]byte, error) {
+	return nil, ErrBackupNotSupported
+}
+
+// Import is not supported, the keys should be imported to the signer directly
+func (ks *KeystoreExternal) Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
+	return accounts.Account{}, ErrBackupNotSupported
+}
+
+// ImportECDSA is not supported, the keys should be imported to the signer directly
+func (ks *KeystoreExternal) ImportECDSA(key *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	return accounts.Account{}, ErrBackupNotSupported
+}
+
+// Close disconnects from the signer
+func (ks *KeystoreExternal) Close() {
+	ks.client.Close()
+}
+
+func (ks *KeystoreExternal) call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), externalSignerTimeout)
+	defer cancel()
+	return ks.client.CallContext(ctx, result, method, args...)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// standInSigner acts as the external signer keeping the keys in memory
+type standInSigner struct {
+	mu   sync.Mutex
+	keys map[common.Address]*ecdsa.PrivateKey
+}
+
+func (signer *standInSigner) List() []common.Address {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+
+	addresses := make([]common.Address, 0, len(signer.keys))
+	for address := range signer.keys {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+func (signer *standInSigner) New(passphrase string) (common.Address, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	signer.keys[address] = key
+	return address, nil
+}
+
+func (signer *standInSigner) Unlock(address common.Address, passphrase string) bool {
+	return passphrase != "wrong"
+}
+
+func (signer *standInSigner) Lock(address common.Address) error {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+
+	if _, ok := signer.keys[address]; !ok {
+		return errors.New("unknown account")
+	}
+	return nil
+}
+
+func (signer *standInSigner) SignHash(address common.Address, hash hexutil.Bytes) (hexutil.Bytes, error) {
+	signer.mu.Lock()
+	defer signer.mu.Unlock()
+
+	key, ok := signer.keys[address]
+	if !ok {
+		return nil, errors.New("unknown account")
+	}
+	return crypto.Sign(hash, key)
+}
+
+func startStandInSigner(t *testing.T) (socketPath string, stop func()) {
+	dir, err := ioutil.TempDir("", "external-signer")
+	assert.NoError(t, err)
+	socketPath = filepath.Join(dir, "signer.ipc")
+
+	listener, err := net.Listen("unix", socketPath)
+	assert.NoError(t, err)
+
+	server := rpc.NewServer()
+	err = server.RegisterName("account", &standInSigner{keys: map[common.Address]*ecdsa.PrivateKey{}})
+	assert.NoError(t, err)
+	go server.ServeListener(listener)
+
+	return socketPath, func() {
+		server.Stop()
+		listener.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestKeystoreExternal_SignsWithoutHoldingKeys(t *testing.T) {
+	socketPath, stop := startStandInSigner(t)
+	defer stop()
+
+	ks, err := NewKeystoreExternal(socketPath)
+	assert.NoError(t, err)
+	defer ks.Close()
+
+	manager := NewIdentityManager(ks)
+	id, err := manager.CreateNewIdentity("")
+	assert.NoError(t, err)
+	assert.Equal(t, []Identity{id}, manager.GetIdentities())
+	assert.True(t, manager.HasIdentity(id.Address))
+	assert.NoError(t, manager.Unlock(id.Address, ""))
+
+	message := []byte("MystVpnSessionId:Boop!")
+	signature, err := NewSigner(ks, id).Sign(message)
+	assert.NoError(t, err)
+	assert.True(t, NewVerifierIdentity(id).Verify(message, signature))
+}
+
+func TestKeystoreExternal_Errors(t *testing.T) {
+	socketPath, stop := startStandInSigner(t)
+	defer stop()
+
+	ks, err := NewKeystoreExternal(socketPath)
+	assert.NoError(t, err)
+	defer ks.Close()
+
+	unknown := FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+	_, err = NewSigner(ks, unknown).Sign([]byte("message"))
+	assert.EqualError(t, err, "external signer failed to sign: unknown account")
+
+	account, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.EqualError(t, ks.Unlock(account, "wrong"), "external signer refused to unlock account")
+	assert.NoError(t, ks.Lock(account.Address))
+	assert.EqualError(t, ks.Lock(common.HexToAddress(unknown.Address)), "external signer failed to lock account: unknown account")
+	assert.Equal(t, ErrPassphraseChangeNotSupported, ks.Update(account, "", "new"))
+
+	_, err = NewKeystoreExternal(filepath.Join(os.TempDir(), "missing-signer.ipc"))
+	assert.Error(t, err)
+}
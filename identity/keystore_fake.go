@@ -18,15 +18,19 @@
 package identity
 
 import (
+	"crypto/ecdsa"
+
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 )
 
 type keyStoreFake struct {
-	AccountsMock []accounts.Account
-	ErrorMock    error
-	LastHash     []byte
+	AccountsMock  []accounts.Account
+	ErrorMock     error
+	LastHash      []byte
+	LockedAddress common.Address
 }
 
 func (keyStore *keyStoreFake) Accounts() []accounts.Account {
@@ -54,6 +58,23 @@ func (keyStore *keyStoreFake) Unlock(a accounts.Account, passphrase string) erro
 	return nil
 }
 
+func (keyStore *keyStoreFake) Lock(addr common.Address) error {
+	if keyStore.ErrorMock != nil {
+		return keyStore.ErrorMock
+	}
+
+	keyStore.LockedAddress = addr
+	return nil
+}
+
+func (keyStore *keyStoreFake) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	if keyStore.ErrorMock != nil {
+		return keyStore.ErrorMock
+	}
+
+	return nil
+}
+
 func (keyStore *keyStoreFake) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	if keyStore.ErrorMock != nil {
 		return []byte{}, keyStore.ErrorMock
@@ -76,3 +97,37 @@ func (keyStore *keyStoreFake) Find(a accounts.Account) (accounts.Account, error)
 
 	return a, errors.New("account not found")
 }
+
+func (keyStore *keyStoreFake) Export(a accounts.Account, passphrase, newPassphrase string) ([]byte, error) {
+	if keyStore.ErrorMock != nil {
+		return nil, keyStore.ErrorMock
+	}
+
+	return []byte(`{"address":"` + a.Address.Hex() + `"}`), nil
+}
+
+func (keyStore *keyStoreFake) Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
+	if keyStore.ErrorMock != nil {
+		return accounts.Account{}, keyStore.ErrorMock
+	}
+
+	accountNew := accounts.Account{
+		Address: common.HexToAddress("0x000000000000000000000000000000000000cafe"),
+	}
+	keyStore.AccountsMock = append(keyStore.AccountsMock, accountNew)
+
+	return accountNew, nil
+}
+
+func (keyStore *keyStoreFake) ImportECDSA(key *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	if keyStore.ErrorMock != nil {
+		return accounts.Account{}, keyStore.ErrorMock
+	}
+
+	accountNew := accounts.Account{
+		Address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+	keyStore.AccountsMock = append(keyStore.AccountsMock, accountNew)
+
+	return accountNew, nil
+}
@@ -17,13 +17,23 @@
 
 package identity
 
-import "github.com/ethereum/go-ethereum/accounts"
+import (
+	"crypto/ecdsa"
 
-// Keystore allows actions with accounts (listing, creating, unlocking, signing)
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Keystore allows actions with accounts (listing, creating, unlocking, locking, signing, exporting and importing)
 type Keystore interface {
 	Accounts() []accounts.Account
 	NewAccount(passphrase string) (accounts.Account, error)
 	Find(a accounts.Account) (accounts.Account, error)
 	Unlock(a accounts.Account, passphrase string) error
+	Lock(addr common.Address) error
+	Update(a accounts.Account, passphrase, newPassphrase string) error
 	SignHash(a accounts.Account, hash []byte) ([]byte, error)
+	Export(a accounts.Account, passphrase, newPassphrase string) ([]byte, error)
+	Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error)
+	ImportECDSA(key *ecdsa.PrivateKey, passphrase string) (accounts.Account, error)
 }
@@ -21,13 +21,25 @@
 package identity
 
 import (
+	"encoding/json"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 )
 
+var (
+	// ErrIdentityExists is returned when the imported identity is already in the keystore
+	ErrIdentityExists = errors.New("identity already exists")
+	// ErrInvalidPassphrase is returned when the key can't be decrypted with the given passphrase
+	ErrInvalidPassphrase = errors.New("invalid passphrase")
+	// ErrInvalidMnemonic is returned when the mnemonic phrase has unknown words or a wrong checksum
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+)
+
 type identityManager struct {
 	keystoreManager Keystore
 	unlocked        map[string]bool // Currently unlocked addresses
@@ -115,6 +127,103 @@ func (idm *identityManager) Unlock(address string, passphrase string) error {
 	return nil
 }
 
+// Lock forgets the decrypted key of the identity, it has to be unlocked again before signing
+func (idm *identityManager) Lock(address string) error {
+	idm.unlockedMu.Lock()
+	defer idm.unlockedMu.Unlock()
+
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return err
+	}
+
+	err = idm.keystoreManager.Lock(account.Address)
+	if err != nil {
+		return errors.Wrapf(err, "keystore failed to lock identity: %s", address)
+	}
+	log.Infof("identity locked: %s", address)
+	delete(idm.unlocked, address)
+
+	return nil
+}
+
+// ChangePassphrase re-encrypts the key of the identity with the new passphrase, the unlocked key stays unlocked
+func (idm *identityManager) ChangePassphrase(address, passphrase, newPassphrase string) error {
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return err
+	}
+
+	err = idm.keystoreManager.Update(account, passphrase, newPassphrase)
+	if err == keystore.ErrDecrypt {
+		return ErrInvalidPassphrase
+	}
+	if err != nil {
+		return errors.Wrapf(err, "keystore failed to change passphrase of identity: %s", address)
+	}
+	log.Infof("identity passphrase changed: %s", address)
+	return nil
+}
+
+// ExportIdentity returns the encrypted keystore JSON of the identity, the key is re-encrypted with the new passphrase
+func (idm *identityManager) ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error) {
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	keyJSON, err := idm.keystoreManager.Export(account, passphrase, newPassphrase)
+	if err == keystore.ErrDecrypt {
+		return nil, ErrInvalidPassphrase
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "keystore failed to export identity: %s", address)
+	}
+	log.Infof("identity exported: %s", address)
+	return keyJSON, nil
+}
+
+// ImportIdentity imports the encrypted keystore JSON, the passphrase is validated by decrypting the key
+func (idm *identityManager) ImportIdentity(keyJSON []byte, passphrase, newPassphrase string) (Identity, error) {
+	var key struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return Identity{}, errors.Wrap(err, "malformed keystore JSON")
+	}
+	if key.Address != "" && idm.HasIdentity(common.HexToAddress(key.Address).Hex()) {
+		return Identity{}, ErrIdentityExists
+	}
+
+	account, err := idm.keystoreManager.Import(keyJSON, passphrase, newPassphrase)
+	if err == keystore.ErrDecrypt {
+		return Identity{}, ErrInvalidPassphrase
+	}
+	if err != nil {
+		return Identity{}, errors.Wrap(err, "keystore failed to import identity")
+	}
+	log.Infof("identity imported: %s", account.Address.Hex())
+	return accountToIdentity(account), nil
+}
+
+// ImportIdentityFromMnemonic imports the first Ethereum account of the BIP-39 mnemonic phrase encrypted with the passphrase
+func (idm *identityManager) ImportIdentityFromMnemonic(mnemonic, mnemonicPassword, passphrase string) (Identity, error) {
+	key, err := privateKeyFromMnemonic(mnemonic, mnemonicPassword)
+	if err != nil {
+		return Identity{}, err
+	}
+	if idm.HasIdentity(crypto.PubkeyToAddress(key.PublicKey).Hex()) {
+		return Identity{}, ErrIdentityExists
+	}
+
+	account, err := idm.keystoreManager.ImportECDSA(key, passphrase)
+	if err != nil {
+		return Identity{}, errors.Wrap(err, "keystore failed to import identity")
+	}
+	log.Infof("identity imported from mnemonic: %s", account.Address.Hex())
+	return accountToIdentity(account), nil
+}
+
 func (idm *identityManager) findAccount(address string) (accounts.Account, error) {
 	account, err := idm.keystoreManager.Find(addressToAccount(address))
 	if err != nil {
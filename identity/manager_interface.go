@@ -26,3 +26,16 @@ type Manager interface {
 	HasIdentity(address string) bool
 	Unlock(address string, passphrase string) error
 }
+
+// Backup exposes identity export and import methods
+type Backup interface {
+	ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error)
+	ImportIdentity(keyJSON []byte, passphrase, newPassphrase string) (Identity, error)
+	ImportIdentityFromMnemonic(mnemonic, mnemonicPassword, passphrase string) (Identity, error)
+}
+
+// Locker exposes identity locking and passphrase change methods
+type Locker interface {
+	Lock(address string) error
+	ChangePassphrase(address, passphrase, newPassphrase string) error
+}
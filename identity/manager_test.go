@@ -19,6 +19,8 @@ package identity
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -95,3 +97,82 @@ func TestManager_HasIdentity(t *testing.T) {
 	assert.True(t, manager.HasIdentity("0x000000000000000000000000000000000000000a"))
 	assert.False(t, manager.HasIdentity("0x000000000000000000000000000000000000000B"))
 }
+
+func TestManager_ExportImportIdentity(t *testing.T) {
+	sourceDir, err := ioutil.TempDir("", "identity-export")
+	assert.NoError(t, err)
+	defer os.RemoveAll(sourceDir)
+	targetDir, err := ioutil.TempDir("", "identity-import")
+	assert.NoError(t, err)
+	defer os.RemoveAll(targetDir)
+
+	source := NewIdentityManager(NewKeystoreFilesystem(sourceDir, true))
+	target := NewIdentityManager(NewKeystoreFilesystem(targetDir, true))
+
+	identity, err := source.CreateNewIdentity("old")
+	assert.NoError(t, err)
+
+	_, err = source.ExportIdentity(identity.Address, "wrong", "new")
+	assert.Equal(t, ErrInvalidPassphrase, err)
+
+	keyJSON, err := source.ExportIdentity(identity.Address, "old", "new")
+	assert.NoError(t, err)
+
+	_, err = target.ImportIdentity(keyJSON, "old", "new")
+	assert.Equal(t, ErrInvalidPassphrase, err)
+
+	imported, err := target.ImportIdentity(keyJSON, "new", "new")
+	assert.NoError(t, err)
+	assert.Equal(t, identity, imported)
+	assert.NoError(t, target.Unlock(imported.Address, "new"))
+
+	_, err = target.ImportIdentity(keyJSON, "new", "new")
+	assert.Equal(t, ErrIdentityExists, err)
+}
+
+func TestManager_LockAndChangePassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identity-lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ks := NewKeystoreFilesystem(dir, true)
+	manager := NewIdentityManager(ks)
+	identity, err := manager.CreateNewIdentity("old")
+	assert.NoError(t, err)
+	assert.NoError(t, manager.Unlock(identity.Address, "old"))
+
+	assert.Equal(t, ErrInvalidPassphrase, manager.ChangePassphrase(identity.Address, "wrong", "new"))
+	assert.NoError(t, manager.ChangePassphrase(identity.Address, "old", "new"))
+	_, err = NewSigner(ks, identity).Sign([]byte("message"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, manager.Lock(identity.Address))
+	_, err = NewSigner(ks, identity).Sign([]byte("message"))
+	assert.Error(t, err)
+	assert.Error(t, manager.Unlock(identity.Address, "old"))
+	assert.NoError(t, manager.Unlock(identity.Address, "new"))
+
+	assert.EqualError(t, manager.Lock("0x000000000000000000000000000000000000000B"), "identity not found: 0x000000000000000000000000000000000000000B")
+}
+
+func TestManager_ImportIdentityFromMnemonic(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+
+	identity, err := manager.ImportIdentityFromMnemonic(
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"",
+		"passphrase",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, FromAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"), identity)
+
+	_, err = manager.ImportIdentityFromMnemonic(
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"",
+		"passphrase",
+	)
+	assert.Equal(t, ErrIdentityExists, err)
+
+	_, err = manager.ImportIdentityFromMnemonic("abandon abandon abandon", "", "passphrase")
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const hardenedKeyStart = 0x80000000
+
+// mnemonicDerivationPath is m/44'/60'/0'/0/0, the first Ethereum account used by the wallets
+var mnemonicDerivationPath = []uint32{
+	hardenedKeyStart + 44,
+	hardenedKeyStart + 60,
+	hardenedKeyStart + 0,
+	0,
+	0,
+}
+
+// privateKeyFromMnemonic derives the key of the first Ethereum account from the BIP-39 mnemonic phrase using BIP-32
+func privateKeyFromMnemonic(mnemonic, mnemonicPassword string) (*ecdsa.PrivateKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, mnemonicPassword)
+	if err != nil {
+		log.Warn("invalid mnemonic: ", err)
+		return nil, ErrInvalidMnemonic
+	}
+
+	master := hmacSHA512([]byte("Bitcoin seed"), seed)
+	key, chainCode := master[:32], master[32:]
+	for _, index := range mnemonicDerivationPath {
+		key, chainCode, err = deriveChildKey(key, chainCode, index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return crypto.ToECDSA(key)
+}
+
+func deriveChildKey(key, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	data := make([]byte, 0, 37)
+	if index >= hardenedKeyStart {
+		data = append(data, 0x00)
+		data = append(data, key...)
+	} else {
+		privateKey, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = append(data, crypto.CompressPubkey(&privateKey.PublicKey)...)
+	}
+	indexBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(indexBytes, index)
+	data = append(data, indexBytes...)
+
+	derived := hmacSHA512(chainCode, data)
+	curveOrder := crypto.S256().Params().N
+	tweak := new(big.Int).SetBytes(derived[:32])
+	if tweak.Cmp(curveOrder) >= 0 {
+		return nil, nil, errors.New("invalid derived key")
+	}
+
+	child := tweak.Add(tweak, new(big.Int).SetBytes(key))
+	child.Mod(child, curveOrder)
+	if child.Sign() == 0 {
+		return nil, nil, errors.New("invalid derived key")
+	}
+	return math.PaddedBigBytes(child, 32), derived[32:], nil
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
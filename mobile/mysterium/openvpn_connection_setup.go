@@ -43,6 +43,7 @@ type sessionWrapper struct {
 	natPinger     cmd.NatPinger
 	ipResolver    ip.Resolver
 	pingerStop    chan struct{}
+	relayed       bool
 	stopOnce      sync.Once
 }
 
@@ -60,6 +61,12 @@ func (wrapper *sessionWrapper) Start(options connection.ConnectOptions) error {
 			clientConfig.LocalPort,
 			wrapper.pingerStop,
 		)
+		// session connects through the local NATProxy, so it doesn't change when falling back to the relay
+		if err != nil && clientConfig.VpnConfig.Relay != nil && !options.DisableRelay {
+			log.Warn("NAT pinging to provider failed, falling back to relay: ", err)
+			err = wrapper.natPinger.RelayProvider(*clientConfig.VpnConfig.Relay, clientConfig.LocalPort, wrapper.pingerStop)
+			wrapper.relayed = err == nil
+		}
 		if err != nil {
 			return err
 		}
@@ -70,6 +77,11 @@ func (wrapper *sessionWrapper) Start(options connection.ConnectOptions) error {
 	return nil
 }
 
+// Relayed tells whether the session goes through the relay
+func (wrapper *sessionWrapper) Relayed() bool {
+	return wrapper.relayed
+}
+
 func (wrapper *sessionWrapper) Stop() {
 	wrapper.stopOnce.Do(func() {
 		if wrapper.session != nil {
@@ -184,7 +196,7 @@ func (st *sessionTracker) Reconnect(afterSeconds int) error {
 
 func (st *sessionTracker) handleState(stateEvent connection.StateEvent) {
 	// On disconnected - remove session
-	if stateEvent.State == connection.Disconnecting {
+	if stateEvent.ConnectionID == connection.DefaultConnectionID && stateEvent.State == connection.Disconnecting {
 		st.mux.Lock()
 		st.session = nil
 		st.mux.Unlock()
@@ -219,7 +231,7 @@ func (ocf *OpenvpnConnectionFactory) Create(stateChannel connection.StateChannel
 			sessionConfig.RemotePort = sessionConfig.LocalPort + 1
 		}
 
-		vpnClientConfig, err := openvpn.NewClientConfigFromSession(sessionConfig, "", "", false)
+		vpnClientConfig, err := openvpn.NewClientConfigFromSession(sessionConfig, "", "", false, connection.TunnelRoutes{})
 		if err != nil {
 			return nil, nil, err
 		}
@@ -18,6 +18,7 @@
 package event
 
 import (
+	"sync"
 	"time"
 
 	log "github.com/cihub/seelog"
@@ -32,6 +33,9 @@ const eventsTrackerLogPrefix = "[traversal-events-tracker] "
 type Tracker struct {
 	lastEvent *Event
 	eventChan chan Event
+
+	stageLock  sync.Mutex
+	stageEvent map[string]Event
 }
 
 // BuildSuccessfulEvent returns new event for successful NAT traversal
@@ -46,7 +50,10 @@ func BuildFailureEvent(stage string, err error) Event {
 
 // NewTracker returns a new instance of event tracker
 func NewTracker() *Tracker {
-	return &Tracker{eventChan: make(chan Event, 1)}
+	return &Tracker{
+		eventChan:  make(chan Event, 1),
+		stageEvent: make(map[string]Event),
+	}
 }
 
 // ConsumeNATEvent consumes a NAT event
@@ -54,6 +61,10 @@ func (et *Tracker) ConsumeNATEvent(event Event) {
 	log.Info(eventsTrackerLogPrefix, "got NAT event: ", event)
 
 	et.lastEvent = &event
+	et.stageLock.Lock()
+	et.stageEvent[event.Stage] = event
+	et.stageLock.Unlock()
+
 	select {
 	case et.eventChan <- event:
 	case <-time.After(300 * time.Millisecond):
@@ -66,6 +77,18 @@ func (et *Tracker) LastEvent() *Event {
 	return et.lastEvent
 }
 
+// LastStageEvent returns the last known event of the given stage, events of the other stages do not replace it
+func (et *Tracker) LastStageEvent(stage string) *Event {
+	et.stageLock.Lock()
+	defer et.stageLock.Unlock()
+
+	event, ok := et.stageEvent[stage]
+	if !ok {
+		return nil
+	}
+	return &event
+}
+
 // WaitForEvent waits for event to occur
 func (et *Tracker) WaitForEvent() Event {
 	if et.lastEvent != nil {
@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package event
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_LastStageEventIsKeptPerStage(t *testing.T) {
+	tracker := NewTracker()
+	assert.Nil(t, tracker.LastStageEvent("port_mapping"))
+
+	tracker.ConsumeNATEvent(BuildFailureEvent("port_mapping", errors.New("no gateway")))
+	tracker.ConsumeNATEvent(BuildSuccessfulEvent("relay"))
+
+	assert.Equal(t, "relay", tracker.LastEvent().Stage)
+	mappingEvent := tracker.LastStageEvent("port_mapping")
+	assert.NotNil(t, mappingEvent)
+	assert.False(t, mappingEvent.Successful)
+	assert.True(t, tracker.LastStageEvent("relay").Successful)
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PCP is described in RFC 6887, only the MAP opcode is used to map the ports on the gateway
+const (
+	pcpPort          = 5351
+	pcpVersion       = 2
+	pcpOpcodeMap     = 1
+	pcpResponseBit   = 0x80
+	pcpResultSuccess = 0
+	pcpRequestLen    = 60
+	pcpResponseLen   = 60
+	pcpRetries       = 4
+	pcpFirstTimeout  = 250 * time.Millisecond
+)
+
+var pcpResultCodes = map[byte]string{
+	1:  "UNSUPP_VERSION",
+	2:  "NOT_AUTHORIZED",
+	3:  "MALFORMED_REQUEST",
+	4:  "UNSUPP_OPCODE",
+	5:  "UNSUPP_OPTION",
+	6:  "MALFORMED_OPTION",
+	7:  "NETWORK_FAILURE",
+	8:  "NO_RESOURCES",
+	9:  "UNSUPP_PROTOCOL",
+	10: "USER_EX_QUOTA",
+	11: "CANNOT_PROVIDE_EXTERNAL",
+	12: "ADDRESS_MISMATCH",
+	13: "EXCESSIVE_REMOTE_PEERS",
+}
+
+// pcp maps the ports on the gateway speaking Port Control Protocol
+type pcp struct {
+	gateway *net.UDPAddr
+
+	// the same nonce has to be used to renew and to delete the mapping
+	nonces map[string][]byte
+	mu     sync.Mutex
+}
+
+// newPCP returns PCP client of the gateway
+func newPCP(gateway net.IP) *pcp {
+	return &pcp{
+		gateway: &net.UDPAddr{IP: gateway, Port: pcpPort},
+		nonces:  make(map[string][]byte),
+	}
+}
+
+// AddMapping maps the external port of the gateway to the internal port of the machine
+func (p *pcp) AddMapping(protocol string, extPort, intPort int, name string, lifetime time.Duration) error {
+	nonce, err := p.nonce(protocol, intPort)
+	if err != nil {
+		return err
+	}
+
+	assignedPort, err := p.mapPort(nonce, protocol, extPort, intPort, lifetime)
+	if err != nil {
+		return err
+	}
+	if assignedPort != extPort {
+		p.DeleteMapping(protocol, extPort, intPort)
+		return errors.Errorf("gateway assigned external port %d instead of %d", assignedPort, extPort)
+	}
+	return nil
+}
+
+// DeleteMapping removes the mapping of the internal port
+func (p *pcp) DeleteMapping(protocol string, extPort, intPort int) error {
+	nonce, err := p.nonce(protocol, intPort)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.mapPort(nonce, protocol, 0, intPort, 0)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	delete(p.nonces, mappingKey(protocol, intPort))
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *pcp) String() string {
+	return fmt.Sprintf("PCP(%v)", p.gateway.IP)
+}
+
+func (p *pcp) nonce(protocol string, intPort int) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := mappingKey(protocol, intPort)
+	if nonce, ok := p.nonces[key]; ok {
+		return nonce, nil
+	}
+
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate PCP nonce")
+	}
+	p.nonces[key] = nonce
+	return nonce, nil
+}
+
+// mapPort sends the MAP request retransmitting it with the growing timeout, returns the assigned external port
+func (p *pcp) mapPort(nonce []byte, protocol string, extPort, intPort int, lifetime time.Duration) (int, error) {
+	protocolNumber, err := pcpProtocolNumber(protocol)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := net.DialUDP("udp", nil, p.gateway)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to dial PCP server")
+	}
+	defer conn.Close()
+
+	clientIP := conn.LocalAddr().(*net.UDPAddr).IP
+	request := newPCPMapRequest(clientIP, nonce, protocolNumber, extPort, intPort, lifetime)
+
+	timeout := pcpFirstTimeout
+	response := make([]byte, 1100)
+	for i := 0; i < pcpRetries; i++ {
+		if _, err := conn.Write(request); err != nil {
+			return 0, errors.Wrap(err, "failed to send PCP request")
+		}
+
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		n, err := conn.Read(response)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			timeout *= 2
+			continue
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to read PCP response")
+		}
+		return parsePCPMapResponse(response[:n], nonce)
+	}
+	return 0, errors.New("PCP server did not respond")
+}
+
+func newPCPMapRequest(clientIP net.IP, nonce []byte, protocol byte, extPort, intPort int, lifetime time.Duration) []byte {
+	request := make([]byte, pcpRequestLen)
+	request[0] = pcpVersion
+	request[1] = pcpOpcodeMap
+	binary.BigEndian.PutUint32(request[4:8], uint32(lifetime/time.Second))
+	copy(request[8:24], clientIP.To16())
+
+	copy(request[24:36], nonce)
+	request[36] = protocol
+	binary.BigEndian.PutUint16(request[40:42], uint16(intPort))
+	binary.BigEndian.PutUint16(request[42:44], uint16(extPort))
+	// suggested external IP is left unspecified as the IPv4-mapped zero address
+	copy(request[44:60], net.IPv4zero.To16())
+	return request
+}
+
+func parsePCPMapResponse(response, nonce []byte) (int, error) {
+	if len(response) < pcpResponseLen {
+		return 0, errors.Errorf("PCP response too short: %d bytes", len(response))
+	}
+	if response[0] != pcpVersion {
+		return 0, errors.Errorf("unsupported PCP version: %d", response[0])
+	}
+	if response[1] != pcpOpcodeMap|pcpResponseBit {
+		return 0, errors.Errorf("unexpected PCP opcode: %d", response[1])
+	}
+	if result := response[3]; result != pcpResultSuccess {
+		return 0, errors.Errorf("PCP request failed: %s", pcpResultCode(result))
+	}
+	if !bytes.Equal(response[24:36], nonce) {
+		return 0, errors.New("PCP response nonce mismatch")
+	}
+	return int(binary.BigEndian.Uint16(response[42:44])), nil
+}
+
+func pcpResultCode(result byte) string {
+	if name, ok := pcpResultCodes[result]; ok {
+		return name
+	}
+	return fmt.Sprintf("result code %d", result)
+}
+
+func pcpProtocolNumber(protocol string) (byte, error) {
+	switch strings.ToUpper(protocol) {
+	case "TCP":
+		return 6, nil
+	case "UDP":
+		return 17, nil
+	}
+	return 0, errors.Errorf("unsupported protocol: %s", protocol)
+}
+
+func mappingKey(protocol string, intPort int) string {
+	return fmt.Sprintf("%s:%d", strings.ToUpper(protocol), intPort)
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pcpStandIn answers the MAP requests the way PCP server does
+type pcpStandIn struct {
+	conn *net.UDPConn
+
+	result       byte
+	assignedPort func(suggested int) int
+
+	requests []pcpMapRequest
+	mu       sync.Mutex
+}
+
+type pcpMapRequest struct {
+	lifetime uint32
+	protocol byte
+	intPort  int
+	extPort  int
+}
+
+func newPCPStandIn(t *testing.T, result byte, assignedPort func(suggested int) int) *pcpStandIn {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.NoError(t, err)
+
+	standIn := &pcpStandIn{
+		conn:         conn,
+		result:       result,
+		assignedPort: assignedPort,
+	}
+	go standIn.serve()
+	return standIn
+}
+
+func suggestedPort(suggested int) int {
+	return suggested
+}
+
+func (s *pcpStandIn) serve() {
+	buf := make([]byte, 1100)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n != pcpRequestLen {
+			continue
+		}
+
+		request := pcpMapRequest{
+			lifetime: binary.BigEndian.Uint32(buf[4:8]),
+			protocol: buf[36],
+			intPort:  int(binary.BigEndian.Uint16(buf[40:42])),
+			extPort:  int(binary.BigEndian.Uint16(buf[42:44])),
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, request)
+		s.mu.Unlock()
+
+		response := make([]byte, pcpResponseLen)
+		response[0] = pcpVersion
+		response[1] = pcpOpcodeMap | pcpResponseBit
+		response[3] = s.result
+		binary.BigEndian.PutUint32(response[4:8], request.lifetime)
+		copy(response[24:36], buf[24:36])
+		response[36] = request.protocol
+		binary.BigEndian.PutUint16(response[40:42], uint16(request.intPort))
+		binary.BigEndian.PutUint16(response[42:44], uint16(s.assignedPort(request.extPort)))
+		s.conn.WriteToUDP(response, addr)
+	}
+}
+
+func (s *pcpStandIn) client() *pcp {
+	client := newPCP(net.IPv4(127, 0, 0, 1))
+	client.gateway = s.conn.LocalAddr().(*net.UDPAddr)
+	return client
+}
+
+func (s *pcpStandIn) received() []pcpMapRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]pcpMapRequest(nil), s.requests...)
+}
+
+func TestPCP_AddMapping(t *testing.T) {
+	server := newPCPStandIn(t, pcpResultSuccess, suggestedPort)
+	defer server.conn.Close()
+
+	err := server.client().AddMapping("UDP", 40000, 40000, "test", 20*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []pcpMapRequest{{lifetime: 1200, protocol: 17, intPort: 40000, extPort: 40000}}, server.received())
+}
+
+func TestPCP_DeleteMappingReusesNonce(t *testing.T) {
+	server := newPCPStandIn(t, pcpResultSuccess, suggestedPort)
+	defer server.conn.Close()
+	client := server.client()
+
+	err := client.AddMapping("TCP", 40000, 40000, "test", 20*time.Minute)
+	assert.NoError(t, err)
+	nonce := client.nonces["TCP:40000"]
+
+	err = client.DeleteMapping("TCP", 40000, 40000)
+	assert.NoError(t, err)
+	assert.Equal(t, pcpMapRequest{lifetime: 0, protocol: 6, intPort: 40000, extPort: 0}, server.received()[1])
+	assert.Len(t, nonce, 12)
+	assert.Empty(t, client.nonces)
+}
+
+func TestPCP_AddMappingFailsWithResultCode(t *testing.T) {
+	server := newPCPStandIn(t, 2, suggestedPort)
+	defer server.conn.Close()
+
+	err := server.client().AddMapping("UDP", 40000, 40000, "test", 20*time.Minute)
+	assert.EqualError(t, err, "PCP request failed: NOT_AUTHORIZED")
+}
+
+func TestPCP_AddMappingFailsWhenOtherPortAssigned(t *testing.T) {
+	server := newPCPStandIn(t, pcpResultSuccess, func(suggested int) int {
+		if suggested == 0 {
+			return 0
+		}
+		return suggested + 1
+	})
+	defer server.conn.Close()
+
+	err := server.client().AddMapping("UDP", 40000, 40000, "test", 20*time.Minute)
+	assert.EqualError(t, err, "gateway assigned external port 40001 instead of 40000")
+	assert.Len(t, server.received(), 2)
+}
+
+func TestPCP_AddMappingFailsWithoutServer(t *testing.T) {
+	server := newPCPStandIn(t, pcpResultSuccess, suggestedPort)
+	client := server.client()
+	server.conn.Close()
+
+	err := client.AddMapping("UDP", 40000, 40000, "test", 20*time.Minute)
+	assert.Error(t, err)
+}
+
+func TestPCP_AddMappingFailsWithUnsupportedProtocol(t *testing.T) {
+	client := newPCP(net.IPv4(127, 0, 0, 1))
+
+	err := client.AddMapping("SCTP", 40000, 40000, "test", 20*time.Minute)
+	assert.EqualError(t, err, "unsupported protocol: SCTP")
+}
@@ -18,11 +18,16 @@
 package mapping
 
 import (
+	"net"
+	"sync"
 	"time"
 
 	log "github.com/cihub/seelog"
 	portmap "github.com/ethereum/go-ethereum/p2p/nat"
+	"github.com/jackpal/gateway"
+	"github.com/mysteriumnetwork/node/core/network"
 	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/pkg/errors"
 )
 
 const logPrefix = "[port mapping] "
@@ -32,14 +37,63 @@ const (
 	mapUpdateInterval = 15 * time.Minute
 )
 
-// StageName is used to indicate port mapping NAT traversal stage
-const StageName = "port_mapping"
+const (
+	// StageName is used to indicate port mapping NAT traversal stage, it fails when none of the methods managed to map the port
+	StageName = "port_mapping"
+	// StageUPnP is used to indicate port mapping attempt using UPnP IGD
+	StageUPnP = "port_mapping_upnp"
+	// StageNATPMP is used to indicate port mapping attempt using NAT-PMP
+	StageNATPMP = "port_mapping_natpmp"
+	// StagePCP is used to indicate port mapping attempt using PCP
+	StagePCP = "port_mapping_pcp"
+)
 
 // Publisher is responsible for publishing given events
 type Publisher interface {
 	Publish(topic string, data interface{})
 }
 
+// Backend maps the ports on the gateway using one of the port mapping protocols
+type Backend interface {
+	AddMapping(protocol string, extPort, intPort int, name string, lifetime time.Duration) error
+	DeleteMapping(protocol string, extPort, intPort int) error
+	String() string
+}
+
+// Method is the port mapping protocol tried on the gateway, its attempts are published as the NAT events of the stage
+type Method struct {
+	Stage   string
+	Backend func() (Backend, error)
+}
+
+// DefaultMethods returns the port mapping methods in the order they are tried: UPnP, NAT-PMP and PCP
+func DefaultMethods() []Method {
+	return []Method{
+		{Stage: StageUPnP, Backend: func() (Backend, error) {
+			return portmap.UPnP(), nil
+		}},
+		{Stage: StageNATPMP, Backend: func() (Backend, error) {
+			gw, err := discoverGateway()
+			if err != nil {
+				return nil, err
+			}
+			return portmap.PMP(gw), nil
+		}},
+		{Stage: StagePCP, Backend: func() (Backend, error) {
+			gw, err := discoverGateway()
+			if err != nil {
+				return nil, err
+			}
+			return newPCP(gw), nil
+		}},
+	}
+}
+
+func discoverGateway() (net.IP, error) {
+	gw, err := gateway.DiscoverGateway()
+	return gw, errors.Wrap(err, "failed to discover gateway")
+}
+
 // GetPortMappingFunc returns PortMapping function if service is behind NAT
 func GetPortMappingFunc(pubIP, outIP, protocol string, port int, description string, publisher Publisher) func() {
 	if pubIP != outIP {
@@ -52,7 +106,7 @@ func GetPortMappingFunc(pubIP, outIP, protocol string, port int, description str
 // 'name' denotes rule name added on a gateway.
 func PortMapping(protocol string, port int, name string, publisher Publisher) func() {
 	mapperQuit := make(chan struct{})
-	go mapPort(portmap.Any(),
+	go mapPort(DefaultMethods(),
 		mapperQuit,
 		protocol,
 		port,
@@ -63,36 +117,101 @@ func PortMapping(protocol string, port int, name string, publisher Publisher) fu
 	return func() { close(mapperQuit) }
 }
 
-// mapPort adds a port mapping on m and keeps it alive until c is closed.
+var networkChange = struct {
+	sync.Mutex
+	changed chan struct{}
+}{changed: make(chan struct{})}
+
+// HandleNetworkChangedEvent makes the running port mappings be added again using all the methods,
+// as the node may be behind another gateway now.
+func HandleNetworkChangedEvent(_ network.ChangedEvent) {
+	networkChange.Lock()
+	defer networkChange.Unlock()
+
+	close(networkChange.changed)
+	networkChange.changed = make(chan struct{})
+}
+
+func networkChanged() <-chan struct{} {
+	networkChange.Lock()
+	defer networkChange.Unlock()
+	return networkChange.changed
+}
+
+// mappedPort is the port mapping kept alive by the backend of the method
+type mappedPort struct {
+	stage   string
+	backend Backend
+}
+
+// mapPort adds a port mapping using the first working method and keeps it alive until c is closed.
 // This function is typically invoked in its own goroutine.
-func mapPort(m portmap.Interface, c chan struct{}, protocol string, extPort, intPort int, name string, publisher Publisher) {
+func mapPort(methods []Method, c chan struct{}, protocol string, extPort, intPort int, name string, publisher Publisher) {
+	var mapped *mappedPort
 	defer func() {
+		if mapped == nil {
+			return
+		}
 		log.Debug(logPrefix, "Deleting port mapping for port: ", extPort)
 
-		if err := m.DeleteMapping(protocol, extPort, intPort); err != nil {
+		if err := mapped.backend.DeleteMapping(protocol, extPort, intPort); err != nil {
 			log.Warn(logPrefix, "Couldn't delete port mapping: ", err)
 		}
 	}()
 	for {
-		addMapping(m, protocol, extPort, intPort, name, publisher)
+		changed := networkChanged()
+		mapped = renewMapping(methods, mapped, protocol, extPort, intPort, name, publisher)
 		select {
 		case <-c:
 			return
+		case <-changed:
+			log.Info(logPrefix, "Network changed, mapping port again: ", extPort)
+			// the mapping on the gateway of the previous network is not reachable anymore
+			mapped = nil
 		case <-time.After(mapUpdateInterval):
 		}
 	}
 }
 
-func addMapping(m portmap.Interface, protocol string, extPort, intPort int, name string, publisher Publisher) {
+// renewMapping renews the lease of the mapped port, all the methods are tried again if the renewal fails
+func renewMapping(methods []Method, mapped *mappedPort, protocol string, extPort, intPort int, name string, publisher Publisher) *mappedPort {
+	if mapped != nil {
+		err := addMapping(mapped.backend, protocol, extPort, intPort, name)
+		if err == nil {
+			publisher.Publish(event.Topic, event.BuildSuccessfulEvent(mapped.stage))
+			return mapped
+		}
+		publisher.Publish(event.Topic, event.BuildFailureEvent(mapped.stage, err))
+		log.Warnf("%s Couldn't renew port mapping for port %d using %v: %v", logPrefix, extPort, mapped.backend, err)
+	}
+
+	var lastErr = errors.New("no port mapping methods")
+	for _, method := range methods {
+		backend, err := method.Backend()
+		if err == nil {
+			err = addMapping(backend, protocol, extPort, intPort, name)
+		}
+		if err != nil {
+			publisher.Publish(event.Topic, event.BuildFailureEvent(method.Stage, err))
+			log.Warnf("%s Couldn't add port mapping for port %d using %s: %v", logPrefix, extPort, method.Stage, err)
+			lastErr = err
+			continue
+		}
+
+		publisher.Publish(event.Topic, event.BuildSuccessfulEvent(method.Stage))
+		log.Infof("%s Mapped network port %d using %v", logPrefix, extPort, backend)
+		return &mappedPort{stage: method.Stage, backend: backend}
+	}
+
+	publisher.Publish(event.Topic, event.BuildFailureEvent(StageName, lastErr))
+	return nil
+}
+
+func addMapping(m Backend, protocol string, extPort, intPort int, name string) error {
 	if err := m.AddMapping(protocol, extPort, intPort, name, mapTimeout); err != nil {
 		log.Warnf("%s Couldn't add port mapping for port %d: %v, retrying with permanent lease", logPrefix, extPort, err)
-		if err := m.AddMapping(protocol, extPort, intPort, name, 0); err != nil {
-			// some gateways support only permanent leases
-			publisher.Publish(event.Topic, event.BuildFailureEvent(StageName, err))
-			log.Warnf("%s Couldn't add port mapping for port %d: %v", logPrefix, extPort, err)
-			return
-		}
+		// some gateways support only permanent leases
+		return m.AddMapping(protocol, extPort, intPort, name, 0)
 	}
-	publisher.Publish(event.Topic, event.BuildSuccessfulEvent(StageName))
-	log.Info(logPrefix, "Mapped network port: ", extPort)
+	return nil
 }
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/network"
+	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBackend struct {
+	addErr  error
+	added   int
+	deleted int
+}
+
+func (mb *mockBackend) AddMapping(protocol string, extPort, intPort int, name string, lifetime time.Duration) error {
+	mb.added++
+	return mb.addErr
+}
+
+func (mb *mockBackend) DeleteMapping(protocol string, extPort, intPort int) error {
+	mb.deleted++
+	return nil
+}
+
+func (mb *mockBackend) String() string {
+	return "mock"
+}
+
+type mockPublisher struct {
+	events []event.Event
+}
+
+func (mp *mockPublisher) Publish(topic string, data interface{}) {
+	mp.events = append(mp.events, data.(event.Event))
+}
+
+func method(stage string, backend Backend) Method {
+	return Method{Stage: stage, Backend: func() (Backend, error) {
+		return backend, nil
+	}}
+}
+
+func Test_RenewMapping_TriesMethodsInTurn(t *testing.T) {
+	upnp := &mockBackend{addErr: errors.New("no UPnP gateway")}
+	natpmp := &mockBackend{}
+	pcp := &mockBackend{}
+	publisher := &mockPublisher{}
+
+	mapped := renewMapping([]Method{method(StageUPnP, upnp), method(StageNATPMP, natpmp), method(StagePCP, pcp)}, nil, "UDP", 1000, 1000, "test", publisher)
+
+	assert.Equal(t, &mappedPort{stage: StageNATPMP, backend: natpmp}, mapped)
+	assert.Equal(t, 0, pcp.added)
+	assert.Len(t, publisher.events, 2)
+	assert.Equal(t, StageUPnP, publisher.events[0].Stage)
+	assert.False(t, publisher.events[0].Successful)
+	assert.EqualError(t, publisher.events[0].Error, "no UPnP gateway")
+	assert.Equal(t, event.BuildSuccessfulEvent(StageNATPMP), publisher.events[1])
+}
+
+func Test_RenewMapping_FailsWhenNoMethodWorks(t *testing.T) {
+	publisher := &mockPublisher{}
+	failing := Method{Stage: StagePCP, Backend: func() (Backend, error) {
+		return nil, errors.New("no gateway")
+	}}
+
+	mapped := renewMapping([]Method{failing}, nil, "UDP", 1000, 1000, "test", publisher)
+
+	assert.Nil(t, mapped)
+	assert.Len(t, publisher.events, 2)
+	assert.Equal(t, StagePCP, publisher.events[0].Stage)
+	assert.Equal(t, StageName, publisher.events[1].Stage)
+	assert.False(t, publisher.events[1].Successful)
+	assert.EqualError(t, publisher.events[1].Error, "no gateway")
+}
+
+func Test_RenewMapping_RenewsMappedPort(t *testing.T) {
+	upnp := &mockBackend{}
+	pcp := &mockBackend{}
+	publisher := &mockPublisher{}
+
+	mapped := renewMapping([]Method{method(StageUPnP, upnp)}, &mappedPort{stage: StagePCP, backend: pcp}, "UDP", 1000, 1000, "test", publisher)
+
+	assert.Equal(t, &mappedPort{stage: StagePCP, backend: pcp}, mapped)
+	assert.Equal(t, 1, pcp.added)
+	assert.Equal(t, 0, upnp.added)
+	assert.Equal(t, []event.Event{event.BuildSuccessfulEvent(StagePCP)}, publisher.events)
+}
+
+func Test_RenewMapping_RetriesPermanentLease(t *testing.T) {
+	backend := &mockBackend{addErr: errors.New("only permanent leases")}
+	publisher := &mockPublisher{}
+
+	renewMapping([]Method{method(StageUPnP, backend)}, nil, "UDP", 1000, 1000, "test", publisher)
+
+	assert.Equal(t, 2, backend.added)
+}
+
+func Test_MapPort_DeletesMappingOnQuit(t *testing.T) {
+	backend := &mockBackend{}
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		mapPort([]Method{method(StageUPnP, backend)}, quit, "UDP", 1000, 1000, "test", &mockPublisher{})
+		close(done)
+	}()
+
+	close(quit)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("port mapping was not stopped")
+	}
+	assert.Equal(t, 1, backend.added)
+	assert.Equal(t, 1, backend.deleted)
+}
+
+func Test_MapPort_MapsPortAgainOnNetworkChange(t *testing.T) {
+	created := make(chan struct{}, 2)
+	methods := []Method{{Stage: StageUPnP, Backend: func() (Backend, error) {
+		created <- struct{}{}
+		return &mockBackend{}, nil
+	}}}
+	quit := make(chan struct{})
+	defer close(quit)
+	go mapPort(methods, quit, "UDP", 1000, 1000, "test", &mockPublisher{})
+
+	waitForMapping := func() {
+		select {
+		case <-created:
+		case <-time.After(time.Second):
+			t.Fatal("port was not mapped")
+		}
+	}
+	waitForMapping()
+	HandleNetworkChangedEvent(network.ChangedEvent{})
+	waitForMapping()
+}
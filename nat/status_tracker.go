@@ -19,10 +19,12 @@ package nat
 
 import (
 	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 )
 
 // StatusTracker keeps status of NAT traversal by consuming NAT events - whether if finished and was it successful.
 // It can finish either by successful event from any stage, or by a failure of the last stage.
+// Relaying of a single session tells nothing about the NAT of the provider, so its events are ignored.
 type StatusTracker struct {
 	lastStageName string
 	status        Status
@@ -34,10 +36,12 @@ const (
 	statusFailure     = "failure"
 )
 
-// Status represents NAT traversal status (either "not_finished", "successful" or "failure") and an optional error.
+// Status represents NAT traversal status (either "not_finished", "successful" or "failure"), an optional error
+// and the stage which made NAT traversal successful.
 type Status struct {
 	Status string
 	Error  error
+	Stage  string
 }
 
 // Status returns NAT traversal status
@@ -47,13 +51,17 @@ func (t *StatusTracker) Status() Status {
 
 // ConsumeNATEvent processes NAT event to determine NAT traversal status
 func (t *StatusTracker) ConsumeNATEvent(event event.Event) {
+	if event.Stage == traversal.RelayStageName {
+		return
+	}
+
 	if event.Stage == t.lastStageName && event.Successful == false {
 		t.status = Status{Status: statusFailure, Error: event.Error}
 		return
 	}
 
 	if event.Successful {
-		t.status = Status{Status: statusSuccessful}
+		t.status = Status{Status: statusSuccessful, Stage: event.Stage}
 		return
 	}
 
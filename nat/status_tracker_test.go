@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -40,6 +41,7 @@ func Test_StatusTracker_Status_ReturnsSuccessful_WithSuccessfulEvent(t *testing.
 
 	assert.Equal(t, "successful", status.Status)
 	assert.Nil(t, status.Error)
+	assert.Equal(t, "any stage", status.Stage)
 }
 
 func Test_StatusTracker_Status_ReturnsFailure_WithHolepunchingFailureEvent(t *testing.T) {
@@ -72,3 +74,16 @@ func Test_StatusTracker_Status_ReturnsNotFinished_AfterSuccess(t *testing.T) {
 	status = tracker.Status()
 	assert.Equal(t, "not_finished", status.Status)
 }
+
+func Test_StatusTracker_Status_IgnoresRelayEvents(t *testing.T) {
+	tracker := NewStatusTracker("last stage")
+	tracker.ConsumeNATEvent(event.Event{Successful: true, Stage: "any stage"})
+
+	tracker.ConsumeNATEvent(event.Event{Successful: false, Stage: traversal.RelayStageName, Error: errors.New("test error")})
+	status := tracker.Status()
+	assert.Equal(t, "successful", status.Status)
+	assert.Equal(t, "any stage", status.Stage)
+
+	tracker.ConsumeNATEvent(event.Event{Successful: true, Stage: traversal.RelayStageName})
+	assert.Equal(t, "any stage", tracker.Status().Stage)
+}
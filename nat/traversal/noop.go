@@ -46,6 +46,11 @@ func (np *NoopPinger) PingProvider(ip string, port int, consumerPort int, stop <
 	return nil
 }
 
+// RelayProvider does nothing
+func (np *NoopPinger) RelayProvider(relay RelayParams, consumerPort int, stop <-chan struct{}) error {
+	return nil
+}
+
 // PingTarget does nothing
 func (np *NoopPinger) PingTarget(*Params) {}
 
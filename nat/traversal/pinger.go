@@ -41,6 +41,7 @@ const pingTimeout = 10000
 var (
 	errNATPunchAttemptStopped  = errors.New("NAT punch attempt stopped")
 	errNATPunchAttemptTimedOut = errors.New("NAT punch attempt timed out")
+	errNATSymmetric            = errors.New("NAT punching skipped, node is behind symmetric NAT")
 )
 
 // Pinger represents NAT pinger structure
@@ -56,6 +57,8 @@ type Pinger struct {
 	portPool       PortSupplier
 	previousStage  string
 	eventPublisher Publisher
+	natType        NATType
+	natTypeLock    sync.RWMutex
 }
 
 // NatEventWaiter is responsible for waiting for nat events
@@ -94,6 +97,7 @@ func NewPinger(waiter NatEventWaiter, parser ConfigParser, proxy natProxy, previ
 		natProxy:       proxy,
 		previousStage:  previousStage,
 		eventPublisher: publisher,
+		natType:        NATTypeUnknown,
 	}
 }
 
@@ -110,7 +114,9 @@ type Params struct {
 	RequestConfig json.RawMessage
 	ProviderPort  int
 	ConsumerPort  int
-	Cancel        chan struct{}
+	// Relay is the relay both peers fall back to when hole punching fails, no fallback if nil
+	Relay  *RelayParams
+	Cancel chan struct{}
 }
 
 // Start starts NAT pinger and waits for PingTarget to ping
@@ -134,6 +140,19 @@ func isPunchingRequired(params *Params) bool {
 	return params.ConsumerPort > 0
 }
 
+// ConsumeNATTypeEvent keeps the detected NAT type, punching is skipped if the node is behind symmetric NAT
+func (p *Pinger) ConsumeNATTypeEvent(e NATTypeEvent) {
+	p.natTypeLock.Lock()
+	defer p.natTypeLock.Unlock()
+	p.natType = e.Type
+}
+
+func (p *Pinger) isBehindSymmetricNAT() bool {
+	p.natTypeLock.RLock()
+	defer p.natTypeLock.RUnlock()
+	return p.natType == NATTypeSymmetric
+}
+
 // Stop stops pinger loop
 func (p *Pinger) Stop() {
 	p.once.Do(func() {
@@ -146,6 +165,10 @@ func (p *Pinger) Stop() {
 func (p *Pinger) PingProvider(ip string, port int, consumerPort int, stop <-chan struct{}) error {
 	log.Info(prefix, "NAT pinging to provider")
 
+	if p.isBehindSymmetricNAT() {
+		return errNATSymmetric
+	}
+
 	conn, err := p.getConnection(ip, port, consumerPort)
 	if err != nil {
 		return errors.Wrap(err, "failed to get connection")
@@ -161,6 +184,7 @@ func (p *Pinger) PingProvider(ip string, port int, consumerPort int, stop <-chan
 	time.Sleep(pingInterval * time.Millisecond)
 	err = p.pingReceiver(conn, stop)
 	if err != nil {
+		conn.Close()
 		return err
 	}
 
@@ -180,6 +204,29 @@ func (p *Pinger) PingProvider(ip string, port int, consumerPort int, stop <-chan
 	return nil
 }
 
+// RelayProvider joins provider at the relay, consumer falls back to it when NAT pinging to provider fails
+func (p *Pinger) RelayProvider(relay RelayParams, consumerPort int, stop <-chan struct{}) error {
+	log.Info(prefix, "joining provider at relay: ", relay.Address)
+
+	conn, err := dialRelay(relay.Address, consumerPort)
+	if err != nil {
+		return errors.Wrap(err, "failed to get relay connection")
+	}
+
+	err = joinRelay(conn, relay, pingTimeout*time.Millisecond, stop)
+	if err != nil {
+		conn.Close()
+		p.eventPublisher.Publish(event.Topic, event.BuildFailureEvent(RelayStageName, err))
+		return err
+	}
+	p.eventPublisher.Publish(event.Topic, event.BuildSuccessfulEvent(RelayStageName))
+
+	consumerAddr := fmt.Sprintf("127.0.0.1:%d", consumerPort+1)
+	log.Info(prefix, "Handing relay connection to consumer NATProxy: ", consumerAddr)
+	p.stopNATProxy = p.natProxy.consumerHandOff(consumerAddr, conn)
+	return nil
+}
+
 func (p *Pinger) waitForPreviousStageResult() bool {
 	for {
 		event := p.natEventWaiter.WaitForEvent()
@@ -288,7 +335,14 @@ func (p *Pinger) pingReceiver(conn *net.UDPConn, stop <-chan struct{}) error {
 		default:
 		}
 
+		// read with deadline, otherwise the timeout and the stop are not noticed while nothing is received
+		if err := conn.SetReadDeadline(time.Now().Add(pingInterval * time.Millisecond)); err != nil {
+			return errors.Wrap(err, "failed to set read deadline")
+		}
 		n, err := conn.Read(buf)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			continue
+		}
 		if err != nil {
 			log.Errorf("%sfailed to read remote peer: %s cause: %s - attempting to continue", prefix, conn.RemoteAddr().String(), err)
 			continue
@@ -296,7 +350,7 @@ func (p *Pinger) pingReceiver(conn *net.UDPConn, stop <-chan struct{}) error {
 
 		if n > 0 {
 			log.Infof("%sremote peer data received: %s, len: %d", prefix, string(buf[:n]), n)
-			return nil
+			return conn.SetReadDeadline(time.Time{})
 		}
 	}
 }
@@ -332,6 +386,13 @@ func (p *Pinger) pingTargetConsumer(pingParams *Params) {
 		return
 	}
 
+	if p.isBehindSymmetricNAT() {
+		log.Info(prefix, "behind symmetric NAT, not pinging consumer")
+		p.eventPublisher.Publish(event.Topic, event.BuildFailureEvent(StageName, errNATSymmetric))
+		p.relayTargetConsumer(pingParams, serviceType)
+		return
+	}
+
 	conn, err := p.getConnection(IP, pingParams.ConsumerPort, pingParams.ProviderPort)
 	if err != nil {
 		log.Error(prefix, "failed to get connection: ", err)
@@ -348,6 +409,10 @@ func (p *Pinger) pingTargetConsumer(pingParams *Params) {
 	err = p.pingReceiver(conn, pingParams.Cancel)
 	if err != nil {
 		log.Error(prefix, "ping receiver error: ", err)
+		conn.Close()
+		if err == errNATPunchAttemptTimedOut {
+			p.relayTargetConsumer(pingParams, serviceType)
+		}
 		return
 	}
 
@@ -359,3 +424,29 @@ func (p *Pinger) pingTargetConsumer(pingParams *Params) {
 
 	go p.natProxy.handOff(serviceType, conn)
 }
+
+// relayTargetConsumer joins consumer at the relay and hands the relayed connection off to the service
+func (p *Pinger) relayTargetConsumer(pingParams *Params, serviceType services.ServiceType) {
+	if pingParams.Relay == nil {
+		return
+	}
+	log.Info(prefix, "joining consumer at relay: ", pingParams.Relay.Address)
+
+	conn, err := dialRelay(pingParams.Relay.Address, 0)
+	if err != nil {
+		log.Error(prefix, "failed to get relay connection: ", err)
+		return
+	}
+
+	err = joinRelay(conn, *pingParams.Relay, pingTimeout*time.Millisecond, pingParams.Cancel)
+	if err != nil {
+		log.Error(prefix, "failed to join relay: ", err)
+		conn.Close()
+		p.eventPublisher.Publish(event.Topic, event.BuildFailureEvent(RelayStageName, err))
+		return
+	}
+	p.eventPublisher.Publish(event.Topic, event.BuildSuccessfulEvent(RelayStageName))
+
+	log.Info(prefix, "relay joined, handing off the relayed connection")
+	go p.natProxy.handOff(serviceType, conn)
+}
@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinger_PingProviderSkippedBehindSymmetricNAT(t *testing.T) {
+	pinger := NewPinger(nil, nil, nil, "", &mockPublisher{})
+	pinger.ConsumeNATTypeEvent(NATTypeEvent{Type: NATTypeSymmetric})
+
+	err := pinger.PingProvider("127.0.0.1", 1, 0, make(chan struct{}))
+	assert.Equal(t, errNATSymmetric, err)
+}
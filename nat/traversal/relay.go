@@ -0,0 +1,430 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/pkg/errors"
+)
+
+// RelayStageName represents relaying stage of NAT traversal, the peers fall back to it once hole punching fails
+const RelayStageName = "relay"
+
+const (
+	relayPrefix      = "[NATRelay] "
+	relayJoinMessage = "MYST-RELAY-JOIN "
+	relayJoinedReply = "MYST-RELAY-JOINED"
+	relayIdleTimeout = 5 * time.Minute
+	relayTicketTTL   = 5 * time.Minute
+	relayBufferLen   = 64 * 1024
+	// relayMaxPairs limits the pairs the relay keeps at once, joins of the new pairs are ignored above it
+	relayMaxPairs = 1024
+	// relayMaxVerificationsPerSecond limits the ticket signature checks, joins of the new pairs are ignored above it
+	relayMaxVerificationsPerSecond = 100
+)
+
+var (
+	errRelayJoinTimedOut  = errors.New("relay join timed out")
+	errRelayTicketExpired = errors.New("relay ticket expired")
+	errRelayTooManyPairs  = errors.New("too many relayed pairs")
+	errRelayTooManyJoins  = errors.New("too many relay joins")
+	errRelayNotRegistered = errors.New("relay ticket signer is not a registered provider")
+)
+
+// IdentityRegistry tells whether the identity is registered, the relay serves only the sessions of registered providers
+type IdentityRegistry interface {
+	IsRegistered(id identity.Identity) (bool, error)
+}
+
+// RelayParams describes the relay both peers of the session meet at and the ticket they join it with.
+// The ticket is signed by the provider of the session, the relay pairs only the peers presenting the same valid ticket.
+type RelayParams struct {
+	Address   string `json:"address"`
+	Token     string `json:"token"`
+	Expires   int64  `json:"expires"`
+	Signature string `json:"signature"`
+}
+
+// RelayFinder finds the address of the relay to fall back to when NAT hole punching fails
+type RelayFinder func() (address string, err error)
+
+// NewRelayParams returns params for relaying through the given relay with a new random token signed by the provider
+func NewRelayParams(address string, signer identity.Signer) (*RelayParams, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, errors.Wrap(err, "failed to generate relay token")
+	}
+
+	params := &RelayParams{
+		Address: address,
+		Token:   hex.EncodeToString(token),
+		Expires: time.Now().Add(relayTicketTTL).Unix(),
+	}
+	signature, err := signer.Sign(params.ticket())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign relay ticket")
+	}
+	params.Signature = signature.Base64()
+	return params, nil
+}
+
+// ticket returns the signed part of the params
+func (params RelayParams) ticket() []byte {
+	return []byte(fmt.Sprintf("%s %d", params.Token, params.Expires))
+}
+
+func (params RelayParams) joinMessage() []byte {
+	return []byte(fmt.Sprintf("%s%s %d %s", relayJoinMessage, params.Token, params.Expires, params.Signature))
+}
+
+func parseJoinMessage(message []byte) (RelayParams, error) {
+	fields := strings.Fields(string(message[len(relayJoinMessage):]))
+	if len(fields) != 3 {
+		return RelayParams{}, errors.New("malformed relay join")
+	}
+	expires, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return RelayParams{}, errors.Wrap(err, "malformed relay ticket expiration")
+	}
+	return RelayParams{Token: fields[0], Expires: expires, Signature: fields[2]}, nil
+}
+
+// Relay forwards UDP datagrams between the peers which can't reach each other directly.
+// Both peers join the relay with the same ticket, afterwards the datagrams of one of them are forwarded to the other.
+type Relay struct {
+	address   string
+	extractor identity.Extractor
+	registry  IdentityRegistry
+	conn      *net.UDPConn
+
+	pairs              map[string]*relayPair
+	peers              map[string]*relayPair
+	verifications      int
+	verificationsSince time.Time
+	mu                 sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type relayPair struct {
+	ticket   RelayParams
+	provider identity.Identity
+	peers    []*net.UDPAddr
+	lastSeen time.Time
+}
+
+func (pair *relayPair) has(addr *net.UDPAddr) bool {
+	for _, peer := range pair.peers {
+		if peer.String() == addr.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func (pair *relayPair) other(addr *net.UDPAddr) *net.UDPAddr {
+	if len(pair.peers) < 2 {
+		return nil
+	}
+	if pair.peers[0].String() == addr.String() {
+		return pair.peers[1]
+	}
+	return pair.peers[0]
+}
+
+// NewRelay creates relay listening on the given UDP address, the ticket signers are recovered with the given extractor
+// and have to be registered in the given identity registry
+func NewRelay(address string, extractor identity.Extractor, registry IdentityRegistry) *Relay {
+	return &Relay{
+		address:   address,
+		extractor: extractor,
+		registry:  registry,
+		pairs:     make(map[string]*relayPair),
+		peers:     make(map[string]*relayPair),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start starts listening for the peers
+func (r *Relay) Start() error {
+	addr, err := net.ResolveUDPAddr("udp4", r.address)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve relay address")
+	}
+	r.conn, err = net.ListenUDP("udp4", addr)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for relayed peers")
+	}
+
+	log.Info(relayPrefix, "relaying on ", r.conn.LocalAddr())
+	go r.serve()
+	go r.expireLoop()
+	return nil
+}
+
+// Stop stops relaying
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		if r.conn != nil {
+			r.conn.Close()
+		}
+	})
+}
+
+// Addr returns the address the relay listens on
+func (r *Relay) Addr() *net.UDPAddr {
+	return r.conn.LocalAddr().(*net.UDPAddr)
+}
+
+func (r *Relay) serve() {
+	buf := make([]byte, relayBufferLen)
+	for {
+		n, addr, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-r.stop:
+				return
+			default:
+			}
+			log.Warn(relayPrefix, "failed to read from peer: ", err)
+			continue
+		}
+
+		if bytes.HasPrefix(buf[:n], []byte(relayJoinMessage)) {
+			// the registration of the ticket signer is looked up remotely, forwarding must not wait for it
+			go r.join(append([]byte(nil), buf[:n]...), addr)
+			continue
+		}
+		r.forward(buf[:n], addr)
+	}
+}
+
+// join pairs the peer with the one joined with the same ticket. The peers are told about joining
+// once the pair is complete, the repeated joins of the complete pair are only answered to the joining peer.
+// The ticket is verified once the first peer of the pair joins, the peer can't be a part of several pairs.
+func (r *Relay) join(message []byte, addr *net.UDPAddr) {
+	params, err := parseJoinMessage(message)
+	if err != nil {
+		log.Warn(relayPrefix, "ignoring join of peer ", addr, ": ", err)
+		return
+	}
+
+	r.mu.Lock()
+	var verified *relayPair
+	if _, ok := r.pairs[params.Token]; !ok {
+		verified, err = r.newPair(params, time.Now())
+	}
+	r.mu.Unlock()
+	if err == nil && verified != nil {
+		err = r.checkProvider(verified.provider)
+	}
+	if err != nil {
+		log.Warn(relayPrefix, "ignoring join of peer ", addr, ": ", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if joined, ok := r.peers[addr.String()]; ok && joined.ticket.Token != params.Token {
+		log.Warn(relayPrefix, "peer ", addr, " is already relayed in another pair, ignoring")
+		return
+	}
+
+	pair, ok := r.pairs[params.Token]
+	if !ok {
+		if verified == nil {
+			log.Warn(relayPrefix, "pair of peer ", addr, " expired while joining, ignoring")
+			return
+		}
+		if len(r.pairs) >= relayMaxPairs {
+			log.Warn(relayPrefix, "ignoring join of peer ", addr, ": ", errRelayTooManyPairs)
+			return
+		}
+		pair = verified
+		r.pairs[params.Token] = pair
+	} else if pair.ticket != params {
+		log.Warn(relayPrefix, "ticket of peer ", addr, " does not match its pair, ignoring")
+		return
+	}
+	pair.lastSeen = time.Now()
+
+	if pair.has(addr) {
+		if len(pair.peers) == 2 {
+			r.reply(addr)
+		}
+		return
+	}
+	if len(pair.peers) == 2 {
+		log.Warn(relayPrefix, "relay pair is complete, ignoring peer ", addr)
+		return
+	}
+
+	pair.peers = append(pair.peers, addr)
+	r.peers[addr.String()] = pair
+	if len(pair.peers) == 2 {
+		log.Infof("%srelaying between %s and %s for provider %s", relayPrefix, pair.peers[0], pair.peers[1], pair.provider.Address)
+		for _, peer := range pair.peers {
+			r.reply(peer)
+		}
+	}
+}
+
+// newPair verifies the ticket of the first peer joining the pair, it has to be called holding the lock.
+// The registration of the signer is checked separately, as it takes a remote call
+func (r *Relay) newPair(params RelayParams, now time.Time) (*relayPair, error) {
+	if len(r.pairs) >= relayMaxPairs {
+		return nil, errRelayTooManyPairs
+	}
+	if now.Unix() > params.Expires {
+		return nil, errRelayTicketExpired
+	}
+
+	if now.Sub(r.verificationsSince) >= time.Second {
+		r.verificationsSince = now
+		r.verifications = 0
+	}
+	if r.verifications >= relayMaxVerificationsPerSecond {
+		return nil, errRelayTooManyJoins
+	}
+	r.verifications++
+
+	provider, err := r.extractor.Extract(params.ticket(), identity.SignatureBase64(params.Signature))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid relay ticket signature")
+	}
+	return &relayPair{ticket: params, provider: provider}, nil
+}
+
+// checkProvider tells whether the ticket signer is a registered provider, it is called without holding the lock
+func (r *Relay) checkProvider(provider identity.Identity) error {
+	registered, err := r.registry.IsRegistered(provider)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check registration of %s", provider.Address)
+	}
+	if !registered {
+		return errRelayNotRegistered
+	}
+	return nil
+}
+
+func (r *Relay) reply(addr *net.UDPAddr) {
+	if _, err := r.conn.WriteToUDP([]byte(relayJoinedReply), addr); err != nil {
+		log.Warn(relayPrefix, "failed to reply to peer: ", err)
+	}
+}
+
+func (r *Relay) forward(data []byte, from *net.UDPAddr) {
+	r.mu.Lock()
+	var to *net.UDPAddr
+	if pair, ok := r.peers[from.String()]; ok {
+		to = pair.other(from)
+		pair.lastSeen = time.Now()
+	}
+	r.mu.Unlock()
+
+	if to == nil {
+		return
+	}
+	if _, err := r.conn.WriteToUDP(data, to); err != nil {
+		log.Warn(relayPrefix, "failed to forward to peer: ", err)
+	}
+}
+
+func (r *Relay) expireLoop() {
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-time.After(relayIdleTimeout / 2):
+			r.expire(time.Now().Add(-relayIdleTimeout))
+		}
+	}
+}
+
+// expire forgets the pairs idle since the given deadline
+func (r *Relay) expire(deadline time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for token, pair := range r.pairs {
+		if pair.lastSeen.After(deadline) {
+			continue
+		}
+		for _, peer := range pair.peers {
+			if r.peers[peer.String()] == pair {
+				delete(r.peers, peer.String())
+			}
+		}
+		delete(r.pairs, token)
+	}
+}
+
+// dialRelay opens the connection to the relay from the given local port, any free port is used if it is 0
+func dialRelay(address string, localPort int) (*net.UDPConn, error) {
+	relayAddr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve relay address")
+	}
+	return net.DialUDP("udp4", &net.UDPAddr{Port: localPort}, relayAddr)
+}
+
+// joinRelay repeats joining the relay until it replies that the other peer has joined too
+func joinRelay(conn *net.UDPConn, relay RelayParams, timeout time.Duration, stop <-chan struct{}) error {
+	join := relay.joinMessage()
+	buf := make([]byte, len(relayJoinedReply))
+	deadline := time.Now().Add(timeout)
+	defer conn.SetReadDeadline(time.Time{})
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-stop:
+			return errNATPunchAttemptStopped
+		default:
+		}
+
+		if _, err := conn.Write(join); err != nil {
+			return errors.Wrap(err, "failed to join relay")
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(pingInterval * time.Millisecond)); err != nil {
+			return err
+		}
+		n, err := conn.Read(buf)
+		if err == nil && string(buf[:n]) == relayJoinedReply {
+			return nil
+		}
+		if netErr, ok := err.(net.Error); err != nil && (!ok || !netErr.Timeout()) {
+			// e.g. the relay is not reachable yet, do not flood it
+			time.Sleep(pingInterval * time.Millisecond)
+		}
+	}
+	return errRelayJoinTimedOut
+}
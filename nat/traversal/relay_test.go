@@ -0,0 +1,249 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var relayProvider = identity.FromAddress("0x1")
+
+// signerFake signs the message by prefixing it with the address of the signer
+type signerFake struct {
+	address string
+}
+
+func (signer signerFake) Sign(message []byte) (identity.Signature, error) {
+	return identity.SignatureBytes(append([]byte(signer.address+" "), message...)), nil
+}
+
+// extractorFake recovers the signer from the signatures of signerFake
+type extractorFake struct{}
+
+func (extractorFake) Extract(message []byte, signature identity.Signature) (identity.Identity, error) {
+	parts := bytes.SplitN(signature.Bytes(), []byte(" "), 2)
+	if len(parts) != 2 || !bytes.Equal(parts[1], message) {
+		return identity.Identity{}, errors.New("invalid signature")
+	}
+	return identity.FromAddress(string(parts[0])), nil
+}
+
+// registryFake knows only the relay provider as registered
+type registryFake struct{}
+
+func (registryFake) IsRegistered(id identity.Identity) (bool, error) {
+	return id == relayProvider, nil
+}
+
+func startRelay(t *testing.T) *Relay {
+	relay := NewRelay("127.0.0.1:0", extractorFake{}, registryFake{})
+	assert.NoError(t, relay.Start())
+	return relay
+}
+
+func relayParams(t *testing.T, relay *Relay) RelayParams {
+	params, err := NewRelayParams(relay.Addr().String(), signerFake{address: relayProvider.Address})
+	assert.NoError(t, err)
+	return *params
+}
+
+func pairCount(relay *Relay) int {
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	return len(relay.pairs)
+}
+
+func joinPeer(t *testing.T, params RelayParams, joined chan<- *net.UDPConn) {
+	conn, err := dialRelay(params.Address, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, joinRelay(conn, params, time.Second, make(chan struct{})))
+	joined <- conn
+}
+
+func read(t *testing.T, conn *net.UDPConn) string {
+	buf := make([]byte, 100)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := conn.Read(buf)
+	assert.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestRelay_ForwardsBetweenJoinedPeers(t *testing.T) {
+	relay := startRelay(t)
+	defer relay.Stop()
+
+	params := relayParams(t, relay)
+	joined := make(chan *net.UDPConn, 2)
+	go joinPeer(t, params, joined)
+	go joinPeer(t, params, joined)
+	provider, consumer := <-joined, <-joined
+	defer provider.Close()
+	defer consumer.Close()
+
+	_, err := consumer.Write([]byte("ping"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", read(t, provider))
+
+	_, err = provider.Write([]byte("pong"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", read(t, consumer))
+}
+
+func TestRelay_DoesNotForwardBetweenDifferentTickets(t *testing.T) {
+	relay := startRelay(t)
+	defer relay.Stop()
+
+	first, err := dialRelay(relay.Addr().String(), 0)
+	assert.NoError(t, err)
+	defer first.Close()
+	second, err := dialRelay(relay.Addr().String(), 0)
+	assert.NoError(t, err)
+	defer second.Close()
+
+	firstParams, secondParams := relayParams(t, relay), relayParams(t, relay)
+	errs := make(chan error, 2)
+	go func() { errs <- joinRelay(first, firstParams, 500*time.Millisecond, make(chan struct{})) }()
+	go func() { errs <- joinRelay(second, secondParams, 500*time.Millisecond, make(chan struct{})) }()
+
+	assert.Equal(t, errRelayJoinTimedOut, <-errs)
+	assert.Equal(t, errRelayJoinTimedOut, <-errs)
+}
+
+func TestRelay_IgnoresUnsignedTickets(t *testing.T) {
+	relay := startRelay(t)
+	defer relay.Stop()
+
+	params := relayParams(t, relay)
+	params.Signature = "forged"
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		conn, err := dialRelay(params.Address, 0)
+		assert.NoError(t, err)
+		defer conn.Close()
+		go func() { errs <- joinRelay(conn, params, 500*time.Millisecond, make(chan struct{})) }()
+	}
+
+	assert.Equal(t, errRelayJoinTimedOut, <-errs)
+	assert.Equal(t, errRelayJoinTimedOut, <-errs)
+	assert.Equal(t, 0, pairCount(relay))
+}
+
+func TestRelay_IgnoresTicketsSignedByUnknownKeys(t *testing.T) {
+	relay := startRelay(t)
+	defer relay.Stop()
+
+	params, err := NewRelayParams(relay.Addr().String(), signerFake{address: "0xunknown"})
+	assert.NoError(t, err)
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		conn, err := dialRelay(params.Address, 0)
+		assert.NoError(t, err)
+		defer conn.Close()
+		go func() { errs <- joinRelay(conn, *params, 500*time.Millisecond, make(chan struct{})) }()
+	}
+
+	assert.Equal(t, errRelayJoinTimedOut, <-errs)
+	assert.Equal(t, errRelayJoinTimedOut, <-errs)
+	assert.Equal(t, 0, pairCount(relay))
+	assert.Equal(t, errRelayNotRegistered, relay.checkProvider(identity.FromAddress("0xunknown")))
+}
+
+func TestRelay_IgnoresExpiredTickets(t *testing.T) {
+	relay := NewRelay("127.0.0.1:0", extractorFake{}, registryFake{})
+	params := RelayParams{Token: "token", Expires: time.Now().Add(-time.Second).Unix()}
+
+	_, err := relay.newPair(params, time.Now())
+	assert.Equal(t, errRelayTicketExpired, err)
+}
+
+func TestRelay_LimitsPairsAndVerifications(t *testing.T) {
+	relay := NewRelay("127.0.0.1:0", extractorFake{}, registryFake{})
+	params := RelayParams{Token: "token", Expires: time.Now().Add(time.Minute).Unix()}
+	now := time.Now()
+
+	for i := 0; i < relayMaxVerificationsPerSecond; i++ {
+		_, err := relay.newPair(params, now)
+		assert.Error(t, err)
+		assert.NotEqual(t, errRelayTooManyJoins, err)
+	}
+	_, err := relay.newPair(params, now)
+	assert.Equal(t, errRelayTooManyJoins, err)
+
+	for i := 0; i < relayMaxPairs; i++ {
+		relay.pairs[strconv.Itoa(i)] = &relayPair{}
+	}
+	_, err = relay.newPair(params, now.Add(time.Second))
+	assert.Equal(t, errRelayTooManyPairs, err)
+}
+
+func TestRelay_KeepsPeersOfOtherPairsOnExpire(t *testing.T) {
+	relay := NewRelay("127.0.0.1:0", extractorFake{}, registryFake{})
+	peer := &net.UDPAddr{IP: net.IPv4(1, 1, 1, 1), Port: 1000}
+	idle := &relayPair{peers: []*net.UDPAddr{peer}, lastSeen: time.Now().Add(-time.Hour)}
+	active := &relayPair{peers: []*net.UDPAddr{peer}, lastSeen: time.Now()}
+	relay.pairs["idle"] = idle
+	relay.pairs["active"] = active
+	relay.peers[peer.String()] = active
+
+	relay.expire(time.Now().Add(-time.Minute))
+	assert.Len(t, relay.pairs, 1)
+	assert.Equal(t, active, relay.peers[peer.String()])
+}
+
+func TestRelay_ExpiresIdlePairs(t *testing.T) {
+	relay := startRelay(t)
+	defer relay.Stop()
+
+	params := relayParams(t, relay)
+	joined := make(chan *net.UDPConn, 2)
+	go joinPeer(t, params, joined)
+	go joinPeer(t, params, joined)
+	provider, consumer := <-joined, <-joined
+	defer provider.Close()
+	defer consumer.Close()
+
+	relay.expire(time.Now().Add(-time.Minute))
+	assert.Equal(t, 1, pairCount(relay))
+
+	relay.expire(time.Now().Add(time.Minute))
+	assert.Equal(t, 0, pairCount(relay))
+	relay.mu.Lock()
+	assert.Len(t, relay.peers, 0)
+	relay.mu.Unlock()
+}
+
+func TestJoinRelay_Stops(t *testing.T) {
+	relay := startRelay(t)
+	defer relay.Stop()
+
+	conn, err := dialRelay(relay.Addr().String(), 0)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	stop := make(chan struct{})
+	close(stop)
+	assert.Equal(t, errNATPunchAttemptStopped, joinRelay(conn, relayParams(t, relay), time.Second, stop))
+}
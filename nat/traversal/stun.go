@@ -0,0 +1,346 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/core/network"
+	"github.com/pkg/errors"
+)
+
+// NATType represents the kind of NAT the node is behind
+type NATType string
+
+const (
+	// NATTypeUnknown means the NAT type wasn't detected yet or the detection failed
+	NATTypeUnknown NATType = "unknown"
+	// NATTypeNone means the node has a public address and isn't behind NAT
+	NATTypeNone NATType = "none"
+	// NATTypeFullCone means any remote host can reach the mapped address
+	NATTypeFullCone NATType = "full_cone"
+	// NATTypeRestricted means only the hosts contacted before can reach the mapped address
+	NATTypeRestricted NATType = "restricted"
+	// NATTypePortRestricted means only the host and port contacted before can reach the mapped address
+	NATTypePortRestricted NATType = "port_restricted"
+	// NATTypeSymmetric means every remote host is given a different mapped address, hole punching doesn't work
+	NATTypeSymmetric NATType = "symmetric"
+)
+
+// NATTypeTopic is the topic detected NAT types are published on
+const NATTypeTopic = "NAT type"
+
+// NATTypeEvent represents the result of NAT type detection, the error is kept as text so the event can be serialized
+type NATTypeEvent struct {
+	Type  NATType `json:"type"`
+	Error string  `json:"error,omitempty"`
+}
+
+// DefaultSTUNServers are the public STUN servers used for NAT type detection.
+// The first one has to support CHANGE-REQUEST to tell the cone NAT types apart.
+var DefaultSTUNServers = []string{
+	"stun.stunprotocol.org:3478",
+	"stun.l.google.com:19302",
+}
+
+const (
+	classifierPrefix = "[NATClassifier] "
+
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMagicCookie     = 0x2112A442
+	stunHeaderLen       = 20
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrChangeRequest    = 0x0003
+	stunAttrXorMappedAddress = 0x0020
+
+	stunChangeIP   = 0x04
+	stunChangePort = 0x02
+
+	stunFamilyIPv4 = 0x01
+
+	stunAttempts       = 3
+	stunDefaultTimeout = 3 * time.Second
+)
+
+var errSTUNMalformedResponse = errors.New("malformed STUN response")
+
+// Classifier detects the NAT type of the node by sending STUN binding requests to two STUN servers
+type Classifier struct {
+	servers   []string
+	publisher Publisher
+	timeout   time.Duration
+	localIPs  func() ([]net.IP, error)
+
+	natType NATType
+	mu      sync.RWMutex
+}
+
+// NewClassifier returns NAT classifier using the given STUN servers
+func NewClassifier(servers []string, publisher Publisher) *Classifier {
+	return &Classifier{
+		servers:   servers,
+		publisher: publisher,
+		timeout:   stunDefaultTimeout,
+		localIPs:  interfaceIPs,
+		natType:   NATTypeUnknown,
+	}
+}
+
+// NATType returns the last detected NAT type
+func (c *Classifier) NATType() NATType {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.natType
+}
+
+// Detect detects the NAT type and publishes it
+func (c *Classifier) Detect() (NATType, error) {
+	natType, err := c.classify()
+	if err != nil {
+		log.Warn(classifierPrefix, "NAT type detection failed: ", err)
+	} else {
+		log.Info(classifierPrefix, "NAT type detected: ", natType)
+	}
+
+	c.mu.Lock()
+	c.natType = natType
+	c.mu.Unlock()
+
+	event := NATTypeEvent{Type: natType}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	c.publisher.Publish(NATTypeTopic, event)
+	return natType, err
+}
+
+// HandleNetworkChangedEvent detects the NAT type again, the node may be behind another NAT in the new network
+func (c *Classifier) HandleNetworkChangedEvent(_ network.ChangedEvent) {
+	c.Detect()
+}
+
+// classify follows the RFC 3489 algorithm, except that the mapping behaviour is checked against a second server
+// instead of the alternate address of the first one, which a lot of STUN servers don't have
+func (c *Classifier) classify() (NATType, error) {
+	if len(c.servers) < 2 {
+		return NATTypeUnknown, errors.New("two STUN servers are required for NAT type detection")
+	}
+
+	primary, err := net.ResolveUDPAddr("udp4", c.servers[0])
+	if err != nil {
+		return NATTypeUnknown, errors.Wrap(err, "failed to resolve STUN server")
+	}
+	secondary, err := net.ResolveUDPAddr("udp4", c.servers[1])
+	if err != nil {
+		return NATTypeUnknown, errors.Wrap(err, "failed to resolve STUN server")
+	}
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return NATTypeUnknown, errors.Wrap(err, "failed to open STUN socket")
+	}
+	defer conn.Close()
+
+	mapped, err := c.bindingRequest(conn, primary, 0)
+	if err != nil {
+		return NATTypeUnknown, errors.Wrap(err, "STUN server did not respond")
+	}
+	if c.isLocal(mapped, conn.LocalAddr().(*net.UDPAddr).Port) {
+		return NATTypeNone, nil
+	}
+
+	mappedSecondary, err := c.bindingRequest(conn, secondary, 0)
+	if err != nil {
+		return NATTypeUnknown, errors.Wrap(err, "STUN server did not respond")
+	}
+	if !mapped.IP.Equal(mappedSecondary.IP) || mapped.Port != mappedSecondary.Port {
+		return NATTypeSymmetric, nil
+	}
+
+	if _, err := c.bindingRequest(conn, primary, stunChangeIP|stunChangePort); err == nil {
+		return NATTypeFullCone, nil
+	}
+	if _, err := c.bindingRequest(conn, primary, stunChangePort); err == nil {
+		return NATTypeRestricted, nil
+	}
+	return NATTypePortRestricted, nil
+}
+
+// bindingRequest sends the binding request to the server and returns the mapped address of the response.
+// With the change flags set the response comes from another address, so the response of any source is accepted.
+func (c *Classifier) bindingRequest(conn *net.UDPConn, server *net.UDPAddr, change uint32) (*net.UDPAddr, error) {
+	transactionID, request, err := newBindingRequest(change)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1500)
+	for attempt := 0; attempt < stunAttempts; attempt++ {
+		if _, err := conn.WriteToUDP(request, server); err != nil {
+			return nil, errors.Wrap(err, "failed to send STUN request")
+		}
+
+		deadline := time.Now().Add(c.timeout / stunAttempts)
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+					break
+				}
+				return nil, errors.Wrap(err, "failed to read STUN response")
+			}
+
+			mapped, err := parseBindingResponse(buf[:n], transactionID)
+			if err != nil {
+				// late responses of the previous requests or garbage
+				continue
+			}
+			return mapped, nil
+		}
+	}
+	return nil, errors.New("STUN request timed out")
+}
+
+func (c *Classifier) isLocal(mapped *net.UDPAddr, localPort int) bool {
+	if mapped.Port != localPort {
+		return false
+	}
+
+	ips, err := c.localIPs()
+	if err != nil {
+		log.Warn(classifierPrefix, "failed to list local addresses: ", err)
+		return false
+	}
+	for _, ip := range ips {
+		if ip.Equal(mapped.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func interfaceIPs() ([]net.IP, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips, nil
+}
+
+func newBindingRequest(change uint32) (transactionID []byte, request []byte, err error) {
+	transactionID = make([]byte, 12)
+	if _, err := rand.Read(transactionID); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate STUN transaction ID")
+	}
+
+	var attributes []byte
+	if change != 0 {
+		attributes = make([]byte, 8)
+		binary.BigEndian.PutUint16(attributes[0:], stunAttrChangeRequest)
+		binary.BigEndian.PutUint16(attributes[2:], 4)
+		binary.BigEndian.PutUint32(attributes[4:], change)
+	}
+
+	request = make([]byte, stunHeaderLen, stunHeaderLen+len(attributes))
+	binary.BigEndian.PutUint16(request[0:], stunBindingRequest)
+	binary.BigEndian.PutUint16(request[2:], uint16(len(attributes)))
+	binary.BigEndian.PutUint32(request[4:], stunMagicCookie)
+	copy(request[8:], transactionID)
+	return transactionID, append(request, attributes...), nil
+}
+
+func parseBindingResponse(msg []byte, transactionID []byte) (*net.UDPAddr, error) {
+	if len(msg) < stunHeaderLen ||
+		binary.BigEndian.Uint16(msg[0:]) != stunBindingResponse ||
+		binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie ||
+		string(msg[8:stunHeaderLen]) != string(transactionID) {
+		return nil, errSTUNMalformedResponse
+	}
+
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if len(msg) < stunHeaderLen+length {
+		return nil, errSTUNMalformedResponse
+	}
+
+	var mapped *net.UDPAddr
+	attributes := msg[stunHeaderLen : stunHeaderLen+length]
+	for len(attributes) >= 4 {
+		attrType := binary.BigEndian.Uint16(attributes[0:])
+		attrLen := int(binary.BigEndian.Uint16(attributes[2:]))
+		if len(attributes) < 4+attrLen {
+			return nil, errSTUNMalformedResponse
+		}
+		value := attributes[4 : 4+attrLen]
+
+		switch attrType {
+		case stunAttrXorMappedAddress:
+			addr, err := parseAddress(value)
+			if err != nil {
+				return nil, err
+			}
+			addr.Port ^= stunMagicCookie >> 16
+			binary.BigEndian.PutUint32(addr.IP, binary.BigEndian.Uint32(addr.IP)^stunMagicCookie)
+			return addr, nil
+		case stunAttrMappedAddress:
+			addr, err := parseAddress(value)
+			if err != nil {
+				return nil, err
+			}
+			mapped = addr
+		}
+
+		// attributes are padded to 4 bytes
+		padded := (attrLen + 3) &^ 3
+		if len(attributes) < 4+padded {
+			break
+		}
+		attributes = attributes[4+padded:]
+	}
+
+	if mapped == nil {
+		return nil, errors.New("STUN response has no mapped address")
+	}
+	return mapped, nil
+}
+
+func parseAddress(value []byte) (*net.UDPAddr, error) {
+	if len(value) < 8 || value[1] != stunFamilyIPv4 {
+		return nil, errors.New("unsupported STUN address family")
+	}
+
+	ip := make(net.IP, net.IPv4len)
+	copy(ip, value[4:8])
+	return &net.UDPAddr{IP: ip, Port: int(binary.BigEndian.Uint16(value[2:]))}, nil
+}
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traversal
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stunStandIn answers STUN binding requests like a STUN server behind the simulated NAT would be seen
+type stunStandIn struct {
+	primary   *net.UDPConn
+	alternate *net.UDPConn
+	// mapping translates the source address of the request to the address reported back
+	mapping func(addr *net.UDPAddr) *net.UDPAddr
+	// answerChangeIP and answerChangePort tell whether the NAT lets the responses to CHANGE-REQUEST through
+	answerChangeIP   bool
+	answerChangePort bool
+}
+
+func newSTUNStandIn(t *testing.T, mapping func(addr *net.UDPAddr) *net.UDPAddr) *stunStandIn {
+	primary, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.NoError(t, err)
+	alternate, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.NoError(t, err)
+
+	return &stunStandIn{primary: primary, alternate: alternate, mapping: mapping}
+}
+
+func (s *stunStandIn) addr() string {
+	return s.primary.LocalAddr().String()
+}
+
+func (s *stunStandIn) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := s.primary.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n < stunHeaderLen || binary.BigEndian.Uint16(buf[0:]) != stunBindingRequest {
+			continue
+		}
+
+		var change uint32
+		if n >= stunHeaderLen+8 && binary.BigEndian.Uint16(buf[stunHeaderLen:]) == stunAttrChangeRequest {
+			change = binary.BigEndian.Uint32(buf[stunHeaderLen+4:])
+		}
+
+		response := bindingResponse(buf[8:stunHeaderLen], s.mapping(addr))
+		switch {
+		case change&stunChangeIP != 0:
+			if s.answerChangeIP {
+				s.alternate.WriteToUDP(response, addr)
+			}
+		case change&stunChangePort != 0:
+			if s.answerChangePort {
+				s.alternate.WriteToUDP(response, addr)
+			}
+		default:
+			s.primary.WriteToUDP(response, addr)
+		}
+	}
+}
+
+func (s *stunStandIn) close() {
+	s.primary.Close()
+	s.alternate.Close()
+}
+
+func bindingResponse(transactionID []byte, mapped *net.UDPAddr) []byte {
+	msg := make([]byte, stunHeaderLen+12)
+	binary.BigEndian.PutUint16(msg[0:], stunBindingResponse)
+	binary.BigEndian.PutUint16(msg[2:], 12)
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:], transactionID)
+
+	attr := msg[stunHeaderLen:]
+	binary.BigEndian.PutUint16(attr[0:], stunAttrXorMappedAddress)
+	binary.BigEndian.PutUint16(attr[2:], 8)
+	attr[5] = stunFamilyIPv4
+	binary.BigEndian.PutUint16(attr[6:], uint16(mapped.Port)^uint16(stunMagicCookie>>16))
+	binary.BigEndian.PutUint32(attr[8:], binary.BigEndian.Uint32(mapped.IP.To4())^stunMagicCookie)
+	return msg
+}
+
+func translated(port int) func(addr *net.UDPAddr) *net.UDPAddr {
+	return func(addr *net.UDPAddr) *net.UDPAddr {
+		return &net.UDPAddr{IP: net.IPv4(203, 0, 113, 1), Port: port}
+	}
+}
+
+func untranslated(addr *net.UDPAddr) *net.UDPAddr {
+	return addr
+}
+
+type mockPublisher struct {
+	published []NATTypeEvent
+	lock      sync.Mutex
+}
+
+func (mp *mockPublisher) Publish(topic string, data interface{}) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	if topic == NATTypeTopic {
+		mp.published = append(mp.published, data.(NATTypeEvent))
+	}
+}
+
+func classify(t *testing.T, publisher *mockPublisher, primary, secondary *stunStandIn) (NATType, error) {
+	go primary.serve()
+	defer primary.close()
+	go secondary.serve()
+	defer secondary.close()
+
+	classifier := NewClassifier([]string{primary.addr(), secondary.addr()}, publisher)
+	classifier.timeout = 300 * time.Millisecond
+	classifier.localIPs = func() ([]net.IP, error) {
+		return []net.IP{net.IPv4(127, 0, 0, 1)}, nil
+	}
+
+	natType, err := classifier.Detect()
+	assert.Equal(t, natType, classifier.NATType())
+	return natType, err
+}
+
+func TestClassifier_DetectsNoNAT(t *testing.T) {
+	publisher := &mockPublisher{}
+	natType, err := classify(t, publisher, newSTUNStandIn(t, untranslated), newSTUNStandIn(t, untranslated))
+
+	assert.NoError(t, err)
+	assert.Equal(t, NATTypeNone, natType)
+	assert.Equal(t, []NATTypeEvent{{Type: NATTypeNone}}, publisher.published)
+}
+
+func TestClassifier_DetectsFullConeNAT(t *testing.T) {
+	primary := newSTUNStandIn(t, translated(40000))
+	primary.answerChangeIP = true
+	primary.answerChangePort = true
+
+	natType, err := classify(t, &mockPublisher{}, primary, newSTUNStandIn(t, translated(40000)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, NATTypeFullCone, natType)
+}
+
+func TestClassifier_DetectsRestrictedNAT(t *testing.T) {
+	primary := newSTUNStandIn(t, translated(40000))
+	primary.answerChangePort = true
+
+	natType, err := classify(t, &mockPublisher{}, primary, newSTUNStandIn(t, translated(40000)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, NATTypeRestricted, natType)
+}
+
+func TestClassifier_DetectsPortRestrictedNAT(t *testing.T) {
+	natType, err := classify(t, &mockPublisher{}, newSTUNStandIn(t, translated(40000)), newSTUNStandIn(t, translated(40000)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, NATTypePortRestricted, natType)
+}
+
+func TestClassifier_DetectsSymmetricNAT(t *testing.T) {
+	publisher := &mockPublisher{}
+	natType, err := classify(t, publisher, newSTUNStandIn(t, translated(40000)), newSTUNStandIn(t, translated(40001)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, NATTypeSymmetric, natType)
+	assert.Equal(t, []NATTypeEvent{{Type: NATTypeSymmetric}}, publisher.published)
+}
+
+func TestClassifier_FailsWhenServerDoesNotRespond(t *testing.T) {
+	silent := newSTUNStandIn(t, untranslated)
+	silent.primary.Close()
+
+	publisher := &mockPublisher{}
+	natType, err := classify(t, publisher, silent, newSTUNStandIn(t, untranslated))
+
+	assert.Error(t, err)
+	assert.Equal(t, NATTypeUnknown, natType)
+	assert.Len(t, publisher.published, 1)
+	assert.Equal(t, NATTypeUnknown, publisher.published[0].Type)
+	assert.Equal(t, err.Error(), publisher.published[0].Error)
+}
+
+func TestParseBindingResponse_RejectsOtherTransaction(t *testing.T) {
+	response := bindingResponse([]byte("0123456789ab"), &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 5})
+
+	mapped, err := parseBindingResponse(response, []byte("0123456789ab"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4:5", mapped.String())
+
+	_, err = parseBindingResponse(response, []byte("ba9876543210"))
+	assert.Equal(t, errSTUNMalformedResponse, err)
+}
@@ -18,6 +18,9 @@
 package openvpn
 
 import (
+	"encoding/json"
+	"net"
+	"strconv"
 	"sync"
 
 	log "github.com/cihub/seelog"
@@ -39,6 +42,7 @@ type processFactory func(options connection.ConnectOptions) (openvpn.Process, *C
 type NATPinger interface {
 	Stop()
 	PingProvider(ip string, port int, consumerPort int, stop <-chan struct{}) error
+	RelayProvider(relay traversal.RelayParams, consumerPort int, stop <-chan struct{}) error
 }
 
 // Client takes in the openvpn process and works with it
@@ -50,6 +54,7 @@ type Client struct {
 	publicIP            string
 	pingerStop          chan struct{}
 	removeAllowedIPRule func()
+	relayed             bool
 	stopOnce            sync.Once
 }
 
@@ -76,18 +81,84 @@ func (c *Client) Start(options connection.ConnectOptions) error {
 			clientConfig.LocalPort,
 			c.pingerStop,
 		)
+		if err != nil && clientConfig.VpnConfig.Relay != nil && !options.DisableRelay {
+			log.Warn("NAT pinging to provider failed, falling back to relay: ", err)
+			err = c.startRelayed(options, *clientConfig.VpnConfig.Relay, clientConfig.LocalPort)
+		}
 		if err != nil {
-			removeAllowedIPRule()
+			c.removeAllowedIPRule()
 			return err
 		}
 	}
 	err = c.process.Start()
 	if err != nil {
-		removeAllowedIPRule()
+		c.removeAllowedIPRule()
 	}
 	return err
 }
 
+// startRelayed joins provider at the relay and recreates the process to connect through it
+func (c *Client) startRelayed(options connection.ConnectOptions, relay traversal.RelayParams, localPort int) error {
+	relayIP, relayPort, err := splitRelayAddress(relay.Address)
+	if err != nil {
+		return err
+	}
+
+	removeRelayRule, err := firewall.AllowIPAccess(relayIP)
+	if err != nil {
+		return err
+	}
+	removeAllowedIPRule := c.removeAllowedIPRule
+	c.removeAllowedIPRule = func() {
+		removeRelayRule()
+		removeAllowedIPRule()
+	}
+
+	if err := c.natPinger.RelayProvider(relay, localPort, c.pingerStop); err != nil {
+		return errors.Wrap(err, "failed to connect through relay")
+	}
+
+	options.SessionConfig, err = relayedSessionConfig(options.SessionConfig, relayIP, relayPort)
+	if err != nil {
+		return err
+	}
+	proc, _, err := c.processFactory(options)
+	if err != nil {
+		return err
+	}
+	c.process = proc
+	c.relayed = true
+	return nil
+}
+
+// Relayed tells whether the connection goes through the relay
+func (c *Client) Relayed() bool {
+	return c.relayed
+}
+
+func splitRelayAddress(address string) (string, int, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "invalid relay address")
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "invalid relay port")
+	}
+	return host, portNum, nil
+}
+
+// relayedSessionConfig points the session config to the relay instead of provider
+func relayedSessionConfig(sessionConfig []byte, relayIP string, relayPort int) ([]byte, error) {
+	vpnConfig := &VPNConfig{}
+	if err := json.Unmarshal(sessionConfig, vpnConfig); err != nil {
+		return nil, err
+	}
+	vpnConfig.RemoteIP = relayIP
+	vpnConfig.RemotePort = relayPort
+	return json.Marshal(vpnConfig)
+}
+
 // Wait waits for the connection to exit
 func (c *Client) Wait() error {
 	if c.process == nil {
@@ -138,4 +209,6 @@ type VPNConfig struct {
 	RemoteProtocol  string `json:"protocol"`
 	TLSPresharedKey string `json:"TLSPresharedKey"`
 	CACertificate   string `json:"CACertificate"`
+	// Relay is the relay to fall back to when NAT hole punching fails
+	Relay *traversal.RelayParams `json:"relay,omitempty"`
 }
@@ -18,9 +18,11 @@
 package openvpn
 
 import (
+	"net"
 	"strconv"
 
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
+	"github.com/mysteriumnetwork/node/core/connection"
 )
 
 // ClientConfig represents specific "openvpn as client" configuration
@@ -55,6 +57,20 @@ func (c *ClientConfig) SetProtocol(protocol string) {
 	}
 }
 
+// SetRoutes redirects all traffic into the tunnel, unless it is limited to the included networks.
+// Excluded networks are routed through the physical gateway.
+func (c *ClientConfig) SetRoutes(routes connection.TunnelRoutes) {
+	if len(routes.Include) == 0 {
+		c.SetParam("redirect-gateway", "def1", "bypass-dhcp")
+	}
+	for _, network := range routes.Include {
+		c.SetParam("route", network.IP.String(), net.IP(network.Mask).String())
+	}
+	for _, network := range routes.Exclude {
+		c.SetParam("route", network.IP.String(), net.IP(network.Mask).String(), "net_gateway")
+	}
+}
+
 func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConfig {
 	clientConfig := ClientConfig{GenericConfig: config.NewConfig(runtimeDir, scriptSearchPath), VpnConfig: nil}
 
@@ -70,7 +86,6 @@ func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConf
 
 	clientConfig.SetParam("reneg-sec", "60")
 	clientConfig.SetParam("resolv-retry", "infinite")
-	clientConfig.SetParam("redirect-gateway", "def1", "bypass-dhcp")
 
 	return &clientConfig
 }
@@ -78,7 +93,7 @@ func defaultClientConfig(runtimeDir string, scriptSearchPath string) *ClientConf
 // NewClientConfigFromSession creates client configuration structure for given VPNConfig, configuration dir to store serialized file args, and
 // configuration filename to store other args
 // TODO this will become the part of openvpn service consumer separate package
-func NewClientConfigFromSession(vpnConfig *VPNConfig, configDir string, runtimeDir string, enableDNS bool) (*ClientConfig, error) {
+func NewClientConfigFromSession(vpnConfig *VPNConfig, configDir string, runtimeDir string, enableDNS bool, routes connection.TunnelRoutes) (*ClientConfig, error) {
 	// TODO Rename `vpnConfig` to `sessionConfig`
 	err := NewDefaultValidator().IsValid(vpnConfig)
 	if err != nil {
@@ -95,6 +110,7 @@ func NewClientConfigFromSession(vpnConfig *VPNConfig, configDir string, runtimeD
 	clientFileConfig.SetProtocol(vpnConfig.RemoteProtocol)
 	clientFileConfig.SetTLSCACertificate(vpnConfig.CACertificate)
 	clientFileConfig.SetTLSCrypt(vpnConfig.TLSPresharedKey)
+	clientFileConfig.SetRoutes(routes)
 
 	return clientFileConfig, nil
 }
@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package openvpn
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientConfigRedirectsAllTrafficByDefault(t *testing.T) {
+	config := defaultClientConfig("", "")
+	config.SetRoutes(connection.TunnelRoutes{})
+
+	content, err := config.ToConfigFileContent()
+	assert.NoError(t, err)
+	assert.Contains(t, content, "redirect-gateway def1 bypass-dhcp")
+	assert.NotContains(t, content, "route ")
+}
+
+func TestClientConfigSetsSplitTunnelRoutes(t *testing.T) {
+	_, included, _ := net.ParseCIDR("10.0.0.0/8")
+	_, excluded, _ := net.ParseCIDR("192.168.1.0/24")
+
+	config := defaultClientConfig("", "")
+	config.SetRoutes(connection.TunnelRoutes{
+		Include: []net.IPNet{*included},
+		Exclude: []net.IPNet{*excluded},
+	})
+
+	content, err := config.ToConfigFileContent()
+	assert.NoError(t, err)
+	assert.NotContains(t, content, "redirect-gateway")
+	assert.Contains(t, content, "route 10.0.0.0 255.0.0.0")
+	assert.Contains(t, content, "route 192.168.1.0 255.255.255.0 net_gateway")
+}
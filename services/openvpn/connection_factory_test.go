@@ -24,6 +24,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -69,5 +70,10 @@ func (mnp *MockNATPinger) PingProvider(_ string, port int, consumerPort int, _ <
 	return nil
 }
 
+// RelayProvider does nothing
+func (mnp *MockNATPinger) RelayProvider(_ traversal.RelayParams, consumerPort int, _ <-chan struct{}) error {
+	return nil
+}
+
 // Stop does nothing
 func (mnp *MockNATPinger) Stop() {}
@@ -25,11 +25,11 @@ import (
 // PaymentMethodPerBytes indicates payment method for data amount transferred
 const PaymentMethodPerBytes = "PER_BYTES"
 
-// PaymentPerBytes structure describes price per unit and how much bytes were transferred
+// PaymentPerBytes structure describes price of the given amount of data transferred in both directions
 type PaymentPerBytes struct {
 	Price money.Money `json:"price"`
 
-	// Service bytes provided for paid price
+	// Service data provided for paid price
 	Bytes datasize.BitSize `json:"bytes,omitempty"`
 }
 
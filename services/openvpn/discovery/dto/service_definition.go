@@ -31,6 +31,9 @@ type ServiceDefinition struct {
 	// Available per session bandwidth
 	SessionBandwidth Bandwidth `json:"session_bandwidth,omitempty"`
 
+	// Available bandwidth of all the sessions together, unlimited if empty
+	ServiceBandwidth Bandwidth `json:"service_bandwidth,omitempty"`
+
 	// Transport protocol used by service
 	Protocol string `json:"protocol,omitempty"`
 }
@@ -20,6 +20,7 @@ package discovery
 import (
 	"time"
 
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
@@ -27,17 +28,27 @@ import (
 	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 )
 
+// defaultSessionBandwidth is announced when the bandwidth of sessions is not limited
+const defaultSessionBandwidth = dto.Bandwidth(10 * datasize.MB)
+
 // NewServiceProposalWithLocation creates service proposal description for openvpn service
 func NewServiceProposalWithLocation(
 	serviceLocation market.Location,
 	protocol string,
+	rateLimit service.OptionsRateLimit,
 ) market.ServiceProposal {
+	sessionBandwidth := defaultSessionBandwidth
+	if rateLimit.Session != 0 {
+		sessionBandwidth = dto.Bandwidth(rateLimit.Session)
+	}
+
 	return market.ServiceProposal{
 		ServiceType: openvpn.ServiceType,
 		ServiceDefinition: dto.ServiceDefinition{
 			Location:          serviceLocation,
 			LocationOriginate: serviceLocation,
-			SessionBandwidth:  dto.Bandwidth(10 * datasize.MB),
+			SessionBandwidth:  sessionBandwidth,
+			ServiceBandwidth:  dto.Bandwidth(rateLimit.Service),
 			Protocol:          protocol,
 		},
 		PaymentMethodType: dto.PaymentMethodPerTime,
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
@@ -41,7 +42,7 @@ var (
 )
 
 func Test_NewServiceProposalWithLocation(t *testing.T) {
-	proposal := NewServiceProposalWithLocation(locationLTTelia, protocol)
+	proposal := NewServiceProposalWithLocation(locationLTTelia, protocol, service.OptionsRateLimit{})
 
 	assert.Exactly(
 		t,
@@ -63,3 +64,11 @@ func Test_NewServiceProposalWithLocation(t *testing.T) {
 		proposal,
 	)
 }
+
+func Test_NewServiceProposalWithLocation_AnnouncesRateLimits(t *testing.T) {
+	proposal := NewServiceProposalWithLocation(locationLTTelia, protocol, service.OptionsRateLimit{Session: 1000, Service: 5000})
+
+	definition := proposal.ServiceDefinition.(dto.ServiceDefinition)
+	assert.Equal(t, dto.Bandwidth(1000), definition.SessionBandwidth)
+	assert.Equal(t, dto.Bandwidth(5000), definition.ServiceBandwidth)
+}
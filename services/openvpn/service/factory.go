@@ -29,6 +29,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/nat/traversal"
@@ -36,6 +37,7 @@ import (
 	openvpn_session "github.com/mysteriumnetwork/node/services/openvpn/session"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/shaper"
 )
 
 const statisticsReportingIntervalInSeconds = 30
@@ -55,6 +57,9 @@ func NewManager(nodeOptions node.Options,
 	natEventGetter NATEventGetter,
 	portPool port.ServicePortSupplier,
 	publisher eventPublisher,
+	relayFinder traversal.RelayFinder,
+	signerFactory identity.SignerFactory,
+	dnsResolver *dns.Resolver,
 ) *Manager {
 	clientMap := openvpn_session.NewClientMap(sessionMap)
 
@@ -88,7 +93,11 @@ func NewManager(nodeOptions node.Options,
 		serviceOptions:                 serviceOptions,
 		mapPort:                        mapPort,
 		natEventGetter:                 natEventGetter,
+		relayFinder:                    relayFinder,
+		signerFactory:                  signerFactory,
+		dnsResolver:                    dnsResolver,
 		ports:                          portPool,
+		shaper:                         shaper.New(),
 	}
 }
 
@@ -158,6 +167,7 @@ type OpenvpnConfigNegotiator struct {
 func (ocn *OpenvpnConfigNegotiator) ProvideConfig(sessionConfig json.RawMessage, traversalParams *traversal.Params) (*session.ConfigParams, error) {
 	ocn.vpnConfig.LocalPort = traversalParams.ConsumerPort
 	ocn.vpnConfig.RemotePort = traversalParams.ProviderPort
+	ocn.vpnConfig.Relay = traversalParams.Relay
 
 	return &session.ConfigParams{SessionServiceConfig: ocn.vpnConfig, TraversalParams: traversalParams}, nil
 }
@@ -35,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/services"
 	openvpn_service "github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/shaper"
 	"github.com/mysteriumnetwork/node/utils"
 	"github.com/pkg/errors"
 )
@@ -58,9 +59,9 @@ type NATPinger interface {
 	Valid() bool
 }
 
-// NATEventGetter allows us to fetch the last known NAT event
+// NATEventGetter allows us to fetch the last known NAT event of the given stage
 type NATEventGetter interface {
-	LastEvent() *event.Event
+	LastStageEvent(stage string) *event.Event
 }
 
 // Manager represents entrypoint for Openvpn service with top level components
@@ -71,6 +72,10 @@ type Manager struct {
 	natPingerPorts port.ServicePortSupplier
 	natPinger      NATPinger
 	natEventGetter NATEventGetter
+	relayFinder    traversal.RelayFinder
+	signerFactory  identity.SignerFactory
+	providerID     identity.Identity
+	dnsResolver    *dns.Resolver
 	dnsServer      *dns.Server
 
 	sessionConfigNegotiatorFactory SessionConfigNegotiatorFactory
@@ -87,10 +92,12 @@ type Manager struct {
 	outboundIP      string
 	currentLocation string
 	serviceOptions  Options
+	shaper          shaper.Shaper
 }
 
 // Serve starts service - does block
 func (m *Manager) Serve(providerID identity.Identity) (err error) {
+	m.providerID = providerID
 	m.vpnNetwork = net.IPNet{
 		IP:   net.ParseIP(m.serviceOptions.Subnet),
 		Mask: net.IPMask(net.ParseIP(m.serviceOptions.Netmask).To4()),
@@ -121,6 +128,9 @@ func (m *Manager) Serve(providerID identity.Identity) (err error) {
 	m.vpnServiceConfigProvider = m.sessionConfigNegotiatorFactory(primitives, dnsIP, m.outboundIP, m.publicIP, m.vpnServerPort)
 
 	vpnServerConfig := m.vpnServerConfigFactory(primitives, m.vpnServerPort)
+	if m.rateLimited() {
+		vpnServerConfig.SetDevice(rateLimitDevice(m.vpnServerPort))
+	}
 	stateChannel := make(chan openvpn.State, 10)
 	m.vpnServer = m.vpnServerFactory(vpnServerConfig, stateChannel)
 
@@ -141,7 +151,14 @@ func (m *Manager) Serve(providerID identity.Identity) (err error) {
 		return errors.Wrap(err, "failed to start Openvpn server")
 	}
 
-	m.dnsServer = dns.NewServer(net.JoinHostPort(dnsIP, "53"), dns.ResolveViaConfigured())
+	if m.rateLimited() {
+		if err := m.limitRate(rateLimitDevice(m.vpnServerPort)); err != nil {
+			m.vpnServer.Stop()
+			return errors.Wrap(err, "failed to limit bandwidth of the service")
+		}
+	}
+
+	m.dnsServer = dns.NewServer(net.JoinHostPort(dnsIP, "53"), m.dnsResolver)
 	log.Info("starting DNS on: ", m.dnsServer.Addr)
 	go func() {
 		if err := m.dnsServer.Run(); err != nil {
@@ -208,12 +225,34 @@ func (m *Manager) ProvideConfig(sessionConfig json.RawMessage, traversalParams *
 
 			traversalParams.ProviderPort = pp.Num()
 			traversalParams.ConsumerPort = cp.Num()
+			traversalParams.Relay = m.findRelay()
 		}
 	}
 
 	return m.vpnServiceConfigProvider.ProvideConfig(sessionConfig, traversalParams)
 }
 
+// findRelay picks the relay the consumer falls back to if pinging the provider fails,
+// the relay ticket is signed by the provider, so the relay is able to tell the peers of the session
+func (m *Manager) findRelay() *traversal.RelayParams {
+	if m.relayFinder == nil || m.signerFactory == nil {
+		return nil
+	}
+
+	address, err := m.relayFinder()
+	if err != nil {
+		log.Warn("no relay for the session found: ", err)
+		return nil
+	}
+
+	relay, err := traversal.NewRelayParams(address, m.signerFactory(m.providerID))
+	if err != nil {
+		log.Warn("failed to prepare relay params: ", err)
+		return nil
+	}
+	return relay
+}
+
 func (m *Manager) startServer(server openvpn.Process, stateChannel chan openvpn.State) error {
 	if err := m.vpnServer.Start(); err != nil {
 		return err
@@ -250,14 +289,11 @@ func (m *Manager) isBehindNAT() bool {
 	return m.outboundIP != m.publicIP
 }
 
+// portMappingFailed tells whether the consumers have to punch the provider,
+// the events of the later stages (e.g. relaying of the previous sessions) do not change the port mapping result
 func (m *Manager) portMappingFailed() bool {
-	event := m.natEventGetter.LastEvent()
-	if event == nil {
-		return false
-	}
-
-	if event.Stage == traversal.StageName {
-		return true
+	if event := m.natEventGetter.LastStageEvent(mapping.StageName); event != nil {
+		return !event.Successful
 	}
-	return event.Stage == mapping.StageName && !event.Successful
+	return m.natEventGetter.LastStageEvent(traversal.StageName) != nil
 }
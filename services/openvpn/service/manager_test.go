@@ -21,9 +21,15 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/services"
 	"github.com/mysteriumnetwork/node/session"
 )
 
@@ -50,3 +56,125 @@ type mockConfigProvider struct{}
 func (cp *mockConfigProvider) ProvideConfig(sessionConfig json.RawMessage, traversalParams *traversal.Params) (*session.ConfigParams, error) {
 	return &session.ConfigParams{SessionServiceConfig: traversalParams}, nil
 }
+
+func TestManager_ProvideConfigPicksRelayWhenPunching(t *testing.T) {
+	m := Manager{
+		vpnServiceConfigProvider: &mockConfigProvider{},
+		vpnServerPort:            1000,
+		natPinger:                &mockNATPinger{},
+		natPingerPorts:           port.NewPool(),
+		natEventGetter:           newMockNATEventGetter(event.Event{Stage: traversal.StageName}),
+		publicIP:                 "1.1.1.1",
+		outboundIP:               "192.168.0.1",
+		relayFinder: func() (string, error) {
+			return "2.2.2.2:3000", nil
+		},
+		signerFactory: func(identity.Identity) identity.Signer {
+			return &identity.SignerFake{}
+		},
+	}
+
+	config, err := m.ProvideConfig([]byte(`{"IP":"3.3.3.3"}`), nil)
+	assert.NoError(t, err)
+
+	params := config.SessionServiceConfig.(*traversal.Params)
+	assert.NotNil(t, params.Relay)
+	assert.Equal(t, "2.2.2.2:3000", params.Relay.Address)
+	assert.NotEmpty(t, params.Relay.Token)
+	assert.NotEmpty(t, params.Relay.Signature)
+}
+
+func TestManager_ProvideConfigWithoutRelayWhenNoneFound(t *testing.T) {
+	m := Manager{
+		vpnServiceConfigProvider: &mockConfigProvider{},
+		vpnServerPort:            1000,
+		natPinger:                &mockNATPinger{},
+		natPingerPorts:           port.NewPool(),
+		natEventGetter:           newMockNATEventGetter(event.Event{Stage: traversal.StageName}),
+		publicIP:                 "1.1.1.1",
+		outboundIP:               "192.168.0.1",
+		relayFinder: func() (string, error) {
+			return "", errors.New("no relays")
+		},
+		signerFactory: func(identity.Identity) identity.Signer {
+			return &identity.SignerFake{}
+		},
+	}
+
+	config, err := m.ProvideConfig([]byte(`{"IP":"3.3.3.3"}`), nil)
+	assert.NoError(t, err)
+
+	params := config.SessionServiceConfig.(*traversal.Params)
+	assert.NotZero(t, params.ConsumerPort)
+	assert.Nil(t, params.Relay)
+}
+
+func TestManager_ProvideConfigKeepsPunchingAfterRelayedSession(t *testing.T) {
+	m := Manager{
+		vpnServiceConfigProvider: &mockConfigProvider{},
+		vpnServerPort:            1000,
+		natPinger:                &mockNATPinger{},
+		natPingerPorts:           port.NewPool(),
+		natEventGetter: newMockNATEventGetter(
+			event.Event{Stage: mapping.StageName, Successful: false},
+			event.Event{Stage: traversal.RelayStageName, Successful: true},
+		),
+		publicIP:   "1.1.1.1",
+		outboundIP: "192.168.0.1",
+	}
+
+	config, err := m.ProvideConfig([]byte(`{"IP":"3.3.3.3"}`), nil)
+	assert.NoError(t, err)
+
+	params := config.SessionServiceConfig.(*traversal.Params)
+	assert.NotZero(t, params.ProviderPort)
+	assert.NotZero(t, params.ConsumerPort)
+}
+
+func TestManager_ProvideConfigWithoutPunchingWhenPortIsMapped(t *testing.T) {
+	m := Manager{
+		vpnServiceConfigProvider: &mockConfigProvider{},
+		vpnServerPort:            1000,
+		natPinger:                &mockNATPinger{},
+		natPingerPorts:           port.NewPool(),
+		natEventGetter:           newMockNATEventGetter(event.Event{Stage: mapping.StageName, Successful: true}),
+		publicIP:                 "1.1.1.1",
+		outboundIP:               "192.168.0.1",
+	}
+
+	config, err := m.ProvideConfig([]byte(`{"IP":"3.3.3.3"}`), nil)
+	assert.NoError(t, err)
+
+	params := config.SessionServiceConfig.(*traversal.Params)
+	assert.Zero(t, params.ConsumerPort)
+}
+
+type mockNATPinger struct{}
+
+func (mnp *mockNATPinger) BindServicePort(serviceType services.ServiceType, port int) {}
+
+func (mnp *mockNATPinger) Stop() {}
+
+func (mnp *mockNATPinger) Valid() bool {
+	return true
+}
+
+type mockNATEventGetter struct {
+	stageEvents map[string]event.Event
+}
+
+func newMockNATEventGetter(events ...event.Event) *mockNATEventGetter {
+	mneg := &mockNATEventGetter{stageEvents: make(map[string]event.Event)}
+	for _, e := range events {
+		mneg.stageEvents[e.Stage] = e
+	}
+	return mneg
+}
+
+func (mneg *mockNATEventGetter) LastStageEvent(stage string) *event.Event {
+	e, ok := mneg.stageEvents[stage]
+	if !ok {
+		return nil
+	}
+	return &e
+}
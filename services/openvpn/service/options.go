@@ -23,6 +23,7 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/config/urfavecli/cliflags"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/services/shared"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -32,6 +33,8 @@ type Options struct {
 	Port     int    `json:"port"`
 	Subnet   string `json:"subnet"`
 	Netmask  string `json:"netmask"`
+
+	service.OptionsRateLimit
 }
 
 var (
@@ -91,6 +94,8 @@ func ConfiguredOptions() Options {
 		Port:     config.Current.GetInt(portFlag.Name),
 		Subnet:   config.Current.GetString(subnetFlag.Name),
 		Netmask:  config.Current.GetString(netmaskFlag.Name),
+
+		OptionsRateLimit: shared.ConfiguredRateLimit(),
 	}
 }
 
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,3 +55,12 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 		Netmask:  "255.255.255.0",
 	}, options)
 }
+
+func Test_ParseJSONOptions_RateLimits(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"sessionRateLimit": 1000000, "serviceRateLimit": 5000000}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, service.OptionsRateLimit{Session: 1000000, Service: 5000000}, options.(Options).OptionsRateLimit)
+}
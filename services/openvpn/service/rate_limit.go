@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/mysteriumnetwork/node/shaper"
+)
+
+// rateLimitDevice names the tun device of the service, so its bandwidth could be limited
+func rateLimitDevice(port int) string {
+	return fmt.Sprintf("tun%d", port)
+}
+
+func (m *Manager) rateLimited() bool {
+	return m.serviceOptions.Session != 0 || m.serviceOptions.Service != 0
+}
+
+// limitRate limits the bandwidth of every consumer and all of them together,
+// since all the consumers share a single tun device they are told apart by their addresses in the VPN network
+func (m *Manager) limitRate(device string) error {
+	hosts, ok := vpnHosts(m.vpnNetwork)
+	if !ok {
+		if m.serviceOptions.Session != 0 {
+			log.Warnf("VPN network %s has more than %d hosts, only the service bandwidth limit is applied", m.vpnNetwork.String(), shaper.MaxHosts)
+		}
+		return m.shaper.Limit(device, m.serviceOptions.Service)
+	}
+	return m.shaper.LimitHosts(device, hosts, m.serviceOptions.Session, m.serviceOptions.Service)
+}
+
+// vpnHosts returns the addresses of consumers in the VPN network, the first address of the network belongs to the server.
+// Networks having more than shaper.MaxHosts consumers are not listed.
+func vpnHosts(network net.IPNet) ([]net.IP, bool) {
+	first := network.IP.Mask(network.Mask).To4()
+	ones, bits := network.Mask.Size()
+	if first == nil || bits-ones > 16 {
+		return nil, false
+	}
+
+	count := 1<<uint(bits-ones) - 3 // network, server and broadcast addresses
+	if count > shaper.MaxHosts {
+		return nil, false
+	}
+	hosts := make([]net.IP, 0, count)
+	for i := 0; i < count; i++ {
+		host := make(net.IP, len(first))
+		copy(host, first)
+		addToIP(host, i+2)
+		hosts = append(hosts, host)
+	}
+	return hosts, true
+}
+
+func addToIP(ip net.IP, value int) {
+	for i := len(ip) - 1; i >= 0 && value > 0; i-- {
+		sum := int(ip[i]) + value
+		ip[i] = byte(sum)
+		value = sum >> 8
+	}
+}
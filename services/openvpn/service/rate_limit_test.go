@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_vpnHosts(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.8.0.0/29")
+
+	hosts, ok := vpnHosts(*network)
+
+	assert.True(t, ok)
+	assert.Equal(t, []string{"10.8.0.2", "10.8.0.3", "10.8.0.4", "10.8.0.5", "10.8.0.6"}, hostStrings(hosts))
+}
+
+func Test_vpnHosts_SkipsLargeNetworks(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.8.0.0/16")
+
+	hosts, ok := vpnHosts(*network)
+
+	assert.False(t, ok)
+	assert.Empty(t, hosts)
+}
+
+func hostStrings(hosts []net.IP) []string {
+	result := make([]string, len(hosts))
+	for i := range hosts {
+		result[i] = hosts[i].String()
+	}
+	return result
+}
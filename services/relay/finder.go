@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"math/rand"
+
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/pkg/errors"
+)
+
+// ProposalFinder finds the proposals matching the given condition
+type ProposalFinder interface {
+	MatchProposals(match discovery.ProposalReducer) ([]market.ServiceProposal, error)
+}
+
+// ErrNoRelays is returned when there are no relays proposed in the network
+var ErrNoRelays = errors.New("no relays found")
+
+// NewFinder returns the finder of a random relay among the proposed ones
+func NewFinder(proposals ProposalFinder) traversal.RelayFinder {
+	return func() (string, error) {
+		relays, err := proposals.MatchProposals(func(proposal market.ServiceProposal) bool {
+			definition, ok := proposal.ServiceDefinition.(ServiceDefinition)
+			return proposal.ServiceType == ServiceType && ok && definition.Address != ""
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to find relays")
+		}
+		if len(relays) == 0 {
+			return "", ErrNoRelays
+		}
+
+		relay := relays[rand.Intn(len(relays))]
+		return relay.ServiceDefinition.(ServiceDefinition).Address, nil
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProposalFinder struct {
+	proposals []market.ServiceProposal
+	err       error
+}
+
+func (finder *mockProposalFinder) MatchProposals(match discovery.ProposalReducer) ([]market.ServiceProposal, error) {
+	var matched []market.ServiceProposal
+	for _, proposal := range finder.proposals {
+		if match(proposal) {
+			matched = append(matched, proposal)
+		}
+	}
+	return matched, finder.err
+}
+
+func Test_Finder_FindsRelayAddress(t *testing.T) {
+	finder := NewFinder(&mockProposalFinder{
+		proposals: []market.ServiceProposal{
+			{ServiceType: "noop"},
+			{ServiceType: ServiceType, ServiceDefinition: ServiceDefinition{}},
+			{ServiceType: ServiceType, ServiceDefinition: ServiceDefinition{Address: "1.2.3.4:5000"}},
+		},
+	})
+
+	address, err := finder()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4:5000", address)
+}
+
+func Test_Finder_FailsWithoutRelays(t *testing.T) {
+	finder := NewFinder(&mockProposalFinder{
+		proposals: []market.ServiceProposal{{ServiceType: "noop"}},
+	})
+
+	_, err := finder()
+	assert.Equal(t, ErrNoRelays, err)
+}
+
+func Test_Finder_FailsOnFinderError(t *testing.T) {
+	finder := NewFinder(&mockProposalFinder{err: errors.New("storage failed")})
+
+	_, err := finder()
+	assert.EqualError(t, err, "failed to find relays: storage failed")
+}
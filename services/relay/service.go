@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/services/noop"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/pkg/errors"
+)
+
+const logPrefix = "[service-relay] "
+
+// NewManager creates new instance of Relay service relaying on the given port the sessions of registered providers
+func NewManager(port int, mapPort func(int) (releasePortMapping func()), registry traversal.IdentityRegistry) *Manager {
+	return &Manager{
+		port:    port,
+		mapPort: mapPort,
+		relay:   traversal.NewRelay(fmt.Sprintf(":%d", port), identity.NewExtractor(), registry),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Manager represents entrypoint for Relay service
+type Manager struct {
+	port    int
+	mapPort func(int) (releasePortMapping func())
+	relay   *traversal.Relay
+	stop    chan struct{}
+}
+
+// ProvideConfig provides the session configuration
+func (manager *Manager) ProvideConfig(sessionConfig json.RawMessage, traversalParams *traversal.Params) (*session.ConfigParams, error) {
+	return &session.ConfigParams{TraversalParams: traversalParams}, nil
+}
+
+// Serve starts service - does block
+func (manager *Manager) Serve(providerID identity.Identity) error {
+	releasePorts := manager.mapPort(manager.port)
+	defer releasePorts()
+
+	if err := firewall.AddInboundRule("udp", manager.port); err != nil {
+		return errors.Wrap(err, "failed to add firewall rule")
+	}
+	defer func() {
+		if err := firewall.RemoveInboundRule("udp", manager.port); err != nil {
+			log.Error(logPrefix, "failed to delete firewall rule for relay: ", err)
+		}
+	}()
+
+	if err := manager.relay.Start(); err != nil {
+		return errors.Wrap(err, "failed to start relay")
+	}
+	log.Info(logPrefix, "Relay service started successfully")
+
+	<-manager.stop
+	return nil
+}
+
+// Stop stops service
+func (manager *Manager) Stop() error {
+	manager.relay.Stop()
+	close(manager.stop)
+	log.Info(logPrefix, "Relay service stopped")
+	return nil
+}
+
+// GetProposal returns the proposal for Relay service for given country and relay address
+func GetProposal(location location.Location, address string) market.ServiceProposal {
+	return market.ServiceProposal{
+		ServiceType: ServiceType,
+		ServiceDefinition: ServiceDefinition{
+			Location: market.Location{
+				Continent: location.Continent,
+				Country:   location.Country,
+				City:      location.City,
+
+				ASN:      location.ASN,
+				ISP:      location.ISP,
+				NodeType: location.NodeType,
+			},
+			Address: address,
+		},
+		PaymentMethodType: noop.PaymentMethodNoop,
+		PaymentMethod: noop.PaymentNoop{
+			Price: money.NewMoney(0, money.CurrencyMyst),
+		},
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/services/noop"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	providerID  = identity.FromAddress("provider-id")
+	noopMapPort = func(int) func() { return func() {} }
+)
+
+var _ service.Service = NewManager(0, noopMapPort)
+
+func Test_GetProposal(t *testing.T) {
+	country := "LT"
+	assert.Exactly(
+		t,
+		market.ServiceProposal{
+			ServiceType: "relay",
+			ServiceDefinition: ServiceDefinition{
+				Location: market.Location{Country: country},
+				Address:  "1.2.3.4:5000",
+			},
+
+			PaymentMethodType: "NOOP",
+			PaymentMethod: noop.PaymentNoop{
+				Price: money.Money{
+					Amount:   0,
+					Currency: money.Currency("MYST"),
+				},
+			},
+		},
+		GetProposal(location.Location{Country: country}, "1.2.3.4:5000"),
+	)
+}
+
+func Test_Manager_Serve_Stop(t *testing.T) {
+	manager := NewManager(0, noopMapPort)
+	served := make(chan error)
+	go func() {
+		served <- manager.Serve(providerID)
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+	err := manager.Stop()
+	assert.NoError(t, err)
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Error("relay service was not stopped")
+	}
+}
@@ -22,6 +22,12 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/config/urfavecli/cliflags"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -36,11 +42,39 @@ var (
 		Usage: "Comma separated list that determines the allowed identities on our service.",
 		Value: "",
 	}
+	sessionRateLimitFlag = cli.IntFlag{
+		Name:  "rate-limit.session",
+		Usage: "Bandwidth limit of every consumer session in Kbit/s, unlimited by default",
+	}
+	serviceRateLimitFlag = cli.IntFlag{
+		Name:  "rate-limit.service",
+		Usage: "Bandwidth limit of all the consumer sessions of the service together in Kbit/s, unlimited by default",
+	}
+	pricePerGBFlag = cli.Float64Flag{
+		Name:  "payment.price-per-gb",
+		Usage: "Price in MYST of every GB transferred by consumers, services are paid per time if not set",
+	}
+	dnsUpstreamsFlag = cli.StringFlag{
+		Name: "dns.upstreams",
+		Usage: "Comma separated list of DNS servers resolving the queries of consumers: plain (1.1.1.1), " +
+			"DNS-over-TLS (tls://1.1.1.1) or DNS-over-HTTPS (https://cloudflare-dns.com/dns-query), servers of the system are used by default",
+	}
+	dnsBlocklistsFlag = cli.StringFlag{
+		Name:  "dns.blocklists",
+		Usage: "Comma separated list of files with the domains consumers are not allowed to resolve, i.e. malware or ad lists",
+	}
+	dnsCacheSizeFlag = cli.IntFlag{
+		Name:  "dns.cache-size",
+		Usage: "Count of DNS responses kept in cache",
+		Value: 4096,
+	}
 )
 
 // RegisterFlags registers shared service CLI flags
 func RegisterFlags(flags *[]cli.Flag) {
-	*flags = append(*flags, accessPoliciesFlag)
+	*flags = append(*flags, accessPoliciesFlag, sessionRateLimitFlag, serviceRateLimitFlag, pricePerGBFlag,
+		dnsUpstreamsFlag, dnsBlocklistsFlag, dnsCacheSizeFlag,
+	)
 }
 
 // Configure parses shared service CLI flags and registers values to the configuration
@@ -51,10 +85,22 @@ func Configure(ctx *cli.Context) {
 
 func configureDefaults() {
 	config.Current.SetDefault("access-policy.list", "")
+	config.Current.SetDefault(sessionRateLimitFlag.Name, 0)
+	config.Current.SetDefault(serviceRateLimitFlag.Name, 0)
+	config.Current.SetDefault(pricePerGBFlag.Name, 0)
+	config.Current.SetDefault(dnsUpstreamsFlag.Name, "")
+	config.Current.SetDefault(dnsBlocklistsFlag.Name, "")
+	config.Current.SetDefault(dnsCacheSizeFlag.Name, dnsCacheSizeFlag.Value)
 }
 
 func configureCLI(ctx *cli.Context) {
 	cliflags.SetString(config.Current, accessPoliciesFlag.Name, ctx)
+	cliflags.SetInt(config.Current, sessionRateLimitFlag.Name, ctx)
+	cliflags.SetInt(config.Current, serviceRateLimitFlag.Name, ctx)
+	cliflags.SetFloat64(config.Current, pricePerGBFlag.Name, ctx)
+	cliflags.SetString(config.Current, dnsUpstreamsFlag.Name, ctx)
+	cliflags.SetString(config.Current, dnsBlocklistsFlag.Name, ctx)
+	cliflags.SetInt(config.Current, dnsCacheSizeFlag.Name, ctx)
 }
 
 // ConfiguredOptions returns effective shared service options
@@ -70,3 +116,53 @@ func ConfiguredOptions() Options {
 		AccessPolicies: policies,
 	}
 }
+
+// ConfiguredDNS returns effective options of the DNS resolver serving consumers
+func ConfiguredDNS() dns.Options {
+	return dns.Options{
+		Upstreams:  splitList(config.Current.GetString(dnsUpstreamsFlag.Name)),
+		Blocklists: splitList(config.Current.GetString(dnsBlocklistsFlag.Name)),
+		CacheSize:  config.Current.GetInt(dnsCacheSizeFlag.Name),
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ConfiguredRateLimit returns effective bandwidth limits of services
+func ConfiguredRateLimit() service.OptionsRateLimit {
+	return service.OptionsRateLimit{
+		Session: kbitToBits(config.Current.GetInt(sessionRateLimitFlag.Name)),
+		Service: kbitToBits(config.Current.GetInt(serviceRateLimitFlag.Name)),
+	}
+}
+
+func kbitToBits(kbit int) uint64 {
+	if kbit < 0 {
+		return 0
+	}
+	return uint64(kbit) * 1000
+}
+
+// WithConfiguredPayment returns the proposal paid per bytes when the price per GB is configured,
+// otherwise the proposal is returned as is
+func WithConfiguredPayment(proposal market.ServiceProposal) market.ServiceProposal {
+	price := config.Current.GetFloat64(pricePerGBFlag.Name)
+	if price <= 0 {
+		return proposal
+	}
+
+	proposal.PaymentMethodType = dto.PaymentMethodPerBytes
+	proposal.PaymentMethod = dto.PaymentPerBytes{
+		Price: money.NewMoney(price, money.CurrencyMyst),
+		Bytes: datasize.GB,
+	}
+	return proposal
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 )
 
 // Bootstrap is called on program initialization time and registers various deserializers related to wireguard service
@@ -35,7 +36,7 @@ func Bootstrap() {
 		},
 	)
 
-	// TODO per time or per bytes payment methods should be defined here
+	// TODO per time payment method should be defined here
 	market.RegisterPaymentMethodUnserializer(
 		PaymentMethod,
 		func(rawDefinition *json.RawMessage) (market.PaymentMethod, error) {
@@ -45,4 +46,14 @@ func Bootstrap() {
 			return method, err
 		},
 	)
+
+	market.RegisterPaymentMethodUnserializer(
+		dto.PaymentMethodPerBytes,
+		func(rawDefinition *json.RawMessage) (market.PaymentMethod, error) {
+			var method dto.PaymentPerBytes
+			err := json.Unmarshal(*rawDefinition, &method)
+
+			return method, err
+		},
+	)
 }
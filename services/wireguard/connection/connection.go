@@ -18,6 +18,7 @@
 package connection
 
 import (
+	"context"
 	"encoding/json"
 	"net"
 	"sync"
@@ -45,9 +46,15 @@ type Connection struct {
 
 	config              wg.ServiceConfig
 	connectionEndpoint  wg.ConnectionEndpoint
+	routes              wg.Routes
 	removeAllowedIPRule func()
 
 	configDir string
+
+	handshake handshakeMonitor
+	monitor   sync.WaitGroup
+	stopOnce  sync.Once
+	exitErr   error
 }
 
 // Start establish wireguard connection to the service provider.
@@ -93,20 +100,26 @@ func (c *Connection) Start(options connection.ConnectOptions) (err error) {
 		return errors.Wrap(err, "failed to add peer to the connection endpoint")
 	}
 
-	if err := c.connectionEndpoint.ConfigureRoutes(c.config.Provider.Endpoint.IP); err != nil {
+	c.routes = wg.Routes{
+		Via:     options.ChainedThrough,
+		Include: options.Routes.Include,
+		Exclude: options.Routes.Exclude,
+	}
+	if err := c.connectionEndpoint.ConfigureRoutes(c.config.Provider.Endpoint.IP, c.routes); err != nil {
 		c.stateChannel <- connection.NotConnected
 		c.connection.Done()
 		removeAllowedIPRule()
 		return errors.Wrap(err, "failed to configure routes for connection endpoint")
 	}
 
-	if err := c.waitHandshake(); err != nil {
+	if err := c.waitHandshake(context.Background(), nil); err != nil {
 		c.stateChannel <- connection.NotConnected
 		c.connection.Done()
 		removeAllowedIPRule()
 		return errors.Wrap(err, "failed while waiting for a peer handshake")
 	}
 
+	c.monitor.Add(1)
 	go c.runPeriodically(time.Second)
 
 	if options.EnableDNS {
@@ -122,9 +135,39 @@ func (c *Connection) Start(options connection.ConnectOptions) (err error) {
 	return nil
 }
 
+// InterfaceName returns the name of the tunnel interface, so the following hops could be chained through it.
+func (c *Connection) InterfaceName() string {
+	return c.connectionEndpoint.InterfaceName()
+}
+
 // Wait blocks until wireguard connection not stopped.
+// Error is returned if the connection gave up waiting for the peer handshake.
 func (c *Connection) Wait() error {
 	c.connection.Wait()
+	return c.exitErr
+}
+
+// Roam updates the provider endpoint once the network has changed: the route to the provider is set through
+// the new gateway and the peer is set again to forget the source address and the keys of the previous network.
+// Then the handshake is initiated again as on start, the connection is reported connected once the provider answers.
+// Waiting for the handshake is given up once the context is canceled.
+func (c *Connection) Roam(ctx context.Context) error {
+	c.reportState(connection.Reconnecting)
+
+	if err := c.connectionEndpoint.ConfigureRoutes(c.config.Provider.Endpoint.IP, c.routes); err != nil {
+		return errors.Wrap(err, "failed to configure routes for the new network")
+	}
+	if err := c.connectionEndpoint.RemovePeer(c.config.Provider.PublicKey); err != nil {
+		return errors.Wrap(err, "failed to remove the peer of the previous network")
+	}
+	if err := c.connectionEndpoint.AddPeer(c.config.Provider.PublicKey, &c.config.Provider.Endpoint); err != nil {
+		return errors.Wrap(err, "failed to update the peer endpoint")
+	}
+	if err := c.waitHandshake(ctx, time.After(c.handshake.timeout)); err != nil {
+		return errors.Wrap(err, "failed while waiting for a peer handshake in the new network")
+	}
+
+	c.reportState(connection.Connected)
 	return nil
 }
 
@@ -141,6 +184,13 @@ func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
 
 // Stop stops wireguard connection and closes connection endpoint.
 func (c *Connection) Stop() {
+	c.stopOnce.Do(c.stop)
+}
+
+func (c *Connection) stop() {
+	close(c.stopChannel)
+	c.monitor.Wait()
+
 	c.stateChannel <- connection.Disconnecting
 	c.sendStats()
 
@@ -153,16 +203,32 @@ func (c *Connection) Stop() {
 	c.removeAllowedIPRule()
 	c.stateChannel <- connection.NotConnected
 	c.connection.Done()
-	close(c.stopChannel)
 	close(c.stateChannel)
 	close(c.statisticsChannel)
 }
 
+// runPeriodically reports the statistics and watches the peer handshake until the connection is stopped,
+// the connection is stopped by itself if the handshake is not renewed within the reconnect timeout
 func (c *Connection) runPeriodically(duration time.Duration) {
+	defer c.monitor.Done()
 	for {
 		select {
 		case <-time.After(duration):
-			c.sendStats()
+			stats, err := c.sendStats()
+			if err != nil {
+				continue
+			}
+
+			state, err := c.handshake.check(stats.LastHandshake, time.Now())
+			if err != nil {
+				log.Error("giving up the connection: ", err)
+				c.exitErr = err
+				go c.Stop()
+				return
+			}
+			if state != "" {
+				c.reportState(state)
+			}
 
 		case <-c.stopChannel:
 			return
@@ -170,21 +236,35 @@ func (c *Connection) runPeriodically(duration time.Duration) {
 	}
 }
 
-func (c *Connection) sendStats() {
+func (c *Connection) sendStats() (wg.Stats, error) {
 	stats, err := c.connectionEndpoint.PeerStats()
 	if err != nil {
 		log.Error("failed to receive peer stats: ", err)
-		return
+		return stats, err
 	}
 	c.statisticsChannel <- consumer.SessionStatistics{
 		BytesSent:     stats.BytesSent,
 		BytesReceived: stats.BytesReceived,
 	}
+	return stats, nil
 }
 
-func (c *Connection) waitHandshake() error {
-	// We need to send any packet to initialize handshake process
+// sendPacket sends any packet to the tunnel, which initializes the handshake process if there is no session
+func sendPacket() {
 	_, _ = net.DialTimeout("tcp", "8.8.8.8:53", 100*time.Millisecond)
+}
+
+// reportState sends the state unless the connection is being stopped, the state channel is not read anymore then
+func (c *Connection) reportState(state connection.State) {
+	select {
+	case c.stateChannel <- state:
+	case <-c.stopChannel:
+	}
+}
+
+// waitHandshake initiates the handshake and waits for it until the timeout, nil timeout waits until the connection is stopped
+func (c *Connection) waitHandshake(ctx context.Context, timeout <-chan time.Time) error {
+	sendPacket()
 	for {
 		select {
 		case <-time.After(100 * time.Millisecond):
@@ -196,6 +276,12 @@ func (c *Connection) waitHandshake() error {
 				return nil
 			}
 
+		case <-timeout:
+			return ErrHandshakeTimeout
+
+		case <-ctx.Done():
+			return ctx.Err()
+
 		case <-c.stopChannel:
 			return errors.New("stop received")
 		}
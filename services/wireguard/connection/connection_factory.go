@@ -18,6 +18,8 @@
 package connection
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/core/connection"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
@@ -25,7 +27,8 @@ import (
 
 // Factory is the wireguard connection factory
 type Factory struct {
-	configDir string
+	configDir        string
+	reconnectTimeout time.Duration
 }
 
 // Create creates a new wireguard connection
@@ -43,12 +46,14 @@ func (f *Factory) Create(stateChannel connection.StateChannel, statisticsChannel
 		statisticsChannel: statisticsChannel,
 		config:            config,
 		configDir:         f.configDir,
+		handshake:         handshakeMonitor{timeout: f.reconnectTimeout},
 	}, nil
 }
 
-// NewConnectionCreator creates wireguard connections
-func NewConnectionCreator(configDir string) connection.Factory {
+// NewConnectionCreator creates wireguard connections, which give up reconnecting to the lost peer after the given timeout
+func NewConnectionCreator(configDir string, reconnectTimeout time.Duration) connection.Factory {
 	return &Factory{
-		configDir: configDir,
+		configDir:        configDir,
+		reconnectTimeout: reconnectTimeout,
 	}
 }
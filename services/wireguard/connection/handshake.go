@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/pkg/errors"
+)
+
+// staleHandshakeAge is the age of the last handshake after which the peer is considered lost.
+// Keys are renewed every 2 minutes while the keepalives flow and are rejected by WireGuard after 3 minutes.
+const staleHandshakeAge = 3 * time.Minute
+
+// ErrHandshakeTimeout indicates that the handshake with the peer was not renewed within the reconnect timeout
+var ErrHandshakeTimeout = errors.New("no handshake with the peer within the reconnect timeout")
+
+// handshakeMonitor tracks the age of the peer handshake, reports the connection reconnecting once it is stale
+// and gives up if no handshake happens within the timeout.
+type handshakeMonitor struct {
+	timeout           time.Duration
+	reconnectingSince time.Time
+}
+
+// check returns the state the connection has moved to or empty state if it hasn't changed.
+// Zero last handshake means the handshake with the peer has not happened yet, it is neither fresh nor stale.
+func (m *handshakeMonitor) check(lastHandshake, now time.Time) (connection.State, error) {
+	handshaken := !lastHandshake.IsZero()
+	fresh := handshaken && now.Sub(lastHandshake) <= staleHandshakeAge
+	reconnecting := !m.reconnectingSince.IsZero()
+
+	switch {
+	case fresh && reconnecting:
+		log.Info("peer handshake renewed, connection restored")
+		m.reconnectingSince = time.Time{}
+		return connection.Connected, nil
+	case handshaken && !fresh && !reconnecting:
+		log.Warn("no peer handshake since ", lastHandshake, ", reconnecting")
+		m.reconnectingSince = now
+		return connection.Reconnecting, nil
+	case !fresh && reconnecting && now.Sub(m.reconnectingSince) > m.timeout:
+		return "", errors.Wrapf(ErrHandshakeTimeout, "last handshake at %v, gave up after %v", lastHandshake, m.timeout)
+	}
+	return "", nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandshakeMonitor_ReportsNothingWhileHandshakeIsFresh(t *testing.T) {
+	monitor := handshakeMonitor{timeout: time.Minute}
+	now := time.Now()
+
+	state, err := monitor.check(now.Add(-2*time.Minute), now)
+	assert.NoError(t, err)
+	assert.Equal(t, connection.State(""), state)
+}
+
+func TestHandshakeMonitor_ReportsNothingBeforeFirstHandshake(t *testing.T) {
+	monitor := handshakeMonitor{timeout: time.Minute}
+	now := time.Now()
+
+	state, err := monitor.check(time.Time{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, connection.State(""), state)
+
+	state, err = monitor.check(time.Time{}, now.Add(staleHandshakeAge+time.Minute+time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, connection.State(""), state)
+}
+
+func TestHandshakeMonitor_ReportsReconnectingAndRestored(t *testing.T) {
+	monitor := handshakeMonitor{timeout: time.Minute}
+	lastHandshake := time.Now()
+	now := lastHandshake.Add(staleHandshakeAge + time.Second)
+
+	state, err := monitor.check(lastHandshake, now)
+	assert.NoError(t, err)
+	assert.Equal(t, connection.Reconnecting, state)
+
+	state, err = monitor.check(lastHandshake, now.Add(30*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, connection.State(""), state)
+
+	now = now.Add(40 * time.Second)
+	state, err = monitor.check(now, now)
+	assert.NoError(t, err)
+	assert.Equal(t, connection.Connected, state)
+}
+
+func TestHandshakeMonitor_KeepsReconnectingWhileHandshakeIsPending(t *testing.T) {
+	monitor := handshakeMonitor{timeout: time.Minute}
+	lastHandshake := time.Now()
+	now := lastHandshake.Add(staleHandshakeAge + time.Second)
+
+	state, err := monitor.check(lastHandshake, now)
+	assert.NoError(t, err)
+	assert.Equal(t, connection.Reconnecting, state)
+
+	state, err = monitor.check(time.Time{}, now.Add(30*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, connection.State(""), state)
+
+	_, err = monitor.check(time.Time{}, now.Add(time.Minute+time.Second))
+	assert.Equal(t, ErrHandshakeTimeout, errors.Cause(err))
+}
+
+func TestHandshakeMonitor_GivesUpAfterTimeout(t *testing.T) {
+	monitor := handshakeMonitor{timeout: time.Minute}
+	lastHandshake := time.Now()
+	now := lastHandshake.Add(staleHandshakeAge + time.Second)
+
+	state, err := monitor.check(lastHandshake, now)
+	assert.NoError(t, err)
+	assert.Equal(t, connection.Reconnecting, state)
+
+	_, err = monitor.check(lastHandshake, now.Add(time.Minute+time.Second))
+	assert.Equal(t, ErrHandshakeTimeout, errors.Cause(err))
+}
@@ -33,7 +33,8 @@ const logPrefix = "[wireguard-connection-endpoint] "
 
 type wgClient interface {
 	ConfigureDevice(name string, config wg.DeviceConfig, subnet net.IPNet) error
-	ConfigureRoutes(iface string, ip net.IP) error
+	ConfigureRoutes(iface string, ip net.IP, routes wg.Routes) error
+	LimitRate(iface string, rate uint64) error
 	DestroyDevice(name string) error
 	AddPeer(name string, peer wg.PeerInfo, allowedIP ...string) error
 	RemovePeer(name string, publicKey string) error
@@ -149,8 +150,15 @@ func (ce *connectionEndpoint) Config() (wg.ServiceConfig, error) {
 	return config, nil
 }
 
-func (ce *connectionEndpoint) ConfigureRoutes(ip net.IP) error {
-	return ce.wgClient.ConfigureRoutes(ce.iface, ip)
+// ConfigureRoutes routes the traffic into the tunnel, keeping the provider endpoint reachable
+// either through the physical gateway or through the interface of the previous hop.
+func (ce *connectionEndpoint) ConfigureRoutes(ip net.IP, routes wg.Routes) error {
+	return ce.wgClient.ConfigureRoutes(ce.iface, ip, routes)
+}
+
+// LimitRate limits the bandwidth of the wireguard network interface to the given rate in bits per second.
+func (ce *connectionEndpoint) LimitRate(rate uint64) error {
+	return ce.wgClient.LimitRate(ce.iface, rate)
 }
 
 // Stop closes wireguard client and destroys wireguard network interface.
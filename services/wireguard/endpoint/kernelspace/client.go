@@ -20,16 +20,21 @@ package kernelspace
 import (
 	"encoding/base64"
 	"net"
+	"time"
 
 	log "github.com/cihub/seelog"
 	"github.com/jackpal/gateway"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/shaper"
 	"github.com/mysteriumnetwork/node/utils"
 	"github.com/pkg/errors"
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
 
+// keepAliveInterval is the interval the consumer keeps the peer alive at, so handshakes are renewed on idle tunnel
+const keepAliveInterval = 20 * time.Second
+
 var allowedIPs = []net.IPNet{
 	{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
 	{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
@@ -38,6 +43,9 @@ var allowedIPs = []net.IPNet{
 type client struct {
 	iface    string
 	wgClient *wgctrl.Client
+	shaper   shaper.Shaper
+	// splitRoutes are the routes of the included and excluded networks, they are deleted on close
+	splitRoutes []net.IPNet
 }
 
 // NewWireguardClient creates new wireguard kernel space client.
@@ -46,7 +54,7 @@ func NewWireguardClient() (*client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &client{wgClient: wgClient}, nil
+	return &client{wgClient: wgClient, shaper: shaper.New()}, nil
 }
 
 func (c *client) ConfigureDevice(iface string, config wg.DeviceConfig, ipAddr net.IPNet) error {
@@ -75,12 +83,19 @@ func (c *client) AddPeer(iface string, peer wg.PeerInfo, _ ...string) error {
 		return err
 	}
 
-	var deviceConfig wgtypes.Config
-	deviceConfig.Peers = []wgtypes.PeerConfig{{
+	peerConfig := wgtypes.PeerConfig{
 		Endpoint:   endpoint,
 		PublicKey:  publicKey,
 		AllowedIPs: allowedIPs,
-	}}
+	}
+	if endpoint != nil {
+		// only the consumer knows the endpoint of its peer
+		keepAlive := keepAliveInterval
+		peerConfig.PersistentKeepaliveInterval = &keepAlive
+	}
+
+	var deviceConfig wgtypes.Config
+	deviceConfig.Peers = []wgtypes.PeerConfig{peerConfig}
 	return c.wgClient.ConfigureDevice(iface, deviceConfig)
 }
 
@@ -131,11 +146,36 @@ func (c *client) up(iface string, ipAddr net.IPNet) error {
 	return utils.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
-func (c *client) ConfigureRoutes(iface string, ip net.IP) error {
-	if err := excludeRoute(ip); err != nil {
+func (c *client) ConfigureRoutes(iface string, ip net.IP, routes wg.Routes) error {
+	if routes.Via != "" {
+		if err := routeThrough(ip, routes.Via); err != nil {
+			return err
+		}
+	} else if err := excludeRoute(ip); err != nil {
 		return err
 	}
-	return addDefaultRoute(iface)
+
+	for _, network := range routes.Exclude {
+		if err := excludeNetwork(network); err != nil {
+			return err
+		}
+		c.splitRoutes = append(c.splitRoutes, network)
+	}
+
+	if len(routes.Include) == 0 {
+		return addDefaultRoute(iface)
+	}
+	for _, network := range routes.Include {
+		if err := includeNetwork(network, iface); err != nil {
+			return err
+		}
+		c.splitRoutes = append(c.splitRoutes, network)
+	}
+	return nil
+}
+
+func (c *client) LimitRate(iface string, rate uint64) error {
+	return c.shaper.Limit(iface, rate)
 }
 
 func excludeRoute(ip net.IP) error {
@@ -147,6 +187,27 @@ func excludeRoute(ip net.IP) error {
 	return utils.SudoExec("ip", "route", "replace", ip.String(), "via", gw.String())
 }
 
+func excludeNetwork(network net.IPNet) error {
+	gw, err := gateway.DiscoverGateway()
+	if err != nil {
+		return err
+	}
+
+	return utils.SudoExec("ip", "route", "replace", network.String(), "via", gw.String())
+}
+
+func includeNetwork(network net.IPNet, iface string) error {
+	return utils.SudoExec("ip", "route", "replace", network.String(), "dev", iface)
+}
+
+func deleteNetworkRoute(network net.IPNet) error {
+	return utils.SudoExec("ip", "route", "del", network.String())
+}
+
+func routeThrough(ip net.IP, iface string) error {
+	return utils.SudoExec("ip", "route", "replace", ip.String(), "dev", iface)
+}
+
 func addDefaultRoute(iface string) error {
 	if err := utils.SudoExec("ip", "route", "replace", "0.0.0.0/1", "dev", iface); err != nil {
 		return err
@@ -165,6 +226,13 @@ func (c *client) Close() (err error) {
 		}
 	}()
 
+	for _, network := range c.splitRoutes {
+		if err := deleteNetworkRoute(network); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.splitRoutes = nil
+
 	if err := c.DestroyDevice(c.iface); err != nil {
 		errs = append(errs, err)
 	}
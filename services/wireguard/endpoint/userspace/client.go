@@ -23,25 +23,40 @@ import (
 	"time"
 
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/shaper"
 	"github.com/mysteriumnetwork/wireguard-go/device"
 	"github.com/mysteriumnetwork/wireguard-go/tun"
 	"github.com/pkg/errors"
 )
 
+// keepAlivePeriod is the period in seconds the consumer keeps the peer alive at
+const keepAlivePeriod = 20
+
 type client struct {
 	tun    tun.TUNDevice
 	devAPI *device.DeviceApi
+
+	readLimit  *shaper.Bucket
+	writeLimit *shaper.Bucket
+
+	// splitRoutes are the routes of the included and excluded networks, they are deleted on close
+	splitRoutes []net.IPNet
 }
 
 // NewWireguardClient creates new wireguard user space client.
 func NewWireguardClient() (*client, error) {
-	return &client{}, nil
+	return &client{
+		readLimit:  shaper.NewBucket(),
+		writeLimit: shaper.NewBucket(),
+	}, nil
 }
 
-func (c *client) ConfigureDevice(name string, config wg.DeviceConfig, subnet net.IPNet) (err error) {
-	if c.tun, err = CreateTUN(name, subnet); err != nil {
+func (c *client) ConfigureDevice(name string, config wg.DeviceConfig, subnet net.IPNet) error {
+	tunDevice, err := CreateTUN(name, subnet)
+	if err != nil {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
+	c.tun = &limitedTUN{TUNDevice: tunDevice, readLimit: c.readLimit, writeLimit: c.writeLimit}
 
 	c.devAPI = device.UserspaceDeviceApi(c.tun)
 	if err := c.devAPI.SetListeningPort(uint16(config.ListenPort())); err != nil {
@@ -81,6 +96,8 @@ func (c *client) AddPeer(name string, peer wg.PeerInfo, allowedIPs ...string) er
 		if err != nil {
 			return err
 		}
+		// only the consumer knows the endpoint of its peer, keepalives renew handshakes on idle tunnel
+		extPeer.KeepAlivePeriod = keepAlivePeriod
 	}
 
 	return c.devAPI.AddPeer(extPeer)
@@ -98,15 +115,50 @@ func (c *client) RemovePeer(_ string, publicKey string) error {
 }
 
 func (c *client) Close() error {
+	var routeErr error
+	for _, network := range c.splitRoutes {
+		if err := deleteNetworkRoute(network); err != nil && routeErr == nil {
+			routeErr = errors.Wrap(err, "failed to delete split tunnel route "+network.String())
+		}
+	}
+	c.splitRoutes = nil
+
 	c.devAPI.Close() // c.devAPI.Close() closes c.tun too
-	return nil
+	return routeErr
 }
 
-func (c *client) ConfigureRoutes(iface string, ip net.IP) error {
-	if err := excludeRoute(ip); err != nil {
+func (c *client) ConfigureRoutes(iface string, ip net.IP, routes wg.Routes) error {
+	if routes.Via != "" {
+		if err := routeThrough(ip, routes.Via); err != nil {
+			return err
+		}
+	} else if err := excludeRoute(ip); err != nil {
 		return err
 	}
-	return addDefaultRoute(iface)
+
+	for _, network := range routes.Exclude {
+		if err := excludeNetwork(network); err != nil {
+			return err
+		}
+		c.splitRoutes = append(c.splitRoutes, network)
+	}
+
+	if len(routes.Include) == 0 {
+		return addDefaultRoute(iface)
+	}
+	for _, network := range routes.Include {
+		if err := includeNetwork(network, iface); err != nil {
+			return err
+		}
+		c.splitRoutes = append(c.splitRoutes, network)
+	}
+	return nil
+}
+
+func (c *client) LimitRate(_ string, rate uint64) error {
+	c.readLimit.SetRate(rate)
+	c.writeLimit.SetRate(rate)
+	return nil
 }
 
 func (c *client) PeerStats() (wg.Stats, error) {
@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package userspace
+
+import (
+	"github.com/mysteriumnetwork/node/shaper"
+	"github.com/mysteriumnetwork/wireguard-go/tun"
+)
+
+// limitedTUN limits the rate of packets read from and written to the TUN device
+type limitedTUN struct {
+	tun.TUNDevice
+	readLimit  *shaper.Bucket
+	writeLimit *shaper.Bucket
+}
+
+func (t *limitedTUN) Read(buff []byte, offset int) (int, error) {
+	n, err := t.TUNDevice.Read(buff, offset)
+	t.readLimit.Wait(n)
+	return n, err
+}
+
+func (t *limitedTUN) Write(buff []byte, offset int) (int, error) {
+	t.writeLimit.Wait(len(buff) - offset)
+	return t.TUNDevice.Write(buff, offset)
+}
@@ -37,6 +37,27 @@ func excludeRoute(ip net.IP) error {
 	return utils.SudoExec("route", "add", "-host", ip.String(), gw.String())
 }
 
+func excludeNetwork(network net.IPNet) error {
+	gw, err := gateway.DiscoverGateway()
+	if err != nil {
+		return err
+	}
+
+	return utils.SudoExec("route", "add", "-net", network.String(), gw.String())
+}
+
+func includeNetwork(network net.IPNet, iface string) error {
+	return utils.SudoExec("route", "add", "-net", network.String(), "-interface", iface)
+}
+
+func deleteNetworkRoute(network net.IPNet) error {
+	return utils.SudoExec("route", "delete", "-net", network.String())
+}
+
+func routeThrough(ip net.IP, iface string) error {
+	return utils.SudoExec("route", "add", "-host", ip.String(), "-interface", iface)
+}
+
 func addDefaultRoute(iface string) error {
 	if err := utils.SudoExec("route", "add", "-net", "0.0.0.0/1", "-interface", iface); err != nil {
 		return err
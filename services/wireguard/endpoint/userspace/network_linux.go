@@ -37,15 +37,36 @@ func excludeRoute(ip net.IP) error {
 		return err
 	}
 
-	return utils.SudoExec("route", "add", "-host", ip.String(), gw.String())
+	return utils.SudoExec("ip", "route", "replace", ip.String()+"/32", "via", gw.String())
+}
+
+func excludeNetwork(network net.IPNet) error {
+	gw, err := gateway.DiscoverGateway()
+	if err != nil {
+		return err
+	}
+
+	return utils.SudoExec("ip", "route", "replace", network.String(), "via", gw.String())
+}
+
+func includeNetwork(network net.IPNet, iface string) error {
+	return utils.SudoExec("ip", "route", "replace", network.String(), "dev", iface)
+}
+
+func deleteNetworkRoute(network net.IPNet) error {
+	return utils.SudoExec("ip", "route", "del", network.String())
+}
+
+func routeThrough(ip net.IP, iface string) error {
+	return utils.SudoExec("ip", "route", "replace", ip.String()+"/32", "dev", iface)
 }
 
 func addDefaultRoute(iface string) error {
-	if err := utils.SudoExec("route", "add", "-net", "0.0.0.0/1", "-interface", iface); err != nil {
+	if err := utils.SudoExec("ip", "route", "replace", "0.0.0.0/1", "dev", iface); err != nil {
 		return err
 	}
 
-	return utils.SudoExec("route", "add", "-net", "128.0.0.0/1", "-interface", iface)
+	return utils.SudoExec("ip", "route", "replace", "128.0.0.0/1", "dev", iface)
 }
 
 func destroyDevice(name string) error {
@@ -49,6 +49,46 @@ func excludeRoute(ip net.IP) error {
 	return errors.Wrap(err, string(out))
 }
 
+func excludeNetwork(network net.IPNet) error {
+	gw, err := gateway.DiscoverGateway()
+	if err != nil {
+		return err
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route add "+networkWithMask(network)+" "+gw.String()).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
+func includeNetwork(network net.IPNet, name string) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route add "+networkWithMask(network)+" "+gw+" if "+id).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
+func deleteNetworkRoute(network net.IPNet) error {
+	out, err := exec.Command("powershell", "-Command", "route delete "+networkWithMask(network)).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
+// networkWithMask formats the network as route command expects it, i.e. "10.0.0.0 MASK 255.0.0.0"
+func networkWithMask(network net.IPNet) string {
+	return network.IP.String() + " MASK " + net.IP(network.Mask).String()
+}
+
+func routeThrough(ip net.IP, name string) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	out, err := exec.Command("powershell", "-Command", "route add "+ip.String()+"/32 "+gw+" if "+id).CombinedOutput()
+	return errors.Wrap(err, string(out))
+}
+
 func addDefaultRoute(name string) error {
 	id, gw, err := interfaceInfo(name)
 	if err != nil {
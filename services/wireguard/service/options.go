@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/logconfig"
+	"github.com/mysteriumnetwork/node/services/shared"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -37,6 +38,7 @@ type Options struct {
 	ConnectDelay int
 	Ports        *port.Range
 	Subnet       net.IPNet
+	RateLimit    service.OptionsRateLimit
 }
 
 var (
@@ -110,6 +112,7 @@ func ConfiguredOptions() Options {
 		ConnectDelay: config.Current.GetInt(delayFlag.Name),
 		Ports:        portRange,
 		Subnet:       *ipnet,
+		RateLimit:    shared.ConfiguredRateLimit(),
 	}
 }
 
@@ -128,22 +131,28 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		ConnectDelay int    `json:"connectDelay"`
-		Ports        string `json:"ports"`
-		Subnet       string `json:"subnet"`
+		ConnectDelay     int    `json:"connectDelay"`
+		Ports            string `json:"ports"`
+		Subnet           string `json:"subnet"`
+		SessionRateLimit uint64 `json:"sessionRateLimit,omitempty"`
+		ServiceRateLimit uint64 `json:"serviceRateLimit,omitempty"`
 	}{
-		ConnectDelay: o.ConnectDelay,
-		Ports:        o.Ports.String(),
-		Subnet:       o.Subnet.String(),
+		ConnectDelay:     o.ConnectDelay,
+		Ports:            o.Ports.String(),
+		Subnet:           o.Subnet.String(),
+		SessionRateLimit: o.RateLimit.Session,
+		ServiceRateLimit: o.RateLimit.Service,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		ConnectDelay int    `json:"connectDelay"`
-		Ports        string `json:"ports"`
-		Subnet       string `json:"subnet"`
+		ConnectDelay     int    `json:"connectDelay"`
+		Ports            string `json:"ports"`
+		Subnet           string `json:"subnet"`
+		SessionRateLimit uint64 `json:"sessionRateLimit"`
+		ServiceRateLimit uint64 `json:"serviceRateLimit"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -167,6 +176,12 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		}
 		o.Subnet = *ipnet
 	}
+	if options.SessionRateLimit != 0 {
+		o.RateLimit.Session = options.SessionRateLimit
+	}
+	if options.ServiceRateLimit != 0 {
+		o.RateLimit.Service = options.ServiceRateLimit
+	}
 
 	return nil
 }
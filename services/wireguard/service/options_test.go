@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,3 +59,22 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 		},
 	}, options)
 }
+
+func Test_ParseJSONOptions_RateLimits(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"sessionRateLimit": 1000000, "serviceRateLimit": 5000000}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, service.OptionsRateLimit{Session: 1000000, Service: 5000000}, options.(Options).RateLimit)
+}
+
+func Test_Options_MarshalJSON_RateLimits(t *testing.T) {
+	options := DefaultOptions
+	options.RateLimit = service.OptionsRateLimit{Session: 1000000}
+
+	data, err := json.Marshal(options)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"connectDelay": 2000, "ports": "0:0", "subnet": "10.182.0.0/16", "sessionRateLimit": 1000000}`, string(data))
+}
@@ -19,13 +19,14 @@ package service
 
 import (
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 )
 
 // GetProposal returns the proposal for wireguard service
-func GetProposal(location location.Location) market.ServiceProposal {
+func GetProposal(location location.Location, rateLimit service.OptionsRateLimit) market.ServiceProposal {
 	marketLocation := market.Location{
 		Continent: location.Continent,
 		Country:   location.Country,
@@ -41,6 +42,8 @@ func GetProposal(location location.Location) market.ServiceProposal {
 		ServiceDefinition: wg.ServiceDefinition{
 			Location:          marketLocation,
 			LocationOriginate: marketLocation,
+			SessionBandwidth:  rateLimit.Session,
+			ServiceBandwidth:  rateLimit.Service,
 		},
 		PaymentMethodType: wg.PaymentMethod,
 		PaymentMethod: wg.Payment{
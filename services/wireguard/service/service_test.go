@@ -25,11 +25,13 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/nat"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/shaper"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,10 +61,18 @@ func Test_GetProposal(t *testing.T) {
 				},
 			},
 		},
-		GetProposal(location.Location{Country: country}),
+		GetProposal(location.Location{Country: country}, service.OptionsRateLimit{}),
 	)
 }
 
+func Test_GetProposal_AnnouncesRateLimits(t *testing.T) {
+	proposal := GetProposal(location.Location{Country: country}, service.OptionsRateLimit{Session: 1000, Service: 5000})
+
+	definition := proposal.ServiceDefinition.(wg.ServiceDefinition)
+	assert.Equal(t, uint64(1000), definition.SessionBandwidth)
+	assert.Equal(t, uint64(5000), definition.ServiceBandwidth)
+}
+
 func Test_Manager_Serve(t *testing.T) {
 	manager := newManagerStub(pubIP, outIP, country)
 
@@ -102,7 +112,8 @@ func (mce *mockConnectionEndpoint) Start(_ *wg.ServiceConfig) error
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)                   { return wg.ServiceConfig{}, nil }
 func (mce *mockConnectionEndpoint) AddPeer(_ string, _ *net.UDPAddr, _ ...string) error { return nil }
 func (mce *mockConnectionEndpoint) RemovePeer(_ string) error                           { return nil }
-func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP) error                      { return nil }
+func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP, _ wg.Routes) error         { return nil }
+func (mce *mockConnectionEndpoint) LimitRate(_ uint64) error                            { return nil }
 func (mce *mockConnectionEndpoint) PeerStats() (wg.Stats, error) {
 	return wg.Stats{LastHandshake: time.Now()}, nil
 }
@@ -111,6 +122,7 @@ func newManagerStub(pub, out, country string) *Manager {
 	return &Manager{
 		ipResolver: ip.NewResolverMock("1.2.3.4"),
 		natService: &serviceFake{},
+		rateShare:  shaper.NewShare(service.OptionsRateLimit{}),
 		connectionEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return connectionEndpointStub, nil
 		},
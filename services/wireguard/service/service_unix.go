@@ -34,6 +34,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/shaper"
 	"github.com/mysteriumnetwork/node/utils"
 )
 
@@ -44,11 +45,14 @@ func NewManager(
 	portMap func(port int) (releasePortMapping func()),
 	options Options,
 	portSupplier port.ServicePortSupplier,
+	dnsResolver *dns.Resolver,
 ) *Manager {
 	resourceAllocator := resources.NewAllocator(portSupplier, options.Subnet)
 	return &Manager{
-		natService: natService,
-		ipResolver: ipResolver,
+		natService:  natService,
+		ipResolver:  ipResolver,
+		rateShare:   shaper.NewShare(options.RateLimit),
+		dnsResolver: dnsResolver,
 
 		connectionEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return endpoint.NewConnectionEndpoint(ipResolver, resourceAllocator, portMap, options.ConnectDelay)
@@ -63,7 +67,9 @@ type Manager struct {
 
 	connectionEndpointFactory func() (wg.ConnectionEndpoint, error)
 
-	ipResolver ip.Resolver
+	ipResolver  ip.Resolver
+	rateShare   *shaper.Share
+	dnsResolver *dns.Resolver
 }
 
 // ProvideConfig provides the config for consumer
@@ -87,6 +93,11 @@ func (manager *Manager) ProvideConfig(sessionConfig json.RawMessage, traversalPa
 		return nil, err
 	}
 
+	// every session has its own interface, so the service limits are shared among the interfaces
+	if err := manager.rateShare.Add(connectionEndpoint.InterfaceName(), connectionEndpoint); err != nil {
+		return nil, err
+	}
+
 	config, err := connectionEndpoint.Config()
 	if err != nil {
 		return nil, err
@@ -95,7 +106,7 @@ func (manager *Manager) ProvideConfig(sessionConfig json.RawMessage, traversalPa
 	config.Consumer.DNS = utils.FirstIP(config.Consumer.IPAddress).String()
 	dnsServer := dns.NewServer(
 		net.JoinHostPort(config.Consumer.DNS, "53"),
-		dns.ResolveViaConfigured(),
+		manager.dnsResolver,
 	)
 
 	log.Info("starting DNS on: ", dnsServer.Addr)
@@ -116,6 +127,7 @@ func (manager *Manager) ProvideConfig(sessionConfig json.RawMessage, traversalPa
 	}
 
 	destroy := func() {
+		manager.rateShare.Remove(connectionEndpoint.InterfaceName())
 		if err := dnsServer.Stop(); err != nil {
 			log.Error("failed to stop DNS server", err)
 		}
@@ -127,7 +139,21 @@ func (manager *Manager) ProvideConfig(sessionConfig json.RawMessage, traversalPa
 		}
 	}
 
-	return &session.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy, TraversalParams: traversalParams}, nil
+	// every session has its own interface, so the stats of its only peer are the data transferred during the session
+	dataTransfer := func() (session.DataTransfered, error) {
+		stats, err := connectionEndpoint.PeerStats()
+		if err != nil {
+			return session.DataTransfered{}, err
+		}
+		return session.DataTransfered{Up: int64(stats.BytesSent), Down: int64(stats.BytesReceived)}, nil
+	}
+
+	return &session.ConfigParams{
+		SessionServiceConfig:   config,
+		SessionDestroyCallback: destroy,
+		TraversalParams:        traversalParams,
+		SessionDataTransfer:    dataTransfer,
+	}, nil
 }
 
 // Serve starts service - does block
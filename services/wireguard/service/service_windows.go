@@ -23,6 +23,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/nat"
@@ -34,13 +35,15 @@ import (
 	"github.com/pkg/errors"
 )
 
-// NewManager creates new instance of Wireguard service
+// NewManager creates new instance of Wireguard service.
+// DNS resolver is not used on Windows, as the consumers are not served DNS there yet.
 func NewManager(
 	ipResolver ip.Resolver,
 	natService nat.NATService,
 	portMap func(port int) (releasePortMapping func()),
 	options Options,
 	portSupplier port.ServicePortSupplier,
+	_ *dns.Resolver,
 ) *Manager {
 
 	resourceAllocator := resources.NewAllocator(portSupplier, options.Subnet)
@@ -109,6 +112,14 @@ func (manager *Manager) Serve(providerID identity.Identity) error {
 		return err
 	}
 
+	// all the sessions share a single interface, so only the limit of the whole service is enforced
+	if manager.options.RateLimit.Session != 0 {
+		log.Warn("per session bandwidth limits are not supported on windows, only the service limit is applied")
+	}
+	if err := connectionEndpoint.LimitRate(manager.options.RateLimit.Service); err != nil {
+		return err
+	}
+
 	outIP, err := manager.ipResolver.GetOutboundIPAsString()
 	if err != nil {
 		return err
@@ -37,6 +37,12 @@ type ServiceDefinition struct {
 	// Approximate information on location where the actual tunnelled traffic will originate from.
 	// This is used by providers having their own means of setting tunnels to other remote exit points.
 	LocationOriginate market.Location `json:"location_originate"`
+
+	// Available per session bandwidth in bits per second, unlimited if empty
+	SessionBandwidth uint64 `json:"session_bandwidth,omitempty"`
+
+	// Available bandwidth of all the sessions together in bits per second, unlimited if empty
+	ServiceBandwidth uint64 `json:"service_bandwidth,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
@@ -64,12 +70,23 @@ type ConnectionEndpoint interface {
 	AddPeer(publicKey string, endpoint *net.UDPAddr, allowedIPs ...string) error
 	RemovePeer(publicKey string) error
 	PeerStats() (Stats, error)
-	ConfigureRoutes(ip net.IP) error
+	ConfigureRoutes(ip net.IP, routes Routes) error
+	LimitRate(rate uint64) error
 	Config() (ServiceConfig, error)
 	InterfaceName() string
 	Stop() error
 }
 
+// Routes describes how the consumer traffic is routed into the tunnel.
+type Routes struct {
+	// Via is the interface the provider endpoint is reached through, the physical gateway is used when empty
+	Via string
+	// Include limits the tunnel to the given networks, all traffic is tunneled when empty
+	Include []net.IPNet
+	// Exclude networks are routed through the physical gateway
+	Exclude []net.IPNet
+}
+
 // DeviceConfig describes wireguard device configuration.
 type DeviceConfig interface {
 	PrivateKey() string
@@ -18,6 +18,7 @@
 package session
 
 import (
+	"math/big"
 	"time"
 
 	"github.com/mysteriumnetwork/node/money"
@@ -42,3 +43,26 @@ func (ac AmountCalc) TotalAmount(duration time.Duration) money.Money {
 		Currency: ac.PaymentDef.Price.Currency,
 	}
 }
+
+// DataAmountCalc calculates the pay required given the amount of data transferred
+type DataAmountCalc struct {
+	PaymentDef dto.PaymentPerBytes
+}
+
+// TotalAmount gets the total amount of money to pay given the count of bytes transferred.
+// Partially used units of data are charged proportionally.
+func (ac DataAmountCalc) TotalAmount(bytes uint64) money.Money {
+	total := money.Money{Currency: ac.PaymentDef.Price.Currency}
+
+	unit := uint64(ac.PaymentDef.Bytes.Bytes())
+	if unit == 0 {
+		return total
+	}
+
+	// bytes * price may overflow uint64 for large transfers, so the calculation is done with big ints
+	amount := new(big.Int).SetUint64(bytes)
+	amount.Mul(amount, new(big.Int).SetUint64(ac.PaymentDef.Price.Amount))
+	amount.Div(amount, new(big.Int).SetUint64(unit))
+	total.Amount = amount.Uint64()
+	return total
+}
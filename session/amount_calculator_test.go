@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 	"github.com/stretchr/testify/assert"
@@ -43,3 +44,24 @@ func Test_CorrectMoneyValueIsReturnedForTotalAmount(t *testing.T) {
 
 	assert.Equal(t, uint64(300), totalAmount.Amount)
 }
+
+func Test_DataAmountCalc_TotalAmount(t *testing.T) {
+	aCalc := DataAmountCalc{
+		PaymentDef: dto.PaymentPerBytes{
+			Bytes: datasize.GB,
+			Price: money.NewMoney(2, money.CurrencyMyst),
+		},
+	}
+
+	assert.Equal(t, uint64(0), aCalc.TotalAmount(0).Amount)
+	assert.Equal(t, uint64(300000000), aCalc.TotalAmount(uint64(datasize.GB.Bytes()*1.5)).Amount)
+	assert.Equal(t, money.CurrencyMyst, aCalc.TotalAmount(1).Currency)
+}
+
+func Test_DataAmountCalc_TotalAmountWithoutUnit(t *testing.T) {
+	aCalc := DataAmountCalc{
+		PaymentDef: dto.PaymentPerBytes{Price: money.NewMoney(2, money.CurrencyMyst)},
+	}
+
+	assert.Equal(t, uint64(0), aCalc.TotalAmount(1024).Amount)
+}
@@ -35,10 +35,21 @@ type AmountCalculator interface {
 	TotalAmount(duration time.Duration) money.Money
 }
 
+// DataKeeper keeps track of the data transferred for payments
+type DataKeeper interface {
+	StartTracking()
+	Transferred() uint64
+}
+
+// DataAmountCalculator is able to deduce the amount required for payment from a given count of bytes transferred
+type DataAmountCalculator interface {
+	TotalAmount(bytes uint64) money.Money
+}
+
 // BalanceTracker is responsible for tracking the balance on the provider side
 type BalanceTracker struct {
-	timeKeeper       TimeKeeper
-	amountCalculator AmountCalculator
+	startTracking func()
+	totalCost     func() money.Money
 
 	totalPromised uint64
 	balance       uint64
@@ -49,16 +60,33 @@ type BalanceTracker struct {
 // NewBalanceTracker returns a new instance of the providerBalanceTracker
 func NewBalanceTracker(timeKeeper TimeKeeper, amountCalculator AmountCalculator, initialBalance uint64) *BalanceTracker {
 	return &BalanceTracker{
-		timeKeeper:       timeKeeper,
-		amountCalculator: amountCalculator,
-		totalPromised:    initialBalance,
+		startTracking: timeKeeper.StartTracking,
+		totalCost: func() money.Money {
+			return amountCalculator.TotalAmount(timeKeeper.Elapsed())
+		},
+		totalPromised: initialBalance,
+	}
+}
+
+// NewDataBalanceTracker returns a new instance of the balance tracker charging for the data transferred
+func NewDataBalanceTracker(dataKeeper DataKeeper, amountCalculator DataAmountCalculator, initialBalance uint64) *BalanceTracker {
+	return &BalanceTracker{
+		startTracking: dataKeeper.StartTracking,
+		totalCost: func() money.Money {
+			return amountCalculator.TotalAmount(dataKeeper.Transferred())
+		},
+		totalPromised: initialBalance,
 	}
 }
 
 func (bt *BalanceTracker) calculateBalance() {
 	bt.Lock()
 	defer bt.Unlock()
-	cost := bt.amountCalculator.TotalAmount(bt.timeKeeper.Elapsed())
+	cost := bt.totalCost()
+	if cost.Amount > bt.totalPromised {
+		bt.balance = 0
+		return
+	}
 	bt.balance = bt.totalPromised - cost.Amount
 }
 
@@ -68,9 +96,9 @@ func (bt *BalanceTracker) GetBalance() uint64 {
 	return bt.balance
 }
 
-// Start starts keeping track of time for balance
+// Start starts keeping track of the usage for balance
 func (bt *BalanceTracker) Start() {
-	bt.timeKeeper.StartTracking()
+	bt.startTracking()
 }
 
 // Add increases the current balance by the given amount
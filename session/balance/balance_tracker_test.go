@@ -75,3 +75,45 @@ func (mac *mockAmountCalculator) TotalAmount(duration time.Duration) money.Money
 	mac.calledWith = duration
 	return mac.toReturn
 }
+
+func Test_DataBalanceTracker(t *testing.T) {
+	mdk := &mockDataKeeper{transferred: 2048}
+	mac := &mockDataAmountCalculator{toReturn: money.Money{Amount: 30, Currency: money.CurrencyMyst}}
+	tracker := NewDataBalanceTracker(mdk, mac, 100)
+
+	tracker.Start()
+	assert.True(t, mdk.startCalled)
+
+	assert.Equal(t, uint64(70), tracker.GetBalance())
+	assert.Equal(t, uint64(2048), mac.calledWith)
+}
+
+func Test_BalanceTrackerDoesNotGoBelowZero(t *testing.T) {
+	mac := &mockDataAmountCalculator{toReturn: money.Money{Amount: 130, Currency: money.CurrencyMyst}}
+	tracker := NewDataBalanceTracker(&mockDataKeeper{}, mac, 100)
+
+	assert.Equal(t, uint64(0), tracker.GetBalance())
+}
+
+type mockDataKeeper struct {
+	transferred uint64
+	startCalled bool
+}
+
+func (mdk *mockDataKeeper) StartTracking() {
+	mdk.startCalled = true
+}
+
+func (mdk *mockDataKeeper) Transferred() uint64 {
+	return mdk.transferred
+}
+
+type mockDataAmountCalculator struct {
+	calledWith uint64
+	toReturn   money.Money
+}
+
+func (mac *mockDataAmountCalculator) TotalAmount(bytes uint64) money.Money {
+	mac.calledWith = bytes
+	return mac.toReturn
+}
@@ -19,15 +19,21 @@ package session
 
 import (
 	"encoding/json"
+	"time"
 
+	log "github.com/cihub/seelog"
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/promise"
 )
 
 const consumerLogPrefix = "[session-create-consumer] "
 
+// dataTransferReportPeriod is how often the data transfer is published for the sessions of services not publishing it themselves
+const dataTransferReportPeriod = 10 * time.Second
+
 // PromiseLoader loads the last known promise info for the given consumer
 type PromiseLoader interface {
 	LoadPaymentInfo(consumerID, receiverID, issuerID identity.Identity) *promise.PaymentInfo
@@ -40,6 +46,7 @@ type createConsumer struct {
 	peerID         identity.Identity
 	configProvider ConfigProvider
 	promiseLoader  PromiseLoader
+	publisher      publisher
 }
 
 // Creator defines method for session creation
@@ -84,6 +91,9 @@ func (consumer *createConsumer) Consume(requestPtr interface{}) (response interf
 				sessionConfigParams.SessionDestroyCallback()
 			}()
 		}
+		if sessionConfigParams.SessionDataTransfer != nil {
+			go consumer.reportDataTransfer(sessionInstance, sessionConfigParams.SessionDataTransfer)
+		}
 		return responseWithSession(sessionInstance, sessionConfigParams.SessionServiceConfig, consumer.promiseLoader.LoadPaymentInfo(consumer.peerID, consumer.receiverID, issuerID)), nil
 	case ErrorInvalidProposal:
 		return responseInvalidProposal, nil
@@ -92,6 +102,27 @@ func (consumer *createConsumer) Consume(requestPtr interface{}) (response interf
 	}
 }
 
+// reportDataTransfer periodically publishes the data transferred during the session until the session is done
+func (consumer *createConsumer) reportDataTransfer(sessionInstance Session, getDataTransfer DataTransferGetter) {
+	for {
+		select {
+		case <-sessionInstance.done:
+			return
+		case <-time.After(dataTransferReportPeriod):
+			transferred, err := getDataTransfer()
+			if err != nil {
+				log.Warn(consumerLogPrefix, "failed to get data transfer of session ", sessionInstance.ID, ": ", err)
+				continue
+			}
+			consumer.publisher.Publish(event.DataTransfered, event.DataTransferEventPayload{
+				ID:   string(sessionInstance.ID),
+				Up:   transferred.Up,
+				Down: transferred.Down,
+			})
+		}
+	}
+}
+
 func responseWithSession(sessionInstance Session, config ServiceConfiguration, pi *promise.PaymentInfo) CreateResponse {
 	serializedConfig, err := json.Marshal(config)
 	if err != nil {
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+// DataTracker tracks the data transferred in both directions during the session.
+// It's passive and simply reads the session counters from the given function whenever asked.
+type DataTracker struct {
+	started        bool
+	getTransferred func() DataTransfered
+}
+
+// NewDataTracker initializes DataTracker with the function returning current data transfer counters of the session
+func NewDataTracker(getTransferred func() DataTransfered) DataTracker {
+	return DataTracker{
+		getTransferred: getTransferred,
+	}
+}
+
+// StartTracking starts tracking the data transferred
+func (dt *DataTracker) StartTracking() {
+	dt.started = true
+}
+
+// Transferred returns the total count of bytes transferred in both directions
+func (dt DataTracker) Transferred() uint64 {
+	if !dt.started {
+		return 0
+	}
+
+	transferred := dt.getTransferred()
+	if transferred.Up < 0 || transferred.Down < 0 {
+		return 0
+	}
+	return uint64(transferred.Up + transferred.Down)
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NotStartedDataTrackerReturnsZeroValue(t *testing.T) {
+	dt := NewDataTracker(func() DataTransfered { return DataTransfered{Up: 10, Down: 20} })
+
+	assert.Equal(t, uint64(0), dt.Transferred())
+}
+
+func Test_DataTrackerReturnsTransferredInBothDirections(t *testing.T) {
+	transferred := DataTransfered{Up: 10, Down: 20}
+	dt := NewDataTracker(func() DataTransfered { return transferred })
+
+	dt.StartTracking()
+	assert.Equal(t, uint64(30), dt.Transferred())
+
+	transferred.Down = 100
+	assert.Equal(t, uint64(110), dt.Transferred())
+}
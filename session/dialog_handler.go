@@ -26,12 +26,13 @@ import (
 type ManagerFactory func(dialog communication.Dialog) *Manager
 
 // NewDialogHandler constructs handler which gets all incoming dialogs and starts handling them
-func NewDialogHandler(sessionManagerFactory ManagerFactory, configProvider ConfigProvider, promiseLoader PromiseLoader, receiverID identity.Identity) *handler {
+func NewDialogHandler(sessionManagerFactory ManagerFactory, configProvider ConfigProvider, promiseLoader PromiseLoader, receiverID identity.Identity, publisher publisher) *handler {
 	return &handler{
 		sessionManagerFactory: sessionManagerFactory,
 		configProvider:        configProvider,
 		promiseLoader:         promiseLoader,
 		receiverID:            receiverID,
+		publisher:             publisher,
 	}
 }
 
@@ -56,6 +57,7 @@ func (handler *handler) subscribeSessionRequests(dialog communication.Dialog) er
 			configProvider: handler.configProvider,
 			promiseLoader:  handler.promiseLoader,
 			receiverID:     handler.receiverID,
+			publisher:      handler.publisher,
 		},
 	)
 	if err != nil {
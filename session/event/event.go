@@ -29,6 +29,28 @@ type DataTransferEventPayload struct {
 	Up, Down int64
 }
 
+// Earnings represents the session earnings topic
+const Earnings = "Session earnings"
+
+// EarningsEventPayload represents the total amount the provider has earned during the session
+type EarningsEventPayload struct {
+	ID     string
+	Amount uint64
+}
+
+// PromiseReceived represents the topic of promises received from the consumer
+const PromiseReceived = "Session promise received"
+
+// PromiseEventPayload represents the amount added by the received promise together with the resulting balance
+type PromiseEventPayload struct {
+	ProviderID  string
+	ConsumerID  string
+	SessionID   string
+	ServiceType string
+	Amount      uint64
+	Balance     uint64
+}
+
 // Action represents the different actions that might happen on a session
 type Action string
 
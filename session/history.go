@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import "time"
+
+// History is the persisted record of a session served by the provider
+type History struct {
+	ID             ID        `storm:"id"`
+	ConsumerID     string    `storm:"index"`
+	ServiceID      string    `storm:"index"`
+	Started        time.Time `storm:"index"`
+	Updated        time.Time
+	Ended          time.Time
+	DataTransfered DataTransfered
+	Earned         uint64
+}
+
+// NewHistory creates a history record of the given session
+func NewHistory(sessionInstance Session) History {
+	return History{
+		ID:             sessionInstance.ID,
+		ConsumerID:     sessionInstance.ConsumerID.Address,
+		ServiceID:      sessionInstance.ServiceID,
+		Started:        sessionInstance.CreatedAt,
+		Updated:        sessionInstance.CreatedAt,
+		DataTransfered: sessionInstance.DataTransfered,
+	}
+}
+
+// HistoryQuery filters and paginates the session history, empty fields are not filtered by
+type HistoryQuery struct {
+	ConsumerID string
+	ServiceID  string
+	// StartedFrom and StartedTo limit the sessions to the ones started within [StartedFrom, StartedTo)
+	StartedFrom time.Time
+	StartedTo   time.Time
+	Offset      int
+	Limit       int
+}
@@ -50,6 +50,8 @@ type ConfigParams struct {
 	SessionServiceConfig   ServiceConfiguration
 	SessionDestroyCallback DestroyCallback
 	TraversalParams        *traversal.Params
+	// SessionDataTransfer is set by the services which do not publish the data transfer of their sessions themselves
+	SessionDataTransfer DataTransferGetter
 }
 
 type publisher interface {
@@ -67,6 +69,9 @@ type ConfigProvider func(sessionConfig json.RawMessage, traversalParams *travers
 // DestroyCallback cleanups session
 type DestroyCallback func()
 
+// DataTransferGetter returns the data transferred during the session
+type DataTransferGetter func() (DataTransfered, error)
+
 // PromiseProcessor processes promises at provider side.
 // Provider checks promises from consumer and signs them also.
 // Provider clears promises from consumer.
@@ -82,8 +87,13 @@ type Storage interface {
 	Remove(id ID)
 }
 
-// BalanceTrackerFactory returns a new instance of balance tracker
-type BalanceTrackerFactory func(consumer, provider, issuer identity.Identity) (BalanceTracker, error)
+// earningsTracker is implemented by balance trackers knowing the total amount paid by the consumer
+type earningsTracker interface {
+	Earned() uint64
+}
+
+// BalanceTrackerFactory returns a new instance of balance tracker for the given session
+type BalanceTrackerFactory func(sessionID ID, consumer, provider, issuer identity.Identity) (BalanceTracker, error)
 
 // NATEventGetter lets us access the last known traversal event
 type NATEventGetter interface {
@@ -150,7 +160,7 @@ func (manager *Manager) Create(consumerID identity.Identity, issuerID identity.I
 	sessionInstance.Config = config
 	sessionInstance.CreatedAt = time.Now().UTC()
 
-	balanceTracker, err := manager.balanceTrackerFactory(consumerID, identity.FromAddress(manager.currentProposal.ProviderID), issuerID)
+	balanceTracker, err := manager.balanceTrackerFactory(sessionInstance.ID, consumerID, identity.FromAddress(manager.currentProposal.ProviderID), issuerID)
 	if err != nil {
 		return
 	}
@@ -160,6 +170,13 @@ func (manager *Manager) Create(consumerID identity.Identity, issuerID identity.I
 		<-sessionInstance.done
 		close(pingerParams.Cancel)
 		balanceTracker.Stop()
+
+		if tracker, ok := balanceTracker.(earningsTracker); ok {
+			manager.publisher.Publish(sevent.Earnings, sevent.EarningsEventPayload{
+				ID:     string(sessionInstance.ID),
+				Amount: tracker.Earned(),
+			})
+		}
 	}()
 
 	go func() {
@@ -81,7 +81,7 @@ func (m mockBalanceTracker) Stop() {
 
 }
 
-func mockBalanceTrackerFactory(consumer, provider, issuer identity.Identity) (BalanceTracker, error) {
+func mockBalanceTrackerFactory(sessionID ID, consumer, provider, issuer identity.Identity) (BalanceTracker, error) {
 	return &mockBalanceTracker{}, nil
 }
 
@@ -23,7 +23,10 @@ import (
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/balance"
@@ -39,10 +42,52 @@ const PromiseWaitTimeout = time.Second * 10
 // BalanceSendPeriod is how often the provider will send balance messages to the consumer
 const BalanceSendPeriod = time.Second * 20
 
+// dataDifferenceThreshold is the amount of data the counters of the consumer and the provider may differ by,
+// as they are read at different moments
+const dataDifferenceThreshold = 50 * datasize.MB
+
+// defaultPaymentPerTime is charged for the services not paid per bytes
+// TODO: set the time and proper payment info
+var defaultPaymentPerTime = dto.PaymentPerTime{
+	Price: money.Money{
+		Currency: money.CurrencyMyst,
+		Amount:   uint64(0),
+	},
+	Duration: time.Minute,
+}
+
+// NewBalanceTracker creates a balance tracker charging by the given payment method.
+// Payments per bytes are charged for the data transferred during the session, which is read from the given function.
+func NewBalanceTracker(paymentMethod market.PaymentMethod, getTransferred func() session.DataTransfered, initialBalance uint64) *balance.BalanceTracker {
+	if method, ok := paymentMethod.(dto.PaymentPerBytes); ok {
+		dataTracker := session.NewDataTracker(getTransferred)
+		return balance.NewDataBalanceTracker(&dataTracker, session.DataAmountCalc{PaymentDef: method}, initialBalance)
+	}
+
+	timeTracker := session.NewTracker(time.Now)
+	return balance.NewBalanceTracker(&timeTracker, session.AmountCalc{PaymentDef: defaultPaymentPerTime}, initialBalance)
+}
+
+// balanceDifferenceThreshold returns how much the balances of the consumer and the provider may differ for the given payment method
+func balanceDifferenceThreshold(paymentMethod market.PaymentMethod) uint64 {
+	method, ok := paymentMethod.(dto.PaymentPerBytes)
+	if !ok {
+		return payment.BalanceDifferenceThreshold
+	}
+
+	calc := session.DataAmountCalc{PaymentDef: method}
+	threshold := calc.TotalAmount(uint64(dataDifferenceThreshold.Bytes())).Amount
+	if threshold < payment.BalanceDifferenceThreshold {
+		return payment.BalanceDifferenceThreshold
+	}
+	return threshold
+}
+
 // PaymentIssuerFactoryFunc returns a factory for payment issuer. It will be noop if the experimental payment flag is not set
 func PaymentIssuerFactoryFunc(nodeOptions node.Options, signerFactory identity.SignerFactory) func(
 	initialState promise.PaymentInfo,
-	paymentDefinition dto.PaymentPerTime,
+	paymentMethod market.PaymentMethod,
+	getTransferred func() session.DataTransfered,
 	messageChan chan balance.Message,
 	dialog communication.Dialog,
 	consumer, provider identity.Identity) (connection.PaymentIssuer, error) {
@@ -51,13 +96,15 @@ func PaymentIssuerFactoryFunc(nodeOptions node.Options, signerFactory identity.S
 
 func paymentIssuerFactory(signerFactory identity.SignerFactory) func(
 	initialState promise.PaymentInfo,
-	paymentDefinition dto.PaymentPerTime,
+	paymentMethod market.PaymentMethod,
+	getTransferred func() session.DataTransfered,
 	messageChan chan balance.Message,
 	dialog communication.Dialog,
 	consumer, provider identity.Identity) (connection.PaymentIssuer, error) {
 	return func(
 		initialState promise.PaymentInfo,
-		paymentDefinition dto.PaymentPerTime,
+		paymentMethod market.PaymentMethod,
+		getTransferred func() session.DataTransfered,
 		messageChan chan balance.Message,
 		dialog communication.Dialog,
 		consumer, provider identity.Identity) (connection.PaymentIssuer, error) {
@@ -68,11 +115,10 @@ func paymentIssuerFactory(signerFactory identity.SignerFactory) func(
 
 		promiseState := mapInitialStateToPromiseState(initialState)
 		tracker := promise.NewConsumerTracker(promiseState, consumer, provider, issuer)
-		timeTracker := session.NewTracker(time.Now)
-		amountCalc := session.AmountCalc{PaymentDef: paymentDefinition}
-
-		balanceTracker := balance.NewBalanceTracker(&timeTracker, amountCalc, initialState.FreeCredit)
-		payments := payment.NewSessionPayments(messageChan, ps, tracker, balanceTracker)
+		// the charged amount is validated against the balance calculated from our own counters
+		balanceTracker := NewBalanceTracker(paymentMethod, getTransferred, initialState.FreeCredit)
+		threshold := balanceDifferenceThreshold(paymentMethod)
+		payments := payment.NewSessionPayments(messageChan, ps, tracker, balanceTracker, threshold)
 		err := dialog.Receive(bl.GetConsumer())
 		return payments, errors.Wrap(err, "fail to receive from consumer")
 	}
@@ -22,6 +22,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/services/openvpn/discovery/dto"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/payment"
 	"github.com/mysteriumnetwork/node/session/promise"
 )
 
@@ -36,3 +41,32 @@ func Test_MapInitialStateToPromiseState(t *testing.T) {
 	assert.Equal(t, paymentInfo.LastPromise.Amount, state.Amount)
 	assert.Equal(t, paymentInfo.LastPromise.SequenceID, state.Seq)
 }
+
+func Test_NewBalanceTracker_ChargesPerBytes(t *testing.T) {
+	paymentMethod := dto.PaymentPerBytes{
+		Price: money.Money{Amount: 100, Currency: money.CurrencyMyst},
+		Bytes: datasize.KB,
+	}
+	transferred := session.DataTransfered{}
+	tracker := NewBalanceTracker(paymentMethod, func() session.DataTransfered { return transferred }, 1000)
+	tracker.Start()
+
+	transferred = session.DataTransfered{Up: 1024, Down: 1024}
+	assert.Equal(t, uint64(800), tracker.GetBalance())
+
+	transferred = session.DataTransfered{Up: 1024, Down: 4096}
+	assert.Equal(t, uint64(500), tracker.GetBalance())
+}
+
+func Test_BalanceDifferenceThreshold(t *testing.T) {
+	assert.Equal(t, payment.BalanceDifferenceThreshold, balanceDifferenceThreshold(dto.PaymentPerTime{}))
+
+	paymentMethod := dto.PaymentPerBytes{
+		Price: money.Money{Amount: 100, Currency: money.CurrencyMyst},
+		Bytes: datasize.MB,
+	}
+	assert.Equal(t, uint64(5000), balanceDifferenceThreshold(paymentMethod))
+
+	paymentMethod.Bytes = datasize.TB
+	assert.Equal(t, payment.BalanceDifferenceThreshold, balanceDifferenceThreshold(paymentMethod))
+}
@@ -27,6 +27,7 @@ import (
 	log "github.com/cihub/seelog"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session/balance"
+	"github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/promise"
 	"github.com/pkg/errors"
 )
@@ -55,6 +56,11 @@ type PeerBalanceSender interface {
 	Send(balance.Message) error
 }
 
+// Publisher is responsible for publishing given events
+type Publisher interface {
+	Publish(topic string, data interface{})
+}
+
 // ErrPromiseWaitTimeout indicates that we waited for a promise long enough, but with no result
 var ErrPromiseWaitTimeout = errors.New("did not get a new promise")
 
@@ -81,11 +87,15 @@ type SessionBalance struct {
 	promiseWaitTimeout time.Duration
 	promiseValidator   PromiseValidator
 	promiseStorage     PromiseStorage
+	publisher          Publisher
 	issuerID           identity.Identity
 	consumerID         identity.Identity
 	receiverID         identity.Identity
+	sessionID          string
+	serviceType        string
 
 	sequenceID              uint64
+	earned                  uint64
 	notReceivedPromiseCount uint64
 	maxNotReceivedPromises  uint64
 }
@@ -99,6 +109,8 @@ func NewSessionBalance(
 	promiseWaitTimeout time.Duration,
 	promiseValidator PromiseValidator,
 	promiseStorage PromiseStorage,
+	publisher Publisher,
+	sessionID, serviceType string,
 	consumerID, receiverID, issuerID identity.Identity) *SessionBalance {
 	return &SessionBalance{
 		stop:                   make(chan struct{}),
@@ -109,9 +121,12 @@ func NewSessionBalance(
 		promiseWaitTimeout:     promiseWaitTimeout,
 		promiseValidator:       promiseValidator,
 		promiseStorage:         promiseStorage,
+		publisher:              publisher,
 		consumerID:             consumerID,
 		receiverID:             receiverID,
 		issuerID:               issuerID,
+		sessionID:              sessionID,
+		serviceType:            serviceType,
 		maxNotReceivedPromises: calculateMaxNotReceivedPromiseCount(chargePeriodLeeway, chargePeriod),
 	}
 }
@@ -264,11 +279,24 @@ func (sb *SessionBalance) storePromiseAndUpdateBalance(pm promise.Message) error
 	}
 	amount := sb.calculateAmountToAdd(pm, p)
 	sb.balanceTracker.Add(amount)
+	atomic.AddUint64(&sb.earned, amount)
 
 	p.Message = &pm
 	p.UnconsumedAmount += amount
 	err = sb.promiseStorage.Update(sb.issuerID, p)
-	return err
+	if err != nil {
+		return err
+	}
+
+	sb.publisher.Publish(event.PromiseReceived, event.PromiseEventPayload{
+		ProviderID:  sb.receiverID.Address,
+		ConsumerID:  sb.consumerID.Address,
+		SessionID:   sb.sessionID,
+		ServiceType: sb.serviceType,
+		Amount:      amount,
+		Balance:     sb.balanceTracker.GetBalance(),
+	})
+	return nil
 }
 
 func (sb *SessionBalance) receivePromiseOrTimeout() error {
@@ -289,6 +317,11 @@ func (sb *SessionBalance) receivePromiseOrTimeout() error {
 	return nil
 }
 
+// Earned returns the total amount promised by the consumer during the session
+func (sb *SessionBalance) Earned() uint64 {
+	return atomic.LoadUint64(&sb.earned)
+}
+
 // Stop stops the payment orchestrator
 func (sb *SessionBalance) Stop() {
 	close(sb.stop)
@@ -26,6 +26,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session/balance"
+	"github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/promise"
 )
 
@@ -57,6 +58,9 @@ func NewMockSessionBalance(balanceSender *MockPeerBalanceSender, mpv *MockPromis
 		time.Millisecond*1,
 		mpv,
 		mps,
+		&mockPublisher{},
+		"session-1",
+		"openvpn",
 		consumer,
 		receiver,
 		issuer,
@@ -242,6 +246,62 @@ func Test_SessionBalance_CalculateAmountToAdd(t *testing.T) {
 
 }
 
+func Test_SessionBalance_StorePromise_AccumulatesEarnings(t *testing.T) {
+	mbt := *MBT
+	mps := &MockPromiseStorage{
+		promiseForConsumerToReturn: promise.StoredPromise{
+			Message: &promise.Message{Amount: 50},
+		},
+	}
+	orch := NewMockSessionBalance(newMockPeerBalanceSender(), MPV, mps, &mbt)
+
+	err := orch.storePromiseAndUpdateBalance(promise.Message{Amount: 80})
+	assert.Nil(t, err)
+	err = orch.storePromiseAndUpdateBalance(promise.Message{Amount: 60})
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint64(40), orch.Earned())
+}
+
+func Test_SessionBalance_StorePromise_PublishesPromiseEvent(t *testing.T) {
+	mbt := MockBalanceTracker{balanceToReturn: 30}
+	mps := &MockPromiseStorage{
+		promiseForConsumerToReturn: promise.StoredPromise{
+			Message: &promise.Message{Amount: 50},
+		},
+	}
+	publisher := &mockPublisher{}
+	orch := NewMockSessionBalance(newMockPeerBalanceSender(), MPV, mps, &mbt)
+	orch.publisher = publisher
+
+	err := orch.storePromiseAndUpdateBalance(promise.Message{Amount: 80})
+	assert.Nil(t, err)
+
+	assert.Equal(t, event.PromiseReceived, publisher.topic)
+	assert.Equal(
+		t,
+		event.PromiseEventPayload{
+			ProviderID:  receiver.Address,
+			ConsumerID:  consumer.Address,
+			SessionID:   "session-1",
+			ServiceType: "openvpn",
+			Amount:      30,
+			Balance:     30,
+		},
+		publisher.data,
+	)
+}
+
+type mockPublisher struct {
+	topic string
+	data  interface{}
+}
+
+func (mp *mockPublisher) Publish(topic string, data interface{}) {
+	mp.topic = topic
+	mp.data = data
+}
+
 type MockPromiseStorage struct {
 	promiseForConsumerToReturn promise.StoredPromise
 	promiseForConsumerError    error
@@ -47,22 +47,25 @@ type SessionPayments struct {
 	peerPromiseSender PeerPromiseSender
 	promiseTracker    PromiseTracker
 	balanceTracker    BalanceTracker
+	balanceThreshold  uint64
 	once              sync.Once
 }
 
-// NewSessionPayments returns a new instance of consumer payment orchestrator
-func NewSessionPayments(balanceChan chan balance.Message, peerPromiseSender PeerPromiseSender, promiseTracker PromiseTracker, balanceTracker BalanceTracker) *SessionPayments {
+// NewSessionPayments returns a new instance of consumer payment orchestrator.
+// The session is cancelled if the provider balance differs from ours by the given threshold or more.
+func NewSessionPayments(balanceChan chan balance.Message, peerPromiseSender PeerPromiseSender, promiseTracker PromiseTracker, balanceTracker BalanceTracker, balanceThreshold uint64) *SessionPayments {
 	return &SessionPayments{
 		stop:              make(chan struct{}),
 		balanceChan:       balanceChan,
 		peerPromiseSender: peerPromiseSender,
 		promiseTracker:    promiseTracker,
 		balanceTracker:    balanceTracker,
+		balanceThreshold:  balanceThreshold,
 	}
 }
 
-// balanceDifferenceThreshold determines the threshold where we'll cancel the session if there's a missmatch larger than the threshold provided between the provider and the consumer balances
-const balanceDifferenceThreshold uint64 = 20
+// BalanceDifferenceThreshold determines the default threshold where we'll cancel the session if there's a missmatch larger than the threshold provided between the provider and the consumer balances
+const BalanceDifferenceThreshold uint64 = 20
 
 const sessionPaymentsLogPrefix = "[session-payments] "
 
@@ -126,7 +129,7 @@ func (cpo *SessionPayments) issuePromise(balance balance.Message) error {
 func (cpo *SessionPayments) validateBalanceDifference(balance uint64) error {
 	myBalance := cpo.balanceTracker.GetBalance()
 	diff := calculateBalanceDifference(balance, myBalance)
-	if diff >= balanceDifferenceThreshold {
+	if diff >= cpo.balanceThreshold {
 		return ErrBalanceMissmatch
 	}
 	return nil
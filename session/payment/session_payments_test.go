@@ -75,6 +75,7 @@ func NewTestSessionPayments(bm chan balance.Message, ps PeerPromiseSender, pt Pr
 		ps,
 		pt,
 		bt,
+		BalanceDifferenceThreshold,
 	)
 }
 
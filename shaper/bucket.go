@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"sync"
+	"time"
+)
+
+// minBurst is the smallest amount of bytes the bucket lets through without waiting
+const minBurst = 16 * 1024
+
+// Bucket is a token bucket limiting the rate of data passing through it, for the limits enforced in the user space
+type Bucket struct {
+	lock   sync.Mutex
+	rate   float64 // bytes per second, zero means unlimited
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewBucket returns a bucket which doesn't limit the rate until it's set
+func NewBucket() *Bucket {
+	return &Bucket{
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+}
+
+// SetRate sets the rate limit of the bucket in bits per second, zero rate removes the limit
+func (b *Bucket) SetRate(rate uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.rate = float64(rate) / 8
+	b.burst = b.rate / 20
+	if b.burst < minBurst {
+		b.burst = minBurst
+	}
+	b.tokens = b.burst
+	b.last = b.now()
+}
+
+// Wait blocks until the given amount of bytes is allowed to pass through the bucket
+func (b *Bucket) Wait(size int) {
+	b.lock.Lock()
+	if b.rate == 0 {
+		b.lock.Unlock()
+		return
+	}
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens -= float64(size)
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.lock.Unlock()
+
+	if delay > 0 {
+		b.sleep(delay)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BucketDelaysDataExceedingRate(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	var slept time.Duration
+	bucket := NewBucket()
+	bucket.now = func() time.Time { return now }
+	bucket.sleep = func(d time.Duration) { slept += d }
+
+	bucket.Wait(1000000)
+	assert.Zero(t, slept)
+
+	// 1 Mbit/s lets 125000 bytes per second through, burst of 16KiB passes immediately
+	bucket.SetRate(1000000)
+	bucket.Wait(16 * 1024)
+	assert.Zero(t, slept)
+
+	bucket.Wait(125000)
+	assert.Equal(t, time.Second, slept)
+
+	now = now.Add(2 * time.Second)
+	slept = 0
+	bucket.Wait(1000)
+	assert.Zero(t, slept)
+}
@@ -0,0 +1,50 @@
+// +build !linux
+
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"net"
+	"runtime"
+)
+
+// New returns the bandwidth shaper which only warns about the limits, since the platform has no traffic control
+func New() Shaper {
+	return &noopShaper{}
+}
+
+type noopShaper struct{}
+
+func (noopShaper) Limit(iface string, rate uint64) error {
+	if rate != 0 {
+		log.Warnf("bandwidth limits of %s are not supported on %s", iface, runtime.GOOS)
+	}
+	return nil
+}
+
+func (s noopShaper) LimitHosts(iface string, _ []net.IP, hostRate, totalRate uint64) error {
+	if hostRate != 0 {
+		return s.Limit(iface, hostRate)
+	}
+	return s.Limit(iface, totalRate)
+}
+
+func (noopShaper) Clear(string) error {
+	return nil
+}
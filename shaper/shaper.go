@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package shaper limits the bandwidth consumers are allowed to use on the provider side.
+package shaper
+
+import (
+	"net"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/logconfig"
+	"github.com/mysteriumnetwork/node/utils"
+)
+
+var log = logconfig.NewLogger()
+
+// MaxHosts limits the count of hosts which bandwidth is limited separately
+const MaxHosts = 1024
+
+// Shaper limits the bandwidth of network interfaces in both directions
+type Shaper interface {
+	// Limit limits the interface to the given rate in bits per second, zero rate removes the limit
+	Limit(iface string, rate uint64) error
+	// LimitHosts limits the traffic of every host behind the interface to the hostRate
+	// and the traffic of all of them together to the totalRate, at most MaxHosts are limited separately
+	LimitHosts(iface string, hosts []net.IP, hostRate, totalRate uint64) error
+	// Clear removes all the limits of the interface
+	Clear(iface string) error
+}
+
+// Limiter limits the bandwidth of a single session
+type Limiter interface {
+	LimitRate(rate uint64) error
+}
+
+// Share splits the bandwidth limits of a service fairly among its sessions
+type Share struct {
+	lock     sync.Mutex
+	limit    service.OptionsRateLimit
+	sessions map[string]Limiter
+}
+
+// NewShare returns a share of the given service limits
+func NewShare(limit service.OptionsRateLimit) *Share {
+	return &Share{
+		limit:    limit,
+		sessions: make(map[string]Limiter),
+	}
+}
+
+// Add adds the session to the share and recalculates the rates of all the sessions
+func (s *Share) Add(id string, limiter Limiter) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sessions[id] = limiter
+	return s.apply()
+}
+
+// Remove removes the session from the share and recalculates the rates of the remaining sessions
+func (s *Share) Remove(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.sessions[id]; !ok {
+		return
+	}
+	delete(s.sessions, id)
+	if err := s.apply(); err != nil {
+		log.Warn("failed to update session rate limits: ", err)
+	}
+}
+
+func (s *Share) apply() error {
+	if s.limit.Session == 0 && s.limit.Service == 0 {
+		return nil
+	}
+
+	rate := s.limit.SessionRate(len(s.sessions))
+	var errs utils.ErrorCollection
+	for _, limiter := range s.sessions {
+		errs.Add(limiter.LimitRate(rate))
+	}
+	return errs.Error()
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/stretchr/testify/assert"
+)
+
+type limiterMock struct {
+	rate uint64
+}
+
+func (lm *limiterMock) LimitRate(rate uint64) error {
+	lm.rate = rate
+	return nil
+}
+
+func Test_ShareSplitsServiceLimitAmongSessions(t *testing.T) {
+	share := NewShare(service.OptionsRateLimit{Session: 4000, Service: 6000})
+	first, second := &limiterMock{}, &limiterMock{}
+
+	assert.NoError(t, share.Add("session-1", first))
+	assert.Equal(t, uint64(4000), first.rate)
+
+	assert.NoError(t, share.Add("session-2", second))
+	assert.Equal(t, uint64(3000), first.rate)
+	assert.Equal(t, uint64(3000), second.rate)
+
+	share.Remove("session-1")
+	assert.Equal(t, uint64(4000), second.rate)
+}
+
+func Test_ShareDoesNotLimitUnlimitedService(t *testing.T) {
+	share := NewShare(service.OptionsRateLimit{})
+	limiter := &limiterMock{rate: 1}
+
+	assert.NoError(t, share.Add("session-1", limiter))
+	assert.Equal(t, uint64(1), limiter.rate)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/mysteriumnetwork/node/utils"
+	"github.com/pkg/errors"
+)
+
+// unlimitedRate is the rate of traffic classes which are not limited
+const unlimitedRate = 10000000000
+
+// hostClassOffset is the first minor number of the per host traffic classes, the lower ones are reserved
+const hostClassOffset = 0x10
+
+// tcShaper limits the bandwidth with the linux traffic control.
+// Egress traffic is shaped with queueing disciplines, ingress traffic is policed since it can't be queued.
+type tcShaper struct {
+	exec func(args ...string) error
+}
+
+func newTCShaper() *tcShaper {
+	return &tcShaper{exec: utils.SudoExec}
+}
+
+// Limit limits the interface to the given rate in bits per second, zero rate removes the limit
+func (tc *tcShaper) Limit(iface string, rate uint64) error {
+	tc.Clear(iface)
+	if rate == 0 {
+		return nil
+	}
+
+	commands := [][]string{
+		{"qdisc", "add", "dev", iface, "root", "tbf", "rate", bits(rate), "burst", burst(rate), "latency", "50ms"},
+		{"qdisc", "add", "dev", iface, "handle", "ffff:", "ingress"},
+		police(iface, "u32", "match", "u32", "0", "0", "police", "rate", bits(rate), "burst", burst(rate), "drop", "flowid", ":1"),
+	}
+	return tc.run(iface, commands)
+}
+
+// LimitHosts limits the traffic of every host behind the interface to the hostRate
+// and the traffic of all of them together to the totalRate.
+// Ingress traffic of the hosts is policed separately, so the totalRate is enforced for it only if hostRate is unlimited.
+func (tc *tcShaper) LimitHosts(iface string, hosts []net.IP, hostRate, totalRate uint64) error {
+	if hostRate == 0 {
+		return tc.Limit(iface, totalRate)
+	}
+	if len(hosts) > MaxHosts {
+		log.Warnf("can't limit more than %d hosts of %s separately, limiting all of them together", MaxHosts, iface)
+		return tc.Limit(iface, totalRate)
+	}
+
+	tc.Clear(iface)
+	if totalRate == 0 {
+		totalRate = unlimitedRate
+	}
+	if hostRate > totalRate {
+		hostRate = totalRate
+	}
+
+	commands := [][]string{
+		{"qdisc", "add", "dev", iface, "root", "handle", "1:", "htb", "default", "2"},
+		{"class", "add", "dev", iface, "parent", "1:", "classid", "1:1", "htb", "rate", bits(totalRate), "ceil", bits(totalRate)},
+		{"class", "add", "dev", iface, "parent", "1:1", "classid", "1:2", "htb", "rate", bits(totalRate), "ceil", bits(totalRate)},
+		{"qdisc", "add", "dev", iface, "handle", "ffff:", "ingress"},
+	}
+	for i, host := range hosts {
+		classID := fmt.Sprintf("1:%x", hostClassOffset+i)
+		commands = append(commands,
+			[]string{"class", "add", "dev", iface, "parent", "1:1", "classid", classID, "htb", "rate", bits(hostRate), "ceil", bits(hostRate)},
+			[]string{"filter", "add", "dev", iface, "parent", "1:", "protocol", "ip", "prio", "1", "u32", "match", "ip", "dst", host.String() + "/32", "flowid", classID},
+			police(iface, "u32", "match", "ip", "src", host.String()+"/32", "police", "rate", bits(hostRate), "burst", burst(hostRate), "drop", "flowid", ":1"),
+		)
+	}
+	return tc.run(iface, commands)
+}
+
+// Clear removes all the limits of the interface
+func (tc *tcShaper) Clear(iface string) error {
+	var errs utils.ErrorCollection
+	errs.Add(
+		tc.exec("tc", "qdisc", "del", "dev", iface, "root"),
+		tc.exec("tc", "qdisc", "del", "dev", iface, "ingress"),
+	)
+	return errs.Error()
+}
+
+func (tc *tcShaper) run(iface string, commands [][]string) error {
+	for _, command := range commands {
+		if err := tc.exec(append([]string{"tc"}, command...)...); err != nil {
+			tc.Clear(iface)
+			return errors.Wrap(err, "failed to limit bandwidth of "+iface)
+		}
+	}
+	return nil
+}
+
+func police(iface string, args ...string) []string {
+	return append([]string{"filter", "add", "dev", iface, "parent", "ffff:", "protocol", "ip", "prio", "1"}, args...)
+}
+
+func bits(rate uint64) string {
+	return strconv.FormatUint(rate, 10) + "bit"
+}
+
+// burst allows the traffic to exceed the rate for 50ms, but not less than a few full sized packets
+func burst(rate uint64) string {
+	size := rate / 8 / 20
+	if size < 16*1024 {
+		size = 16 * 1024
+	}
+	return strconv.FormatUint(size, 10)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type execRecorder struct {
+	commands []string
+	failOn   string
+}
+
+func (er *execRecorder) exec(args ...string) error {
+	command := strings.Join(args, " ")
+	er.commands = append(er.commands, command)
+	if er.failOn != "" && strings.HasPrefix(command, er.failOn) {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func Test_TCShaper_Limit(t *testing.T) {
+	recorder := &execRecorder{}
+	tc := &tcShaper{exec: recorder.exec}
+
+	err := tc.Limit("myst0", 8000000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"tc qdisc del dev myst0 root",
+		"tc qdisc del dev myst0 ingress",
+		"tc qdisc add dev myst0 root tbf rate 8000000bit burst 50000 latency 50ms",
+		"tc qdisc add dev myst0 handle ffff: ingress",
+		"tc filter add dev myst0 parent ffff: protocol ip prio 1 u32 match u32 0 0 police rate 8000000bit burst 50000 drop flowid :1",
+	}, recorder.commands)
+}
+
+func Test_TCShaper_LimitZeroRateClearsLimits(t *testing.T) {
+	recorder := &execRecorder{}
+	tc := &tcShaper{exec: recorder.exec}
+
+	err := tc.Limit("myst0", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"tc qdisc del dev myst0 root",
+		"tc qdisc del dev myst0 ingress",
+	}, recorder.commands)
+}
+
+func Test_TCShaper_LimitHosts(t *testing.T) {
+	recorder := &execRecorder{}
+	tc := &tcShaper{exec: recorder.exec}
+
+	err := tc.LimitHosts("tun0", []net.IP{net.ParseIP("10.8.0.2")}, 1000000, 5000000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"tc qdisc del dev tun0 root",
+		"tc qdisc del dev tun0 ingress",
+		"tc qdisc add dev tun0 root handle 1: htb default 2",
+		"tc class add dev tun0 parent 1: classid 1:1 htb rate 5000000bit ceil 5000000bit",
+		"tc class add dev tun0 parent 1:1 classid 1:2 htb rate 5000000bit ceil 5000000bit",
+		"tc qdisc add dev tun0 handle ffff: ingress",
+		"tc class add dev tun0 parent 1:1 classid 1:10 htb rate 1000000bit ceil 1000000bit",
+		"tc filter add dev tun0 parent 1: protocol ip prio 1 u32 match ip dst 10.8.0.2/32 flowid 1:10",
+		"tc filter add dev tun0 parent ffff: protocol ip prio 1 u32 match ip src 10.8.0.2/32 police rate 1000000bit burst 16384 drop flowid :1",
+	}, recorder.commands)
+}
+
+func Test_TCShaper_LimitClearsOnFailure(t *testing.T) {
+	recorder := &execRecorder{failOn: "tc qdisc add dev myst0 handle ffff:"}
+	tc := &tcShaper{exec: recorder.exec}
+
+	err := tc.Limit("myst0", 8000000)
+
+	assert.Error(t, err)
+	assert.Equal(t, "tc qdisc del dev myst0 ingress", recorder.commands[len(recorder.commands)-1])
+}
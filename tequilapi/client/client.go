@@ -20,6 +20,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 
@@ -96,6 +97,66 @@ func (client *Client) CurrentIdentity(identity, passphrase string) (id IdentityD
 	return id, err
 }
 
+// ExportIdentity returns the encrypted keystore JSON of the identity, re-encrypted with the new passphrase
+func (client *Client) ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error) {
+	payload := struct {
+		Passphrase    string `json:"passphrase"`
+		NewPassphrase string `json:"new_passphrase"`
+	}{
+		passphrase,
+		newPassphrase,
+	}
+	response, err := client.http.Post("identities/"+address+"/export", payload)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return ioutil.ReadAll(response.Body)
+}
+
+// ImportIdentity imports the identity from the encrypted keystore JSON
+func (client *Client) ImportIdentity(keyJSON []byte, passphrase, newPassphrase string) (id IdentityDTO, err error) {
+	payload := struct {
+		Keystore      json.RawMessage `json:"keystore"`
+		Passphrase    string          `json:"passphrase"`
+		NewPassphrase string          `json:"new_passphrase"`
+	}{
+		keyJSON,
+		passphrase,
+		newPassphrase,
+	}
+	response, err := client.http.Post("identities-import", payload)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &id)
+	return id, err
+}
+
+// ImportIdentityFromMnemonic imports the identity from the BIP-39 mnemonic phrase and encrypts it with the passphrase
+func (client *Client) ImportIdentityFromMnemonic(mnemonic, mnemonicPassword, passphrase string) (id IdentityDTO, err error) {
+	payload := struct {
+		Mnemonic         string `json:"mnemonic"`
+		MnemonicPassword string `json:"mnemonic_password"`
+		Passphrase       string `json:"passphrase"`
+	}{
+		mnemonic,
+		mnemonicPassword,
+		passphrase,
+	}
+	response, err := client.http.Post("identities-import", payload)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &id)
+	return id, err
+}
+
 // RegisterIdentity registers identity
 func (client *Client) RegisterIdentity(address, beneficiary string, stake, fee uint64) error {
 	payload := transactor.IdentityRegistrationRequestDTO{
@@ -267,6 +328,21 @@ func (client *Client) Proposals() ([]ProposalDTO, error) {
 	return proposals.Proposals, err
 }
 
+// ProposalsWhere returns proposals filtered, sorted and limited by the query expression
+func (client *Client) ProposalsWhere(where string) ([]ProposalDTO, error) {
+	queryParams := url.Values{}
+	queryParams.Add("where", where)
+	response, err := client.http.Get("proposals", queryParams)
+	if err != nil {
+		return []ProposalDTO{}, err
+	}
+	defer response.Body.Close()
+
+	var proposals ProposalList
+	err = parseResponseJSON(response, &proposals)
+	return proposals.Proposals, err
+}
+
 // Unlock allows using identity in following commands
 func (client *Client) Unlock(identity, passphrase string) error {
 	path := fmt.Sprintf("identities/%s/unlock", identity)
@@ -285,6 +361,69 @@ func (client *Client) Unlock(identity, passphrase string) error {
 	return nil
 }
 
+// Lock locks given identity, it has to be unlocked again before signing
+func (client *Client) Lock(identity string) error {
+	path := fmt.Sprintf("identities/%s/lock", identity)
+	response, err := client.http.Put(path, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ChangePassphrase re-encrypts given identity with the new passphrase
+func (client *Client) ChangePassphrase(identity, passphrase, newPassphrase string) error {
+	path := fmt.Sprintf("identities/%s/passphrase", identity)
+	payload := struct {
+		Passphrase    string `json:"passphrase"`
+		NewPassphrase string `json:"new_passphrase"`
+	}{
+		passphrase,
+		newPassphrase,
+	}
+
+	response, err := client.http.Put(path, payload)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// Earnings returns the earnings of the provider identity summed up by the given period: day, week or month
+func (client *Client) Earnings(identity, period string) (EarningsReportDTO, error) {
+	values := url.Values{}
+	values.Set("period", period)
+
+	var report EarningsReportDTO
+	response, err := client.http.Get("identities/"+identity+"/earnings", values)
+	if err != nil {
+		return report, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &report)
+	return report, err
+}
+
+// EarningsCSV returns the earnings of the provider identity summed up by the given period as CSV
+func (client *Client) EarningsCSV(identity, period string) ([]byte, error) {
+	values := url.Values{}
+	values.Set("period", period)
+	values.Set("format", "csv")
+
+	response, err := client.http.Get("identities/"+identity+"/earnings", values)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return ioutil.ReadAll(response.Body)
+}
+
 // Payout registers payout address for identity
 func (client *Client) Payout(identity, ethAddress string) error {
 	path := fmt.Sprintf("identities/%s/payout", identity)
@@ -39,7 +39,7 @@ func Test_NATStatus_ReturnsStatus(t *testing.T) {
 		http.MethodGet,
 		"/nat/status",
 		http.StatusOK,
-		`{"status": "failure", "error": "mock error"}`,
+		`{"status": "failure", "error": "mock error", "stage": "port_mapping_natpmp", "type": "symmetric"}`,
 	)
 	client := Client{http: httpClient}
 
@@ -48,6 +48,8 @@ func Test_NATStatus_ReturnsStatus(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "failure", status.Status)
 	assert.Equal(t, "mock error", status.Error)
+	assert.Equal(t, "port_mapping_natpmp", status.Stage)
+	assert.Equal(t, "symmetric", status.Type)
 }
 
 func Test_NATStatus_ReturnsError(t *testing.T) {
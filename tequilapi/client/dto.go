@@ -123,8 +123,20 @@ type SignatureDTO struct {
 
 // ConnectOptions copied from tequilapi endpoint
 type ConnectOptions struct {
-	DisableKillSwitch bool `json:"killSwitch"`
-	EnableDNS         bool `json:"enableDNS"`
+	DisableKillSwitch bool            `json:"killSwitch"`
+	EnableDNS         bool            `json:"enableDNS"`
+	Failover          bool            `json:"failover"`
+	FailoverFilter    *ProposalFilter `json:"failoverFilter,omitempty"`
+	Include           []string        `json:"include,omitempty"`
+	Exclude           []string        `json:"exclude,omitempty"`
+	DisableRelay      bool            `json:"disableRelay"`
+}
+
+// ProposalFilter copied from tequilapi endpoint
+type ProposalFilter struct {
+	AccessPolicyID     string `json:"accessPolicyId,omitempty"`
+	AccessPolicySource string `json:"accessPolicySource,omitempty"`
+	Where              string `json:"where,omitempty"`
 }
 
 // ConnectionSessionListDTO copied from tequilapi endpoint
@@ -143,6 +155,7 @@ type ConnectionSessionDTO struct {
 	BytesReceived   uint64 `json:"bytesReceived"`
 	Duration        uint64 `json:"duration"`
 	Status          string `json:"status"`
+	Relayed         bool   `json:"relayed"`
 }
 
 // ServiceListDTO represents a list of running services on the node
@@ -178,4 +191,23 @@ type AccessPoliciesRequest struct {
 type NATStatusDTO struct {
 	Status string `json:"status"`
 	Error  string `json:"error,omitempty"`
+	Stage  string `json:"stage,omitempty"`
+	Type   string `json:"type"`
+}
+
+// EarningsReportDTO copied from tequilapi endpoint
+type EarningsReportDTO struct {
+	Period   string               `json:"period"`
+	Earnings []EarningsSummaryDTO `json:"earnings"`
+	Total    uint64               `json:"total"`
+}
+
+// EarningsSummaryDTO copied from tequilapi endpoint
+type EarningsSummaryDTO struct {
+	DateStart string `json:"dateStart"`
+	DateEnd   string `json:"dateEnd"`
+	Amount    uint64 `json:"amount"`
+	Promises  int    `json:"promises"`
+	Sessions  int    `json:"sessions"`
+	Consumers int    `json:"consumers"`
 }
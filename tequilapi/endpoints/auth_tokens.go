@@ -0,0 +1,222 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// swagger:model APITokenRequestDTO
+type apiTokenRequest struct {
+	// name describing the token holder
+	// required: true
+	// example: dashboard
+	Name string `json:"name"`
+
+	// scopes granted to the token, possible values are "status", "connection", "service" and "admin"
+	// required: true
+	// example: ["status"]
+	Scopes []string `json:"scopes"`
+}
+
+// swagger:model APITokenDTO
+type apiTokenResponse struct {
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	ID string `json:"id"`
+
+	// example: dashboard
+	Name string `json:"name"`
+
+	// example: ["status"]
+	Scopes []string `json:"scopes"`
+
+	// bearer token sent in the Authorization header, present only in the response of token creation
+	Token string `json:"token,omitempty"`
+
+	// example: 2019-10-01T16:22:05Z
+	CreatedAt string `json:"createdAt"`
+}
+
+// swagger:model APITokenListDTO
+type apiTokenListResponse struct {
+	Tokens []apiTokenResponse `json:"tokens"`
+}
+
+type tokenManager interface {
+	Create(name string, scopes auth.Scopes) (auth.APIToken, string, error)
+	List() ([]auth.APIToken, error)
+	Revoke(id string) error
+}
+
+type authTokensEndpoint struct {
+	tokens tokenManager
+}
+
+// NewAuthTokensEndpoint creates and returns API tokens endpoint
+func NewAuthTokensEndpoint(tokens tokenManager) *authTokensEndpoint {
+	return &authTokensEndpoint{tokens: tokens}
+}
+
+// swagger:operation GET /auth/tokens Authentication listAPITokens
+// ---
+// summary: Returns API tokens
+// description: Returns all issued API tokens without their secrets
+// responses:
+//   200:
+//     description: List of API tokens
+//     schema:
+//       "$ref": "#/definitions/APITokenListDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *authTokensEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	tokens, err := endpoint.tokens.List()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	list := apiTokenListResponse{Tokens: make([]apiTokenResponse, len(tokens))}
+	for i := range tokens {
+		list.Tokens[i] = apiTokenToResponse(tokens[i], "")
+	}
+	utils.WriteAsJSON(list, resp)
+}
+
+// swagger:operation POST /auth/tokens Authentication createAPIToken
+// ---
+// summary: Creates API token
+// description: Issues the named API token granting the given scopes, the token secret is returned only once
+// parameters:
+//   - in: body
+//     name: body
+//     description: name and scopes of the token
+//     schema:
+//       $ref: "#/definitions/APITokenRequestDTO"
+// responses:
+//   201:
+//     description: API token created
+//     schema:
+//       "$ref": "#/definitions/APITokenDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *authTokensEndpoint) Create(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	req := &apiTokenRequest{}
+	if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	errs := validation.NewErrorMap()
+	if req.Name == "" {
+		errs.ForField("name").AddError("required", "Field is required")
+	}
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil {
+		errs.ForField("scopes").AddError("invalid", err.Error())
+	} else if len(scopes) == 0 {
+		errs.ForField("scopes").AddError("required", "Field is required")
+	}
+	if errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	token, secret, err := endpoint.tokens.Create(req.Name, scopes)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	utils.WriteAsJSON(apiTokenToResponse(token, secret), resp)
+}
+
+// swagger:operation DELETE /auth/tokens/{id} Authentication revokeAPIToken
+// ---
+// summary: Revokes API token
+// description: Revokes the API token with the given id
+// parameters:
+//   - in: path
+//     name: id
+//     description: token id
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: API token revoked
+//   404:
+//     description: API token not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *authTokensEndpoint) Revoke(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	err := endpoint.tokens.Revoke(params.ByName("id"))
+	if err == auth.ErrTokenNotFound {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// AddRoutesForAuthTokens attaches API token endpoints to router
+func AddRoutesForAuthTokens(router *httprouter.Router, tokens tokenManager) {
+	authTokensEndpoint := NewAuthTokensEndpoint(tokens)
+	router.GET("/auth/tokens", authTokensEndpoint.List)
+	router.POST("/auth/tokens", authTokensEndpoint.Create)
+	router.DELETE("/auth/tokens/:id", authTokensEndpoint.Revoke)
+}
+
+func apiTokenToResponse(token auth.APIToken, secret string) apiTokenResponse {
+	response := apiTokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    make([]string, len(token.Scopes)),
+		Token:     secret,
+		CreatedAt: token.CreatedAt.Format(time.RFC3339),
+	}
+	for i, scope := range token.Scopes {
+		response.Scopes[i] = string(scope)
+	}
+	return response
+}
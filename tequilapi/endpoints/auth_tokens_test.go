@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenManagerMock struct {
+	tokens []auth.APIToken
+}
+
+func (mock *tokenManagerMock) Create(name string, scopes auth.Scopes) (auth.APIToken, string, error) {
+	token := auth.APIToken{ID: "token-1", Name: name, Scopes: scopes, Hash: "hash", CreatedAt: time.Unix(1, 0).UTC()}
+	mock.tokens = append(mock.tokens, token)
+	return token, "myst_secret", nil
+}
+
+func (mock *tokenManagerMock) List() ([]auth.APIToken, error) {
+	return mock.tokens, nil
+}
+
+func (mock *tokenManagerMock) Revoke(id string) error {
+	for i, token := range mock.tokens {
+		if token.ID == id {
+			mock.tokens = append(mock.tokens[:i], mock.tokens[i+1:]...)
+			return nil
+		}
+	}
+	return auth.ErrTokenNotFound
+}
+
+func serveAuthTokensRequest(tokens *tokenManagerMock, method, path, body string) *httptest.ResponseRecorder {
+	router := httprouter.New()
+	AddRoutesForAuthTokens(router, tokens)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return resp
+}
+
+func TestAuthTokensEndpointCreatesToken(t *testing.T) {
+	tokens := &tokenManagerMock{}
+
+	resp := serveAuthTokensRequest(tokens, http.MethodPost, "/auth/tokens", `{"name": "dashboard", "scopes": ["status"]}`)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.JSONEq(t, `{
+		"id": "token-1",
+		"name": "dashboard",
+		"scopes": ["status"],
+		"token": "myst_secret",
+		"createdAt": "1970-01-01T00:00:01Z"
+	}`, resp.Body.String())
+
+	resp = serveAuthTokensRequest(tokens, http.MethodGet, "/auth/tokens", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var list apiTokenListResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+	assert.Len(t, list.Tokens, 1)
+	assert.Empty(t, list.Tokens[0].Token)
+}
+
+func TestAuthTokensEndpointValidatesRequest(t *testing.T) {
+	tokens := &tokenManagerMock{}
+
+	resp := serveAuthTokensRequest(tokens, http.MethodPost, "/auth/tokens", `{"scopes": ["root"]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"name": [{"code": "required", "message": "Field is required"}],
+			"scopes": [{"code": "invalid", "message": "unknown scope \"root\""}]
+		}
+	}`, resp.Body.String())
+	assert.Empty(t, tokens.tokens)
+}
+
+func TestAuthTokensEndpointRevokesToken(t *testing.T) {
+	tokens := &tokenManagerMock{tokens: []auth.APIToken{{ID: "token-1"}}}
+
+	resp := serveAuthTokensRequest(tokens, http.MethodDelete, "/auth/tokens/token-1", "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Empty(t, tokens.tokens)
+
+	resp = serveAuthTokensRequest(tokens, http.MethodDelete, "/auth/tokens/token-1", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -24,7 +24,10 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/consumer/statistics"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery/query"
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
@@ -44,6 +47,49 @@ type ConnectOptions struct {
 	// example: true
 	DisableKillSwitch bool `json:"killSwitch"`
 	EnableDNS         bool `json:"enableDNS"`
+
+	// automatically switch to another provider of the same service type once the connection is lost
+	// required: false
+	// example: true
+	Failover bool `json:"failover"`
+
+	// proposals the connection can fail over to, proposals of the same country and access policies
+	// as the requested one are failed over to if not given
+	// required: false
+	FailoverFilter *ProposalFilter `json:"failoverFilter,omitempty"`
+
+	// destinations (CIDRs, IP addresses or DNS names) the tunnel is limited to, all traffic is tunneled when empty.
+	// Kill switch has to be disabled.
+	// required: false
+	// example: ["10.0.0.0/8"]
+	Include []string `json:"include,omitempty"`
+
+	// destinations (CIDRs, IP addresses or DNS names) which bypass the tunnel and the kill switch
+	// required: false
+	// example: ["192.168.0.0/16", "intranet.example.com"]
+	Exclude []string `json:"exclude,omitempty"`
+
+	// do not fall back to the relay offered by provider when NAT hole punching fails
+	// required: false
+	// example: false
+	DisableRelay bool `json:"disableRelay"`
+}
+
+// ProposalFilter limits the proposals the same way the query parameters of GET /proposals do
+// swagger:model ProposalFilterDTO
+type ProposalFilter struct {
+	// required: false
+	// example: mysterium
+	AccessPolicyID string `json:"accessPolicyId,omitempty"`
+
+	// required: false
+	// example: https://trust-oracle.mysterium.network/api/v1/access-policies/mysterium
+	AccessPolicySource string `json:"accessPolicySource,omitempty"`
+
+	// proposal query, proposals are failed over to in the order it defines
+	// required: false
+	// example: country in (DE, NL) order by price
+	Where string `json:"where,omitempty"`
 }
 
 // swagger:model ConnectionRequestDTO
@@ -67,6 +113,24 @@ type connectionRequest struct {
 	// connect options
 	// required: false
 	ConnectOptions ConnectOptions `json:"connectOptions,omitempty"`
+
+	// providers chained behind the first one, ordered from the entry towards the exit of the tunnel
+	// required: false
+	Hops []connectionHop `json:"hops,omitempty"`
+}
+
+// swagger:model ConnectionHopDTO
+type connectionHop struct {
+	// provider identity
+	// required: true
+	// example: 0x0000000000000000000000000000000000000003
+	ProviderID string `json:"providerId"`
+
+	// service type. Only "wireguard" connections can be chained at the moment
+	// required: false
+	// default: wireguard
+	// example: wireguard
+	ServiceType string `json:"serviceType"`
 }
 
 // swagger:model ConnectionStatusDTO
@@ -99,6 +163,27 @@ type statisticsResponse struct {
 	// connection duration in seconds
 	// example: 60
 	Duration int `json:"duration"`
+
+	// statistics of every hop, present only for chained connections
+	Hops []hopStatisticsResponse `json:"hops,omitempty"`
+}
+
+// swagger:model HopStatisticsDTO
+type hopStatisticsResponse struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"sessionId"`
+
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"providerId"`
+
+	// example: wireguard
+	ServiceType string `json:"serviceType"`
+
+	// example: 1024
+	BytesSent uint64 `json:"bytesSent"`
+
+	// example: 1024
+	BytesReceived uint64 `json:"bytesReceived"`
 }
 
 // SessionStatisticsTracker represents the session stat keeper
@@ -107,25 +192,40 @@ type SessionStatisticsTracker interface {
 	GetSessionDuration() time.Duration
 }
 
+// HopStatisticsTracker represents the stats keeper of every hop in chained connection
+type HopStatisticsTracker interface {
+	Retrieve() []statistics.HopStatistics
+}
+
 // ProposalGetter defines interface to fetch currently active service proposal by id
 type ProposalGetter interface {
 	GetProposal(id market.ProposalID) (*market.ServiceProposal, error)
 }
 
+// ProposalRepository defines interface to fetch proposals either by id or by given filter
+type ProposalRepository interface {
+	ProposalGetter
+	ProposalFinder
+}
+
 // ConnectionEndpoint struct represents /connection resource and it's subresources
 type ConnectionEndpoint struct {
 	manager           connection.Manager
 	statisticsTracker SessionStatisticsTracker
+	hopsTracker       HopStatisticsTracker
 	//TODO connection should use concrete proposal from connection params and avoid going to marketplace
-	proposalProvider ProposalGetter
+	proposalProvider ProposalRepository
+	qualityProvider  QualityFinder
 }
 
 // NewConnectionEndpoint creates and returns connection endpoint
-func NewConnectionEndpoint(manager connection.Manager, statsKeeper SessionStatisticsTracker, proposalProvider ProposalGetter) *ConnectionEndpoint {
+func NewConnectionEndpoint(manager connection.Manager, statsKeeper SessionStatisticsTracker, hopsKeeper HopStatisticsTracker, proposalProvider ProposalRepository, qualityProvider QualityFinder) *ConnectionEndpoint {
 	return &ConnectionEndpoint{
 		manager:           manager,
 		statisticsTracker: statsKeeper,
+		hopsTracker:       hopsKeeper,
 		proposalProvider:  proposalProvider,
+		qualityProvider:   qualityProvider,
 	}
 }
 
@@ -197,37 +297,20 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 		return
 	}
 
-	// TODO Pass proposal ID directly in request
-	proposal, err := ce.proposalProvider.GetProposal(market.ProposalID{
-		ProviderID:  cr.ProviderID,
-		ServiceType: cr.ServiceType,
-	})
+	proposal, connectOptions, status, err := lookupConnectionProposals(ce.proposalProvider, ce.qualityProvider, cr)
 	if err != nil {
-		utils.SendError(resp, err, http.StatusInternalServerError)
-		return
-	}
-	if proposal == nil {
-		utils.SendError(resp, errors.New("provider has no service proposals"), http.StatusBadRequest)
+		utils.SendError(resp, err, status)
 		return
 	}
 
-	connectOptions := getConnectOptions(cr)
-	err = ce.manager.Connect(identity.FromAddress(cr.ConsumerID), *proposal, connectOptions)
-
+	err = ce.manager.Connect(identity.FromAddress(cr.ConsumerID), proposal, connectOptions)
 	if err != nil {
-		switch err {
-		case connection.ErrAlreadyExists:
-			utils.SendError(resp, err, http.StatusConflict)
-		case connection.ErrConnectionCancelled:
-			utils.SendError(resp, err, statusConnectCancelled)
-		default:
-			log.Error(err)
-			utils.SendError(resp, err, http.StatusInternalServerError)
-		}
+		sendConnectError(resp, err)
 		return
 	}
+	statusResponse := toConnectionResponse(ce.manager.Status())
 	resp.WriteHeader(http.StatusCreated)
-	ce.Status(resp, req, params)
+	utils.WriteAsJSON(statusResponse, resp)
 }
 
 // Kill stops connection
@@ -285,13 +368,125 @@ func (ce *ConnectionEndpoint) GetStatistics(writer http.ResponseWriter, request
 		Duration:      int(duration.Seconds()),
 	}
 
+	if hops := ce.hopsTracker.Retrieve(); len(hops) > 1 {
+		for _, hop := range hops {
+			response.Hops = append(response.Hops, hopStatisticsResponse{
+				SessionID:     string(hop.SessionID),
+				ProviderID:    hop.ProviderID,
+				ServiceType:   hop.ServiceType,
+				BytesSent:     hop.Stats.BytesSent,
+				BytesReceived: hop.Stats.BytesReceived,
+			})
+		}
+	}
+
 	utils.WriteAsJSON(response, writer)
 }
 
+// lookupConnectionProposals finds the proposals of the requested connection and its hops,
+// the http status is returned together with the error to respond with
+func lookupConnectionProposals(proposalProvider ProposalRepository, qualityProvider QualityFinder, cr *connectionRequest) (market.ServiceProposal, connection.ConnectParams, int, error) {
+	connectOptions := getConnectOptions(cr)
+
+	// TODO Pass proposal ID directly in request
+	proposal, err := proposalProvider.GetProposal(market.ProposalID{
+		ProviderID:  cr.ProviderID,
+		ServiceType: cr.ServiceType,
+	})
+	if err != nil {
+		return market.ServiceProposal{}, connectOptions, http.StatusInternalServerError, err
+	}
+	if proposal == nil {
+		return market.ServiceProposal{}, connectOptions, http.StatusBadRequest, errors.New("provider has no service proposals")
+	}
+
+	for _, hop := range cr.Hops {
+		hopProposal, err := proposalProvider.GetProposal(market.ProposalID{
+			ProviderID:  hop.ProviderID,
+			ServiceType: hop.ServiceType,
+		})
+		if err != nil {
+			return market.ServiceProposal{}, connectOptions, http.StatusInternalServerError, err
+		}
+		if hopProposal == nil {
+			return market.ServiceProposal{}, connectOptions, http.StatusBadRequest, errors.Errorf("hop provider %s has no service proposals", hop.ProviderID)
+		}
+		connectOptions.Hops = append(connectOptions.Hops, *hopProposal)
+	}
+	if connectOptions.Failover {
+		connectOptions.ProposalLookup, err = failoverLookup(proposalProvider, qualityProvider, cr, *proposal)
+		if err != nil {
+			return market.ServiceProposal{}, connectOptions, http.StatusBadRequest, err
+		}
+	}
+	return *proposal, connectOptions, http.StatusOK, nil
+}
+
+// failoverLookup returns proposals of the same service type as requested connection is using, which match
+// the failover filter of the request. Proposals of the same country and access policies are returned if there is no filter.
+// Proposals are ordered by the quality metrics reported by the quality oracle at the time of failover, as proposals endpoint does.
+func failoverLookup(proposalProvider ProposalRepository, qualityProvider QualityFinder, cr *connectionRequest, requested market.ServiceProposal) (connection.ProposalLookup, error) {
+	filter := &proposalsFilter{serviceType: cr.ServiceType}
+
+	var q *query.Query
+	if options := cr.ConnectOptions.FailoverFilter; options != nil {
+		filter.accessPolicyID = options.AccessPolicyID
+		filter.accessPolicySource = options.AccessPolicySource
+		if options.Where != "" {
+			var err error
+			if q, err = query.Parse(options.Where); err != nil {
+				return nil, errors.Wrap(err, "invalid failover filter")
+			}
+			filter.where = q.Match
+		}
+	} else {
+		filter.where = sameLocationAndAccess(requested)
+	}
+
+	return func() ([]market.ServiceProposal, error) {
+		proposals, err := proposalProvider.FindProposals(filter)
+		if err != nil || q == nil {
+			return proposals, err
+		}
+		var quality query.QualityLookup
+		if q.NeedsQuality() {
+			quality = qualityLookup(qualityProvider)
+		}
+		return q.SortAndLimit(proposals, quality), nil
+	}, nil
+}
+
+// sameLocationAndAccess matches the proposals of the same country and access policies as the given one has
+func sameLocationAndAccess(requested market.ServiceProposal) func(market.ServiceProposal) bool {
+	conditions := []reducer.AndCondition{
+		reducer.Equal(reducer.LocationCountry, reducer.LocationCountry(requested)),
+	}
+	if requested.AccessPolicies != nil {
+		for _, policy := range *requested.AccessPolicies {
+			conditions = append(conditions, reducer.AccessPolicy(policy.ID, policy.Source))
+		}
+	}
+	return reducer.And(conditions...)
+}
+
+func sendConnectError(resp http.ResponseWriter, err error) {
+	switch err {
+	case connection.ErrAlreadyExists, connection.ErrFullTunnelInUse:
+		utils.SendError(resp, err, http.StatusConflict)
+	case connection.ErrConnectionCancelled:
+		utils.SendError(resp, err, statusConnectCancelled)
+	case connection.ErrChainingUnsupported, connection.ErrIncludeWithKillSwitch:
+		utils.SendError(resp, err, http.StatusBadRequest)
+	default:
+		log.Error(err)
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
 // AddRoutesForConnection adds connections routes to given router
 func AddRoutesForConnection(router *httprouter.Router, manager connection.Manager,
-	statsKeeper SessionStatisticsTracker, proposalProvider ProposalGetter) {
-	connectionEndpoint := NewConnectionEndpoint(manager, statsKeeper, proposalProvider)
+	statsKeeper SessionStatisticsTracker, hopsKeeper HopStatisticsTracker, proposalProvider ProposalRepository, qualityProvider QualityFinder) {
+	connectionEndpoint := NewConnectionEndpoint(manager, statsKeeper, hopsKeeper, proposalProvider, qualityProvider)
 	router.GET("/connection", connectionEndpoint.Status)
 	router.PUT("/connection", connectionEndpoint.Create)
 	router.DELETE("/connection", connectionEndpoint.Kill)
@@ -308,6 +503,11 @@ func toConnectionRequest(req *http.Request) (*connectionRequest, error) {
 	if err != nil {
 		return nil, err
 	}
+	for i := range connectionRequest.Hops {
+		if connectionRequest.Hops[i].ServiceType == "" {
+			connectionRequest.Hops[i].ServiceType = "wireguard"
+		}
+	}
 	return &connectionRequest, nil
 }
 
@@ -315,6 +515,12 @@ func getConnectOptions(cr *connectionRequest) connection.ConnectParams {
 	return connection.ConnectParams{
 		DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch,
 		EnableDNS:         cr.ConnectOptions.EnableDNS,
+		Failover:          cr.ConnectOptions.Failover,
+		SplitTunnel: connection.SplitTunnel{
+			Include: cr.ConnectOptions.Include,
+			Exclude: cr.ConnectOptions.Exclude,
+		},
+		DisableRelay: cr.ConnectOptions.DisableRelay,
 	}
 }
 
@@ -326,6 +532,20 @@ func validateConnectionRequest(cr *connectionRequest) *validation.FieldErrorMap
 	if len(cr.ProviderID) == 0 {
 		errs.ForField("providerId").AddError("required", "Field is required")
 	}
+	for _, hop := range cr.Hops {
+		if len(hop.ProviderID) == 0 {
+			errs.ForField("hops").AddError("required", "Provider of every hop is required")
+			break
+		}
+	}
+	if len(cr.ConnectOptions.Include) > 0 && !cr.ConnectOptions.DisableKillSwitch {
+		errs.ForField("include").AddError("invalid", "Kill switch must be disabled to limit the tunnel to included destinations")
+	}
+	if filter := cr.ConnectOptions.FailoverFilter; filter != nil && filter.Where != "" {
+		if _, err := query.Parse(filter.Where); err != nil {
+			errs.ForField("failoverFilter").AddError("invalid", err.Error())
+		}
+	}
 	return errs
 }
 
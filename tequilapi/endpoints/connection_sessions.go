@@ -62,6 +62,10 @@ type connectionSession struct {
 
 	// example: Completed
 	Status string `json:"status"`
+
+	// whether the session traffic goes through the relay
+	// example: false
+	Relayed bool `json:"relayed"`
 }
 
 type connectionSessionStorage interface {
@@ -120,6 +124,7 @@ func connectionSessionToDto(se session.History) connectionSession {
 		BytesReceived:   se.DataStats.BytesReceived,
 		Duration:        se.GetDuration(),
 		Status:          se.Status,
+		Relayed:         se.Relayed,
 	}
 }
 
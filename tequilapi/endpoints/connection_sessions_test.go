@@ -42,6 +42,7 @@ var (
 		ProviderCountry: "ProviderCountry",
 		Started:         time.Now(),
 		Updated:         time.Now(),
+		Relayed:         true,
 		DataStats: consumer.SessionStatistics{
 			BytesReceived: 10,
 			BytesSent:     10,
@@ -66,6 +67,7 @@ func Test_ConnectionSessionsEndpoint_SessionToDto(t *testing.T) {
 	assert.Equal(t, connectionSessionMock.DataStats.BytesSent, sessionDTO.BytesSent)
 	assert.Equal(t, connectionSessionMock.GetDuration(), sessionDTO.Duration)
 	assert.Equal(t, connectionSessionMock.Status, sessionDTO.Status)
+	assert.Equal(t, connectionSessionMock.Relayed, sessionDTO.Relayed)
 }
 
 func Test_ConnectionSessionsEndpoint_List(t *testing.T) {
@@ -18,6 +18,7 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/consumer/statistics"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
@@ -40,12 +42,14 @@ type mockConnectionManager struct {
 	requestedConsumerID  identity.Identity
 	requestedProvider    identity.Identity
 	requestedServiceType string
+	requestedParams      connection.ConnectParams
 }
 
 func (cm *mockConnectionManager) Connect(consumerID identity.Identity, proposal market.ServiceProposal, options connection.ConnectParams) error {
 	cm.requestedConsumerID = consumerID
 	cm.requestedProvider = identity.FromAddress(proposal.ProviderID)
 	cm.requestedServiceType = proposal.ServiceType
+	cm.requestedParams = options
 	return cm.onConnectReturn
 }
 
@@ -76,6 +80,14 @@ func (ssk *StubStatisticsTracker) GetSessionDuration() time.Duration {
 	return ssk.duration
 }
 
+type StubHopStatisticsTracker struct {
+	hops []statistics.HopStatistics
+}
+
+func (shst *StubHopStatisticsTracker) Retrieve() []statistics.HopStatistics {
+	return shst.hops
+}
+
 func getMockProposalProviderWithSpecifiedProposal(providerID, serviceType string) *mockProposalProvider {
 	sampleProposal := market.ServiceProposal{
 		ID:                1,
@@ -97,7 +109,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 	}
 
 	mockedProposalProvider := getMockProposalProviderWithSpecifiedProposal("node1", "noop")
-	AddRoutesForConnection(router, &fakeManager, statsKeeper, mockedProposalProvider)
+	AddRoutesForConnection(router, &fakeManager, statsKeeper, &StubHopStatisticsTracker{}, mockedProposalProvider, nil)
 
 	tests := []struct {
 		method         string
@@ -148,7 +160,7 @@ func TestDisconnectingState(t *testing.T) {
 		SessionID: "",
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -170,7 +182,7 @@ func TestNotConnectedStateIsReturnedWhenNoConnection(t *testing.T) {
 		SessionID: "",
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -192,7 +204,7 @@ func TestStateConnectingIsReturnedWhenIsConnectionInProgress(t *testing.T) {
 		State: connection.Connecting,
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -215,7 +227,7 @@ func TestConnectedStateAndSessionIdIsReturnedWhenIsConnected(t *testing.T) {
 		SessionID: "My-super-session",
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -235,7 +247,7 @@ func TestConnectedStateAndSessionIdIsReturnedWhenIsConnected(t *testing.T) {
 func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil)
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("a"))
 	resp := httptest.NewRecorder()
 
@@ -254,7 +266,7 @@ func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil)
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("{}"))
 	resp := httptest.NewRecorder()
 
@@ -277,7 +289,7 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	proposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, proposalProvider)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, proposalProvider, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -301,7 +313,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	mystAPI := getMockProposalProviderWithSpecifiedProposal("required-node", "noop")
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, mystAPI)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, mystAPI, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -325,7 +337,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 func TestDeleteCallsDisconnect(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil)
 	req := httptest.NewRequest(http.MethodDelete, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -343,7 +355,7 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	}
 
 	manager := mockConnectionManager{}
-	connEndpoint := NewConnectionEndpoint(&manager, statsKeeper, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&manager, statsKeeper, &StubHopStatisticsTracker{}, &mockProposalProvider{}, nil)
 
 	resp := httptest.NewRecorder()
 	connEndpoint.GetStatistics(resp, nil, nil)
@@ -358,13 +370,45 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	)
 }
 
+func TestGetStatisticsEndpointReturnsStatisticsOfEveryHop(t *testing.T) {
+	statsKeeper := &StubStatisticsTracker{
+		duration: time.Minute,
+		stats:    consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2},
+	}
+	hopsKeeper := &StubHopStatisticsTracker{
+		hops: []statistics.HopStatistics{
+			{SessionID: "session-1", ProviderID: "node1", ServiceType: "wireguard", Stats: consumer.SessionStatistics{BytesSent: 3, BytesReceived: 4}},
+			{SessionID: "session-2", ProviderID: "node2", ServiceType: "wireguard", Stats: consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2}},
+		},
+	}
+
+	manager := mockConnectionManager{}
+	connEndpoint := NewConnectionEndpoint(&manager, statsKeeper, hopsKeeper, &mockProposalProvider{}, nil)
+
+	resp := httptest.NewRecorder()
+	connEndpoint.GetStatistics(resp, nil, nil)
+	assert.JSONEq(
+		t,
+		`{
+			"bytesSent": 1,
+			"bytesReceived": 2,
+			"duration": 60,
+			"hops": [
+				{"sessionId": "session-1", "providerId": "node1", "serviceType": "wireguard", "bytesSent": 3, "bytesReceived": 4},
+				{"sessionId": "session-2", "providerId": "node2", "serviceType": "wireguard", "bytesSent": 1, "bytesReceived": 2}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func TestGetStatisticsEndpointReturnsStatisticsWhenSessionIsNotStarted(t *testing.T) {
 	statsKeeper := &StubStatisticsTracker{
 		stats: consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2},
 	}
 
 	manager := mockConnectionManager{}
-	connEndpoint := NewConnectionEndpoint(&manager, statsKeeper, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&manager, statsKeeper, &StubHopStatisticsTracker{}, &mockProposalProvider{}, nil)
 
 	resp := httptest.NewRecorder()
 	connEndpoint.GetStatistics(resp, nil, nil)
@@ -384,7 +428,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager.onConnectReturn = connection.ErrAlreadyExists
 
 	mystAPI := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, mystAPI)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mystAPI, nil)
 
 	req := httptest.NewRequest(
 		http.MethodPut,
@@ -412,7 +456,7 @@ func TestDisconnectReturnsConflictStatusIfConnectionDoesNotExist(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onDisconnectReturn = connection.ErrNoConnection
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalProvider{})
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, &mockProposalProvider{}, nil)
 
 	req := httptest.NewRequest(
 		http.MethodDelete,
@@ -438,7 +482,7 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	manager.onConnectReturn = connection.ErrConnectionCancelled
 
 	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, mockProposalProvider)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -465,7 +509,7 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onConnectReturn = connection.ErrConnectionCancelled
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalProvider{proposals: make([]market.ServiceProposal, 0)})
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, &mockProposalProvider{proposals: make([]market.ServiceProposal, 0)}, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -487,3 +531,253 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 		resp.Body.String(),
 	)
 }
+
+func TestConnectPassesChainedHopsToManager(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "wireguard")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"serviceType": "wireguard",
+				"hops": [{"providerId": "required-node"}]
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Len(t, manager.requestedParams.Hops, 1)
+	assert.Equal(t, "required-node", manager.requestedParams.Hops[0].ProviderID)
+}
+
+func TestConnectPassesSplitTunnelToManager(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"connectOptions": {
+					"killSwitch": true,
+					"include": ["10.0.0.0/8"],
+					"exclude": ["192.168.0.0/16", "intranet.example.com"]
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(
+		t,
+		connection.SplitTunnel{
+			Include: []string{"10.0.0.0/8"},
+			Exclude: []string{"192.168.0.0/16", "intranet.example.com"},
+		},
+		manager.requestedParams.SplitTunnel,
+	)
+}
+
+func TestConnectPassesDisableRelayToManager(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"connectOptions": {
+					"disableRelay": true
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.True(t, manager.requestedParams.DisableRelay)
+}
+
+type countryServiceDefinition struct {
+	country string
+}
+
+func (service countryServiceDefinition) GetLocation() market.Location {
+	return market.Location{Country: service.country}
+}
+
+func TestConnectFailsOverToProposalsMatchingFailoverFilter(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"connectOptions": {
+					"failover": true,
+					"failoverFilter": {"where": "country = DE"}
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	_, err := manager.requestedParams.ProposalLookup()
+	assert.NoError(t, err)
+
+	filter := mockProposalProvider.recordedFilter
+	assert.True(t, filter.Matches(market.ServiceProposal{ServiceType: "openvpn", ServiceDefinition: countryServiceDefinition{"DE"}}))
+	assert.False(t, filter.Matches(market.ServiceProposal{ServiceType: "openvpn", ServiceDefinition: countryServiceDefinition{"NL"}}))
+	assert.False(t, filter.Matches(market.ServiceProposal{ServiceType: "wireguard", ServiceDefinition: countryServiceDefinition{"DE"}}))
+}
+
+// qualityProviderStub reports the given connect counts of the proposals keyed by provider ID
+type qualityProviderStub map[string]string
+
+func (qps qualityProviderStub) ProposalsMetrics() []json.RawMessage {
+	metrics := make([]json.RawMessage, 0, len(qps))
+	for providerID, connectCount := range qps {
+		metrics = append(metrics, json.RawMessage(`{
+			"proposalID": {"providerID": "`+providerID+`", "serviceType": "openvpn"},
+			"connectCount": `+connectCount+`
+		}`))
+	}
+	return metrics
+}
+
+func TestConnectFailsOverToProposalsOrderedByQuality(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := &mockProposalProvider{proposals: []market.ServiceProposal{
+		{ProviderID: "required-node", ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}},
+		{ProviderID: "unreliable-node", ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}},
+		{ProviderID: "reliable-node", ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}},
+	}}
+	qualityProvider := qualityProviderStub{
+		"unreliable-node": `{"success": 1, "fail": 9, "timeout": 0}`,
+		"reliable-node":   `{"success": 9, "fail": 1, "timeout": 0}`,
+	}
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, qualityProvider)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"connectOptions": {
+					"failover": true,
+					"failoverFilter": {"where": "order by quality desc"}
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	proposals, err := manager.requestedParams.ProposalLookup()
+	assert.NoError(t, err)
+	providers := make([]string, 0, len(proposals))
+	for _, proposal := range proposals {
+		providers = append(providers, proposal.ProviderID)
+	}
+	assert.Equal(t, []string{"reliable-node", "unreliable-node", "required-node"}, providers)
+}
+
+func TestConnectFailsOverToProposalsOfRequestedCountry_WhenNoFailoverFilterIsGiven(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"connectOptions": {"failover": true}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	_, err := manager.requestedParams.ProposalLookup()
+	assert.NoError(t, err)
+
+	filter := mockProposalProvider.recordedFilter
+	assert.True(t, filter.Matches(market.ServiceProposal{ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}}))
+	assert.False(t, filter.Matches(market.ServiceProposal{ServiceType: "openvpn", ServiceDefinition: countryServiceDefinition{"DE"}}))
+}
+
+func TestConnectReturns422Error_WhenFailoverFilterIsInvalid(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"connectOptions": {"failover": true, "failoverFilter": {"where": "country ="}}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestConnectReturns422Error_WhenTunnelIsLimitedWithKillSwitch(t *testing.T) {
+	manager := mockConnectionManager{}
+
+	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"connectOptions": {"include": ["10.0.0.0/8"]}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "validation_error",
+			"errors" : {
+				"include" : [ { "code" : "invalid" , "message" : "Kill switch must be disabled to limit the tunnel to included destinations" } ]
+			}
+		}`, resp.Body.String())
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// swagger:model ConnectionListDTO
+type connectionListResponse struct {
+	Connections []connectionWithIDResponse `json:"connections"`
+}
+
+// swagger:model ConnectionDTO
+type connectionWithIDResponse struct {
+	// example: default
+	ID string `json:"id"`
+
+	connectionResponse
+}
+
+// ConnectionStatisticsTracker represents the stats keeper of multiple connections
+type ConnectionStatisticsTracker interface {
+	Retrieve(connectionID string) (consumer.SessionStatistics, time.Duration, bool)
+}
+
+// ConnectionsEndpoint struct represents /connections resource and it's subresources
+type ConnectionsEndpoint struct {
+	pool              connection.Pool
+	statisticsTracker ConnectionStatisticsTracker
+	proposalProvider  ProposalRepository
+	qualityProvider   QualityFinder
+}
+
+// NewConnectionsEndpoint creates and returns endpoint of multiple connections
+func NewConnectionsEndpoint(pool connection.Pool, statsKeeper ConnectionStatisticsTracker, proposalProvider ProposalRepository, qualityProvider QualityFinder) *ConnectionsEndpoint {
+	return &ConnectionsEndpoint{
+		pool:              pool,
+		statisticsTracker: statsKeeper,
+		proposalProvider:  proposalProvider,
+		qualityProvider:   qualityProvider,
+	}
+}
+
+// List returns statuses of all connections
+// swagger:operation GET /connections Connection connectionList
+// ---
+// summary: Returns all connections
+// description: Returns statuses of all connections, the "default" one is the connection managed by /connection
+// responses:
+//   200:
+//     description: List of connections
+//     schema:
+//       "$ref": "#/definitions/ConnectionListDTO"
+func (ce *ConnectionsEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	statuses := ce.pool.List()
+
+	ids := make([]string, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	response := connectionListResponse{Connections: make([]connectionWithIDResponse, len(ids))}
+	for i, id := range ids {
+		response.Connections[i] = connectionWithIDResponse{
+			ID:                 id,
+			connectionResponse: toConnectionResponse(statuses[id]),
+		}
+	}
+	utils.WriteAsJSON(response, resp)
+}
+
+// Status returns status of the connection
+// swagger:operation GET /connections/{id} Connection connectionStatusByID
+// ---
+// summary: Returns connection status
+// description: Returns status of the connection with the given id
+// parameters:
+//   - in: path
+//     name: id
+//     description: connection id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Status
+//     schema:
+//       "$ref": "#/definitions/ConnectionDTO"
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ce *ConnectionsEndpoint) Status(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+	status, err := ce.pool.Status(id)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+
+	utils.WriteAsJSON(connectionWithIDResponse{
+		ID:                 id,
+		connectionResponse: toConnectionResponse(status),
+	}, resp)
+}
+
+// Create starts new connection with the given id
+// swagger:operation PUT /connections/{id} Connection connectionCreateByID
+// ---
+// summary: Starts new connection
+// description: Consumer opens connection with the given id to provider, only one connection may tunnel all traffic
+// parameters:
+//   - in: path
+//     name: id
+//     description: connection id
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     description: Parameters in body (consumerId, providerId, serviceType) required for creating new connection
+//     schema:
+//       $ref: "#/definitions/ConnectionRequestDTO"
+// responses:
+//   201:
+//     description: Connection started
+//     schema:
+//       "$ref": "#/definitions/ConnectionDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Connection was closed before its status was returned
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Conflict. Connection already exists or another connection tunnels all traffic
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   499:
+//     description: Connection was cancelled
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ce *ConnectionsEndpoint) Create(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	cr, err := toConnectionRequest(req)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	errorMap := validateConnectionRequest(cr)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	proposal, connectOptions, status, err := lookupConnectionProposals(ce.proposalProvider, ce.qualityProvider, cr)
+	if err != nil {
+		utils.SendError(resp, err, status)
+		return
+	}
+
+	id := params.ByName("id")
+	err = ce.pool.Connect(id, identity.FromAddress(cr.ConsumerID), proposal, connectOptions)
+	if err != nil {
+		sendConnectError(resp, err)
+		return
+	}
+
+	connectionStatus, err := ce.pool.Status(id)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	utils.WriteAsJSON(connectionWithIDResponse{
+		ID:                 id,
+		connectionResponse: toConnectionResponse(connectionStatus),
+	}, resp)
+}
+
+// Kill stops the connection
+// swagger:operation DELETE /connections/{id} Connection connectionCancelByID
+// ---
+// summary: Stops connection
+// description: Stops the connection with the given id, the other connections are kept
+// parameters:
+//   - in: path
+//     name: id
+//     description: connection id
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Connection Stopped
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ce *ConnectionsEndpoint) Kill(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	err := ce.pool.Disconnect(params.ByName("id"))
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			utils.SendError(resp, err, http.StatusNotFound)
+		default:
+			utils.SendError(resp, err, http.StatusInternalServerError)
+		}
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// GetStatistics returns statistics of the connection
+// swagger:operation GET /connections/{id}/statistics Connection connectionStatisticsByID
+// ---
+// summary: Returns connection statistics
+// description: Returns statistics of the connection with the given id
+// parameters:
+//   - in: path
+//     name: id
+//     description: connection id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Connection statistics
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatisticsDTO"
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (ce *ConnectionsEndpoint) GetStatistics(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+	if _, err := ce.pool.Status(id); err != nil {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+
+	stats, duration, _ := ce.statisticsTracker.Retrieve(id)
+	utils.WriteAsJSON(statisticsResponse{
+		BytesSent:     stats.BytesSent,
+		BytesReceived: stats.BytesReceived,
+		Duration:      int(duration.Seconds()),
+	}, resp)
+}
+
+// AddRoutesForConnections adds routes of multiple connections to given router
+func AddRoutesForConnections(router *httprouter.Router, pool connection.Pool, statsKeeper ConnectionStatisticsTracker, proposalProvider ProposalRepository, qualityProvider QualityFinder) {
+	connectionsEndpoint := NewConnectionsEndpoint(pool, statsKeeper, proposalProvider, qualityProvider)
+	router.GET("/connections", connectionsEndpoint.List)
+	router.GET("/connections/:id", connectionsEndpoint.Status)
+	router.PUT("/connections/:id", connectionsEndpoint.Create)
+	router.DELETE("/connections/:id", connectionsEndpoint.Kill)
+	router.GET("/connections/:id/statistics", connectionsEndpoint.GetStatistics)
+}
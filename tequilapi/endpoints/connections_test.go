@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConnectionPool struct {
+	connections     map[string]connection.Status
+	onConnectReturn error
+	requestedID     string
+	requestedParams connection.ConnectParams
+	consumerInUse   identity.Identity
+}
+
+func (cp *mockConnectionPool) Connect(id string, consumerID identity.Identity, proposal market.ServiceProposal, params connection.ConnectParams) error {
+	cp.requestedID = id
+	cp.requestedParams = params
+	if cp.onConnectReturn != nil {
+		return cp.onConnectReturn
+	}
+	cp.connections[id] = connection.Status{State: connection.Connected, Proposal: proposal}
+	return nil
+}
+
+func (cp *mockConnectionPool) Status(id string) (connection.Status, error) {
+	status, ok := cp.connections[id]
+	if !ok {
+		return connection.Status{}, connection.ErrNoConnection
+	}
+	return status, nil
+}
+
+func (cp *mockConnectionPool) Disconnect(id string) error {
+	if _, ok := cp.connections[id]; !ok {
+		return connection.ErrNoConnection
+	}
+	delete(cp.connections, id)
+	return nil
+}
+
+func (cp *mockConnectionPool) List() map[string]connection.Status {
+	return cp.connections
+}
+
+func (cp *mockConnectionPool) InUse(consumerID identity.Identity) bool {
+	return cp.consumerInUse == consumerID
+}
+
+type stubConnectionStatisticsTracker struct {
+	stats map[string]consumer.SessionStatistics
+}
+
+func (cst *stubConnectionStatisticsTracker) Retrieve(connectionID string) (consumer.SessionStatistics, time.Duration, bool) {
+	stats, ok := cst.stats[connectionID]
+	return stats, time.Minute, ok
+}
+
+func TestAddRoutesForConnectionsAddsRoutes(t *testing.T) {
+	router := httprouter.New()
+	pool := &mockConnectionPool{
+		connections: map[string]connection.Status{
+			connection.DefaultConnectionID: {State: connection.NotConnected},
+		},
+	}
+	statsKeeper := &stubConnectionStatisticsTracker{
+		stats: map[string]consumer.SessionStatistics{"work": {BytesSent: 1, BytesReceived: 2}},
+	}
+	AddRoutesForConnections(router, pool, statsKeeper, getMockProposalProviderWithSpecifiedProposal("node1", "noop"), nil)
+
+	tests := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedJSON   string
+	}{
+		{
+			http.MethodPut, "/connections/work",
+			`{"consumerId": "me", "providerId": "node1", "serviceType": "noop", "connectOptions": {"killSwitch": true, "include": ["10.0.0.0/8"]}}`,
+			http.StatusCreated, `{"id": "work", "status": "Connected", "proposal": {"id": 1, "providerId": "node1", "serviceType": "noop", "serviceDefinition": {"locationOriginate": {"asn": 123, "country": "Lithuania", "city": "Vilnius"}}}}`,
+		},
+		{
+			http.MethodGet, "/connections", "",
+			http.StatusOK, `{"connections": [
+				{"id": "default", "status": "NotConnected"},
+				{"id": "work", "status": "Connected", "proposal": {"id": 1, "providerId": "node1", "serviceType": "noop", "serviceDefinition": {"locationOriginate": {"asn": 123, "country": "Lithuania", "city": "Vilnius"}}}}
+			]}`,
+		},
+		{
+			http.MethodGet, "/connections/work/statistics", "",
+			http.StatusOK, `{"bytesSent": 1, "bytesReceived": 2, "duration": 60}`,
+		},
+		{
+			http.MethodDelete, "/connections/work", "",
+			http.StatusAccepted, "",
+		},
+		{
+			http.MethodGet, "/connections/work", "",
+			http.StatusNotFound, `{"message": "no connection exists"}`,
+		},
+		{
+			http.MethodDelete, "/connections/work", "",
+			http.StatusNotFound, `{"message": "no connection exists"}`,
+		},
+	}
+
+	for _, test := range tests {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, test.expectedStatus, resp.Code, test.method+" "+test.path)
+		if test.expectedJSON != "" {
+			assert.JSONEq(t, test.expectedJSON, resp.Body.String())
+		} else {
+			assert.Equal(t, "", resp.Body.String())
+		}
+	}
+	assert.Equal(t, "work", pool.requestedID)
+	assert.Equal(t, []string{"10.0.0.0/8"}, pool.requestedParams.SplitTunnel.Include)
+}
+
+func TestConnectionsCreateReturnsConflictWhenFullTunnelIsInUse(t *testing.T) {
+	pool := &mockConnectionPool{
+		connections:     map[string]connection.Status{},
+		onConnectReturn: connection.ErrFullTunnelInUse,
+	}
+	endpoint := NewConnectionsEndpoint(pool, nil, getMockProposalProviderWithSpecifiedProposal("node1", "noop"), nil)
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(`{"consumerId": "me", "providerId": "node1", "serviceType": "noop"}`),
+	)
+	resp := httptest.NewRecorder()
+	endpoint.Create(resp, req, httprouter.Params{{Key: "id", Value: "work"}})
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"message": "another connection already tunnels all traffic"}`, resp.Body.String())
+}
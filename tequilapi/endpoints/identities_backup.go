@@ -0,0 +1,259 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+	"github.com/pkg/errors"
+)
+
+// swagger:model IdentityExportRequestDTO
+type identityExportRequest struct {
+	// passphrase the identity is encrypted with
+	// required: true
+	Passphrase *string `json:"passphrase"`
+
+	// passphrase the exported keystore JSON is encrypted with, defaults to passphrase
+	// required: false
+	NewPassphrase *string `json:"new_passphrase,omitempty"`
+}
+
+// swagger:model IdentityImportRequestDTO
+type identityImportRequest struct {
+	// encrypted keystore JSON of the identity, required unless mnemonic is given
+	// required: false
+	Keystore json.RawMessage `json:"keystore,omitempty"`
+
+	// BIP-39 mnemonic phrase, the first Ethereum account of it (m/44'/60'/0'/0/0) is imported
+	// required: false
+	Mnemonic string `json:"mnemonic,omitempty"`
+
+	// optional BIP-39 password of the mnemonic phrase
+	// required: false
+	MnemonicPassword string `json:"mnemonic_password,omitempty"`
+
+	// passphrase the keystore JSON is encrypted with, the imported mnemonic is encrypted with it
+	// required: true
+	Passphrase *string `json:"passphrase"`
+
+	// passphrase the imported keystore JSON is re-encrypted with, defaults to passphrase
+	// required: false
+	NewPassphrase *string `json:"new_passphrase,omitempty"`
+}
+
+type identitiesBackupAPI struct {
+	backup identity.Backup
+}
+
+// NewIdentitiesBackupEndpoint creates identity export and import api controller used by tequilapi service
+func NewIdentitiesBackupEndpoint(backup identity.Backup) *identitiesBackupAPI {
+	return &identitiesBackupAPI{
+		backup: backup,
+	}
+}
+
+// swagger:operation POST /identities/{id}/export Identity exportIdentity
+// ---
+// summary: Exports identity
+// description: Returns the encrypted keystore JSON of the identity
+// parameters:
+//   - in: path
+//     name: id
+//     description: Identity stored in keystore
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/IdentityExportRequestDTO"
+// responses:
+//   200:
+//     description: Encrypted keystore JSON
+//   400:
+//     description: Bad Request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Invalid passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   501:
+//     description: Keys are kept by the external signer
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesBackupAPI) Export(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	req := &identityExportRequest{}
+	if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	errorMap := validateExportRequest(req)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	newPassphrase := *req.Passphrase
+	if req.NewPassphrase != nil {
+		newPassphrase = *req.NewPassphrase
+	}
+	keyJSON, err := endpoint.backup.ExportIdentity(params.ByName("id"), *req.Passphrase, newPassphrase)
+	if err != nil {
+		sendIdentityBackupError(resp, err)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Write(keyJSON)
+}
+
+// swagger:operation POST /identities-import Identity importIdentity
+// ---
+// summary: Imports identity
+// description: Imports identity from the encrypted keystore JSON or the BIP-39 mnemonic phrase
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/IdentityImportRequestDTO"
+// responses:
+//   200:
+//     description: Identity imported
+//     schema:
+//       "$ref": "#/definitions/IdentityDTO"
+//   400:
+//     description: Bad Request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Invalid passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Identity already exists
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   501:
+//     description: Keys are kept by the external signer
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesBackupAPI) Import(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	req := &identityImportRequest{}
+	if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	keyJSON, errorMap := validateImportRequest(req)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	var id identity.Identity
+	var err error
+	if req.Mnemonic != "" {
+		id, err = endpoint.backup.ImportIdentityFromMnemonic(req.Mnemonic, req.MnemonicPassword, *req.Passphrase)
+	} else {
+		newPassphrase := *req.Passphrase
+		if req.NewPassphrase != nil {
+			newPassphrase = *req.NewPassphrase
+		}
+		id, err = endpoint.backup.ImportIdentity(keyJSON, *req.Passphrase, newPassphrase)
+	}
+	if err != nil {
+		sendIdentityBackupError(resp, err)
+		return
+	}
+
+	utils.WriteAsJSON(idToDto(id), resp)
+}
+
+func validateExportRequest(req *identityExportRequest) *validation.FieldErrorMap {
+	errors := validation.NewErrorMap()
+	if req.Passphrase == nil {
+		errors.ForField("passphrase").AddError("required", "Field is required")
+	}
+	return errors
+}
+
+func validateImportRequest(req *identityImportRequest) ([]byte, *validation.FieldErrorMap) {
+	errors := validation.NewErrorMap()
+	if req.Passphrase == nil {
+		errors.ForField("passphrase").AddError("required", "Field is required")
+	}
+	if len(req.Keystore) == 0 && req.Mnemonic == "" {
+		errors.ForField("keystore").AddError("required", "Either keystore or mnemonic is required")
+	}
+	if len(req.Keystore) > 0 && req.Mnemonic != "" {
+		errors.ForField("mnemonic").AddError("invalid", "Either keystore or mnemonic can be given")
+	}
+
+	// keystore JSON can be given as object or as string holding it
+	keyJSON := []byte(req.Keystore)
+	var keyString string
+	if json.Unmarshal(req.Keystore, &keyString) == nil {
+		keyJSON = []byte(keyString)
+	}
+	return keyJSON, errors
+}
+
+func sendIdentityBackupError(resp http.ResponseWriter, err error) {
+	switch errors.Cause(err) {
+	case identity.ErrInvalidPassphrase:
+		utils.SendError(resp, err, http.StatusForbidden)
+	case identity.ErrIdentityExists:
+		utils.SendError(resp, err, http.StatusConflict)
+	case identity.ErrInvalidMnemonic:
+		utils.SendError(resp, err, http.StatusBadRequest)
+	case identity.ErrBackupNotSupported:
+		utils.SendError(resp, err, http.StatusNotImplemented)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+// AddRoutesForIdentitiesBackup creates identity export and import endpoints on tequilapi service
+func AddRoutesForIdentitiesBackup(router *httprouter.Router, backup identity.Backup) {
+	backupEnd := NewIdentitiesBackupEndpoint(backup)
+	router.POST("/identities-import", backupEnd.Import)
+	router.POST("/identities/:id/export", backupEnd.Export)
+}
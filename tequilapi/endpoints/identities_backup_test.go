@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+type identityBackupMock struct {
+	lastKeyJSON       string
+	lastPassphrase    string
+	lastNewPassphrase string
+	lastMnemonic      string
+	err               error
+}
+
+func (mock *identityBackupMock) ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error) {
+	mock.lastPassphrase = passphrase
+	mock.lastNewPassphrase = newPassphrase
+	return []byte(`{"address":"` + address + `"}`), mock.err
+}
+
+func (mock *identityBackupMock) ImportIdentity(keyJSON []byte, passphrase, newPassphrase string) (identity.Identity, error) {
+	mock.lastKeyJSON = string(keyJSON)
+	mock.lastPassphrase = passphrase
+	mock.lastNewPassphrase = newPassphrase
+	return identity.FromAddress("0x000000000000000000000000000000000000cafe"), mock.err
+}
+
+func (mock *identityBackupMock) ImportIdentityFromMnemonic(mnemonic, mnemonicPassword, passphrase string) (identity.Identity, error) {
+	mock.lastMnemonic = mnemonic
+	mock.lastPassphrase = passphrase
+	return identity.FromAddress("0x9858effd232b4033e47d90003d41ec34ecaeda94"), mock.err
+}
+
+func serveIdentitiesBackupRequest(backup identity.Backup, method, path, body string) *httptest.ResponseRecorder {
+	router := httprouter.New()
+	AddRoutesForIdentitiesBackup(router, backup)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return resp
+}
+
+func TestIdentitiesBackupExport(t *testing.T) {
+	backup := &identityBackupMock{}
+
+	resp := serveIdentitiesBackupRequest(backup, http.MethodPost, "/identities/0x1/export", `{"passphrase": "old", "new_passphrase": "new"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"address":"0x1"}`, resp.Body.String())
+	assert.Equal(t, "old", backup.lastPassphrase)
+	assert.Equal(t, "new", backup.lastNewPassphrase)
+
+	backup.err = identity.ErrInvalidPassphrase
+	resp = serveIdentitiesBackupRequest(backup, http.MethodPost, "/identities/0x1/export", `{"passphrase": "wrong"}`)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, "wrong", backup.lastNewPassphrase)
+
+	resp = serveIdentitiesBackupRequest(backup, http.MethodPost, "/identities/0x1/export", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestIdentitiesBackupImportKeystore(t *testing.T) {
+	backup := &identityBackupMock{}
+
+	resp := serveIdentitiesBackupRequest(backup, http.MethodPost, "/identities-import", `{
+		"keystore": {"address": "000000000000000000000000000000000000cafe"},
+		"passphrase": "old"
+	}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id": "0x000000000000000000000000000000000000cafe"}`, resp.Body.String())
+	assert.JSONEq(t, `{"address": "000000000000000000000000000000000000cafe"}`, backup.lastKeyJSON)
+	assert.Equal(t, "old", backup.lastNewPassphrase)
+
+	backup.err = identity.ErrIdentityExists
+	resp = serveIdentitiesBackupRequest(backup, http.MethodPost, "/identities-import", `{
+		"keystore": "{\"address\": \"cafe\"}",
+		"passphrase": "old",
+		"new_passphrase": "new"
+	}`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, `{"address": "cafe"}`, backup.lastKeyJSON)
+	assert.Equal(t, "new", backup.lastNewPassphrase)
+}
+
+func TestIdentitiesBackupImportMnemonic(t *testing.T) {
+	backup := &identityBackupMock{}
+
+	resp := serveIdentitiesBackupRequest(backup, http.MethodPost, "/identities-import", `{
+		"mnemonic": "abandon about",
+		"passphrase": "new"
+	}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id": "0x9858effd232b4033e47d90003d41ec34ecaeda94"}`, resp.Body.String())
+	assert.Equal(t, "abandon about", backup.lastMnemonic)
+}
+
+func TestIdentitiesBackupImportValidation(t *testing.T) {
+	resp := serveIdentitiesBackupRequest(&identityBackupMock{}, http.MethodPost, "/identities-import", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {
+			"passphrase": [{"code": "required", "message": "Field is required"}],
+			"keystore": [{"code": "required", "message": "Either keystore or mnemonic is required"}]
+		}
+	}`, resp.Body.String())
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/earnings"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// earningsReport defines the earnings of the provider summed up by period representable as json
+// swagger:model EarningsReportDTO
+type earningsReport struct {
+	// example: day
+	Period string `json:"period"`
+
+	Earnings []earningsSummary `json:"earnings"`
+
+	// amount earned during all the reported periods
+	// example: 1500
+	Total uint64 `json:"total"`
+}
+
+// earningsSummary represents the earnings during a single period
+// swagger:model EarningsSummaryDTO
+type earningsSummary struct {
+	// example: 2019-10-01T00:00:00Z
+	DateStart string `json:"dateStart"`
+
+	// example: 2019-10-02T00:00:00Z
+	DateEnd string `json:"dateEnd"`
+
+	// amount promised by the consumers during the period
+	// example: 500
+	Amount uint64 `json:"amount"`
+
+	// count of validated promises
+	// example: 30
+	Promises int `json:"promises"`
+
+	// example: 3
+	Sessions int `json:"sessions"`
+
+	// example: 2
+	Consumers int `json:"consumers"`
+}
+
+type earningsReporter interface {
+	Report(providerID string, period earnings.Period, from, to time.Time) ([]earnings.Summary, error)
+}
+
+type earningsEndpoint struct {
+	reporter earningsReporter
+}
+
+// NewEarningsEndpoint creates and returns provider earnings endpoint
+func NewEarningsEndpoint(reporter earningsReporter) *earningsEndpoint {
+	return &earningsEndpoint{
+		reporter: reporter,
+	}
+}
+
+// swagger:operation GET /identities/{id}/earnings Identity identityEarnings
+// ---
+// summary: Returns earnings of the provider
+// description: Returns the amounts promised by the consumers summed up by day, week or month, oldest period first
+// parameters:
+//   - name: id
+//     in: path
+//     description: Identity of the provider
+//     type: string
+//     required: true
+//   - in: query
+//     name: period
+//     description: period to sum up the earnings by, one of day, week, month. Day by default
+//     type: string
+//   - in: query
+//     name: from
+//     description: RFC3339 time the promises are received at or after
+//     type: string
+//   - in: query
+//     name: to
+//     description: RFC3339 time the promises are received before
+//     type: string
+//   - in: query
+//     name: format
+//     description: json by default, csv for export
+//     type: string
+// responses:
+//   200:
+//     description: Earnings report
+//     schema:
+//       "$ref": "#/definitions/EarningsReportDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *earningsEndpoint) Earnings(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	values := request.URL.Query()
+	errorMap := validation.NewErrorMap()
+
+	periodName := values.Get("period")
+	if periodName == "" {
+		periodName = string(earnings.Day)
+	}
+	period, err := earnings.ParsePeriod(periodName)
+	if err != nil {
+		errorMap.ForField("period").AddError("invalid", err.Error())
+	}
+	from := parseTimeParam(values, "from", errorMap)
+	to := parseTimeParam(values, "to", errorMap)
+	format := values.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		errorMap.ForField("format").AddError("invalid", "Format should be json or csv")
+	}
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	providerID := identity.FromAddress(params.ByName("id"))
+	summaries, err := endpoint.reporter.Report(providerID.Address, period, from, to)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	report := earningsReport{
+		Period:   string(period),
+		Earnings: make([]earningsSummary, len(summaries)),
+	}
+	for i, summary := range summaries {
+		report.Earnings[i] = earningsSummaryToDto(summary)
+		report.Total += summary.Amount
+	}
+
+	if format == "csv" {
+		filename := fmt.Sprintf("earnings-%s-%s.csv", providerID.Address, period)
+		resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		writeEarningsCSV(resp, report)
+		return
+	}
+	utils.WriteAsJSON(report, resp)
+}
+
+// AddRoutesForEarnings attaches provider earnings endpoints to router
+func AddRoutesForEarnings(router *httprouter.Router, reporter earningsReporter) {
+	earningsEndpoint := NewEarningsEndpoint(reporter)
+	router.GET("/identities/:id/earnings", earningsEndpoint.Earnings)
+}
+
+func earningsSummaryToDto(summary earnings.Summary) earningsSummary {
+	return earningsSummary{
+		DateStart: summary.Start.Format(time.RFC3339),
+		DateEnd:   summary.End.Format(time.RFC3339),
+		Amount:    summary.Amount,
+		Promises:  summary.Promises,
+		Sessions:  summary.Sessions,
+		Consumers: summary.Consumers,
+	}
+}
+
+func writeEarningsCSV(resp http.ResponseWriter, report earningsReport) {
+	writer := csv.NewWriter(resp)
+	writer.Write([]string{"date_start", "date_end", "amount", "promises", "sessions", "consumers"})
+	for _, summary := range report.Earnings {
+		writer.Write([]string{
+			summary.DateStart,
+			summary.DateEnd,
+			strconv.FormatUint(summary.Amount, 10),
+			strconv.Itoa(summary.Promises),
+			strconv.Itoa(summary.Sessions),
+			strconv.Itoa(summary.Consumers),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Error("failed to write earnings CSV: ", err)
+	}
+}
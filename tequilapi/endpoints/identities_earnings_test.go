@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/earnings"
+	"github.com/stretchr/testify/assert"
+)
+
+type earningsReporterMock struct {
+	providerID string
+	period     earnings.Period
+	from, to   time.Time
+	summaries  []earnings.Summary
+}
+
+func (mock *earningsReporterMock) Report(providerID string, period earnings.Period, from, to time.Time) ([]earnings.Summary, error) {
+	mock.providerID, mock.period, mock.from, mock.to = providerID, period, from, to
+	return mock.summaries, nil
+}
+
+func newEarningsReporterMock() *earningsReporterMock {
+	return &earningsReporterMock{
+		summaries: []earnings.Summary{
+			{
+				Start:  time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2019, 10, 2, 0, 0, 0, 0, time.UTC),
+				Amount: 35, Promises: 3, Sessions: 2, Consumers: 2,
+			},
+			{
+				Start:  time.Date(2019, 10, 3, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2019, 10, 4, 0, 0, 0, 0, time.UTC),
+				Amount: 7, Promises: 1, Sessions: 1, Consumers: 1,
+			},
+		},
+	}
+}
+
+func Test_EarningsEndpoint_ReturnsReport(t *testing.T) {
+	reporter := newEarningsReporterMock()
+	router := httprouter.New()
+	AddRoutesForEarnings(router, reporter)
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0xAB/earnings?from=2019-10-01T00:00:00Z", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "0xab", reporter.providerID)
+	assert.Equal(t, earnings.Day, reporter.period)
+	assert.Equal(t, time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), reporter.from)
+	assert.True(t, reporter.to.IsZero())
+	assert.JSONEq(
+		t,
+		`{
+			"period": "day",
+			"earnings": [
+				{"dateStart": "2019-10-01T00:00:00Z", "dateEnd": "2019-10-02T00:00:00Z", "amount": 35, "promises": 3, "sessions": 2, "consumers": 2},
+				{"dateStart": "2019-10-03T00:00:00Z", "dateEnd": "2019-10-04T00:00:00Z", "amount": 7, "promises": 1, "sessions": 1, "consumers": 1}
+			],
+			"total": 42
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_EarningsEndpoint_ExportsCSV(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForEarnings(router, newEarningsReporterMock())
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0xab/earnings?period=week&format=csv", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="earnings-0xab-week.csv"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(
+		t,
+		"date_start,date_end,amount,promises,sessions,consumers\n"+
+			"2019-10-01T00:00:00Z,2019-10-02T00:00:00Z,35,3,2,2\n"+
+			"2019-10-03T00:00:00Z,2019-10-04T00:00:00Z,7,1,1,1\n",
+		resp.Body.String(),
+	)
+}
+
+func Test_EarningsEndpoint_ValidatesParameters(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForEarnings(router, newEarningsReporterMock())
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0xab/earnings?period=year&format=xml", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"period": [ {"code": "invalid", "message": "unknown period, should be one of: day, week, month"} ],
+				"format": [ {"code": "invalid", "message": "Format should be json or csv"} ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
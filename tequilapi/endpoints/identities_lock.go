@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+	"github.com/pkg/errors"
+)
+
+// ErrIdentityInUse is returned when the identity is locked while running services or connections sign with it
+var ErrIdentityInUse = errors.New("identity is used by running services or connections, stop them first")
+
+// identityUser tells if something running at the moment signs with the identity
+type identityUser interface {
+	InUse(id identity.Identity) bool
+}
+
+// swagger:model IdentityPassphraseChangeDTO
+type identityPassphraseChangeDto struct {
+	// current passphrase of the identity
+	// required: true
+	Passphrase *string `json:"passphrase"`
+
+	// passphrase the identity is re-encrypted with
+	// required: true
+	NewPassphrase *string `json:"new_passphrase"`
+}
+
+type identitiesLockAPI struct {
+	locker identity.Locker
+	users  []identityUser
+}
+
+// NewIdentitiesLockEndpoint creates identity locking and passphrase change api controller used by tequilapi service,
+// identities used by any of the given users are refused to be locked
+func NewIdentitiesLockEndpoint(locker identity.Locker, users ...identityUser) *identitiesLockAPI {
+	return &identitiesLockAPI{
+		locker: locker,
+		users:  users,
+	}
+}
+
+// swagger:operation PUT /identities/{id}/lock Identity lockIdentity
+// ---
+// summary: Locks identity
+// description: Forgets the decrypted key of the identity, it has to be unlocked before signing again
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Identity locked
+//   409:
+//     description: Identity is used by running services or connections
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesLockAPI) Lock(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := identity.FromAddress(params.ByName("id"))
+	for _, user := range endpoint.users {
+		if user.InUse(id) {
+			utils.SendError(resp, ErrIdentityInUse, http.StatusConflict)
+			return
+		}
+	}
+
+	if err := endpoint.locker.Lock(id.Address); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:operation PUT /identities/{id}/passphrase Identity changeIdentityPassphrase
+// ---
+// summary: Changes identity passphrase
+// description: Re-encrypts the key of the identity with the new passphrase, unlocked identity stays unlocked
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   schema:
+//     $ref: "#/definitions/IdentityPassphraseChangeDTO"
+// responses:
+//   202:
+//     description: Passphrase changed
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Invalid passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   501:
+//     description: Passphrase is kept by the external signer
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesLockAPI) ChangePassphrase(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	req := &identityPassphraseChangeDto{}
+	if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	errorMap := validatePassphraseChangeRequest(req)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	err := endpoint.locker.ChangePassphrase(params.ByName("id"), *req.Passphrase, *req.NewPassphrase)
+	switch errors.Cause(err) {
+	case nil:
+		resp.WriteHeader(http.StatusAccepted)
+	case identity.ErrInvalidPassphrase:
+		utils.SendError(resp, err, http.StatusForbidden)
+	case identity.ErrPassphraseChangeNotSupported:
+		utils.SendError(resp, err, http.StatusNotImplemented)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+func validatePassphraseChangeRequest(req *identityPassphraseChangeDto) *validation.FieldErrorMap {
+	errors := validation.NewErrorMap()
+	if req.Passphrase == nil {
+		errors.ForField("passphrase").AddError("required", "Field is required")
+	}
+	if req.NewPassphrase == nil {
+		errors.ForField("new_passphrase").AddError("required", "Field is required")
+	}
+	return errors
+}
+
+// AddRoutesForIdentitiesLock creates identity locking and passphrase change endpoints on tequilapi service
+func AddRoutesForIdentitiesLock(router *httprouter.Router, locker identity.Locker, users ...identityUser) {
+	lockEnd := NewIdentitiesLockEndpoint(locker, users...)
+	router.PUT("/identities/:id/lock", lockEnd.Lock)
+	router.PUT("/identities/:id/passphrase", lockEnd.ChangePassphrase)
+}
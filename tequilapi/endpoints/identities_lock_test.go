@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type identityLockerMock struct {
+	lastLocked        string
+	lastPassphrase    string
+	lastNewPassphrase string
+	err               error
+}
+
+func (mock *identityLockerMock) Lock(address string) error {
+	mock.lastLocked = address
+	return mock.err
+}
+
+func (mock *identityLockerMock) ChangePassphrase(address, passphrase, newPassphrase string) error {
+	mock.lastPassphrase = passphrase
+	mock.lastNewPassphrase = newPassphrase
+	return mock.err
+}
+
+type identityUserMock struct {
+	used identity.Identity
+}
+
+func (mock *identityUserMock) InUse(id identity.Identity) bool {
+	return mock.used == id
+}
+
+func serveIdentitiesLockRequest(locker identity.Locker, user identityUser, method, path, body string) *httptest.ResponseRecorder {
+	router := httprouter.New()
+	AddRoutesForIdentitiesLock(router, locker, user)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return resp
+}
+
+func TestIdentitiesLock(t *testing.T) {
+	locker := &identityLockerMock{}
+	user := &identityUserMock{}
+
+	resp := serveIdentitiesLockRequest(locker, user, http.MethodPut, "/identities/0x1/lock", "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "0x1", locker.lastLocked)
+
+	locker.lastLocked = ""
+	user.used = identity.FromAddress("0x1")
+	resp = serveIdentitiesLockRequest(locker, user, http.MethodPut, "/identities/0x1/lock", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"message": "identity is used by running services or connections, stop them first"}`, resp.Body.String())
+	assert.Empty(t, locker.lastLocked)
+
+	user.used = identity.Identity{}
+	locker.err = errors.New("identity not found: 0x1")
+	resp = serveIdentitiesLockRequest(locker, user, http.MethodPut, "/identities/0x1/lock", "")
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestIdentitiesChangePassphrase(t *testing.T) {
+	locker := &identityLockerMock{}
+
+	resp := serveIdentitiesLockRequest(locker, &identityUserMock{}, http.MethodPut, "/identities/0x1/passphrase", `{"passphrase": "old", "new_passphrase": "new"}`)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "old", locker.lastPassphrase)
+	assert.Equal(t, "new", locker.lastNewPassphrase)
+
+	resp = serveIdentitiesLockRequest(locker, &identityUserMock{}, http.MethodPut, "/identities/0x1/passphrase", `{"passphrase": "old"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(t, `{
+		"message": "validation_error",
+		"errors": {"new_passphrase": [{"code": "required", "message": "Field is required"}]}
+	}`, resp.Body.String())
+
+	locker.err = identity.ErrInvalidPassphrase
+	resp = serveIdentitiesLockRequest(locker, &identityUserMock{}, http.MethodPut, "/identities/0x1/passphrase", `{"passphrase": "wrong", "new_passphrase": "new"}`)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+
+	locker.err = errors.Wrap(identity.ErrPassphraseChangeNotSupported, "keystore failed to change passphrase of identity: 0x1")
+	resp = serveIdentitiesLockRequest(locker, &identityUserMock{}, http.MethodPut, "/identities/0x1/passphrase", `{"passphrase": "old", "new_passphrase": "new"}`)
+	assert.Equal(t, http.StatusNotImplemented, resp.Code)
+}
@@ -46,7 +46,7 @@ func NewNATEndpoint(stateProvider stateProvider) *NATEndpoint {
 // description: NAT status returns the last known NAT traversal status
 // responses:
 //   200:
-//     description: NAT status ("not_finished"/"successful"/"failed") and optionally error if status is "failed"
+//     description: NAT status ("not_finished"/"successful"/"failed"), optionally error if status is "failed", the stage which succeeded (e.g. "port_mapping_upnp"/"port_mapping_natpmp"/"port_mapping_pcp") and the detected NAT type
 //     schema:
 //       "$ref": "#/definitions/NATStatusDTO"
 func (ne *NATEndpoint) NATStatus(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
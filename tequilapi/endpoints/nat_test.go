@@ -41,6 +41,8 @@ func Test_NATStatus_ReturnsStatusSuccessful_WithSuccessfulEvent(t *testing.T) {
 		NATStatus: stateEvent.NATStatus{
 			Status: "something",
 			Error:  "maybe",
+			Stage:  "port_mapping_upnp",
+			Type:   "full_cone",
 		},
 	}}
 
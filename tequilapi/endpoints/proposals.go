@@ -23,9 +23,11 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/discovery/query"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
 
 // swagger:model ProposalsList
@@ -157,6 +159,11 @@ func NewProposalsEndpoint(proposalProvider ProposalFinder, qualityProvider Quali
 //     description: the access policy source to filter the proposals by
 //     type: string
 //   - in: query
+//     name: where
+//     description: query expression to filter, sort and limit the proposals by, e.g. "country in (DE, NL) and price < 0.1 order by quality desc limit 10".
+//       Fields are providerId, serviceType, country, city, continent, isp, asn, nodeType and price, quality and connects metrics can be sorted by too.
+//     type: string
+//   - in: query
 //     name: fetchConnectCounts
 //     description: if set to true, fetches the connection success metrics for nodes. False by default.
 //     type: boolean
@@ -165,6 +172,10 @@ func NewProposalsEndpoint(proposalProvider ProposalFinder, qualityProvider Quali
 //     description: List of proposals
 //     schema:
 //       "$ref": "#/definitions/ProposalsList"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -172,17 +183,39 @@ func NewProposalsEndpoint(proposalProvider ProposalFinder, qualityProvider Quali
 func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
 	fetchConnectCounts := req.URL.Query().Get("fetchConnectCounts")
 
-	proposals, err := pe.proposalProvider.FindProposals(&proposalsFilter{
+	filter := &proposalsFilter{
 		providerID:         req.URL.Query().Get("providerId"),
 		serviceType:        req.URL.Query().Get("serviceType"),
 		accessPolicyID:     req.URL.Query().Get("accessPolicyId"),
 		accessPolicySource: req.URL.Query().Get("accessPolicySource"),
-	})
+	}
+
+	var q *query.Query
+	if where := req.URL.Query().Get("where"); where != "" {
+		var err error
+		if q, err = query.Parse(where); err != nil {
+			errorMap := validation.NewErrorMap()
+			errorMap.ForField("where").AddError("invalid", err.Error())
+			utils.SendValidationErrorMessage(resp, errorMap)
+			return
+		}
+		filter.where = q.Match
+	}
+
+	proposals, err := pe.proposalProvider.FindProposals(filter)
 	if err != nil {
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
 
+	if q != nil {
+		var quality query.QualityLookup
+		if q.NeedsQuality() {
+			quality = qualityLookup(pe.qualityProvider)
+		}
+		proposals = q.SortAndLimit(proposals, quality)
+	}
+
 	addMetricsToRes := noMetrics
 	if fetchConnectCounts == "true" {
 		addMetricsToRes = addMetrics(pe.qualityProvider)
@@ -200,6 +233,30 @@ func AddRoutesForProposals(router *httprouter.Router, proposalProvider ProposalF
 
 func noMetrics(p proposalRes) proposalRes { return p }
 
+// qualityLookup returns connection counts of the proposals reported by the quality oracle
+func qualityLookup(mc QualityFinder) query.QualityLookup {
+	receivedMetrics := mc.ProposalsMetrics()
+	connectCounts := make(map[string]query.ConnectCount, len(receivedMetrics))
+
+	for _, m := range receivedMetrics {
+		var metrics struct {
+			ProposalID   proposalRes
+			ConnectCount query.ConnectCount `json:"connectCount"`
+		}
+		if err := json.Unmarshal(m, &metrics); err != nil {
+			log.Warn("failed to parse proposal metrics: ", err)
+			continue
+		}
+		p := metrics.ProposalID
+		connectCounts[p.ProviderID+"-"+p.ServiceType] = metrics.ConnectCount
+	}
+
+	return func(p market.ServiceProposal) (query.ConnectCount, bool) {
+		count, ok := connectCounts[p.ProviderID+"-"+p.ServiceType]
+		return count, ok
+	}
+}
+
 func addMetrics(mc QualityFinder) func(p proposalRes) proposalRes {
 	receivedMetrics := mc.ProposalsMetrics()
 	proposalsMetrics := make(map[string]json.RawMessage, len(receivedMetrics))
@@ -30,6 +30,7 @@ type proposalsFilter struct {
 	locationType       string
 	accessPolicyID     string
 	accessPolicySource string
+	where              func(market.ServiceProposal) bool
 }
 
 // Matches return flag if filter matches given proposal
@@ -47,6 +48,9 @@ func (filter *proposalsFilter) Matches(proposal market.ServiceProposal) bool {
 	if filter.accessPolicyID != "" || filter.accessPolicySource != "" {
 		conditions = append(conditions, reducer.AccessPolicy(filter.accessPolicyID, filter.accessPolicySource))
 	}
+	if filter.where != nil {
+		conditions = append(conditions, filter.where)
+	}
 	if len(conditions) > 0 {
 		return reducer.And(conditions...)(proposal)
 	}
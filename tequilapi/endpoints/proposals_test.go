@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/mysteriumnetwork/node/core/discovery"
@@ -250,6 +251,71 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointListWhere(t *testing.T) {
+	proposalProvider := &mockProposalProvider{
+		proposals: []market.ServiceProposal{serviceProposals[1], serviceProposals[0]},
+	}
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?where="+url.QueryEscape("country = Lithuania order by quality desc limit 1"),
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(proposalProvider, &mockQualityProvider{}).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"proposals": [
+				{
+					"id": 1,
+					"providerId": "0xProviderId",
+					"serviceType": "testprotocol",
+					"serviceDefinition": {
+						"locationOriginate": {
+							"asn": 123,
+							"country": "Lithuania",
+							"city": "Vilnius"
+						}
+					}
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+	assert.True(t, proposalProvider.recordedFilter.Matches(serviceProposals[0]))
+	assert.False(t, proposalProvider.recordedFilter.Matches(market.ServiceProposal{ServiceDefinition: mockService{}}))
+}
+
+func TestProposalsEndpointListWhereInvalid(t *testing.T) {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?where="+url.QueryEscape("country < DE"),
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(&mockProposalProvider{}, &mockQualityProvider{}).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"where": [{"code": "invalid", "message": "operator \"<\" at 8 can't compare text field \"country\""}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
 type mockQualityProvider struct{}
 
 // ProposalsMetrics returns a list of proposals connection metrics
@@ -47,8 +47,9 @@ type serviceRequest struct {
 	Type string `json:"type"`
 
 	// service options. Every service has a unique list of allowed options.
+	// Bandwidth limits "sessionRateLimit" and "serviceRateLimit" in bits per second are allowed for openvpn and wireguard services.
 	// required: false
-	// example: {"port": 1123, "protocol": "udp"}
+	// example: {"port": 1123, "protocol": "udp", "sessionRateLimit": 10000000}
 	Options interface{} `json:"options"`
 
 	// access list which determines which identities will be able to receive the service
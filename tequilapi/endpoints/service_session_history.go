@@ -0,0 +1,232 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 500
+)
+
+// serviceSessionHistoryList defines a page of provider session history representable as json
+// swagger:model ServiceSessionHistoryListDTO
+type serviceSessionHistoryList struct {
+	Sessions []serviceSessionHistory `json:"sessions"`
+
+	// example: 1
+	Page int `json:"page"`
+
+	// example: 50
+	PageSize int `json:"pageSize"`
+
+	// count of all sessions matching the filters
+	// example: 120
+	TotalCount int `json:"totalCount"`
+}
+
+// serviceSessionHistory represents the history record of a session served by the provider
+// swagger:model ServiceSessionHistoryDTO
+type serviceSessionHistory struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"sessionId"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumerId"`
+
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	ServiceID string `json:"serviceId"`
+
+	// example: 2019-10-01T16:22:05Z
+	DateStarted string `json:"dateStarted"`
+
+	// empty while the session is ongoing
+	// example: 2019-10-01T16:24:05Z
+	DateEnded string `json:"dateEnded,omitempty"`
+
+	// example: 12345
+	BytesOut int64 `json:"bytesOut"`
+
+	// example: 23451
+	BytesIn int64 `json:"bytesIn"`
+
+	// amount promised by the consumer during the session
+	// example: 100
+	Earned uint64 `json:"earned"`
+}
+
+type serviceSessionHistoryStorage interface {
+	Query(query session.HistoryQuery) ([]session.History, int, error)
+}
+
+type serviceSessionHistoryEndpoint struct {
+	storage serviceSessionHistoryStorage
+}
+
+// NewServiceSessionHistoryEndpoint creates and returns provider session history endpoint
+func NewServiceSessionHistoryEndpoint(storage serviceSessionHistoryStorage) *serviceSessionHistoryEndpoint {
+	return &serviceSessionHistoryEndpoint{
+		storage: storage,
+	}
+}
+
+// swagger:operation GET /service-sessions/history Service serviceSessionHistory
+// ---
+// summary: Returns history of served sessions
+// description: Returns a page of sessions served by the provider, newest first
+// parameters:
+//   - in: query
+//     name: consumerId
+//     description: consumer identity to filter the sessions by
+//     type: string
+//   - in: query
+//     name: serviceId
+//     description: service to filter the sessions by
+//     type: string
+//   - in: query
+//     name: from
+//     description: RFC3339 time the sessions are started at or after
+//     type: string
+//   - in: query
+//     name: to
+//     description: RFC3339 time the sessions are started before
+//     type: string
+//   - in: query
+//     name: page
+//     description: page number, starting from 1
+//     type: integer
+//   - in: query
+//     name: pageSize
+//     description: count of sessions in a page, 50 by default and 500 at most
+//     type: integer
+// responses:
+//   200:
+//     description: Page of sessions
+//     schema:
+//       "$ref": "#/definitions/ServiceSessionHistoryListDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *serviceSessionHistoryEndpoint) List(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	query, page, pageSize, errorMap := toHistoryQuery(request.URL.Query())
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	sessions, total, err := endpoint.storage.Query(query)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	list := serviceSessionHistoryList{
+		Sessions:   make([]serviceSessionHistory, len(sessions)),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: total,
+	}
+	for i := range sessions {
+		list.Sessions[i] = serviceSessionHistoryToDto(sessions[i])
+	}
+	utils.WriteAsJSON(list, resp)
+}
+
+// AddRoutesForServiceSessionHistory attaches provider session history endpoints to router
+func AddRoutesForServiceSessionHistory(router *httprouter.Router, storage serviceSessionHistoryStorage) {
+	historyEndpoint := NewServiceSessionHistoryEndpoint(storage)
+	router.GET("/service-sessions/history", historyEndpoint.List)
+}
+
+func toHistoryQuery(values url.Values) (query session.HistoryQuery, page, pageSize int, errs *validation.FieldErrorMap) {
+	errs = validation.NewErrorMap()
+	query.ConsumerID = values.Get("consumerId")
+	query.ServiceID = values.Get("serviceId")
+
+	query.StartedFrom = parseTimeParam(values, "from", errs)
+	query.StartedTo = parseTimeParam(values, "to", errs)
+	page = parseIntParam(values, "page", 1, errs)
+	pageSize = parseIntParam(values, "pageSize", defaultHistoryPageSize, errs)
+
+	if page < 1 {
+		errs.ForField("page").AddError("invalid", "Page should be positive")
+	}
+	if pageSize < 1 || pageSize > maxHistoryPageSize {
+		errs.ForField("pageSize").AddError("invalid", "Page size should be between 1 and "+strconv.Itoa(maxHistoryPageSize))
+	}
+
+	query.Offset = (page - 1) * pageSize
+	query.Limit = pageSize
+	return query, page, pageSize, errs
+}
+
+func parseTimeParam(values url.Values, name string, errs *validation.FieldErrorMap) time.Time {
+	value := values.Get(name)
+	if value == "" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		errs.ForField(name).AddError("invalid", "Time should be in RFC3339 format")
+	}
+	return parsed
+}
+
+func parseIntParam(values url.Values, name string, defaultValue int, errs *validation.FieldErrorMap) int {
+	value := values.Get(name)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		errs.ForField(name).AddError("invalid", "Value should be an integer")
+		return defaultValue
+	}
+	return parsed
+}
+
+func serviceSessionHistoryToDto(history session.History) serviceSessionHistory {
+	dto := serviceSessionHistory{
+		SessionID:   string(history.ID),
+		ConsumerID:  history.ConsumerID,
+		ServiceID:   history.ServiceID,
+		DateStarted: history.Started.Format(time.RFC3339),
+		BytesOut:    history.DataTransfered.Up,
+		BytesIn:     history.DataTransfered.Down,
+		Earned:      history.Earned,
+	}
+	if !history.Ended.IsZero() {
+		dto.DateEnded = history.Ended.Format(time.RFC3339)
+	}
+	return dto
+}
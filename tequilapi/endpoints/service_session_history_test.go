@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+type serviceSessionHistoryMock struct {
+	requestedQuery session.HistoryQuery
+	sessions       []session.History
+	total          int
+}
+
+func (mock *serviceSessionHistoryMock) Query(query session.HistoryQuery) ([]session.History, int, error) {
+	mock.requestedQuery = query
+	return mock.sessions, mock.total, nil
+}
+
+func Test_ServiceSessionHistoryEndpoint_List(t *testing.T) {
+	started := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	storage := &serviceSessionHistoryMock{
+		sessions: []session.History{
+			{
+				ID:             "session-1",
+				ConsumerID:     "0x1",
+				ServiceID:      "service-1",
+				Started:        started,
+				Ended:          started.Add(time.Minute),
+				DataTransfered: session.DataTransfered{Up: 10, Down: 20},
+				Earned:         5,
+			},
+		},
+		total: 3,
+	}
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		"/service-sessions/history?consumerId=0x1&from=2019-10-01T00:00:00Z&page=2&pageSize=2",
+		nil,
+	)
+	resp := httptest.NewRecorder()
+
+	NewServiceSessionHistoryEndpoint(storage).List(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(
+		t,
+		session.HistoryQuery{
+			ConsumerID:  "0x1",
+			StartedFrom: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC),
+			Offset:      2,
+			Limit:       2,
+		},
+		storage.requestedQuery,
+	)
+	assert.JSONEq(
+		t,
+		`{
+			"sessions": [
+				{
+					"sessionId": "session-1",
+					"consumerId": "0x1",
+					"serviceId": "service-1",
+					"dateStarted": "2019-10-01T12:00:00Z",
+					"dateEnded": "2019-10-01T12:01:00Z",
+					"bytesOut": 10,
+					"bytesIn": 20,
+					"earned": 5
+				}
+			],
+			"page": 2,
+			"pageSize": 2,
+			"totalCount": 3
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_ServiceSessionHistoryEndpoint_ValidatesParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/service-sessions/history?to=yesterday&pageSize=1000", nil)
+	resp := httptest.NewRecorder()
+
+	NewServiceSessionHistoryEndpoint(&serviceSessionHistoryMock{}).List(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"to": [{"code": "invalid", "message": "Time should be in RFC3339 format"}],
+				"pageSize": [{"code": "invalid", "message": "Page size should be between 1 and 500"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/webhook"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// swagger:model WebhookRequestDTO
+type webhookRequest struct {
+	// URL notifications are posted to
+	// required: true
+	// example: https://example.com/mysterium
+	URL string `json:"url"`
+
+	// key of HMAC-SHA256 signature sent in X-Mysterium-Signature header, generated when empty
+	// required: false
+	Secret string `json:"secret,omitempty"`
+
+	// topics the webhook is notified about, all topics when empty.
+	// Possible values are "service-status", "session", "low-balance", "nat", "proposal" and "connection-session"
+	// required: false
+	// example: ["service-status", "nat"]
+	Topics []string `json:"topics,omitempty"`
+}
+
+// swagger:model WebhookDTO
+type webhookResponse struct {
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	ID string `json:"id"`
+
+	// example: https://example.com/mysterium
+	URL string `json:"url"`
+
+	// present only in the response of webhook creation
+	Secret string `json:"secret,omitempty"`
+
+	// example: ["service-status", "nat"]
+	Topics []string `json:"topics"`
+
+	// example: 2019-10-01T16:22:05Z
+	CreatedAt string `json:"createdAt"`
+}
+
+// swagger:model WebhookListDTO
+type webhookListResponse struct {
+	Webhooks []webhookResponse `json:"webhooks"`
+}
+
+type webhooksEndpoint struct {
+	storage webhook.Storage
+}
+
+// NewWebhooksEndpoint creates and returns webhooks endpoint
+func NewWebhooksEndpoint(storage webhook.Storage) *webhooksEndpoint {
+	return &webhooksEndpoint{storage: storage}
+}
+
+// swagger:operation GET /webhooks Webhook listWebhooks
+// ---
+// summary: Returns webhooks
+// description: Returns all webhooks notified about the node events
+// responses:
+//   200:
+//     description: List of webhooks
+//     schema:
+//       "$ref": "#/definitions/WebhookListDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *webhooksEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	hooks, err := endpoint.storage.List()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	list := webhookListResponse{Webhooks: make([]webhookResponse, len(hooks))}
+	for i := range hooks {
+		list.Webhooks[i] = webhookToResponse(hooks[i], false)
+	}
+	utils.WriteAsJSON(list, resp)
+}
+
+// swagger:operation GET /webhooks/{id} Webhook getWebhook
+// ---
+// summary: Returns webhook
+// description: Returns the webhook with the given id
+// parameters:
+//   - in: path
+//     name: id
+//     description: webhook id
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Webhook
+//     schema:
+//       "$ref": "#/definitions/WebhookDTO"
+//   404:
+//     description: Webhook not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *webhooksEndpoint) Get(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	hook, err := endpoint.storage.Get(params.ByName("id"))
+	if err != nil {
+		sendWebhookError(resp, err)
+		return
+	}
+	utils.WriteAsJSON(webhookToResponse(hook, false), resp)
+}
+
+// swagger:operation POST /webhooks Webhook createWebhook
+// ---
+// summary: Creates webhook
+// description: Creates the webhook, signed JSON notifications are posted to its URL and retried with backoff on failures
+// parameters:
+//   - in: body
+//     name: body
+//     description: URL and topics of the webhook
+//     schema:
+//       $ref: "#/definitions/WebhookRequestDTO"
+// responses:
+//   201:
+//     description: Webhook created
+//     schema:
+//       "$ref": "#/definitions/WebhookDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *webhooksEndpoint) Create(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	req, ok := parseWebhookRequest(resp, request)
+	if !ok {
+		return
+	}
+
+	hook, err := webhook.New(req.URL, req.Secret, req.Topics)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	if err := endpoint.storage.Save(hook); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	utils.WriteAsJSON(webhookToResponse(hook, true), resp)
+}
+
+// swagger:operation PUT /webhooks/{id} Webhook updateWebhook
+// ---
+// summary: Updates webhook
+// description: Replaces URL and topics of the webhook, the secret is kept when not given
+// parameters:
+//   - in: path
+//     name: id
+//     description: webhook id
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     description: URL and topics of the webhook
+//     schema:
+//       $ref: "#/definitions/WebhookRequestDTO"
+// responses:
+//   200:
+//     description: Webhook updated
+//     schema:
+//       "$ref": "#/definitions/WebhookDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Webhook not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *webhooksEndpoint) Update(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	hook, err := endpoint.storage.Get(params.ByName("id"))
+	if err != nil {
+		sendWebhookError(resp, err)
+		return
+	}
+
+	req, ok := parseWebhookRequest(resp, request)
+	if !ok {
+		return
+	}
+
+	hook.URL = req.URL
+	hook.Topics = req.Topics
+	if req.Secret != "" {
+		hook.Secret = req.Secret
+	}
+	if err := endpoint.storage.Save(hook); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(webhookToResponse(hook, false), resp)
+}
+
+// swagger:operation DELETE /webhooks/{id} Webhook deleteWebhook
+// ---
+// summary: Deletes webhook
+// description: Deletes the webhook with the given id
+// parameters:
+//   - in: path
+//     name: id
+//     description: webhook id
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Webhook deleted
+//   404:
+//     description: Webhook not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *webhooksEndpoint) Delete(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	if err := endpoint.storage.Delete(params.ByName("id")); err != nil {
+		sendWebhookError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// AddRoutesForWebhooks attaches webhook endpoints to router
+func AddRoutesForWebhooks(router *httprouter.Router, storage webhook.Storage) {
+	webhooksEndpoint := NewWebhooksEndpoint(storage)
+	router.GET("/webhooks", webhooksEndpoint.List)
+	router.POST("/webhooks", webhooksEndpoint.Create)
+	router.GET("/webhooks/:id", webhooksEndpoint.Get)
+	router.PUT("/webhooks/:id", webhooksEndpoint.Update)
+	router.DELETE("/webhooks/:id", webhooksEndpoint.Delete)
+}
+
+func parseWebhookRequest(resp http.ResponseWriter, request *http.Request) (*webhookRequest, bool) {
+	req := &webhookRequest{}
+	if err := json.NewDecoder(request.Body).Decode(req); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return nil, false
+	}
+
+	if errorMap := validateWebhookRequest(req); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return nil, false
+	}
+	return req, true
+}
+
+func validateWebhookRequest(req *webhookRequest) *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if req.URL == "" {
+		errs.ForField("url").AddError("required", "Field is required")
+	} else if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.ForField("url").AddError("invalid", "URL should be absolute http or https URL")
+	}
+	for _, topic := range req.Topics {
+		if !webhook.IsTopic(topic) {
+			errs.ForField("topics").AddError("invalid", "Unknown topic "+topic)
+		}
+	}
+	return errs
+}
+
+func sendWebhookError(resp http.ResponseWriter, err error) {
+	if err == webhook.ErrNotFound {
+		utils.SendError(resp, err, http.StatusNotFound)
+		return
+	}
+	utils.SendError(resp, err, http.StatusInternalServerError)
+}
+
+func webhookToResponse(hook webhook.Webhook, withSecret bool) webhookResponse {
+	response := webhookResponse{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Topics:    hook.Topics,
+		CreatedAt: hook.CreatedAt.Format(time.RFC3339),
+	}
+	if response.Topics == nil {
+		response.Topics = []string{}
+	}
+	if withSecret {
+		response.Secret = hook.Secret
+	}
+	return response
+}
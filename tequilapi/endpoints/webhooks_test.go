@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookStorageMock struct {
+	hooks map[string]webhook.Webhook
+}
+
+func (mock *webhookStorageMock) List() ([]webhook.Webhook, error) {
+	hooks := []webhook.Webhook{}
+	for _, hook := range mock.hooks {
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+func (mock *webhookStorageMock) Get(id string) (webhook.Webhook, error) {
+	hook, ok := mock.hooks[id]
+	if !ok {
+		return hook, webhook.ErrNotFound
+	}
+	return hook, nil
+}
+
+func (mock *webhookStorageMock) Save(hook webhook.Webhook) error {
+	mock.hooks[hook.ID] = hook
+	return nil
+}
+
+func (mock *webhookStorageMock) Delete(id string) error {
+	if _, ok := mock.hooks[id]; !ok {
+		return webhook.ErrNotFound
+	}
+	delete(mock.hooks, id)
+	return nil
+}
+
+func newWebhooksRouter(storage webhook.Storage) *httprouter.Router {
+	router := httprouter.New()
+	AddRoutesForWebhooks(router, storage)
+	return router
+}
+
+func serveWebhookRequest(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return resp
+}
+
+func TestWebhooksEndpointCreatesWebhookWithSecret(t *testing.T) {
+	storage := &webhookStorageMock{hooks: map[string]webhook.Webhook{}}
+	router := newWebhooksRouter(storage)
+
+	resp := serveWebhookRequest(router, http.MethodPost, "/webhooks", `{"url": "https://example.com/hook", "topics": ["nat"]}`)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Len(t, storage.hooks, 1)
+	for _, hook := range storage.hooks {
+		assert.Equal(t, "https://example.com/hook", hook.URL)
+		assert.Equal(t, []string{"nat"}, hook.Topics)
+		assert.NotEmpty(t, hook.Secret)
+		assert.Contains(t, resp.Body.String(), `"secret":"`+hook.Secret+`"`)
+	}
+}
+
+func TestWebhooksEndpointValidatesRequest(t *testing.T) {
+	router := newWebhooksRouter(&webhookStorageMock{hooks: map[string]webhook.Webhook{}})
+
+	resp := serveWebhookRequest(router, http.MethodPost, "/webhooks", `{"url": "example.com", "topics": ["unknown"]}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"url": [{"code": "invalid", "message": "URL should be absolute http or https URL"}],
+				"topics": [{"code": "invalid", "message": "Unknown topic unknown"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestWebhooksEndpointManagesWebhooks(t *testing.T) {
+	storage := &webhookStorageMock{hooks: map[string]webhook.Webhook{
+		"hook-1": {
+			ID:        "hook-1",
+			URL:       "https://example.com/hook",
+			Secret:    "secret",
+			CreatedAt: time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}}
+	router := newWebhooksRouter(storage)
+
+	resp := serveWebhookRequest(router, http.MethodGet, "/webhooks", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{"webhooks": [{"id": "hook-1", "url": "https://example.com/hook", "topics": [], "createdAt": "2019-10-01T12:00:00Z"}]}`,
+		resp.Body.String(),
+	)
+
+	resp = serveWebhookRequest(router, http.MethodPut, "/webhooks/hook-1", `{"url": "http://example.com/other", "topics": ["proposal"]}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{"id": "hook-1", "url": "http://example.com/other", "topics": ["proposal"], "createdAt": "2019-10-01T12:00:00Z"}`,
+		resp.Body.String(),
+	)
+	assert.Equal(t, "secret", storage.hooks["hook-1"].Secret)
+
+	resp = serveWebhookRequest(router, http.MethodDelete, "/webhooks/hook-1", "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = serveWebhookRequest(router, http.MethodGet, "/webhooks/hook-1", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.JSONEq(t, `{"message": "webhook not found"}`, resp.Body.String())
+}
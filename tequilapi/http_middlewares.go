@@ -20,6 +20,10 @@ package tequilapi
 import (
 	"net/http"
 	"strings"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
 type corsHandler struct {
@@ -90,3 +94,57 @@ func DisableCaching(original http.Handler) http.Handler {
 		original,
 	}
 }
+
+type scopeAuthorizer interface {
+	Authorize(req *http.Request, required auth.Scope) error
+	CredentialsRequired() (bool, error)
+}
+
+type scopeHandler struct {
+	originalHandler    http.Handler
+	authorizer         scopeAuthorizer
+	requireCredentials bool
+}
+
+func (wrapper scopeHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	required, protected := RouteScope(req.Method, req.URL.Path)
+	if protected {
+		err := wrapper.authorizer.Authorize(req, required)
+		switch {
+		case err == auth.ErrNoCredentials && !wrapper.credentialsRequired():
+			// local clients without credentials are trusted unless credentials are required
+		case err == auth.ErrForbidden:
+			utils.SendError(resp, err, http.StatusForbidden)
+			return
+		case err != nil:
+			utils.SendError(resp, auth.ErrUnauthorized, http.StatusUnauthorized)
+			return
+		}
+	}
+
+	wrapper.originalHandler.ServeHTTP(resp, req)
+}
+
+// credentialsRequired checks if the request without credentials has to be rejected,
+// it has to once credentials are required by the options or any API token is issued
+func (wrapper scopeHandler) credentialsRequired() bool {
+	if wrapper.requireCredentials {
+		return true
+	}
+	required, err := wrapper.authorizer.CredentialsRequired()
+	if err != nil {
+		log.Warn("failed to check if credentials are required: ", err)
+		return true
+	}
+	return required
+}
+
+// RequireScopes middleware checks if the credentials of the request grant the scope required by the route.
+// Requests without credentials are rejected if requireCredentials is set or any API token is issued.
+func RequireScopes(original http.Handler, authorizer scopeAuthorizer, requireCredentials bool) http.Handler {
+	return scopeHandler{
+		originalHandler:    original,
+		authorizer:         authorizer,
+		requireCredentials: requireCredentials,
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -103,6 +104,106 @@ func TestCacheControlHeadersAreAddedToResponse(t *testing.T) {
 
 }
 
+func TestRouteScope(t *testing.T) {
+	tests := []struct {
+		method    string
+		path      string
+		scope     auth.Scope
+		protected bool
+	}{
+		{http.MethodGet, "/healthcheck", "", false},
+		{http.MethodPost, "/auth/login", "", false},
+		{http.MethodGet, "/auth/tokens", auth.ScopeAdmin, true},
+		{http.MethodGet, "/connection", auth.ScopeStatus, true},
+		{http.MethodPut, "/connection", auth.ScopeConnection, true},
+		{http.MethodDelete, "/connections/vpn", auth.ScopeConnection, true},
+		{http.MethodGet, "/connection-sessions", auth.ScopeStatus, true},
+		{http.MethodPost, "/services", auth.ScopeService, true},
+		{http.MethodGet, "/webhooks", auth.ScopeAdmin, true},
+		{http.MethodPut, "/identities/0x1/payout", auth.ScopeAdmin, true},
+		{http.MethodGet, "/identities/0x1/payout", auth.ScopeStatus, true},
+		{http.MethodPost, "/identities/0x1/export", auth.ScopeAdmin, true},
+		{http.MethodPost, "/identities-import", auth.ScopeAdmin, true},
+		{http.MethodPost, "/stop", auth.ScopeAdmin, true},
+	}
+
+	for _, test := range tests {
+		scope, protected := RouteScope(test.method, test.path)
+		assert.Equal(t, test.scope, scope, test.method+" "+test.path)
+		assert.Equal(t, test.protected, protected, test.method+" "+test.path)
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	tests := []struct {
+		authorizeErr       error
+		requireCredentials bool
+		tokensIssued       bool
+		expectedStatus     int
+	}{
+		{nil, true, false, http.StatusOK},
+		{auth.ErrNoCredentials, false, false, http.StatusOK},
+		{auth.ErrNoCredentials, true, false, http.StatusUnauthorized},
+		{auth.ErrNoCredentials, false, true, http.StatusUnauthorized},
+		{auth.ErrUnauthorized, false, false, http.StatusUnauthorized},
+		{auth.ErrForbidden, false, false, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(http.MethodPut, "/connection", nil)
+		assert.NoError(t, err)
+		respRecorder := httptest.NewRecorder()
+		mock := &mockedHTTPHandler{}
+		authorizer := &scopeAuthorizerMock{err: test.authorizeErr, tokensIssued: test.tokensIssued}
+
+		RequireScopes(mock, authorizer, test.requireCredentials).ServeHTTP(respRecorder, req)
+
+		assert.Equal(t, test.expectedStatus, respRecorder.Code)
+		assert.Equal(t, test.expectedStatus == http.StatusOK, mock.wasCalled)
+		assert.Equal(t, auth.ScopeConnection, authorizer.required)
+	}
+}
+
+func TestRequireScopesRejectsRequestsWithoutCredentials_WhenTokensAreIssued(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/auth/tokens", nil)
+	assert.NoError(t, err)
+	respRecorder := httptest.NewRecorder()
+	mock := &mockedHTTPHandler{}
+	authorizer := &scopeAuthorizerMock{err: auth.ErrNoCredentials, tokensIssued: true}
+
+	RequireScopes(mock, authorizer, false).ServeHTTP(respRecorder, req)
+
+	assert.Equal(t, http.StatusUnauthorized, respRecorder.Code)
+	assert.False(t, mock.wasCalled)
+	assert.Equal(t, auth.ScopeAdmin, authorizer.required)
+}
+
+func TestRequireScopesSkipsPublicRoutes(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/healthcheck", nil)
+	assert.NoError(t, err)
+	respRecorder := httptest.NewRecorder()
+	mock := &mockedHTTPHandler{}
+
+	RequireScopes(mock, &scopeAuthorizerMock{err: auth.ErrUnauthorized}, true).ServeHTTP(respRecorder, req)
+
+	assert.True(t, mock.wasCalled)
+}
+
+type scopeAuthorizerMock struct {
+	err          error
+	tokensIssued bool
+	required     auth.Scope
+}
+
+func (mock *scopeAuthorizerMock) Authorize(req *http.Request, required auth.Scope) error {
+	mock.required = required
+	return mock.err
+}
+
+func (mock *scopeAuthorizerMock) CredentialsRequired() (bool, error) {
+	return mock.tokensIssued, nil
+}
+
 type mockedHTTPHandler struct {
 	wasCalled bool
 }
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/endpoints"
+)
+
+type routeScope struct {
+	prefix string
+	suffix string
+	read   auth.Scope
+	write  auth.Scope
+}
+
+// routeScopes lists the routes which scopes differ from the default ones, the first matching prefix wins
+var routeScopes = []routeScope{
+	{prefix: "/identities", suffix: "/export", read: auth.ScopeAdmin, write: auth.ScopeAdmin},
+	{prefix: "/auth", read: auth.ScopeAdmin, write: auth.ScopeAdmin},
+	{prefix: "/webhooks", read: auth.ScopeAdmin, write: auth.ScopeAdmin},
+	{prefix: "/connection", read: auth.ScopeStatus, write: auth.ScopeConnection},
+	{prefix: "/connections", read: auth.ScopeStatus, write: auth.ScopeConnection},
+	{prefix: "/services", read: auth.ScopeStatus, write: auth.ScopeService},
+}
+
+// publicRoutes can be accessed without any credentials
+var publicRoutes = []string{
+	"/healthcheck",
+	endpoints.TequilapiLoginEndpointPath,
+}
+
+// RouteScope returns the scope required to access the given route, false is returned for public routes.
+// Reading requires the status scope and changing anything requires the admin one, unless the route is listed in routeScopes.
+func RouteScope(method, path string) (auth.Scope, bool) {
+	path = "/" + strings.Trim(path, "/")
+	for _, public := range publicRoutes {
+		if path == public {
+			return "", false
+		}
+	}
+
+	read := isReadMethod(method)
+	for _, route := range routeScopes {
+		if !strings.HasSuffix(path, route.suffix) {
+			continue
+		}
+		if path == route.prefix || strings.HasPrefix(path, route.prefix+"/") {
+			if read {
+				return route.read, true
+			}
+			return route.write, true
+		}
+	}
+
+	if read {
+		return auth.ScopeStatus, true
+	}
+	return auth.ScopeAdmin, true
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -21,12 +21,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	log "github.com/cihub/seelog"
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/core/connection"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 	"github.com/pkg/errors"
 )
 
@@ -48,36 +55,117 @@ const (
 	ServiceStatusEvent EventType = "service-status"
 	// StateChangeEvent represents the state change
 	StateChangeEvent EventType = "state-change"
+	// ProposalAddedEvent represents a proposal appearing in discovery
+	ProposalAddedEvent EventType = "proposal-added"
+	// ProposalRemovedEvent represents a proposal disappearing from discovery
+	ProposalRemovedEvent EventType = "proposal-removed"
+	// ConnectionStateEvent represents the consumer connection state change
+	ConnectionStateEvent EventType = "connection-state"
+	// SessionEvent represents the consumer session creation and end
+	SessionEvent EventType = "session"
+	// ConnectionStatisticsEvent represents the consumer connection statistics update
+	ConnectionStatisticsEvent EventType = "connection-statistics"
 )
 
+var eventTypes = []EventType{
+	NATEvent,
+	ServiceStatusEvent,
+	StateChangeEvent,
+	ProposalAddedEvent,
+	ProposalRemovedEvent,
+	ConnectionStateEvent,
+	SessionEvent,
+	ConnectionStatisticsEvent,
+}
+
+// DefaultStatisticsInterval is the least time between two connection statistics events
+const DefaultStatisticsInterval = time.Second
+
+// clientQueueSize is the number of messages queued for the client,
+// clients falling behind it are disconnected so they don't hold the events of the others
+const clientQueueSize = 100
+
 // Handler represents an sse handler
 type Handler struct {
-	clients       map[chan string]struct{}
-	newClients    chan chan string
-	deadClients   chan chan string
-	messages      chan string
+	clients       map[*client]struct{}
+	newClients    chan *client
+	deadClients   chan *client
+	messages      chan message
 	stopOnce      sync.Once
 	stopChan      chan struct{}
 	stateProvider stateProvider
+	speedProvider speedProvider
+
+	statisticsInterval time.Duration
+	statisticsLock     sync.Mutex
+	statisticsSent     time.Time
+	sessionStarted     time.Time
+	now                func() time.Time
 }
 
 type stateProvider interface {
 	GetState() stateEvent.State
 }
 
+type speedProvider interface {
+	Get() bandwidth.CurrentSpeed
+}
+
+// client is a single subscriber of the chosen event types, all of them if none were chosen
+type client struct {
+	messages chan string
+	events   map[EventType]bool
+}
+
+func (c *client) wants(eventType EventType) bool {
+	return len(c.events) == 0 || c.events[eventType]
+}
+
+type message struct {
+	eventType EventType
+	data      string
+}
+
+type connectionStatePayload struct {
+	State       connection.State `json:"state"`
+	SessionID   string           `json:"sessionId,omitempty"`
+	ProviderID  string           `json:"providerId,omitempty"`
+	ServiceType string           `json:"serviceType,omitempty"`
+}
+
+type sessionPayload struct {
+	Status      string `json:"status"`
+	SessionID   string `json:"sessionId"`
+	ProviderID  string `json:"providerId"`
+	ServiceType string `json:"serviceType"`
+}
+
+type connectionStatisticsPayload struct {
+	BytesSent     uint64 `json:"bytesSent"`
+	BytesReceived uint64 `json:"bytesReceived"`
+	// seconds
+	Duration int `json:"duration"`
+	// bits per second
+	UploadSpeed   float64 `json:"uploadSpeed"`
+	DownloadSpeed float64 `json:"downloadSpeed"`
+}
+
 // NewHandler returns a new instance of handler
-func NewHandler(stateProvider stateProvider) *Handler {
+func NewHandler(stateProvider stateProvider, speedProvider speedProvider, statisticsInterval time.Duration) *Handler {
 	return &Handler{
-		clients:       make(map[chan string]struct{}),
-		newClients:    make(chan (chan string)),
-		deadClients:   make(chan (chan string)),
-		messages:      make(chan string, 20),
-		stopChan:      make(chan struct{}),
-		stateProvider: stateProvider,
+		clients:            make(map[*client]struct{}),
+		newClients:         make(chan *client),
+		deadClients:        make(chan *client),
+		messages:           make(chan message, 20),
+		stopChan:           make(chan struct{}),
+		stateProvider:      stateProvider,
+		speedProvider:      speedProvider,
+		statisticsInterval: statisticsInterval,
+		now:                time.Now,
 	}
 }
 
-// Sub subscribes a user to sse
+// Sub subscribes a user to sse, the comma separated event types can be chosen with the `events` query parameter
 func (h *Handler) Sub(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
 	f, ok := resp.(http.Flusher)
 	if !ok {
@@ -90,12 +178,22 @@ func (h *Handler) Sub(resp http.ResponseWriter, req *http.Request, params httpro
 		return
 	}
 
+	events, err := parseEventTypes(req.URL.Query().Get("events"))
+	if err != nil {
+		errorMap := validation.NewErrorMap()
+		errorMap.ForField("events").AddError("invalid", err.Error())
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
 	resp.Header().Set("Content-Type", "text/event-stream")
 	resp.Header().Set("Cache-Control", "no-cache")
 	resp.Header().Set("Connection", "keep-alive")
 
-	messageChan := make(chan string, 1)
-	err := h.sendInitialState(messageChan)
+	c := &client{messages: make(chan string, clientQueueSize), events: events}
+	if c.wants(StateChangeEvent) {
+		err = h.sendInitialState(c.messages)
+	}
 	if err != nil {
 		resp.WriteHeader(http.StatusBadRequest)
 		resp.Header().Set("Content-type", "application/json; charset=utf-8")
@@ -105,16 +203,19 @@ func (h *Handler) Sub(resp http.ResponseWriter, req *http.Request, params httpro
 		}
 	}
 
-	h.newClients <- messageChan
+	h.newClients <- c
 
 	go func() {
 		<-req.Context().Done()
-		h.deadClients <- messageChan
+		select {
+		case h.deadClients <- c:
+		case <-h.stopChan:
+		}
 	}()
 
 	for {
 		select {
-		case msg, open := <-messageChan:
+		case msg, open := <-c.messages:
 			if !open {
 				return
 			}
@@ -132,6 +233,31 @@ func (h *Handler) Sub(resp http.ResponseWriter, req *http.Request, params httpro
 	}
 }
 
+// parseEventTypes parses the comma separated event types, empty list subscribes to every event type
+func parseEventTypes(list string) (map[EventType]bool, error) {
+	events := make(map[EventType]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !isEventType(EventType(name)) {
+			return nil, errors.Errorf("unknown event type %q", name)
+		}
+		events[EventType(name)] = true
+	}
+	return events, nil
+}
+
+func isEventType(eventType EventType) bool {
+	for _, known := range eventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *Handler) sendInitialState(messageChan chan string) error {
 	res, err := json.Marshal(Event{
 		Type:    StateChangeEvent,
@@ -147,8 +273,8 @@ func (h *Handler) sendInitialState(messageChan chan string) error {
 
 func (h *Handler) serve() {
 	defer func() {
-		for k := range h.clients {
-			close(k)
+		for c := range h.clients {
+			close(c.messages)
 		}
 	}()
 
@@ -156,19 +282,35 @@ func (h *Handler) serve() {
 		select {
 		case <-h.stopChan:
 			return
-		case s := <-h.newClients:
-			h.clients[s] = struct{}{}
-		case s := <-h.deadClients:
-			delete(h.clients, s)
-			close(s)
+		case c := <-h.newClients:
+			h.clients[c] = struct{}{}
+		case c := <-h.deadClients:
+			h.removeClient(c)
 		case msg := <-h.messages:
-			for s := range h.clients {
-				s <- msg
+			for c := range h.clients {
+				if !c.wants(msg.eventType) {
+					continue
+				}
+				select {
+				case c.messages <- msg.data:
+				default:
+					log.Warn(logPrefix, "disconnecting client not keeping up with the events")
+					h.removeClient(c)
+				}
 			}
 		}
 	}
 }
 
+// removeClient closes the messages of the client, it can be removed both as slow and as disconnected one
+func (h *Handler) removeClient(c *client) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	close(c.messages)
+}
+
 func (h *Handler) stop() {
 	h.stopOnce.Do(func() { close(h.stopChan) })
 }
@@ -179,7 +321,7 @@ func (h *Handler) send(e Event) {
 		log.Error(logPrefix, "could not marshal sse message", err)
 		return
 	}
-	h.messages <- string(marshaled)
+	h.messages <- message{eventType: e.Type, data: string(marshaled)}
 }
 
 // ConsumeNodeEvent consumes the node state event
@@ -201,3 +343,95 @@ func (h *Handler) ConsumeStateEvent(event stateEvent.State) {
 		Payload: event,
 	})
 }
+
+// ConsumeProposalAdded consumes the proposal added event
+func (h *Handler) ConsumeProposalAdded(proposal market.ServiceProposal) {
+	h.send(Event{
+		Type:    ProposalAddedEvent,
+		Payload: proposal,
+	})
+}
+
+// ConsumeProposalRemoved consumes the proposal removed event
+func (h *Handler) ConsumeProposalRemoved(proposal market.ServiceProposal) {
+	h.send(Event{
+		Type:    ProposalRemovedEvent,
+		Payload: proposal,
+	})
+}
+
+// ConsumeConnectionStateEvent consumes the state change of the default consumer connection
+func (h *Handler) ConsumeConnectionStateEvent(event connection.StateEvent) {
+	if event.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	h.send(Event{
+		Type: ConnectionStateEvent,
+		Payload: connectionStatePayload{
+			State:       event.State,
+			SessionID:   string(event.SessionInfo.SessionID),
+			ProviderID:  event.SessionInfo.Proposal.ProviderID,
+			ServiceType: event.SessionInfo.Proposal.ServiceType,
+		},
+	})
+}
+
+// ConsumeSessionEvent consumes the session event of the default consumer connection
+func (h *Handler) ConsumeSessionEvent(event connection.SessionEvent) {
+	if event.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	h.statisticsLock.Lock()
+	switch event.Status {
+	case connection.SessionCreatedStatus:
+		h.sessionStarted = h.now()
+	case connection.SessionEndedStatus:
+		h.sessionStarted = time.Time{}
+	}
+	h.statisticsSent = time.Time{}
+	h.statisticsLock.Unlock()
+
+	h.send(Event{
+		Type: SessionEvent,
+		Payload: sessionPayload{
+			Status:      event.Status,
+			SessionID:   string(event.SessionInfo.SessionID),
+			ProviderID:  event.SessionInfo.Proposal.ProviderID,
+			ServiceType: event.SessionInfo.Proposal.ServiceType,
+		},
+	})
+}
+
+// ConsumeStatisticsEvent consumes the statistics of the default consumer connection,
+// sending them not more often than the statistics interval
+func (h *Handler) ConsumeStatisticsEvent(event connection.StatisticsEvent) {
+	if event.ConnectionID != connection.DefaultConnectionID {
+		return
+	}
+	stats := event.Stats
+	h.statisticsLock.Lock()
+	now := h.now()
+	if now.Sub(h.statisticsSent) < h.statisticsInterval {
+		h.statisticsLock.Unlock()
+		return
+	}
+	h.statisticsSent = now
+
+	var duration time.Duration
+	if !h.sessionStarted.IsZero() {
+		duration = now.Sub(h.sessionStarted)
+	}
+	h.statisticsLock.Unlock()
+
+	speed := h.speedProvider.Get()
+	h.send(Event{
+		Type: ConnectionStatisticsEvent,
+		Payload: connectionStatisticsPayload{
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+			Duration:      int(duration.Seconds()),
+			UploadSpeed:   speed.Up.BitsPerSecond,
+			DownloadSpeed: speed.Down.BitsPerSecond,
+		},
+	})
+}
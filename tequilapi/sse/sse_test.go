@@ -23,13 +23,18 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/core/connection"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,8 +46,16 @@ func (msp *mockStateProvider) GetState() stateEvent.State {
 	return msp.stateToreturn
 }
 
+type mockSpeedProvider struct {
+	speed bandwidth.CurrentSpeed
+}
+
+func (msp *mockSpeedProvider) Get() bandwidth.CurrentSpeed {
+	return msp.speed
+}
+
 func TestHandler_Stops(t *testing.T) {
-	h := NewHandler(&mockStateProvider{})
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
 
 	wait := make(chan struct{})
 	go func() {
@@ -55,7 +68,7 @@ func TestHandler_Stops(t *testing.T) {
 }
 
 func TestHandler_ConsumeNodeEvent_Stops(t *testing.T) {
-	h := NewHandler(&mockStateProvider{})
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
 	me := nodeEvent.Payload{
 		Status: nodeEvent.StatusStopped,
 	}
@@ -64,7 +77,7 @@ func TestHandler_ConsumeNodeEvent_Stops(t *testing.T) {
 }
 
 func TestHandler_ConsumeNodeEvent_Starts(t *testing.T) {
-	h := NewHandler(&mockStateProvider{})
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
 	me := nodeEvent.Payload{
 		Status: nodeEvent.StatusStarted,
 	}
@@ -72,15 +85,15 @@ func TestHandler_ConsumeNodeEvent_Starts(t *testing.T) {
 	h.ConsumeNodeEvent(me)
 
 	// without starting, this would block forever
-	h.newClients <- make(chan string)
-	h.newClients <- make(chan string)
+	h.newClients <- &client{messages: make(chan string)}
+	h.newClients <- &client{messages: make(chan string)}
 
 	h.stop()
 }
 
 func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 	msp := &mockStateProvider{}
-	h := NewHandler(msp)
+	h := NewHandler(msp, &mockSpeedProvider{}, DefaultStatisticsInterval)
 	go h.serve()
 	defer h.stop()
 	laddr := net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 0}
@@ -122,19 +135,160 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 	}()
 
 	initialState := <-results
-	assert.Equal(t, `data: {"payload":{"natStatus":{"status":"","error":""},"serviceInfo":null,"sessions":null},"type":"state-change"}`, initialState)
+	assert.Equal(t, `data: {"payload":{"natStatus":{"status":"","error":"","type":""},"serviceInfo":null,"sessions":null},"type":"state-change"}`, initialState)
 
 	changedState := msp.GetState()
 	changedState.NATStatus = stateEvent.NATStatus{
 		Status: "mass panic",
 		Error:  "cookie prices rise drastically",
+		Type:   "symmetric",
 	}
 	h.ConsumeStateEvent(changedState)
 
 	newState := <-results
-	assert.Equal(t, `data: {"payload":{"natStatus":{"status":"mass panic","error":"cookie prices rise drastically"},"serviceInfo":null,"sessions":null},"type":"state-change"}`, newState)
+	assert.Equal(t, `data: {"payload":{"natStatus":{"status":"mass panic","error":"cookie prices rise drastically","type":"symmetric"},"serviceInfo":null,"sessions":null},"type":"state-change"}`, newState)
 	cancel()
 	listener.Close()
 
 	<-serveExit
 }
+
+func TestHandler_SendsProposalEvents(t *testing.T) {
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
+	proposal := market.ServiceProposal{ProviderID: "0x1", ServiceType: "openvpn"}
+
+	h.ConsumeProposalAdded(proposal)
+	assert.Equal(
+		t,
+		`{"payload":{"id":0,"format":"","service_type":"openvpn","service_definition":null,"payment_method_type":"","payment_method":null,"provider_id":"0x1","provider_contacts":[]},"type":"proposal-added"}`,
+		(<-h.messages).data,
+	)
+
+	h.ConsumeProposalRemoved(proposal)
+	assert.Equal(
+		t,
+		`{"payload":{"id":0,"format":"","service_type":"openvpn","service_definition":null,"payment_method_type":"","payment_method":null,"provider_id":"0x1","provider_contacts":[]},"type":"proposal-removed"}`,
+		(<-h.messages).data,
+	)
+}
+
+func TestHandler_SendsConnectionEvents(t *testing.T) {
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
+	sessionInfo := connection.SessionInfo{
+		SessionID: "session-1",
+		Proposal:  market.ServiceProposal{ProviderID: "0x1", ServiceType: "openvpn"},
+	}
+
+	h.ConsumeConnectionStateEvent(connection.StateEvent{ConnectionID: connection.DefaultConnectionID, State: connection.Connected, SessionInfo: sessionInfo})
+	assert.Equal(
+		t,
+		`{"payload":{"state":"Connected","sessionId":"session-1","providerId":"0x1","serviceType":"openvpn"},"type":"connection-state"}`,
+		(<-h.messages).data,
+	)
+
+	h.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionCreatedStatus, SessionInfo: sessionInfo})
+	assert.Equal(
+		t,
+		`{"payload":{"status":"Created","sessionId":"session-1","providerId":"0x1","serviceType":"openvpn"},"type":"session"}`,
+		(<-h.messages).data,
+	)
+}
+
+func TestHandler_SkipsEventsOfOtherConnections(t *testing.T) {
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
+
+	h.ConsumeConnectionStateEvent(connection.StateEvent{ConnectionID: "second", State: connection.Connected})
+	h.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: "second", Status: connection.SessionCreatedStatus})
+	h.ConsumeStatisticsEvent(connection.StatisticsEvent{ConnectionID: "second"})
+
+	assert.Len(t, h.messages, 0)
+}
+
+func defaultStatistics(stats consumer.SessionStatistics) connection.StatisticsEvent {
+	return connection.StatisticsEvent{ConnectionID: connection.DefaultConnectionID, Stats: stats}
+}
+
+func TestHandler_ThrottlesStatistics(t *testing.T) {
+	speed := &mockSpeedProvider{}
+	h := NewHandler(&mockStateProvider{}, speed, time.Second)
+	now := time.Unix(100, 0)
+	h.now = func() time.Time { return now }
+
+	h.ConsumeSessionEvent(connection.SessionEvent{ConnectionID: connection.DefaultConnectionID, Status: connection.SessionCreatedStatus})
+	<-h.messages
+
+	now = now.Add(3 * time.Second)
+	speed.speed = bandwidth.CurrentSpeed{Up: bandwidth.Throughput{BitsPerSecond: 80}, Down: bandwidth.Throughput{BitsPerSecond: 160}}
+	h.ConsumeStatisticsEvent(defaultStatistics(consumer.SessionStatistics{BytesSent: 10, BytesReceived: 20}))
+	assert.Equal(
+		t,
+		`{"payload":{"bytesSent":10,"bytesReceived":20,"duration":3,"uploadSpeed":80,"downloadSpeed":160},"type":"connection-statistics"}`,
+		(<-h.messages).data,
+	)
+
+	now = now.Add(500 * time.Millisecond)
+	h.ConsumeStatisticsEvent(defaultStatistics(consumer.SessionStatistics{BytesSent: 15, BytesReceived: 25}))
+	assert.Len(t, h.messages, 0)
+
+	now = now.Add(500 * time.Millisecond)
+	h.ConsumeStatisticsEvent(defaultStatistics(consumer.SessionStatistics{BytesSent: 20, BytesReceived: 30}))
+	assert.Equal(
+		t,
+		`{"payload":{"bytesSent":20,"bytesReceived":30,"duration":4,"uploadSpeed":80,"downloadSpeed":160},"type":"connection-statistics"}`,
+		(<-h.messages).data,
+	)
+}
+
+func TestHandler_DisconnectsSlowClients(t *testing.T) {
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
+	go h.serve()
+	defer h.stop()
+
+	slow := &client{messages: make(chan string, 1)}
+	fast := &client{messages: make(chan string, 2)}
+	h.newClients <- slow
+	h.newClients <- fast
+
+	h.ConsumeProposalAdded(market.ServiceProposal{ProviderID: "0x1"})
+	h.ConsumeProposalAdded(market.ServiceProposal{ProviderID: "0x2"})
+
+	assert.Contains(t, <-fast.messages, "0x1")
+	assert.Contains(t, <-fast.messages, "0x2")
+	assert.Contains(t, <-slow.messages, "0x1")
+	_, open := <-slow.messages
+	assert.False(t, open)
+}
+
+func TestHandler_SendsOnlySubscribedEvents(t *testing.T) {
+	events, err := parseEventTypes("connection-state, connection-statistics")
+	assert.NoError(t, err)
+	c := &client{events: events}
+	assert.True(t, c.wants(ConnectionStateEvent))
+	assert.True(t, c.wants(ConnectionStatisticsEvent))
+	assert.False(t, c.wants(StateChangeEvent))
+
+	events, err = parseEventTypes("")
+	assert.NoError(t, err)
+	c = &client{events: events}
+	assert.True(t, c.wants(StateChangeEvent))
+}
+
+func TestHandler_RejectsUnknownEventTypes(t *testing.T) {
+	h := NewHandler(&mockStateProvider{}, &mockSpeedProvider{}, DefaultStatisticsInterval)
+	req := httptest.NewRequest(http.MethodGet, "/whatever?events=connection-state,weather", nil)
+	resp := httptest.NewRecorder()
+
+	h.Sub(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"events": [ {"code": "invalid", "message": "unknown event type \"weather\""} ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
@@ -28,7 +28,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/mysteriumnetwork/node/core/auth"
-	"github.com/mysteriumnetwork/node/tequilapi/endpoints"
+	"github.com/mysteriumnetwork/node/tequilapi"
 )
 
 func buildTransport() *http.Transport {
@@ -66,7 +66,7 @@ func buildReverseProxy(bindAddress string, transport *http.Transport, tequilapiP
 }
 
 // ReverseTequilapiProxy proxies UIServer requests to the TequilAPI server
-func ReverseTequilapiProxy(bindAddress string, tequilapiPort int, authenticator jwtAuthenticator) gin.HandlerFunc {
+func ReverseTequilapiProxy(bindAddress string, tequilapiPort int, authorizer authorizer) gin.HandlerFunc {
 	proxy := buildReverseProxy(bindAddress, buildTransport(), tequilapiPort)
 
 	return func(c *gin.Context) {
@@ -75,16 +75,15 @@ func ReverseTequilapiProxy(bindAddress string, tequilapiPort int, authenticator
 			return
 		}
 
-		// authenticate all but the login route
-		if !isTequilapiURL(c.Request.URL.Path, endpoints.TequilapiLoginEndpointPath) {
-			cookieToken, err := c.Cookie(auth.JWTCookieName)
-
-			if err != nil {
-				c.AbortWithStatus(http.StatusUnauthorized)
+		// check the scope of all but the public routes, credentials are always required here
+		tequilapiPath := strings.Replace(c.Request.URL.Path, tequilapiUrlPrefix, "", 1)
+		if required, protected := tequilapi.RouteScope(c.Request.Method, tequilapiPath); protected {
+			err := authorizer.Authorize(c.Request, required)
+			if err == auth.ErrForbidden {
+				c.AbortWithStatus(http.StatusForbidden)
 				return
 			}
-
-			if _, err := authenticator.ValidateToken(cookieToken); err != nil {
+			if err != nil {
 				c.AbortWithStatus(http.StatusUnauthorized)
 				return
 			}
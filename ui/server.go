@@ -29,6 +29,7 @@ import (
 	godvpnweb "github.com/mysteriumnetwork/go-dvpn-web"
 	"github.com/pkg/errors"
 
+	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/ui/discovery"
 )
 
@@ -41,17 +42,17 @@ type Server struct {
 	discovery discovery.LANDiscovery
 }
 
-type jwtAuthenticator interface {
-	ValidateToken(token string) (bool, error)
+type authorizer interface {
+	Authorize(req *http.Request, required auth.Scope) error
 }
 
 // NewServer creates a new instance of the server for the given port
-func NewServer(bindAddress string, port int, tequilapiPort int, authenticator jwtAuthenticator) *Server {
+func NewServer(bindAddress string, port int, tequilapiPort int, authorizer authorizer) *Server {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
 	r.Use(cors.Default())
-	r.NoRoute(ReverseTequilapiProxy(bindAddress, tequilapiPort, authenticator))
+	r.NoRoute(ReverseTequilapiProxy(bindAddress, tequilapiPort, authorizer))
 
 	r.StaticFS("/", godvpnweb.Assets)
 
@@ -22,19 +22,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/html"
 )
 
-type jwtAuth struct {
+type authorizerMock struct {
 }
 
-func (j *jwtAuth) ValidateToken(token string) (bool, error) {
-	return false, nil
+func (a *authorizerMock) Authorize(req *http.Request, required auth.Scope) error {
+	return auth.ErrUnauthorized
 }
 
 func Test_Server_ServesHTML(t *testing.T) {
-	s := NewServer("localhost", 55555, 55554, &jwtAuth{})
+	s := NewServer("localhost", 55555, 55554, &authorizerMock{})
 	s.discovery = &mockDiscovery{}
 	serverError := make(chan error)
 	go func() {